@@ -18,6 +18,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	m "github.com/RHEnVision/provisioning-backend/internal/middleware"
 	"github.com/RHEnVision/provisioning-backend/internal/notifications"
+	"github.com/RHEnVision/provisioning-backend/internal/progress"
 	"github.com/RHEnVision/provisioning-backend/internal/queue/jq"
 	"github.com/RHEnVision/provisioning-backend/internal/routes"
 	s "github.com/RHEnVision/provisioning-backend/internal/services"
@@ -65,6 +66,9 @@ func api() {
 	// initialize cache
 	cache.Initialize()
 
+	// publish reservation progress via the database (in-memory worker)
+	progress.Initialize()
+
 	// initialize platform kafka and notifications
 	if config.Kafka.Enabled {
 		err = kafka.InitializeKafkaBroker(ctx)
@@ -119,6 +123,8 @@ func api() {
 		Addr:    fmt.Sprintf(":%d", config.Application.Port),
 		Handler: rootRouter,
 	}
+	// reservation event streams would block the shutdown
+	apiServer.RegisterOnShutdown(s.StopReservationEvents)
 
 	metricsServer := http.Server{
 		Addr:    fmt.Sprintf(":%d", config.Prometheus.Port),
@@ -12,8 +12,10 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/background"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
 	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/RHEnVision/provisioning-backend/internal/notifications"
 	"github.com/RHEnVision/provisioning-backend/internal/queue/jq"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"github.com/go-chi/chi/v5"
@@ -34,11 +36,12 @@ func stats() {
 	tel := telemetry.Initialize(ctx, &log.Logger)
 	defer tel.Close(ctx)
 
-	// initialize the job queue but don't register any workers
+	// initialize the job queue, job types must be registered to enqueue expiry jobs
 	err := jq.Initialize(ctx, &logger)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error initializing job queue")
 	}
+	jq.RegisterJobs(&logger)
 
 	// metrics
 	logger.Info().Msgf("Starting new instance on port %d with prometheus on %d", config.Application.Port, config.Prometheus.Port)
@@ -81,6 +84,24 @@ func stats() {
 	}
 	defer db.Close()
 
+	// initialize platform kafka and notifications (expiry warnings)
+	if config.Kafka.Enabled {
+		err = kafka.InitializeKafkaBroker(ctx)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Unable to initialize the platform kafka")
+		}
+
+		if config.Application.Notifications.Enabled {
+			notifications.Initialize(ctx)
+		}
+	}
+
+	// the memory queue is not shared with workers, jobs enqueued by this process are processed here
+	if config.Worker.Queue == "memory" {
+		jq.StartDequeueLoop(ctx)
+		defer jq.StopDequeueLoop(ctx)
+	}
+
 	// initialize background goroutines
 	bgCtx, bgCancel := context.WithCancel(ctx)
 	background.InitializeStats(bgCtx)
@@ -12,24 +12,27 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http/sources"
 	"github.com/RHEnVision/provisioning-backend/internal/db"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/notifications"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"github.com/RHEnVision/provisioning-backend/internal/random"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
 
 	// Clients
 	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/azure"
 	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/ec2"
 	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/gcp"
 	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/image_builder"
-	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/sources"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 
 	"github.com/RHEnVision/provisioning-backend/internal/kafka"
 	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/RHEnVision/provisioning-backend/internal/progress"
 
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
@@ -43,6 +46,7 @@ const ChannelBuffer = 32
 
 type SourceInfo struct {
 	MessageContext      context.Context // Carries logger and identity
+	SourceID            string
 	Authentication      clients.Authentication
 	SourceApplicationID string
 }
@@ -84,6 +88,9 @@ func processMessage(msgCtx context.Context, message *kafka.GenericMessage) {
 		return
 	}
 
+	// Availability check is requested when the source changes, drop the cached authentication
+	invalidateAuthentication(ctx, sourceId)
+
 	// Fetch authentication from Sources
 	authentication, err := sourcesClient.GetAuthentication(ctx, sourceId)
 	if err != nil {
@@ -102,6 +109,7 @@ func processMessage(msgCtx context.Context, message *kafka.GenericMessage) {
 
 	s := SourceInfo{
 		MessageContext:      ctx,
+		SourceID:            sourceId,
 		Authentication:      *authentication,
 		SourceApplicationID: authentication.SourceApplictionID,
 	}
@@ -119,6 +127,40 @@ func processMessage(msgCtx context.Context, message *kafka.GenericMessage) {
 	}
 }
 
+// invalidateAuthentication deletes the cached authentication of the source, errors are only logged.
+func invalidateAuthentication(ctx context.Context, sourceId string) {
+	err := sources.InvalidateAuthentication(ctx, sourceId)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Could not invalidate cached authentication")
+	}
+}
+
+// setMissingPermissions marks the source unavailable when the permission check found missing
+// permissions. The list is empty when the check itself failed, the error is then only logged and
+// the source is kept available so a transient error does not disable a working source.
+func setMissingPermissions(logger *zerolog.Logger, sr *kafka.SourceResult, err error, permissions []string, provider string) {
+	if len(permissions) == 0 {
+		logger.Warn().Err(err).Str("source_id", sr.ResourceID).Msgf("Could not check %s permissions", provider)
+		return
+	}
+
+	sr.Status = kafka.StatusUnavailable
+	sr.Err = err
+	sr.UserError = fmt.Sprintf("Missing %s permissions %s", provider, strings.Join(permissions, ", "))
+	sr.MissingPermissions = permissions
+	logger.Info().Err(err).
+		Array(fmt.Sprintf("missing_%s_permissions", strings.ToLower(provider)), permissionsArray(permissions)).
+		Str("source_id", sr.ResourceID).Msgf("Missing %s permissions", provider)
+}
+
+func permissionsArray(permissions []string) *zerolog.Array {
+	arr := zerolog.Arr()
+	for _, p := range permissions {
+		arr.Str(p)
+	}
+	return arr
+}
+
 func checkSourceAvailabilityAzure(cancelCtx context.Context) {
 	defer processingWG.Done()
 
@@ -155,8 +197,16 @@ func checkSourceAvailabilityAzure(cancelCtx context.Context) {
 					logger.Info().Err(err).Str("source_id", sr.ResourceID).Msg("Failed to fetch subscription's tenant from Azure")
 				} else {
 					sr.Status = kafka.StatusAvailable
+					var permissions []string
+					permissions, err = azureClient.CheckPermission(ctx)
+					if err != nil {
+						setMissingPermissions(logger, &sr, err, permissions, "Azure")
+					}
 				}
 			}
+			if sr.Status == kafka.StatusUnavailable {
+				invalidateAuthentication(ctx, s.SourceID)
+			}
 			chSend <- sr
 			metrics.IncTotalSentAvailabilityCheckReqs(models.ProviderTypeAzure.String(), sr.Status.String(), nil)
 
@@ -203,21 +253,12 @@ func checkSourceAvailabilityAWS(cancelCtx context.Context) {
 				sr.Status = kafka.StatusAvailable
 				permissions, err = ec2Client.CheckPermission(ctx, &s.Authentication)
 				if err != nil {
-					sr.Status = kafka.StatusUnavailable
-					sr.Err = err
-					sr.UserError = fmt.Sprintf("Missing AWS permissions %s", strings.Join(permissions, ", "))
-					sr.MissingPermissions = permissions
-					if logger.Info().Enabled() {
-						arr := zerolog.Arr()
-						for _, p := range permissions {
-							arr.Str(p)
-						}
-						logger.Info().Err(err).
-							Array("missing_aws_permissions", arr).
-							Str("source_id", sr.ResourceID).Msg("Missing AWS permissions")
-					}
+					setMissingPermissions(logger, &sr, err, permissions, "AWS")
 				}
 			}
+			if sr.Status == kafka.StatusUnavailable {
+				invalidateAuthentication(ctx, s.SourceID)
+			}
 			chSend <- sr
 			metrics.IncTotalSentAvailabilityCheckReqs(models.ProviderTypeAWS.String(), sr.Status.String(), err)
 			return fmt.Errorf("error during check: %w", err)
@@ -258,19 +299,23 @@ func checkSourceAvailabilityGCP(cancelCtx context.Context) {
 				sr.Err = err
 				sr.UserError = "Could not list log into GCP account"
 				logger.Warn().Err(err).Msg("Could not get gcp client")
-				chSend <- sr
-			}
-			_, err = gcpClient.ListAllRegions(ctx)
-			if err != nil {
+			} else if _, err = gcpClient.ListAllRegions(ctx); err != nil {
 				sr.Status = kafka.StatusUnavailable
 				sr.Err = err
 				sr.UserError = "Could not list gcp regions using the provided source"
 				logger.Warn().Err(err).Msg("Could not list gcp regions")
-				chSend <- sr
 			} else {
 				sr.Status = kafka.StatusAvailable
-				chSend <- sr
+				var permissions []string
+				permissions, err = gcpClient.CheckPermission(ctx)
+				if err != nil {
+					setMissingPermissions(logger, &sr, err, permissions, "GCP")
+				}
+			}
+			if sr.Status == kafka.StatusUnavailable {
+				invalidateAuthentication(ctx, s.SourceID)
 			}
+			chSend <- sr
 			metrics.IncTotalSentAvailabilityCheckReqs(models.ProviderTypeGCP.String(), sr.Status.String(), err)
 
 			return fmt.Errorf("error during check: %w", err)
@@ -408,6 +453,9 @@ func statuser() {
 
 	metrics.RegisterStatuserMetrics()
 
+	// initialize cache, authentications are invalidated on source changes
+	cache.Initialize()
+
 	// initialize the database
 	logger.Debug().Msg("Initializing database connection")
 	err := db.Initialize(ctx, "public")
@@ -416,6 +464,17 @@ func statuser() {
 	}
 	defer db.Close()
 
+	// publish progress of reservations failed on source deletion
+	progress.Initialize()
+
+	// start the sources event stream consumer, it needs the database. There is no consumer group,
+	// events published while the statuser is not running are not processed.
+	receiverWG.Add(1)
+	go func() {
+		defer receiverWG.Done()
+		kafka.Consume(cancelCtx, kafka.SourcesEventStreamTopic, time.Now(), services.ProcessSourcesEvent)
+	}()
+
 	// start processing goroutines
 	processingWG.Add(3)
 
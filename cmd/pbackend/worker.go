@@ -17,6 +17,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
 	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	"github.com/RHEnVision/provisioning-backend/internal/notifications"
+	"github.com/RHEnVision/provisioning-backend/internal/progress"
 	"github.com/RHEnVision/provisioning-backend/internal/queue/jq"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"github.com/go-chi/chi/v5"
@@ -54,6 +55,9 @@ func worker() {
 	}
 	defer db.Close()
 
+	// publish reservation progress via the database
+	progress.Initialize()
+
 	// initialize platform kafka and notifications
 	if config.Kafka.Enabled {
 		err = kafka.InitializeKafkaBroker(ctx)
@@ -30,3 +30,14 @@ var ResponseErrorUserFriendlyExample = payloads.ResponseError{
 	Version:   "df8a489",
 	BuildTime: "2023-04-14_17:15:02",
 }
+
+var ResponseTooManyRequestsErrorExample = payloads.ResponseError{
+	Message:    "Limit exceeded: reservations_per_second (maximum 5)",
+	TraceId:    "b57f7b78c",
+	Error:      "pgx tx error: rate limit exceeded: reservations_per_second maximum is 5",
+	Version:    "df8a489",
+	BuildTime:  "2023-04-14_17:15:02",
+	Limit:      "reservations_per_second",
+	Maximum:    5,
+	RetryAfter: 1,
+}
@@ -16,17 +16,27 @@ import (
 // addPayloads - MAKE SURE THE TYPE HAS JSON/YAML Go STRUCT TAGS (or "map key XXX not found" error occurs)
 func addPayloads(gen *APISchemaGen) {
 	gen.addSchema("v1.PubkeyRequest", &payloads.PubkeyRequest{})
+	gen.addSchema("v1.PubkeyUpdateRequest", &payloads.PubkeyUpdateRequest{})
+	gen.addSchema("v1.PubkeyGenerateRequest", &payloads.PubkeyGenerateRequest{})
+	gen.addSchema("v1.PubkeyGenerateResponse", &payloads.PubkeyGenerateResponse{})
 	gen.addSchema("v1.PubkeyResponse", &payloads.PubkeyResponse{})
+	gen.addSchema("v1.PubkeyDeleteResponse", &payloads.PubkeyDeleteResponse{})
 	gen.addSchema("v1.SourceResponse", &payloads.SourceResponse{})
 	gen.addSchema("v1.InstanceTypeResponse", &payloads.InstanceTypeResponse{})
 	gen.addSchema("v1.GenericReservationResponse", &payloads.GenericReservationResponse{})
 	gen.addSchema("v1.NoopReservationResponse", &payloads.NoopReservationResponse{})
+	gen.addSchema("v1.ReservationEventResponse", &payloads.ReservationEventResponse{})
 	gen.addSchema("v1.AWSReservationRequest", &payloads.AWSReservationRequest{})
 	gen.addSchema("v1.AWSReservationResponse", &payloads.AWSReservationResponse{})
 	gen.addSchema("v1.AzureReservationRequest", &payloads.AzureReservationRequest{})
 	gen.addSchema("v1.AzureReservationResponse", &payloads.AzureReservationResponse{})
 	gen.addSchema("v1.GCPReservationRequest", &payloads.GCPReservationRequest{})
 	gen.addSchema("v1.GCPReservationResponse", &payloads.GCPReservationResponse{})
+	gen.addSchema("v1.PreflightResponse", &payloads.PreflightResponse{})
+	gen.addSchema("v1.QuotaListResponse", &payloads.QuotaListResponse{})
+	gen.addSchema("v1.NetworkListResponse", &payloads.NetworkListResponse{})
+	gen.addSchema("v1.InstanceActionRequest", &payloads.InstanceActionRequest{})
+	gen.addSchema("v1.InstanceActionResponse", &payloads.InstanceActionResponse{})
 	gen.addSchema("v1.AvailabilityStatusRequest", &payloads.AvailabilityStatusRequest{})
 	gen.addSchema("v1.AccountIDTypeResponse", &payloads.AccountIdentityResponse{})
 	gen.addSchema("v1.SourceUploadInfoResponse", &payloads.SourceUploadInfoResponse{})
@@ -71,6 +81,7 @@ func addParameters(gen *APISchemaGen) {
 	gen.addQueryParameter("Limit", LimitQueryParam)
 	gen.addQueryParameter("Offset", OffsetQueryParam)
 	gen.addQueryParameter("Token", TokenQueryParam)
+	gen.addQueryParameter("SortDir", SortDirQueryParam)
 }
 
 // addErrorSchemas all generic errors, that can be returned.
@@ -82,6 +93,7 @@ func addErrorSchemas(gen *APISchemaGen) {
 	gen.addResponse("NotFound", "The requested resource was not found", "#/components/schemas/v1.ResponseError", ResponseNotFoundErrorExample)
 	gen.addResponse("InternalError", "The server encountered an internal error", "#/components/schemas/v1.ResponseError", ResponseErrorGenericExample)
 	gen.addResponse("BadRequest", "The request's parameters are not valid", "#/components/schemas/v1.ResponseError", ResponseBadRequestErrorExample)
+	gen.addResponse("TooManyRequests", "Account limit was exceeded, retry after the time from Retry-After header", "#/components/schemas/v1.ResponseError", ResponseTooManyRequestsErrorExample)
 }
 
 type APISchemaGen struct {
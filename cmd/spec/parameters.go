@@ -35,3 +35,12 @@ var TokenQueryParam = Parameter{
 	Required:    false,
 	In:          "query",
 }
+
+var SortDirQueryParam = Parameter{
+	Name:        "sort_dir",
+	Description: "The sort direction: asc or desc.",
+	Default:     "asc",
+	Type:        "string",
+	Required:    false,
+	In:          "query",
+}
@@ -38,4 +38,9 @@ func cleanupReservations(ctx context.Context) {
 	if err != nil {
 		logger.Error().Err(err).Msg("Error while performing reservation cleanup")
 	}
+
+	err = sdao.CleanupResources(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error while performing reservation resources cleanup")
+	}
 }
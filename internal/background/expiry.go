@@ -0,0 +1,122 @@
+package background
+
+import (
+	"context"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/notifications"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+// Maximum amount of reservations processed in one expiry tick.
+const expiryBatchSize = 100
+
+func expiryLoop(ctx context.Context, sleep time.Duration) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Started reservation expiry %s", sleep.String())
+	defer func() {
+		logger.Debug().Msgf("Reservation expiry routine exited")
+	}()
+
+	ticker := time.NewTicker(sleep)
+
+	expireReservations(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			expireReservations(ctx)
+
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func expireReservations(ctx context.Context) {
+	notifyExpiring(ctx)
+	enqueueExpired(ctx)
+}
+
+// accountContext returns context with the account and system identity of the reservation owner.
+func accountContext(ctx context.Context, reservation *models.Reservation) (context.Context, error) {
+	account, err := dao.GetAccountDao(ctx).GetById(ctx, reservation.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = identity.WithAccountId(ctx, account.ID)
+	ctx = identity.WithIdentity(ctx, identity.NewSystemPrincipal(account.OrgID, account.AccountNumber.String))
+	return ctx, nil
+}
+
+func notifyExpiring(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	rDao := dao.GetReservationDao(ctx)
+	reservations, err := rDao.UnscopedListExpiring(ctx, config.Reservation.ExpiryWarning, expiryBatchSize)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to list expiring reservations")
+		return
+	}
+
+	for _, reservation := range reservations {
+		accCtx, err := accountContext(ctx, reservation)
+		if err != nil {
+			logger.Error().Err(err).Int64("reservation_id", reservation.ID).Msg("Unable to find account of expiring reservation")
+			continue
+		}
+
+		notifications.GetNotificationClient(accCtx).ExpiringLaunch(accCtx, reservation.ID, reservation.ExpiresAt.Time)
+
+		err = rDao.UnscopedMarkExpiryNotified(ctx, reservation.ID)
+		if err != nil {
+			logger.Error().Err(err).Int64("reservation_id", reservation.ID).Msg("Unable to mark reservation expiry as notified")
+		}
+	}
+}
+
+func enqueueExpired(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	rDao := dao.GetReservationDao(ctx)
+	reservations, err := rDao.UnscopedListExpired(ctx, expiryBatchSize)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to list expired reservations")
+		return
+	}
+
+	for _, reservation := range reservations {
+		accCtx, err := accountContext(ctx, reservation)
+		if err != nil {
+			logger.Error().Err(err).Int64("reservation_id", reservation.ID).Msg("Unable to find account of expired reservation")
+			continue
+		}
+
+		job := worker.Job{
+			Type:      jobs.TypeExpireReservation,
+			Identity:  identity.Identity(accCtx),
+			AccountID: reservation.AccountID,
+			Args: jobs.ExpireReservationTaskArgs{
+				ReservationID: reservation.ID,
+			},
+		}
+		err = queue.GetEnqueuer(accCtx).Enqueue(accCtx, &job)
+		if err != nil {
+			logger.Error().Err(err).Int64("reservation_id", reservation.ID).Msg("Unable to enqueue expire reservation job")
+			continue
+		}
+
+		// only mark after the job is enqueued so a failed enqueue is retried in the next tick
+		err = rDao.UnscopedMarkExpired(ctx, reservation.ID)
+		if err != nil {
+			logger.Error().Err(err).Int64("reservation_id", reservation.ID).Msg("Unable to mark reservation as expired")
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/progress"
 	"github.com/rs/zerolog"
 )
 
@@ -22,6 +23,9 @@ func InitializeApi(ctx context.Context) {
 
 	// start availability request batch sender
 	go sendAvailabilityRequestMessages(ctx, availabilityStatusBatchSize, 2*time.Second)
+
+	// start reservation progress listener for streaming clients
+	go progress.Listen(ctx)
 }
 
 // InitializeWorker starts background goroutines for worker processes.
@@ -46,4 +50,7 @@ func InitializeStats(ctx context.Context) {
 	if config.Reservation.CleanupEnabled {
 		go dbCleanup(ctx, config.Reservation.CleanupInterval)
 	}
+
+	// notify about and terminate expired reservations
+	go expiryLoop(ctx, config.Reservation.ExpiryInterval)
 }
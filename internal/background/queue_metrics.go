@@ -25,8 +25,9 @@ func jobQueueMetricLoop(ctx context.Context, sleep time.Duration, name string) {
 		select {
 		case <-ticker.C:
 			stats := jq.Stats(ctx)
-			logger.Debug().Msgf("Job queue statistics: enqueued=%d, in-flight=%d", stats.EnqueuedJobs, stats.InFlight)
+			logger.Debug().Msgf("Job queue statistics: enqueued=%d, in-flight=%d, dead=%d", stats.EnqueuedJobs, stats.InFlight, stats.DeadJobs)
 			metrics.SetJobQueueSize(stats.EnqueuedJobs)
+			metrics.SetJobQueueDeadSize(stats.DeadJobs)
 			metrics.SetJobQueueInFlight(name, stats.InFlight)
 
 		case <-ctx.Done():
@@ -0,0 +1,192 @@
+// Package cache provides application cache based on Redis or an in-memory store. This feature
+// can be turned off via configuration and in that case function Find return ErrNotFound and
+// functions Set do nothing.
+//
+// Values are serialized with encoding/gob in both backends, the in-memory store therefore never
+// shares pointers with callers and behaves the same way as Redis.
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrNotFound = errors.New("not found in cache")
+	ErrNilValue = errors.New("value is nil")
+
+	// the backend, nil when cache is disabled via configuration
+	store backend
+)
+
+// Forever is used for items that should be cached "forever". Expiration of 30 days
+// is used to allow cleanup of unused items.
+const Forever = 24 * time.Hour * 30
+
+type Cacheable interface {
+	CacheKeyName() string
+}
+
+// backend stores serialized values
+type backend interface {
+	// get returns the value or ErrNotFound when not present or expired
+	get(ctx context.Context, key string) ([]byte, error)
+
+	// set creates or updates the value
+	set(ctx context.Context, key string, value []byte, expiration time.Duration) error
+
+	// del deletes the value, missing values are ignored
+	del(ctx context.Context, key string) error
+
+	// close releases connections and background goroutines of the backend
+	close() error
+}
+
+// Initialize creates new Redis client or in-memory store if allowed by application config,
+// or does nothing. The previous backend is closed when called again.
+func Initialize() {
+	if store != nil {
+		if err := store.close(); err != nil {
+			log.Logger.Warn().Err(err).Bool("cache", true).Msg("Unable to close application cache")
+		}
+	}
+
+	switch config.Application.Cache.Type {
+	case "redis":
+		log.Logger.Info().Bool("cache", true).Msg("Initializing redis application cache")
+		store = newRedisBackend()
+	case "memory":
+		log.Logger.Info().Bool("cache", true).Msg("Initializing in-memory application cache")
+		store = newMemoryBackend(config.Application.Cache.Memory.CleanupInterval, config.Application.Cache.Memory.MaxEntries)
+	default:
+		log.Logger.Info().Bool("cache", true).Msg("No application cache in use")
+		store = nil
+		return
+	}
+
+	// register all Cacheable types
+	gob.Register(&models.Account{})
+	gob.Register(&clients.AccountDetailsAWS{})
+	gob.Register(&clients.AccessList{})
+	gob.Register(&clients.Authentication{})
+}
+
+// Shared returns true when the cache is shared by all processes, items which are invalidated by
+// other processes (e.g. the statuser) must not be cached otherwise.
+func Shared() bool {
+	_, ok := store.(*redisBackend)
+	return ok
+}
+
+// Find returns an item from cache. ErrNotFound is returned on cache miss or when
+// the item cannot be deserialized
+func Find(ctx context.Context, key string, value Cacheable) error {
+	if store == nil {
+		return ErrNotFound
+	}
+
+	if value == nil {
+		return ErrNilValue
+	}
+
+	prefix := value.CacheKeyName()
+	ctx, span := telemetry.StartSpan(ctx, "Find")
+	defer span.End()
+
+	buf, err := store.get(ctx, prefix+key)
+	if errors.Is(err, ErrNotFound) {
+		metrics.IncCacheHit(prefix, "miss")
+		return ErrNotFound
+	} else if err != nil {
+		metrics.IncCacheHit(prefix, "err")
+		return err
+	}
+
+	dec := gob.NewDecoder(bytes.NewReader(buf))
+
+	err = dec.Decode(value)
+	if err != nil {
+		// decode error can be thrown if previous cache entry was JSON-encoded, return not found to overwrite it
+		zerolog.Ctx(ctx).Warn().Err(err).Bool("cache", true).Msgf("Cache decode error: %s", err.Error())
+		metrics.IncCacheHit(prefix, "err")
+		return ErrNotFound
+	}
+
+	metrics.IncCacheHit(prefix, "hit")
+	zerolog.Ctx(ctx).Trace().Bool("cache", true).Msgf("Cache hit for key '%s%s' type %T", prefix, key, value)
+	return nil
+}
+
+// SetExpires calls Set with specific expiration.
+func SetExpires(ctx context.Context, key string, value Cacheable, expiration time.Duration) error {
+	if store == nil {
+		return nil
+	}
+
+	if value == nil {
+		return ErrNilValue
+	}
+
+	prefix := value.CacheKeyName()
+	ctx, span := telemetry.StartSpan(ctx, "Set")
+	defer span.End()
+
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+
+	err := enc.Encode(value)
+	if err != nil {
+		metrics.IncCacheHit(prefix, "err")
+		return fmt.Errorf("unable to encode for cache: %w", err)
+	}
+
+	err = store.set(ctx, prefix+key, buf.Bytes(), expiration)
+	if err != nil {
+		metrics.IncCacheHit(prefix, "err")
+		return err
+	}
+
+	return nil
+}
+
+// SetForever calls Set with Forever expiration duration.
+// nolint: wrapcheck
+func SetForever(ctx context.Context, key string, value Cacheable) error {
+	return SetExpires(ctx, key, value, Forever)
+}
+
+// Set creates or updates existing cache entry. It uses the default expiration duration
+// specified in the application configuration.
+// nolint: wrapcheck
+func Set(ctx context.Context, key string, value Cacheable) error {
+	return SetExpires(ctx, key, value, config.Application.Cache.Expiration)
+}
+
+// Delete removes an item from cache, the value is only used to determine the key prefix.
+// Deleting a missing item is not an error.
+func Delete(ctx context.Context, key string, value Cacheable) error {
+	if store == nil {
+		return nil
+	}
+
+	if value == nil {
+		return ErrNilValue
+	}
+
+	ctx, span := telemetry.StartSpan(ctx, "Delete")
+	defer span.End()
+
+	return store.del(ctx, value.CacheKeyName()+key)
+}
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	value   []byte
+	expires time.Time
+}
+
+func (i memoryItem) expired(now time.Time) bool {
+	return !i.expires.IsZero() && !now.Before(i.expires)
+}
+
+// memoryBackend is an in-process store for development and small deployments, it is not shared
+// between processes. Expired items are never returned and are deleted by a background goroutine.
+// When the store is full, the item which expires first is evicted to make room for a new one.
+type memoryBackend struct {
+	mu         sync.RWMutex
+	items      map[string]memoryItem
+	maxEntries int
+	stop       chan struct{}
+	stopOnce   sync.Once
+}
+
+// newMemoryBackend creates the store and starts cleanup of expired items every interval, zero
+// interval turns the cleanup off. Zero maxEntries means no limit. The cleanup goroutine
+// terminates when the store is closed.
+func newMemoryBackend(cleanupInterval time.Duration, maxEntries int) *memoryBackend {
+	b := &memoryBackend{
+		items:      make(map[string]memoryItem),
+		maxEntries: maxEntries,
+		stop:       make(chan struct{}),
+	}
+	if cleanupInterval > 0 {
+		go b.cleanupLoop(cleanupInterval)
+	}
+	return b
+}
+
+func (b *memoryBackend) get(_ context.Context, key string) ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	item, ok := b.items[key]
+	if !ok || item.expired(time.Now()) {
+		return nil, ErrNotFound
+	}
+	return item.value, nil
+}
+
+func (b *memoryBackend) set(_ context.Context, key string, value []byte, expiration time.Duration) error {
+	item := memoryItem{value: value}
+	if expiration > 0 {
+		item.expires = time.Now().Add(expiration)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.items[key]; !ok && b.maxEntries > 0 && len(b.items) >= b.maxEntries {
+		b.evict(time.Now())
+	}
+	b.items[key] = item
+	return nil
+}
+
+func (b *memoryBackend) del(_ context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.items, key)
+	return nil
+}
+
+func (b *memoryBackend) close() error {
+	b.stopOnce.Do(func() {
+		close(b.stop)
+	})
+	return nil
+}
+
+// cleanup deletes expired items
+func (b *memoryBackend) cleanup(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.deleteExpired(now)
+}
+
+func (b *memoryBackend) deleteExpired(now time.Time) {
+	for key, item := range b.items {
+		if item.expired(now) {
+			delete(b.items, key)
+		}
+	}
+}
+
+// evict deletes expired items, or the item which expires first when there are none. Items
+// without expiration are evicted last. Must be called with the lock held.
+func (b *memoryBackend) evict(now time.Time) {
+	count := len(b.items)
+	b.deleteExpired(now)
+	if len(b.items) < count {
+		return
+	}
+
+	var evictKey string
+	var evictItem memoryItem
+	found := false
+	for key, item := range b.items {
+		if !found || evictItem.expires.IsZero() || (!item.expires.IsZero() && item.expires.Before(evictItem.expires)) {
+			evictKey, evictItem, found = key, item, true
+		}
+	}
+	if found {
+		delete(b.items, evictKey)
+	}
+}
+
+func (b *memoryBackend) cleanupLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			b.cleanup(now)
+		case <-b.stop:
+			return
+		}
+	}
+}
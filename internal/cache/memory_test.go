@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withMemoryStore(t *testing.T) *memoryBackend {
+	t.Helper()
+	memory := newMemoryBackend(0, 0)
+	store = memory
+	t.Cleanup(func() {
+		_ = memory.close()
+		store = nil
+	})
+	return memory
+}
+
+func TestMemoryFindSet(t *testing.T) {
+	withMemoryStore(t)
+	ctx := context.Background()
+
+	value := models.Account{ID: 42, OrgID: "442", AccountNumber: sql.NullString{}}
+	require.NoError(t, Set(ctx, "42", &value))
+
+	result := models.Account{}
+	require.NoError(t, Find(ctx, "42", &result))
+	assert.Equal(t, value, result)
+
+	// must not overwrite the account
+	details := clients.AccountDetailsAWS{AccountID: "1234"}
+	require.NoError(t, Set(ctx, "42", &details))
+	require.NoError(t, Find(ctx, "42", &result))
+	assert.Equal(t, value, result)
+
+	require.ErrorIs(t, Find(ctx, "43", &result), ErrNotFound)
+
+	require.NoError(t, Delete(ctx, "42", &models.Account{}))
+	require.ErrorIs(t, Find(ctx, "42", &result), ErrNotFound)
+	require.NoError(t, Find(ctx, "42", &details))
+}
+
+func TestMemoryExpiration(t *testing.T) {
+	memory := withMemoryStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, SetExpires(ctx, "1", &models.Account{ID: 1}, time.Millisecond))
+	require.NoError(t, SetForever(ctx, "2", &models.Account{ID: 2}))
+	time.Sleep(5 * time.Millisecond)
+
+	result := models.Account{}
+	require.ErrorIs(t, Find(ctx, "1", &result), ErrNotFound)
+	require.NoError(t, Find(ctx, "2", &result))
+	assert.Equal(t, int64(2), result.ID)
+
+	memory.cleanup(time.Now())
+	assert.Len(t, memory.items, 1)
+}
+
+func TestMemoryMaxEntries(t *testing.T) {
+	memory := withMemoryStore(t)
+	memory.maxEntries = 2
+	ctx := context.Background()
+
+	require.NoError(t, SetForever(ctx, "1", &models.Account{ID: 1}))
+	require.NoError(t, SetExpires(ctx, "2", &models.Account{ID: 2}, time.Minute))
+	require.NoError(t, SetExpires(ctx, "2", &models.Account{ID: 2}, time.Minute))
+	assert.Len(t, memory.items, 2)
+
+	// evicts the item which expires first
+	require.NoError(t, SetExpires(ctx, "3", &models.Account{ID: 3}, time.Hour))
+	assert.Len(t, memory.items, 2)
+
+	result := models.Account{}
+	require.NoError(t, Find(ctx, "1", &result))
+	require.ErrorIs(t, Find(ctx, "2", &result), ErrNotFound)
+	require.NoError(t, Find(ctx, "3", &result))
+}
+
+func TestMemoryClose(t *testing.T) {
+	memory := newMemoryBackend(time.Millisecond, 0)
+	require.NoError(t, memory.close())
+	require.NoError(t, memory.close())
+}
@@ -62,3 +62,7 @@ func (auth *Authentication) MustBe(providerType models.ProviderType) error {
 func (auth *Authentication) String() string {
 	return auth.Payload
 }
+
+func (auth Authentication) CacheKeyName() string {
+	return "authentication"
+}
@@ -15,4 +15,6 @@ var (
 
 	ErrUnexpectedBackendResponse = usrerr.New(500, "backend service returned unexpected HTTP code", "unexpected backend data")
 	ErrNoResponseData            = usrerr.New(500, "no data in response", "missing backend data")
+
+	ErrCapacityUnavailable = usrerr.New(503, "insufficient capacity of the cloud provider", "")
 )
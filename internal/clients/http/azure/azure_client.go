@@ -56,6 +56,22 @@ func (c *client) newVirtualMachinesClient(ctx context.Context) (*armcompute.Virt
 	return vmClient, nil
 }
 
+func (c *client) newDisksClient(ctx context.Context) (*armcompute.DisksClient, error) {
+	diskClient, err := armcompute.NewDisksClient(c.subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create disks Azure client: %w", err)
+	}
+	return diskClient, nil
+}
+
+func (c *client) newUsageClient(ctx context.Context) (*armcompute.UsageClient, error) {
+	usageClient, err := armcompute.NewUsageClient(c.subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create usage Azure client: %w", err)
+	}
+	return usageClient, nil
+}
+
 func (c *client) newSubscriptionsClient(ctx context.Context) (*armsubscriptions.Client, error) {
 	client, err := armsubscriptions.NewClient(c.credential, nil)
 	if err != nil {
@@ -18,6 +18,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"go.opentelemetry.io/otel/codes"
@@ -65,9 +66,95 @@ func (c *client) BeginCreateVM(ctx context.Context, networkInterface *armnetwork
 		return "", fmt.Errorf("cannot generate Azure resume token: %w", err)
 	}
 
+	// disks are recorded first so they are deleted after the machine they are attached to
+	for _, diskName := range diskNames(vmName, len(vmParams.DataDisks)) {
+		vmParams.RecordResource(models.ResourceTypeAzureDisk, c.resourceID(vmParams.ResourceGroupName, "Microsoft.Compute/disks", diskName))
+	}
+	vmParams.RecordResource(models.ResourceTypeAzureVM, c.resourceID(vmParams.ResourceGroupName, "Microsoft.Compute/virtualMachines", vmName))
+
 	return resumeToken, nil
 }
 
+// tagDisks sets tags of the disks, disks created together with the VM do not inherit VM tags.
+func (c *client) tagDisks(ctx context.Context, resourceGroupName string, names []string, tags map[string]*string) error {
+	ctx, span := telemetry.StartSpan(ctx, "tagDisks")
+	defer span.End()
+
+	diskClient, err := c.newDisksClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		poller, err := diskClient.BeginUpdate(ctx, resourceGroupName, name, armcompute.DiskUpdate{Tags: tags}, nil)
+		if err != nil {
+			span.SetStatus(codes.Error, "cannot update disk tags")
+			return fmt.Errorf("update of disk tags failed to start: %w", err)
+		}
+
+		_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{
+			Frequency: resourcePollFrequency,
+		})
+		if err != nil {
+			span.SetStatus(codes.Error, "failed to poll for disk tags update")
+			return fmt.Errorf("failed to poll for disk tags update: %w", err)
+		}
+	}
+	return nil
+}
+
+func osDiskName(vmName string) string {
+	return vmName + "_disk"
+}
+
+func dataDiskName(vmName string, lun int) string {
+	return fmt.Sprintf("%s_data%d", vmName, lun)
+}
+
+// diskNames returns names of the OS disk and data disks of the VM
+func diskNames(vmName string, dataDisks int) []string {
+	names := []string{osDiskName(vmName)}
+	for lun := 0; lun < dataDisks; lun++ {
+		names = append(names, dataDiskName(vmName, lun))
+	}
+	return names
+}
+
+// dataDisks returns empty managed disks deleted together with the VM
+func dataDisks(vmParams clients.AzureInstanceParams, vmName string) []*armcompute.DataDisk {
+	disks := make([]*armcompute.DataDisk, 0, len(vmParams.DataDisks))
+	for lun, disk := range vmParams.DataDisks {
+		storageType := disk.VolumeType
+		if storageType == "" {
+			storageType = storageAccountType(vmParams)
+		}
+		disks = append(disks, &armcompute.DataDisk{
+			Lun:          to.Ptr(int32(lun)),
+			Name:         ptr.To(dataDiskName(vmName, lun)),
+			CreateOption: to.Ptr(armcompute.DiskCreateOptionTypesEmpty),
+			DiskSizeGB:   to.Ptr(int32(disk.SizeGB)),
+			DeleteOption: to.Ptr(armcompute.DiskDeleteOptionTypesDelete),
+			ManagedDisk: &armcompute.ManagedDiskParameters{
+				StorageAccountType: to.Ptr(armcompute.StorageAccountTypes(storageType)),
+			},
+		})
+	}
+	return disks
+}
+
+// storageAccountType returns the requested OS disk type or Standard HDD
+func storageAccountType(vmParams clients.AzureInstanceParams) string {
+	if vmParams.VolumeType != "" {
+		return vmParams.VolumeType
+	}
+	return string(armcompute.StorageAccountTypesStandardLRS)
+}
+
+// resourceID returns full Azure resource ID of a resource in the resource group
+func (c *client) resourceID(resourceGroupName, resourceType, name string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s/%s", c.subscriptionID, resourceGroupName, resourceType, name)
+}
+
 func (c *client) WaitForVM(ctx context.Context, resumeToken string) (clients.AzureInstanceID, error) {
 	ctx, span := telemetry.StartSpan(ctx, "WaitForVM")
 	defer span.End()
@@ -133,28 +220,57 @@ func (c *client) ensureSharedNetworking(ctx context.Context, location, resourceG
 	return subnet, nsg, nil
 }
 
+// vmNetworking returns subnet and network security group for instances: the subnet and group
+// from the parameters or the shared network created by ensureSharedNetworking. Network security group
+// is nil for custom subnets without a group, rules of the subnet group apply.
+func (c *client) vmNetworking(ctx context.Context, vmParams clients.AzureInstanceParams) (*armnetwork.Subnet, *armnetwork.SecurityGroup, error) {
+	var nsg *armnetwork.SecurityGroup
+	if vmParams.SecurityGroupID != "" {
+		nsg = &armnetwork.SecurityGroup{ID: ptr.To(vmParams.SecurityGroupID)}
+	}
+
+	if vmParams.SubnetID != "" {
+		return &armnetwork.Subnet{ID: ptr.To(vmParams.SubnetID)}, nsg, nil
+	}
+
+	subnet, sharedNsg, err := c.ensureSharedNetworking(ctx, vmParams.Location, vmParams.ResourceGroupName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if nsg == nil {
+		nsg = sharedNsg
+	}
+	return subnet, nsg, nil
+}
+
 func (c *client) prepareVMNetworking(ctx context.Context, subnet *armnetwork.Subnet, securityGroup *armnetwork.SecurityGroup, vmParams clients.AzureInstanceParams, vmName string) (*armnetwork.Interface, *armnetwork.PublicIPAddress, error) {
 	ctx, span := telemetry.StartSpan(ctx, "prepareVMNetworking")
 	defer span.End()
 
 	logger := logger(ctx)
 
-	publicIPName := vmName + "_ip"
-	publicIP, err := c.createPublicIP(ctx, vmParams.Location, vmParams.ResourceGroupName, publicIPName)
-	if err != nil {
-		span.SetStatus(codes.Error, "cannot create public IP address")
-		logger.Error().Err(err).Msg("cannot create public IP address")
-		return nil, nil, err
+	var publicIP *armnetwork.PublicIPAddress
+	if !vmParams.NoPublicIP {
+		var err error
+		publicIPName := vmName + "_ip"
+		publicIP, err = c.createPublicIP(ctx, vmParams.Location, vmParams.ResourceGroupName, publicIPName, vmParams.Tags)
+		if err != nil {
+			span.SetStatus(codes.Error, "cannot create public IP address")
+			logger.Error().Err(err).Msg("cannot create public IP address")
+			return nil, nil, err
+		}
+		logger.Trace().Msgf("Using public IP address id=%s", *publicIP.ID)
+		vmParams.RecordResource(models.ResourceTypeAzurePublicIP, *publicIP.ID)
 	}
-	logger.Trace().Msgf("Using public IP address id=%s", *publicIP.ID)
 	nicName := vmName + "_nic"
-	networkInterface, err := c.createNetworkInterface(ctx, vmParams.Location, vmParams.ResourceGroupName, subnet, publicIP, securityGroup, nicName)
+	networkInterface, err := c.createNetworkInterface(ctx, vmParams.Location, vmParams.ResourceGroupName, subnet, publicIP, securityGroup, nicName, vmParams.Tags)
 	if err != nil {
 		span.SetStatus(codes.Error, "cannot create network interface")
 		logger.Error().Err(err).Msg("cannot create network interface")
 		return nil, publicIP, err
 	}
 	logger.Trace().Msgf("Using network interface id=%s", *networkInterface.ID)
+	vmParams.RecordResource(models.ResourceTypeAzureNIC, *networkInterface.ID)
 	return networkInterface, publicIP, nil
 }
 
@@ -343,7 +459,7 @@ func (c *client) createNetworkSecurityGroup(ctx context.Context, location string
 	return &resp.SecurityGroup, nil
 }
 
-func (c *client) createPublicIP(ctx context.Context, location string, resourceGroupName string, name string) (*armnetwork.PublicIPAddress, error) {
+func (c *client) createPublicIP(ctx context.Context, location string, resourceGroupName string, name string, tags map[string]*string) (*armnetwork.PublicIPAddress, error) {
 	ctx, span := telemetry.StartSpan(ctx, "createPublicIP")
 	defer span.End()
 
@@ -354,6 +470,7 @@ func (c *client) createPublicIP(ctx context.Context, location string, resourceGr
 
 	parameters := armnetwork.PublicIPAddress{
 		Location: to.Ptr(location),
+		Tags:     tags,
 		Properties: &armnetwork.PublicIPAddressPropertiesFormat{
 			PublicIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodStatic), // Static or Dynamic
 		},
@@ -373,7 +490,7 @@ func (c *client) createPublicIP(ctx context.Context, location string, resourceGr
 	return &resp.PublicIPAddress, nil
 }
 
-func (c *client) createNetworkInterface(ctx context.Context, location string, resourceGroupName string, subnet *armnetwork.Subnet, publicIP *armnetwork.PublicIPAddress, nsg *armnetwork.SecurityGroup, name string) (*armnetwork.Interface, error) {
+func (c *client) createNetworkInterface(ctx context.Context, location string, resourceGroupName string, subnet *armnetwork.Subnet, publicIP *armnetwork.PublicIPAddress, nsg *armnetwork.SecurityGroup, name string, tags map[string]*string) (*armnetwork.Interface, error) {
 	ctx, span := telemetry.StartSpan(ctx, "createNetworkInterface")
 	defer span.End()
 
@@ -384,6 +501,7 @@ func (c *client) createNetworkInterface(ctx context.Context, location string, re
 
 	parameters := armnetwork.Interface{
 		Location: to.Ptr(location),
+		Tags:     tags,
 		Properties: &armnetwork.InterfacePropertiesFormat{
 			IPConfigurations: []*armnetwork.InterfaceIPConfiguration{
 				{
@@ -393,17 +511,21 @@ func (c *client) createNetworkInterface(ctx context.Context, location string, re
 						Subnet: &armnetwork.Subnet{
 							ID: subnet.ID,
 						},
-						PublicIPAddress: &armnetwork.PublicIPAddress{
-							ID: publicIP.ID,
-						},
 					},
 				},
 			},
-			NetworkSecurityGroup: &armnetwork.SecurityGroup{
-				ID: nsg.ID,
-			},
 		},
 	}
+	if publicIP != nil {
+		parameters.Properties.IPConfigurations[0].Properties.PublicIPAddress = &armnetwork.PublicIPAddress{
+			ID: publicIP.ID,
+		}
+	}
+	if nsg != nil {
+		parameters.Properties.NetworkSecurityGroup = &armnetwork.SecurityGroup{
+			ID: nsg.ID,
+		}
+	}
 
 	pollerResponse, err := nicClient.BeginCreateOrUpdate(ctx, resourceGroupName, name, parameters, nil)
 	if err != nil {
@@ -424,7 +546,7 @@ func (c *client) prepareVirtualMachineParameters(vmParams clients.AzureInstanceP
 	userDataEncoded := make([]byte, base64.StdEncoding.EncodedLen(len(vmParams.UserData)))
 	base64.StdEncoding.Encode(userDataEncoded, vmParams.UserData)
 
-	return &armcompute.VirtualMachine{
+	vm := &armcompute.VirtualMachine{
 		Location: to.Ptr(vmParams.Location),
 		Identity: &armcompute.VirtualMachineIdentity{
 			Type: to.Ptr(armcompute.ResourceIdentityTypeNone),
@@ -436,14 +558,14 @@ func (c *client) prepareVirtualMachineParameters(vmParams clients.AzureInstanceP
 					ID: ptr.To(vmParams.ImageID),
 				},
 				OSDisk: &armcompute.OSDisk{
-					// Name:         ptr.To(vmName + "_disk1"),
+					Name:         ptr.To(osDiskName(vmName)),
 					CreateOption: to.Ptr(armcompute.DiskCreateOptionTypesFromImage),
 					Caching:      to.Ptr(armcompute.CachingTypesReadWrite),
 					ManagedDisk: &armcompute.ManagedDiskParameters{
-						StorageAccountType: to.Ptr(armcompute.StorageAccountTypesStandardLRS), // OSDisk type Standard/Premium HDD/SSD
+						StorageAccountType: to.Ptr(armcompute.StorageAccountTypes(storageAccountType(vmParams))), // OSDisk type Standard/Premium HDD/SSD
 					},
-					// DiskSizeGB: to.Ptr[int32](100), // default 127G
 				},
+				DataDisks: dataDisks(vmParams, vmName),
 			},
 			HardwareProfile: &armcompute.HardwareProfile{
 				VMSize: to.Ptr(armcompute.VirtualMachineSizeTypes(vmParams.InstanceType)), // VM size include vCPUs,RAM,Data Disks,Temp storage.
@@ -474,4 +596,18 @@ func (c *client) prepareVirtualMachineParameters(vmParams clients.AzureInstanceP
 			UserData: to.Ptr(string(userDataEncoded)),
 		},
 	}
+	if vmParams.RootVolumeGB > 0 {
+		vm.Properties.StorageProfile.OSDisk.DiskSizeGB = to.Ptr(int32(vmParams.RootVolumeGB))
+	}
+	if vmParams.Spot {
+		// -1 caps the price at the pay-as-you-go price, the VM is not evicted for price reasons
+		maxPrice := float64(-1)
+		if vmParams.SpotMaxPrice > 0 {
+			maxPrice = vmParams.SpotMaxPrice
+		}
+		vm.Properties.Priority = to.Ptr(armcompute.VirtualMachinePriorityTypesSpot)
+		vm.Properties.EvictionPolicy = to.Ptr(armcompute.VirtualMachineEvictionPolicyTypesDelete)
+		vm.Properties.BillingProfile = &armcompute.BillingProfile{MaxPrice: to.Ptr(maxPrice)}
+	}
+	return vm
 }
@@ -0,0 +1,58 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataDisks(t *testing.T) {
+	t.Run("none", func(t *testing.T) {
+		disks := dataDisks(clients.AzureInstanceParams{}, "redhat-vm-1")
+		assert.Empty(t, disks)
+	})
+
+	t.Run("volume types", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			volumeType string
+			expected   armcompute.StorageAccountTypes
+		}{
+			{"default", "", armcompute.StorageAccountTypesStandardLRS},
+			{"reservation type", "Premium_LRS", armcompute.StorageAccountTypesPremiumLRS},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				disks := dataDisks(clients.AzureInstanceParams{
+					VolumeType: tt.volumeType,
+					DataDisks: []models.DataDisk{
+						{SizeGB: 100},
+						{SizeGB: 500, VolumeType: "StandardSSD_LRS"},
+					},
+				}, "redhat-vm-1")
+
+				require.Len(t, disks, 2)
+				for lun, disk := range disks {
+					assert.Equal(t, int32(lun), *disk.Lun)
+					assert.Equal(t, armcompute.DiskCreateOptionTypesEmpty, *disk.CreateOption)
+					assert.Equal(t, armcompute.DiskDeleteOptionTypesDelete, *disk.DeleteOption, "Expected data disks to be deleted with the VM")
+				}
+				assert.Equal(t, "redhat-vm-1_data0", *disks[0].Name)
+				assert.Equal(t, int32(100), *disks[0].DiskSizeGB)
+				assert.Equal(t, tt.expected, *disks[0].ManagedDisk.StorageAccountType)
+				assert.Equal(t, "redhat-vm-1_data1", *disks[1].Name)
+				assert.Equal(t, int32(500), *disks[1].DiskSizeGB)
+				assert.Equal(t, armcompute.StorageAccountTypesStandardSSDLRS, *disks[1].ManagedDisk.StorageAccountType)
+			})
+		}
+	})
+}
+
+func TestDiskNames(t *testing.T) {
+	assert.Equal(t, []string{"redhat-vm-1_disk"}, diskNames("redhat-vm-1", 0))
+	assert.Equal(t, []string{"redhat-vm-1_disk", "redhat-vm-1_data0", "redhat-vm-1_data1"}, diskNames("redhat-vm-1", 2))
+}
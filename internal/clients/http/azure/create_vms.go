@@ -2,8 +2,11 @@ package azure
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
@@ -18,13 +21,14 @@ func (c *client) CreateVMs(ctx context.Context, vmParams clients.AzureInstancePa
 	logger := logger(ctx)
 	logger.Debug().Msgf("Started creating %d Azure VM instances", amount)
 
-	subnet, nsg, err := c.ensureSharedNetworking(ctx, vmParams.Location, vmParams.ResourceGroupName)
+	subnet, nsg, err := c.vmNetworking(ctx, vmParams)
 	if err != nil {
 		return nil, err
 	}
 
 	vmDescriptions := make([]clients.InstanceDescription, amount)
 	resumeTokens := make([]string, amount)
+	vmNames := make([]string, amount)
 	var i int64
 	for i = 0; i < amount; i++ {
 		uid, err := uuid.NewUUID()
@@ -32,19 +36,22 @@ func (c *client) CreateVMs(ctx context.Context, vmParams clients.AzureInstancePa
 			return vmDescriptions, fmt.Errorf("could not generate a new UUID: %w", err)
 		}
 		vmName := fmt.Sprintf("%s-%s", vmNamePrefix, uid.String())
+		vmNames[i] = vmName
 
 		networkInterface, publicIP, err := c.prepareVMNetworking(ctx, subnet, nsg, vmParams, vmName)
 		if err != nil {
 			return nil, err
 		}
 
-		vmDescriptions[i].IPv4 = ptr.FromOrEmpty(publicIP.Properties.IPAddress)
+		if publicIP != nil {
+			vmDescriptions[i].IPv4 = ptr.FromOrEmpty(publicIP.Properties.IPAddress)
+		}
 		vmDescriptions[i].PrivateIPv4 = ptr.FromOrEmpty(networkInterface.Properties.IPConfigurations[0].Properties.PrivateIPAddress)
 
 		resumeTokens[i], err = c.BeginCreateVM(ctx, networkInterface, vmParams, vmName)
 		if err != nil {
 			span.SetStatus(codes.Error, "failed to start creation of Azure instance")
-			return vmDescriptions, fmt.Errorf("cannot start a create of Azure instance(s): %w", err)
+			return vmDescriptions, fmt.Errorf("cannot start a create of Azure instance(s): %w", capacityError(err))
 		}
 	}
 
@@ -52,13 +59,37 @@ func (c *client) CreateVMs(ctx context.Context, vmParams clients.AzureInstancePa
 		instanceId, err := c.WaitForVM(ctx, token)
 		if err != nil {
 			span.SetStatus(codes.Error, "failed to create Azure instance")
-			return vmDescriptions, fmt.Errorf("cannot create Azure instance(s): %w", err)
+			return vmDescriptions, fmt.Errorf("cannot create Azure instance(s): %w", capacityError(err))
 		}
 		vmDescriptions[j].ID = string(instanceId)
 		logger.Debug().Msgf("Created new instance (%s) via Azure CreateVM", string(instanceId))
+
+		// tags are not essential for the instance, do not fail the launch
+		if err = c.tagDisks(ctx, vmParams.ResourceGroupName, diskNames(vmNames[j], len(vmParams.DataDisks)), vmParams.Tags); err != nil {
+			logger.Warn().Err(err).Msgf("Unable to tag disks of instance %s", string(instanceId))
+		}
 	}
 
 	logger.Debug().Msgf("Created %d new instance", amount)
 
 	return vmDescriptions, nil
 }
+
+// azureCapacityErrorCodes are returned when Azure cannot allocate the VMs at the moment
+var azureCapacityErrorCodes = []string{
+	"AllocationFailed",
+	"ZonalAllocationFailed",
+	"OverconstrainedAllocationRequest",
+	"OverconstrainedZonalAllocationRequest",
+	"SkuNotAvailable",
+	"SpotMaxPriceIsLowerThanCurrentPrice",
+}
+
+// capacityError wraps errors caused by missing Azure capacity with clients.ErrCapacityUnavailable
+func capacityError(err error) error {
+	var azErr *azcore.ResponseError
+	if errors.As(err, &azErr) && slices.Contains(azureCapacityErrorCodes, azErr.ErrorCode) {
+		return fmt.Errorf("%w: %w", clients.ErrCapacityUnavailable, err)
+	}
+	return err
+}
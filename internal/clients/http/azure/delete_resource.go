@@ -0,0 +1,85 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func (c *client) DeleteResource(ctx context.Context, id string) error {
+	ctx, span := telemetry.StartSpan(ctx, "DeleteResource")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Debug().Msgf("Deleting Azure resource %s", id)
+
+	resourceID, err := arm.ParseResourceID(id)
+	if err != nil {
+		span.SetStatus(codes.Error, "unable to parse Azure resource id")
+		return fmt.Errorf("unable to parse Azure resource id %s: %w", id, err)
+	}
+
+	rg, name := resourceID.ResourceGroupName, resourceID.Name
+	pollOptions := &runtime.PollUntilDoneOptions{Frequency: resourcePollFrequency}
+	switch resourceID.ResourceType.String() {
+	case "Microsoft.Compute/virtualMachines":
+		vmClient, clientErr := c.newVirtualMachinesClient(ctx)
+		if clientErr != nil {
+			return clientErr
+		}
+		poller, beginErr := vmClient.BeginDelete(ctx, rg, name, nil)
+		if err = beginErr; err == nil {
+			_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: vmPollFrequency})
+		}
+	case "Microsoft.Compute/disks":
+		diskClient, clientErr := c.newDisksClient(ctx)
+		if clientErr != nil {
+			return clientErr
+		}
+		poller, beginErr := diskClient.BeginDelete(ctx, rg, name, nil)
+		if err = beginErr; err == nil {
+			_, err = poller.PollUntilDone(ctx, pollOptions)
+		}
+	case "Microsoft.Network/networkInterfaces":
+		nicClient, clientErr := c.newInterfacesClient(ctx)
+		if clientErr != nil {
+			return clientErr
+		}
+		poller, beginErr := nicClient.BeginDelete(ctx, rg, name, nil)
+		if err = beginErr; err == nil {
+			_, err = poller.PollUntilDone(ctx, pollOptions)
+		}
+	case "Microsoft.Network/publicIPAddresses":
+		publicIPClient, clientErr := c.newPublicIPAddressesClient(ctx)
+		if clientErr != nil {
+			return clientErr
+		}
+		poller, beginErr := publicIPClient.BeginDelete(ctx, rg, name, nil)
+		if err = beginErr; err == nil {
+			_, err = poller.PollUntilDone(ctx, pollOptions)
+		}
+	default:
+		span.SetStatus(codes.Error, "unsupported Azure resource type")
+		return fmt.Errorf("%w: %s", httpClients.ErrUnknownAzureResource, resourceID.ResourceType.String())
+	}
+
+	if err != nil {
+		var azErr *azcore.ResponseError
+		if errors.As(err, &azErr) && azErr.StatusCode == http.StatusNotFound {
+			logger.Debug().Msgf("Azure resource %s not found, nothing to delete", id)
+			return nil
+		}
+		span.SetStatus(codes.Error, "failed to delete Azure resource")
+		return fmt.Errorf("failed to delete Azure resource %s: %w", name, err)
+	}
+
+	return nil
+}
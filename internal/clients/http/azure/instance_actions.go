@@ -0,0 +1,221 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// vmOperation performs a long-running operation on a single VM and waits until it is done
+type vmOperation func(ctx context.Context, vmClient *armcompute.VirtualMachinesClient, resourceGroupName, vmName string) error
+
+// forEachVM parses every instance ID (full Azure resource ID) and performs the operation on it
+func (c *client) forEachVM(ctx context.Context, instanceIds []string, op vmOperation) error {
+	vmClient, err := c.newVirtualMachinesClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, instanceId := range instanceIds {
+		resourceID, err := arm.ParseResourceID(instanceId)
+		if err != nil {
+			return fmt.Errorf("unable to parse Azure instance id %s: %w", instanceId, err)
+		}
+
+		err = op(ctx, vmClient, resourceID.ResourceGroupName, resourceID.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *client) StartInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := telemetry.StartSpan(ctx, "StartInstances")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Debug().Msgf("Starting %d Azure VM instances", len(instanceIds))
+
+	err := c.forEachVM(ctx, instanceIds, func(ctx context.Context, vmClient *armcompute.VirtualMachinesClient, resourceGroupName, vmName string) error {
+		poller, err := vmClient.BeginStart(ctx, resourceGroupName, vmName, nil)
+		if err != nil {
+			return fmt.Errorf("start of virtual machine %s failed to start: %w", vmName, err)
+		}
+		_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: vmPollFrequency})
+		if err != nil {
+			return fmt.Errorf("failed to poll for start virtual machine %s status: %w", vmName, err)
+		}
+		return nil
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to start Azure instance")
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) StopInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := telemetry.StartSpan(ctx, "StopInstances")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Debug().Msgf("Deallocating %d Azure VM instances", len(instanceIds))
+
+	err := c.forEachVM(ctx, instanceIds, func(ctx context.Context, vmClient *armcompute.VirtualMachinesClient, resourceGroupName, vmName string) error {
+		poller, err := vmClient.BeginDeallocate(ctx, resourceGroupName, vmName, nil)
+		if err != nil {
+			return fmt.Errorf("deallocation of virtual machine %s failed to start: %w", vmName, err)
+		}
+		_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: vmPollFrequency})
+		if err != nil {
+			return fmt.Errorf("failed to poll for deallocate virtual machine %s status: %w", vmName, err)
+		}
+		return nil
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to stop Azure instance")
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) RebootInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := telemetry.StartSpan(ctx, "RebootInstances")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Debug().Msgf("Restarting %d Azure VM instances", len(instanceIds))
+
+	err := c.forEachVM(ctx, instanceIds, func(ctx context.Context, vmClient *armcompute.VirtualMachinesClient, resourceGroupName, vmName string) error {
+		poller, err := vmClient.BeginRestart(ctx, resourceGroupName, vmName, nil)
+		if err != nil {
+			return fmt.Errorf("restart of virtual machine %s failed to start: %w", vmName, err)
+		}
+		_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: vmPollFrequency})
+		if err != nil {
+			return fmt.Errorf("failed to poll for restart virtual machine %s status: %w", vmName, err)
+		}
+		return nil
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to reboot Azure instance")
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) TerminateInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := telemetry.StartSpan(ctx, "TerminateInstances")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Debug().Msgf("Deleting %d Azure VM instances", len(instanceIds))
+
+	err := c.forEachVM(ctx, instanceIds, func(ctx context.Context, vmClient *armcompute.VirtualMachinesClient, resourceGroupName, vmName string) error {
+		getResp, err := vmClient.Get(ctx, resourceGroupName, vmName, nil)
+		if err != nil {
+			var azErr *azcore.ResponseError
+			if errors.As(err, &azErr) && azErr.StatusCode == http.StatusNotFound {
+				logger.Debug().Msgf("Virtual machine %s not found, nothing to delete", vmName)
+				return nil
+			}
+			return fmt.Errorf("cannot get virtual machine %s: %w", vmName, err)
+		}
+
+		// dependent resources must be found before the machine and its interfaces are gone
+		dependents, err := c.vmDependentResources(ctx, &getResp.VirtualMachine)
+		if err != nil {
+			return err
+		}
+
+		poller, err := vmClient.BeginDelete(ctx, resourceGroupName, vmName, nil)
+		if err != nil {
+			return fmt.Errorf("delete of virtual machine %s failed to start: %w", vmName, err)
+		}
+		_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: vmPollFrequency})
+		if err != nil {
+			return fmt.Errorf("failed to poll for delete virtual machine %s status: %w", vmName, err)
+		}
+
+		for _, id := range dependents {
+			if err = c.DeleteResource(ctx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to terminate Azure instance")
+		return err
+	}
+
+	return nil
+}
+
+// vmDependentResources returns IDs of network interfaces, public IP addresses and managed disks of
+// the machine in the order they can be deleted once the machine is gone.
+func (c *client) vmDependentResources(ctx context.Context, vm *armcompute.VirtualMachine) ([]string, error) {
+	if vm.Properties == nil {
+		return nil, nil
+	}
+
+	var nics, publicIPs, disks []string
+	if profile := vm.Properties.NetworkProfile; profile != nil {
+		nicClient, err := c.newInterfacesClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ref := range profile.NetworkInterfaces {
+			nicID := ptr.From(ref.ID)
+			if nicID == "" {
+				continue
+			}
+			nics = append(nics, nicID)
+
+			resourceID, err := arm.ParseResourceID(nicID)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse Azure network interface id %s: %w", nicID, err)
+			}
+			nicResp, err := nicClient.Get(ctx, resourceID.ResourceGroupName, resourceID.Name, nil)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get network interface %s: %w", resourceID.Name, err)
+			}
+			if nicResp.Properties == nil {
+				continue
+			}
+			for _, ipConfig := range nicResp.Properties.IPConfigurations {
+				if ipConfig.Properties != nil && ipConfig.Properties.PublicIPAddress != nil && ipConfig.Properties.PublicIPAddress.ID != nil {
+					publicIPs = append(publicIPs, *ipConfig.Properties.PublicIPAddress.ID)
+				}
+			}
+		}
+	}
+
+	if profile := vm.Properties.StorageProfile; profile != nil {
+		if profile.OSDisk != nil && profile.OSDisk.ManagedDisk != nil && profile.OSDisk.ManagedDisk.ID != nil {
+			disks = append(disks, *profile.OSDisk.ManagedDisk.ID)
+		}
+		for _, dataDisk := range profile.DataDisks {
+			if dataDisk.ManagedDisk != nil && dataDisk.ManagedDisk.ID != nil {
+				disks = append(disks, *dataDisk.ManagedDisk.ID)
+			}
+		}
+	}
+
+	return append(append(nics, publicIPs...), disks...), nil
+}
@@ -0,0 +1,103 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ListNetworks returns virtual networks of the subscription in the location. Network security groups
+// are not bound to networks, all groups of the location are listed for every network.
+func (c *client) ListNetworks(ctx context.Context, location string) ([]*clients.Network, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ListNetworks")
+	defer span.End()
+
+	securityGroups, err := c.listSecurityGroups(ctx, location)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	vnetClient, err := c.newVirtualNetworksClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	networks := make([]*clients.Network, 0)
+	pager := vnetClient.NewListAllPager(nil)
+	for pager.More() {
+		page, pagerErr := pager.NextPage(ctx)
+		if pagerErr != nil {
+			span.SetStatus(codes.Error, pagerErr.Error())
+			return nil, fmt.Errorf("failed to fetch virtual networks: %w", pagerErr)
+		}
+		for _, vnet := range page.Value {
+			if !sameLocation(ptr.FromOrEmpty(vnet.Location), location) {
+				continue
+			}
+			network := &clients.Network{
+				ID:             ptr.FromOrEmpty(vnet.ID),
+				Name:           ptr.FromOrEmpty(vnet.Name),
+				Subnets:        make([]*clients.Subnet, 0),
+				SecurityGroups: securityGroups,
+			}
+			if vnet.Properties != nil {
+				if vnet.Properties.AddressSpace != nil {
+					for _, prefix := range vnet.Properties.AddressSpace.AddressPrefixes {
+						network.CIDR = append(network.CIDR, ptr.FromOrEmpty(prefix))
+					}
+				}
+				for _, subnet := range vnet.Properties.Subnets {
+					var cidr string
+					if subnet.Properties != nil {
+						cidr = ptr.FromOrEmpty(subnet.Properties.AddressPrefix)
+					}
+					network.Subnets = append(network.Subnets, &clients.Subnet{
+						ID:   ptr.FromOrEmpty(subnet.ID),
+						Name: ptr.FromOrEmpty(subnet.Name),
+						CIDR: cidr,
+					})
+				}
+			}
+			networks = append(networks, network)
+		}
+	}
+
+	return networks, nil
+}
+
+func (c *client) listSecurityGroups(ctx context.Context, location string) ([]*clients.SecurityGroup, error) {
+	nsgClient, err := c.newSecurityGroupsClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]*clients.SecurityGroup, 0)
+	pager := nsgClient.NewListAllPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch network security groups: %w", err)
+		}
+		for _, nsg := range page.Value {
+			if !sameLocation(ptr.FromOrEmpty(nsg.Location), location) {
+				continue
+			}
+			groups = append(groups, &clients.SecurityGroup{
+				ID:   ptr.FromOrEmpty(nsg.ID),
+				Name: ptr.FromOrEmpty(nsg.Name),
+			})
+		}
+	}
+	return groups, nil
+}
+
+// sameLocation compares location names ignoring case and spaces ("East US" and "eastus")
+func sameLocation(a, b string) bool {
+	return strings.EqualFold(strings.ReplaceAll(a, " ", ""), strings.ReplaceAll(b, " ", ""))
+}
@@ -0,0 +1,157 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var ErrAzurePermissionsMissing = errors.New("Azure permissions missing")
+
+const permissionsAPIVersion = "2022-04-01"
+
+// requiredActions are Azure RBAC operations the service performs in the subscription
+var requiredActions = []string{
+	"Microsoft.Resources/subscriptions/resourceGroups/read",
+	"Microsoft.Resources/subscriptions/resourceGroups/write",
+	"Microsoft.Compute/images/read",
+	"Microsoft.Compute/virtualMachines/read",
+	"Microsoft.Compute/virtualMachines/write",
+	"Microsoft.Compute/virtualMachines/delete",
+	"Microsoft.Compute/virtualMachines/start/action",
+	"Microsoft.Compute/virtualMachines/deallocate/action",
+	"Microsoft.Compute/virtualMachines/restart/action",
+	"Microsoft.Compute/disks/write",
+	"Microsoft.Compute/disks/delete",
+	"Microsoft.Network/virtualNetworks/read",
+	"Microsoft.Network/virtualNetworks/write",
+	"Microsoft.Network/virtualNetworks/subnets/write",
+	"Microsoft.Network/virtualNetworks/subnets/join/action",
+	"Microsoft.Network/networkSecurityGroups/write",
+	"Microsoft.Network/networkSecurityGroups/join/action",
+	"Microsoft.Network/publicIPAddresses/write",
+	"Microsoft.Network/publicIPAddresses/delete",
+	"Microsoft.Network/publicIPAddresses/join/action",
+	"Microsoft.Network/networkInterfaces/write",
+	"Microsoft.Network/networkInterfaces/delete",
+	"Microsoft.Network/networkInterfaces/join/action",
+}
+
+// permission is an entry of the Microsoft.Authorization permissions API
+type permission struct {
+	Actions    []string `json:"actions"`
+	NotActions []string `json:"notActions"`
+}
+
+type permissionListResult struct {
+	Value    []permission `json:"value"`
+	NextLink string       `json:"nextLink"`
+}
+
+func (c *client) CheckPermission(ctx context.Context) ([]string, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CheckPermission")
+	defer span.End()
+
+	permissions, err := c.listPermissions(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot list permissions")
+		return nil, err
+	}
+
+	var missing []string
+	for _, action := range requiredActions {
+		if !actionAllowed(permissions, action) {
+			missing = append(missing, action)
+		}
+	}
+	if len(missing) != 0 {
+		return missing, fmt.Errorf("%w: %s", ErrAzurePermissionsMissing, strings.Join(missing, ", "))
+	}
+
+	return nil, nil
+}
+
+// listPermissions returns effective permissions of the service in the subscription. The authorization
+// SDK module is not used by the service, so the REST API is called through the ARM pipeline.
+func (c *client) listPermissions(ctx context.Context) ([]permission, error) {
+	armClient, err := arm.NewClient("provisioning", "v1.0.0", c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create ARM Azure client: %w", err)
+	}
+
+	var result []permission
+	next := runtime.JoinPaths(armClient.Endpoint(), "subscriptions", url.PathEscape(c.subscriptionID),
+		"providers/Microsoft.Authorization/permissions") + "?api-version=" + permissionsAPIVersion
+	for next != "" {
+		req, err := runtime.NewRequest(ctx, http.MethodGet, next)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create permissions request: %w", err)
+		}
+
+		resp, err := armClient.Pipeline().Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch permissions: %w", err)
+		}
+		if !runtime.HasStatusCode(resp, http.StatusOK) {
+			return nil, fmt.Errorf("failed to fetch permissions: %w", runtime.NewResponseError(resp))
+		}
+
+		var page permissionListResult
+		if err = runtime.UnmarshalAsJSON(resp, &page); err != nil {
+			return nil, fmt.Errorf("unable to parse permissions: %w", err)
+		}
+		result = append(result, page.Value...)
+		next = page.NextLink
+	}
+
+	return result, nil
+}
+
+// actionAllowed returns true when a permission grants the action and does not exclude it
+func actionAllowed(permissions []permission, action string) bool {
+	for _, p := range permissions {
+		if matchAnyAction(p.Actions, action) && !matchAnyAction(p.NotActions, action) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAnyAction(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		if matchAction(pattern, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAction matches an action against a case-insensitive pattern with * wildcards
+func matchAction(pattern, action string) bool {
+	pattern, action = strings.ToLower(pattern), strings.ToLower(action)
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == action
+	}
+
+	if !strings.HasPrefix(action, parts[0]) {
+		return false
+	}
+	action = action[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(action, part)
+		if i < 0 {
+			return false
+		}
+		action = action[i+len(part):]
+	}
+	return strings.HasSuffix(action, parts[len(parts)-1])
+}
@@ -0,0 +1,60 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchAction(t *testing.T) {
+	tests := []struct {
+		pattern string
+		action  string
+		match   bool
+	}{
+		{"*", "Microsoft.Compute/virtualMachines/write", true},
+		{"Microsoft.Compute/*", "Microsoft.Compute/virtualMachines/write", true},
+		{"Microsoft.Compute/*", "Microsoft.Network/networkInterfaces/write", false},
+		{"Microsoft.Compute/*/write", "Microsoft.Compute/disks/write", true},
+		{"Microsoft.Compute/*/write", "Microsoft.Compute/disks/delete", false},
+		{"*/read", "Microsoft.Compute/images/read", true},
+		{"Microsoft.Compute/virtualMachines/write", "Microsoft.Compute/virtualMachines/write", true},
+		{"Microsoft.Compute/virtualMachines/write", "Microsoft.Compute/virtualMachines/delete", false},
+		{"microsoft.compute/VIRTUALMACHINES/*", "Microsoft.Compute/virtualMachines/start/action", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.action, func(t *testing.T) {
+			assert.Equal(t, tt.match, matchAction(tt.pattern, tt.action))
+		})
+	}
+}
+
+func TestActionAllowed(t *testing.T) {
+	contributor := permission{
+		Actions:    []string{"*"},
+		NotActions: []string{"Microsoft.Authorization/*/Delete", "Microsoft.Compute/virtualMachines/delete"},
+	}
+	compute := permission{
+		Actions: []string{"Microsoft.Compute/*"},
+	}
+
+	tests := []struct {
+		name        string
+		permissions []permission
+		action      string
+		allowed     bool
+	}{
+		{"wildcard", []permission{contributor}, "Microsoft.Network/networkInterfaces/write", true},
+		{"excluded by NotActions", []permission{contributor}, "Microsoft.Compute/virtualMachines/delete", false},
+		{"NotActions are case-insensitive", []permission{contributor}, "microsoft.authorization/roleAssignments/delete", false},
+		{"granted by another permission", []permission{contributor, compute}, "Microsoft.Compute/virtualMachines/delete", true},
+		{"provider wildcard", []permission{compute}, "Microsoft.Compute/disks/write", true},
+		{"other provider", []permission{compute}, "Microsoft.Network/publicIPAddresses/write", false},
+		{"no permissions", nil, "Microsoft.Compute/disks/write", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, actionAllowed(tt.permissions, tt.action))
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// regionalCoresUsage is the name of the "Total Regional vCPUs" usage which applies to all VM sizes
+const regionalCoresUsage = "cores"
+
+// ListVCPUQuotas returns compute vCPU usages of the location: the regional total which applies to all
+// VM sizes and per VM family quotas. VM family is not known for sizes, family quotas are tagged
+// with the Azure family name (e.g. "standardDSv3Family") and never match instance types.
+func (c *client) ListVCPUQuotas(ctx context.Context, location string) ([]*clients.Quota, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ListVCPUQuotas")
+	defer span.End()
+
+	usageClient, err := c.newUsageClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas := make([]*clients.Quota, 0)
+	pager := usageClient.NewListPager(location, nil)
+	for pager.More() {
+		page, pagerErr := pager.NextPage(ctx)
+		if pagerErr != nil {
+			span.SetStatus(codes.Error, pagerErr.Error())
+			return nil, fmt.Errorf("failed to fetch compute usages: %w", pagerErr)
+		}
+		for _, usage := range page.Value {
+			if usage.Name == nil || usage.Name.Value == nil {
+				continue
+			}
+			name := *usage.Name.Value
+			quota := &clients.Quota{
+				Name:  ptr.FromOrEmpty(usage.Name.LocalizedValue),
+				Limit: ptr.FromOrEmpty(usage.Limit),
+				Usage: int64(ptr.FromOrEmpty(usage.CurrentValue)),
+			}
+			switch {
+			case name == regionalCoresUsage:
+			case strings.HasSuffix(name, "Family"):
+				quota.Families = []string{name}
+			default:
+				continue
+			}
+			quotas = append(quotas, quota)
+		}
+	}
+
+	return quotas, nil
+}
@@ -0,0 +1,45 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func (c *client) DeleteSSHKey(ctx context.Context, handle string) error {
+	ctx, span := telemetry.StartSpan(ctx, "DeleteSSHKey")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Debug().Msgf("Deleting Azure SSH public key %s", handle)
+
+	resourceID, err := arm.ParseResourceID(handle)
+	if err != nil {
+		span.SetStatus(codes.Error, "unable to parse Azure SSH key id")
+		return fmt.Errorf("unable to parse Azure SSH key id %s: %w", handle, err)
+	}
+
+	sshKeysClient, err := c.newSshKeysClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = sshKeysClient.Delete(ctx, resourceID.ResourceGroupName, resourceID.Name, nil)
+	if err != nil {
+		var azErr *azcore.ResponseError
+		if errors.As(err, &azErr) && azErr.StatusCode == http.StatusNotFound {
+			logger.Debug().Msgf("Azure SSH public key %s not found, nothing to delete", handle)
+			return nil
+		}
+		span.SetStatus(codes.Error, "failed to delete Azure SSH key")
+		return fmt.Errorf("failed to delete Azure SSH public key %s: %w", resourceID.Name, err)
+	}
+
+	return nil
+}
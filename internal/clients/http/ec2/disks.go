@@ -0,0 +1,80 @@
+package ec2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+var ErrRootDeviceNotFound = errors.New("root device name of the image not found")
+
+const (
+	// defaultDataVolumeType is used for data disks when no volume type was requested
+	defaultDataVolumeType = types.VolumeTypeGp3
+
+	// iopsPerGB is provisioned for io1 and io2 volumes, the maximum ratio of io1 volumes
+	iopsPerGB = 50
+)
+
+// blockDeviceMappings returns the root volume mapping followed by data disk mappings (/dev/sdf,
+// /dev/sdg...), or nil when the image defaults should be kept. The root device name is read from
+// the AMI.
+func (c *ec2Client) blockDeviceMappings(ctx context.Context, params *clients.AWSInstanceParams) ([]types.BlockDeviceMapping, error) {
+	var mappings []types.BlockDeviceMapping
+	if params.RootVolumeGB > 0 || params.VolumeType != "" {
+		if params.AMI == "" {
+			return nil, fmt.Errorf("%w: root volume cannot be configured without AMI", ErrRootDeviceNotFound)
+		}
+		resp, err := c.ec2.DescribeImages(ctx, &ec2.DescribeImagesInput{ImageIds: []string{params.AMI}})
+		if err != nil {
+			return nil, fmt.Errorf("cannot describe image %s: %w", params.AMI, err)
+		}
+		if len(resp.Images) == 0 || resp.Images[0].RootDeviceName == nil {
+			return nil, fmt.Errorf("%w: %s", ErrRootDeviceNotFound, params.AMI)
+		}
+		mappings = append(mappings, types.BlockDeviceMapping{
+			DeviceName: resp.Images[0].RootDeviceName,
+			Ebs:        ebsVolume(params.RootVolumeGB, types.VolumeType(params.VolumeType)),
+		})
+	}
+	return append(mappings, dataDiskMappings(params)...), nil
+}
+
+// dataDiskMappings returns mappings of empty volumes deleted together with the instance.
+func dataDiskMappings(params *clients.AWSInstanceParams) []types.BlockDeviceMapping {
+	mappings := make([]types.BlockDeviceMapping, 0, len(params.DataDisks))
+	for i, disk := range params.DataDisks {
+		volumeType := types.VolumeType(disk.VolumeType)
+		if volumeType == "" {
+			volumeType = types.VolumeType(params.VolumeType)
+		}
+		if volumeType == "" {
+			volumeType = defaultDataVolumeType
+		}
+		mappings = append(mappings, types.BlockDeviceMapping{
+			DeviceName: ptr.To(fmt.Sprintf("/dev/sd%c", 'f'+i)),
+			Ebs:        ebsVolume(disk.SizeGB, volumeType),
+		})
+	}
+	return mappings
+}
+
+// ebsVolume returns EBS parameters, zero size and empty type keep the snapshot values.
+func ebsVolume(sizeGB int64, volumeType types.VolumeType) *types.EbsBlockDevice {
+	ebs := &types.EbsBlockDevice{
+		DeleteOnTermination: ptr.To(true),
+		VolumeType:          volumeType,
+	}
+	if sizeGB > 0 {
+		ebs.VolumeSize = ptr.To(int32(sizeGB))
+	}
+	if volumeType == types.VolumeTypeIo1 || volumeType == types.VolumeTypeIo2 {
+		ebs.Iops = ptr.To(int32(min(max(sizeGB*iopsPerGB, 100), 64000)))
+	}
+	return ebs
+}
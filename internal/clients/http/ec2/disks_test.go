@@ -0,0 +1,39 @@
+package ec2
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataDiskMappings(t *testing.T) {
+	mappings := dataDiskMappings(&clients.AWSInstanceParams{
+		VolumeType: "io2",
+		DataDisks:  []models.DataDisk{{SizeGB: 100}, {SizeGB: 500, VolumeType: "st1"}},
+	})
+
+	assert.Len(t, mappings, 2)
+	assert.Equal(t, "/dev/sdf", *mappings[0].DeviceName)
+	assert.Equal(t, types.VolumeTypeIo2, mappings[0].Ebs.VolumeType)
+	assert.Equal(t, int32(100), *mappings[0].Ebs.VolumeSize)
+	assert.Equal(t, int32(5000), *mappings[0].Ebs.Iops)
+	assert.True(t, *mappings[0].Ebs.DeleteOnTermination)
+	assert.Equal(t, "/dev/sdg", *mappings[1].DeviceName)
+	assert.Equal(t, types.VolumeTypeSt1, mappings[1].Ebs.VolumeType)
+	assert.Nil(t, mappings[1].Ebs.Iops)
+
+	mappings = dataDiskMappings(&clients.AWSInstanceParams{DataDisks: []models.DataDisk{{SizeGB: 10}}})
+	assert.Equal(t, types.VolumeTypeGp3, mappings[0].Ebs.VolumeType)
+}
+
+func TestEBSVolume(t *testing.T) {
+	root := ebsVolume(0, "")
+	assert.Nil(t, root.VolumeSize)
+	assert.Empty(t, root.VolumeType)
+
+	assert.Equal(t, int32(100), *ebsVolume(1, types.VolumeTypeIo1).Iops)
+	assert.Equal(t, int32(64000), *ebsVolume(16384, types.VolumeTypeIo2).Iops)
+}
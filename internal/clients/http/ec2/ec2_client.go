@@ -12,6 +12,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/math"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/page"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
@@ -21,6 +22,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	stsTypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
 	"github.com/rs/zerolog"
@@ -31,6 +33,7 @@ type ec2Client struct {
 	ec2     *ec2.Client
 	sts     *sts.Client
 	iam     *iam.Client
+	quotas  *servicequotas.Client
 	assumed bool
 }
 
@@ -75,6 +78,7 @@ func newEC2ClientWithRegion(ctx context.Context, region string) (clients.EC2, er
 		ec2:     ec2.NewFromConfig(*cfg),
 		sts:     sts.NewFromConfig(*cfg),
 		iam:     iam.NewFromConfig(*cfg),
+		quotas:  servicequotas.NewFromConfig(*cfg),
 		assumed: false,
 	}, nil
 }
@@ -106,6 +110,7 @@ func newAssumedEC2ClientWithRegion(ctx context.Context, auth *clients.Authentica
 		ec2:     ec2.NewFromConfig(*cfg),
 		sts:     sts.NewFromConfig(*cfg),
 		iam:     iam.NewFromConfig(*cfg),
+		quotas:  servicequotas.NewFromConfig(*cfg),
 		assumed: true,
 	}, nil
 }
@@ -393,20 +398,39 @@ func (c *ec2Client) RunInstances(ctx context.Context, params *clients.AWSInstanc
 		KeyName:        &params.KeyName,
 		UserData:       &encodedUserData,
 	}
+	input.NetworkInterfaces = networkInterfaces(params)
+
+	mappings, err := c.blockDeviceMappings(ctx, params)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, nil, err
+	}
+	input.BlockDeviceMappings = mappings
+	if params.Spot {
+		input.InstanceMarketOptions = spotMarketOptions(params)
+	}
+
+	tags := []types.Tag{
+		{
+			Key:   ptr.To("rh-rid"),
+			Value: ptr.To(config.EnvironmentPrefix("r", strconv.FormatInt(reservation.ID, 10))),
+		},
+		{
+			Key:   ptr.To("rh-org"),
+			Value: ptr.To(identity.Identity(ctx).Identity.OrgID),
+		},
+	}
+	for _, key := range math.SortedKeys(params.Tags) {
+		tags = append(tags, types.Tag{
+			Key:   ptr.To(key),
+			Value: ptr.To(params.Tags[key]),
+		})
+	}
 
 	input.TagSpecifications = []types.TagSpecification{
 		{
 			ResourceType: types.ResourceTypeInstance,
-			Tags: []types.Tag{
-				{
-					Key:   ptr.To("rh-rid"),
-					Value: ptr.To(config.EnvironmentPrefix("r", strconv.FormatInt(reservation.ID, 10))),
-				},
-				{
-					Key:   ptr.To("rh-org"),
-					Value: ptr.To(identity.Identity(ctx).Identity.OrgID),
-				},
-			},
+			Tags:         tags,
 		},
 	}
 
@@ -418,10 +442,27 @@ func (c *ec2Client) RunInstances(ctx context.Context, params *clients.AWSInstanc
 		input.TagSpecifications[0].Tags = append(input.TagSpecifications[0].Tags, t)
 	}
 
+	// user tags are also propagated to volumes and network interfaces, launch template tags
+	// of these resources are kept when there are none
+	if len(params.Tags) > 0 {
+		input.TagSpecifications = append(input.TagSpecifications,
+			types.TagSpecification{
+				ResourceType: types.ResourceTypeVolume,
+				Tags:         tags,
+			},
+			types.TagSpecification{
+				ResourceType: types.ResourceTypeNetworkInterface,
+				Tags:         tags,
+			},
+		)
+	}
+
 	resp, err := c.ec2.RunInstances(ctx, input)
 	if err != nil {
 		if isAWSUnauthorizedError(err) {
 			err = clients.ErrUnauthorized
+		} else if isAWSCapacityError(err) {
+			err = fmt.Errorf("%w: %w", clients.ErrCapacityUnavailable, err)
 		}
 		span.SetStatus(codes.Error, err.Error())
 		return nil, nil, fmt.Errorf("cannot run instances: %w", err)
@@ -436,6 +477,95 @@ func (c *ec2Client) RunInstances(ctx context.Context, params *clients.AWSInstanc
 	return instances, resp.ReservationId, nil
 }
 
+func (c *ec2Client) DryRunInstances(ctx context.Context, params *clients.AWSInstanceParams, amount int32) error {
+	ctx, span := telemetry.StartSpan(ctx, "DryRunInstances")
+	defer span.End()
+
+	if !c.assumed {
+		return http.ErrServiceAccountUnsupportedOp
+	}
+	logger := logger(ctx)
+	logger.Trace().Msg("Dry run AWS EC2 instance")
+
+	var templateSpec *types.LaunchTemplateSpecification
+	if params.LaunchTemplateID != "" {
+		templateSpec = &types.LaunchTemplateSpecification{
+			LaunchTemplateId: ptr.To(params.LaunchTemplateID),
+		}
+	}
+
+	input := &ec2.RunInstancesInput{
+		DryRun:         ptr.To(true),
+		LaunchTemplate: templateSpec,
+		MaxCount:       ptr.To(amount),
+		MinCount:       ptr.To(amount),
+		InstanceType:   params.InstanceType,
+	}
+	input.NetworkInterfaces = networkInterfaces(params)
+	if params.AMI != "" {
+		input.ImageId = ptr.To(params.AMI)
+	}
+
+	mappings, err := c.blockDeviceMappings(ctx, params)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("%w: %w", http.ErrDryRunFailed, err)
+	}
+	input.BlockDeviceMappings = mappings
+	if params.Spot {
+		input.InstanceMarketOptions = spotMarketOptions(params)
+	}
+
+	// AWS never returns success for dry runs, the DryRunOperation error means the request would have succeeded
+	_, err = c.ec2.RunInstances(ctx, input)
+	if err == nil || isAWSOperationError(err, "api error DryRunOperation") {
+		return nil
+	}
+
+	span.SetStatus(codes.Error, err.Error())
+	if isAWSUnauthorizedError(err) {
+		return fmt.Errorf("%w: %w", http.ErrDryRunUnauthorized, err)
+	}
+	return fmt.Errorf("%w: %w", http.ErrDryRunFailed, err)
+}
+
+// networkInterfaces returns the primary network interface specification when subnet, security
+// groups or public IP are set, nil otherwise (the default subnet of the default VPC is used).
+// Public IP assignment follows the subnet settings unless disabled.
+func networkInterfaces(params *clients.AWSInstanceParams) []types.InstanceNetworkInterfaceSpecification {
+	if params.SubnetID == "" && len(params.SecurityGroupIDs) == 0 && !params.NoPublicIP {
+		return nil
+	}
+
+	nic := types.InstanceNetworkInterfaceSpecification{
+		DeviceIndex: ptr.To(int32(0)),
+		Groups:      params.SecurityGroupIDs,
+	}
+	if params.SubnetID != "" {
+		nic.SubnetId = ptr.To(params.SubnetID)
+	}
+	if params.NoPublicIP {
+		nic.AssociatePublicIpAddress = ptr.To(false)
+	}
+	return []types.InstanceNetworkInterfaceSpecification{nic}
+}
+
+// spotMarketOptions returns options of one-time spot instances which are terminated when interrupted,
+// the maximum price defaults to the on-demand price.
+func spotMarketOptions(params *clients.AWSInstanceParams) *types.InstanceMarketOptionsRequest {
+	options := &types.SpotMarketOptions{
+		SpotInstanceType:             types.SpotInstanceTypeOneTime,
+		InstanceInterruptionBehavior: types.InstanceInterruptionBehaviorTerminate,
+	}
+	if params.SpotMaxPrice > 0 {
+		options.MaxPrice = ptr.To(strconv.FormatFloat(params.SpotMaxPrice, 'f', -1, 64))
+	}
+	return &types.InstanceMarketOptionsRequest{
+		MarketType:  types.MarketTypeSpot,
+		SpotOptions: options,
+	}
+}
+
 func (c *ec2Client) parseRunInstancesResponse(respAWS *ec2.RunInstancesOutput) []*string {
 	instances := respAWS.Instances
 	list := make([]*string, len(instances))
@@ -463,6 +593,106 @@ func (c *ec2Client) parseDescribeInstances(respAWS *ec2.DescribeInstancesOutput)
 	return list, nil
 }
 
+func (c *ec2Client) StartInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := telemetry.StartSpan(ctx, "StartInstances")
+	defer span.End()
+
+	if !c.assumed {
+		return http.ErrServiceAccountUnsupportedOp
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Starting AWS EC2 instances %v", instanceIds)
+
+	input := &ec2.StartInstancesInput{
+		InstanceIds: instanceIds,
+	}
+	_, err := c.ec2.StartInstances(ctx, input)
+	if err != nil {
+		if isAWSUnauthorizedError(err) {
+			err = clients.ErrUnauthorized
+		}
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot start instances: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ec2Client) StopInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := telemetry.StartSpan(ctx, "StopInstances")
+	defer span.End()
+
+	if !c.assumed {
+		return http.ErrServiceAccountUnsupportedOp
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Stopping AWS EC2 instances %v", instanceIds)
+
+	input := &ec2.StopInstancesInput{
+		InstanceIds: instanceIds,
+	}
+	_, err := c.ec2.StopInstances(ctx, input)
+	if err != nil {
+		if isAWSUnauthorizedError(err) {
+			err = clients.ErrUnauthorized
+		}
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot stop instances: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ec2Client) RebootInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := telemetry.StartSpan(ctx, "RebootInstances")
+	defer span.End()
+
+	if !c.assumed {
+		return http.ErrServiceAccountUnsupportedOp
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Rebooting AWS EC2 instances %v", instanceIds)
+
+	input := &ec2.RebootInstancesInput{
+		InstanceIds: instanceIds,
+	}
+	_, err := c.ec2.RebootInstances(ctx, input)
+	if err != nil {
+		if isAWSUnauthorizedError(err) {
+			err = clients.ErrUnauthorized
+		}
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot reboot instances: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ec2Client) TerminateInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := telemetry.StartSpan(ctx, "TerminateInstances")
+	defer span.End()
+
+	if !c.assumed {
+		return http.ErrServiceAccountUnsupportedOp
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Terminating AWS EC2 instances %v", instanceIds)
+
+	input := &ec2.TerminateInstancesInput{
+		InstanceIds: instanceIds,
+	}
+	_, err := c.ec2.TerminateInstances(ctx, input)
+	if err != nil {
+		if isAWSUnauthorizedError(err) {
+			err = clients.ErrUnauthorized
+		}
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot terminate instances: %w", err)
+	}
+
+	return nil
+}
+
 func (c *ec2Client) GetAccountId(ctx context.Context) (string, error) {
 	ctx, span := telemetry.StartSpan(ctx, "GetAccountId")
 	defer span.End()
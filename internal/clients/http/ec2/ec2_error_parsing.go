@@ -11,6 +11,22 @@ func isAWSUnauthorizedError(err error) bool {
 	return isAWSOperationError(err, "api error UnauthorizedOperation")
 }
 
+// awsCapacityErrorCodes are returned when EC2 cannot provide the instances at the moment
+var awsCapacityErrorCodes = []string{
+	"InsufficientInstanceCapacity",
+	"InsufficientCapacity",
+	"SpotMaxPriceTooLow",
+}
+
+func isAWSCapacityError(err error) bool {
+	for _, code := range awsCapacityErrorCodes {
+		if isAWSOperationError(err, "api error "+code) {
+			return true
+		}
+	}
+	return false
+}
+
 func isAWSOperationError(err error, substr string) bool {
 	var oe *smithy.OperationError
 	if errors.As(err, &oe) {
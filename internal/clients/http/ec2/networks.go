@@ -0,0 +1,96 @@
+package ec2
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ListNetworks returns VPCs of the region, subnets and security groups are assigned to their VPCs.
+func (c *ec2Client) ListNetworks(ctx context.Context) ([]*clients.Network, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ListNetworks")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msg("Listing AWS EC2 VPCs")
+
+	networks := make([]*clients.Network, 0)
+	byID := make(map[string]*clients.Network)
+	vpcPager := ec2.NewDescribeVpcsPaginator(c.ec2, &ec2.DescribeVpcsInput{})
+	for vpcPager.HasMorePages() {
+		resp, err := vpcPager.NextPage(ctx)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("cannot describe VPCs: %w", err)
+		}
+		for _, vpc := range resp.Vpcs {
+			network := &clients.Network{
+				ID:             ptr.FromOrEmpty(vpc.VpcId),
+				Name:           nameTag(vpc.Tags),
+				Default:        ptr.FromOrEmpty(vpc.IsDefault),
+				Subnets:        make([]*clients.Subnet, 0),
+				SecurityGroups: make([]*clients.SecurityGroup, 0),
+			}
+			for _, cidr := range vpc.CidrBlockAssociationSet {
+				network.CIDR = append(network.CIDR, ptr.FromOrEmpty(cidr.CidrBlock))
+			}
+			networks = append(networks, network)
+			byID[network.ID] = network
+		}
+	}
+
+	subnetPager := ec2.NewDescribeSubnetsPaginator(c.ec2, &ec2.DescribeSubnetsInput{})
+	for subnetPager.HasMorePages() {
+		resp, err := subnetPager.NextPage(ctx)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("cannot describe subnets: %w", err)
+		}
+		for _, subnet := range resp.Subnets {
+			if network, ok := byID[ptr.FromOrEmpty(subnet.VpcId)]; ok {
+				network.Subnets = append(network.Subnets, &clients.Subnet{
+					ID:   ptr.FromOrEmpty(subnet.SubnetId),
+					Name: nameTag(subnet.Tags),
+					CIDR: ptr.FromOrEmpty(subnet.CidrBlock),
+					Zone: ptr.FromOrEmpty(subnet.AvailabilityZone),
+				})
+			}
+		}
+	}
+
+	sgPager := ec2.NewDescribeSecurityGroupsPaginator(c.ec2, &ec2.DescribeSecurityGroupsInput{})
+	for sgPager.HasMorePages() {
+		resp, err := sgPager.NextPage(ctx)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("cannot describe security groups: %w", err)
+		}
+		for _, sg := range resp.SecurityGroups {
+			if network, ok := byID[ptr.FromOrEmpty(sg.VpcId)]; ok {
+				network.SecurityGroups = append(network.SecurityGroups, &clients.SecurityGroup{
+					ID:          ptr.FromOrEmpty(sg.GroupId),
+					Name:        ptr.FromOrEmpty(sg.GroupName),
+					Description: ptr.FromOrEmpty(sg.Description),
+				})
+			}
+		}
+	}
+
+	return networks, nil
+}
+
+// nameTag returns value of the "Name" tag or empty string
+func nameTag(tags []types.Tag) string {
+	for _, tag := range tags {
+		if ptr.FromOrEmpty(tag.Key) == "Name" {
+			return ptr.FromOrEmpty(tag.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package ec2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	onDemandQuotaPrefix = "Running On-Demand "
+	onDemandQuotaSuffix = " instances"
+)
+
+// quotaFamilyNames translates multi-word family names from quota names
+var quotaFamilyNames = map[string]string{
+	"high memory": "u",
+}
+
+// ListVCPUQuotas returns On-Demand vCPU quotas of the region from the Service Quotas API, usage is
+// counted from pending and running instances. Requires servicequotas:ListServiceQuotas permission.
+func (c *ec2Client) ListVCPUQuotas(ctx context.Context) ([]*clients.Quota, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ListVCPUQuotas")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msg("Listing AWS EC2 vCPU quotas")
+
+	quotas := make([]*clients.Quota, 0)
+	pager := servicequotas.NewListServiceQuotasPaginator(c.quotas, &servicequotas.ListServiceQuotasInput{
+		ServiceCode: ptr.To("ec2"),
+	})
+	for pager.HasMorePages() {
+		resp, err := pager.NextPage(ctx)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("cannot list service quotas: %w", err)
+		}
+		for _, sq := range resp.Quotas {
+			families := parseQuotaFamilies(ptr.FromOrEmpty(sq.QuotaName))
+			if len(families) == 0 || sq.Value == nil {
+				continue
+			}
+			quotas = append(quotas, &clients.Quota{
+				Name:     *sq.QuotaName,
+				Limit:    int64(*sq.Value),
+				Families: families,
+			})
+		}
+	}
+
+	usage, err := c.vcpuUsage(ctx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	for _, quota := range quotas {
+		for _, family := range quota.Families {
+			quota.Usage += usage[family]
+		}
+	}
+
+	return quotas, nil
+}
+
+// vcpuUsage returns vCPUs of pending and running instances per instance family
+func (c *ec2Client) vcpuUsage(ctx context.Context) (map[string]int64, error) {
+	usage := make(map[string]int64)
+	pager := ec2.NewDescribeInstancesPaginator(c.ec2, &ec2.DescribeInstancesInput{
+		Filters: []types.Filter{
+			{
+				Name:   ptr.To("instance-state-name"),
+				Values: []string{"pending", "running"},
+			},
+		},
+	})
+	for pager.HasMorePages() {
+		resp, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot describe instances: %w", err)
+		}
+		for _, reservation := range resp.Reservations {
+			for _, instance := range reservation.Instances {
+				if instance.CpuOptions == nil {
+					continue
+				}
+				vcpus := int64(ptr.FromOrEmpty(instance.CpuOptions.CoreCount)) * int64(ptr.FromOrEmpty(instance.CpuOptions.ThreadsPerCore))
+				family := clients.InstanceFamily(models.ProviderTypeAWS, clients.InstanceTypeName(instance.InstanceType))
+				usage[family] += vcpus
+			}
+		}
+	}
+	return usage, nil
+}
+
+// parseQuotaFamilies returns instance families from On-Demand quota names like "Running On-Demand
+// Standard (A, C, D, H, I, M, R, T, Z) instances" or "Running On-Demand G and VT instances".
+func parseQuotaFamilies(name string) []string {
+	if !strings.HasPrefix(name, onDemandQuotaPrefix) || !strings.HasSuffix(name, onDemandQuotaSuffix) {
+		return nil
+	}
+	name = strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(name, onDemandQuotaPrefix), onDemandQuotaSuffix))
+	if _, list, found := strings.Cut(name, "("); found {
+		name = strings.TrimSuffix(list, ")")
+	}
+	if family, ok := quotaFamilyNames[name]; ok {
+		return []string{family}
+	}
+
+	families := make([]string, 0)
+	for _, part := range strings.Split(strings.ReplaceAll(name, " and ", ","), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			families = append(families, part)
+		}
+	}
+	return families
+}
@@ -0,0 +1,15 @@
+package ec2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuotaFamilies(t *testing.T) {
+	assert.Equal(t, []string{"a", "c", "d", "h", "i", "m", "r", "t", "z"}, parseQuotaFamilies("Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances"))
+	assert.Equal(t, []string{"g", "vt"}, parseQuotaFamilies("Running On-Demand G and VT instances"))
+	assert.Equal(t, []string{"p"}, parseQuotaFamilies("Running On-Demand P instances"))
+	assert.Equal(t, []string{"u"}, parseQuotaFamilies("Running On-Demand High Memory instances"))
+	assert.Empty(t, parseQuotaFamilies("All Standard (A, C, D, H, I, M, R, T, Z) Spot Instance Requests"))
+}
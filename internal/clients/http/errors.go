@@ -31,9 +31,12 @@ var (
 	ErrServiceAccountUnsupportedOp = usrerr.New(500, "unsupported operation on service account", "")
 	ErrARNParsing                  = usrerr.New(500, "ARN parsing error", "")
 	ErrNoReservation               = usrerr.New(404, "no reservation was found in AWS response", "")
+	ErrDryRunUnauthorized          = usrerr.New(403, "not authorized to launch instances in AWS account", "")
+	ErrDryRunFailed                = usrerr.New(400, "instance launch dry run failed", "")
 )
 
 // Azure
 var (
 	ErrRoleAssignmentNotFound = errors.New("Azure role assignment of Contributor to the service was not found in given subscription")
+	ErrUnknownAzureResource   = errors.New("unsupported Azure resource type")
 )
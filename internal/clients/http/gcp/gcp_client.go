@@ -169,6 +169,21 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 			Value: ptr.To(params.StartupScript),
 		})
 	}
+	if params.UserData != "" {
+		metadata = append(metadata, &computepb.Items{
+			Key:   ptr.To("user-data"),
+			Value: ptr.To(params.UserData),
+		})
+	}
+
+	labels := map[string]string{
+		"rh-rid":  config.EnvironmentPrefix("r", strconv.FormatInt(params.ReservationID, 10)),
+		"rh-uuid": params.UUID,
+		"rh-org":  identity.Identity(ctx).Identity.OrgID,
+	}
+	for key, value := range params.Labels {
+		labels[key] = value
+	}
 
 	req := &computepb.BulkInsertInstanceRequest{
 		Project: c.auth.Payload,
@@ -178,21 +193,9 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 			Count:       &amount,
 			MinCount:    &amount,
 			InstanceProperties: &computepb.InstanceProperties{
-				Labels: map[string]string{
-					"rh-rid":  config.EnvironmentPrefix("r", strconv.FormatInt(params.ReservationID, 10)),
-					"rh-uuid": params.UUID,
-					"rh-org":  identity.Identity(ctx).Identity.OrgID,
-				},
+				Labels: labels,
 				NetworkInterfaces: []*computepb.NetworkInterface{
-					{
-						AccessConfigs: []*computepb.AccessConfig{
-							{
-								Name: ptr.To("External NAT"),
-								Type: ptr.To("ONE_TO_ONE_NAT"),
-							},
-						},
-						Name: ptr.To("global/networks/default"),
-					},
+					networkInterface(params),
 				},
 				Metadata: &computepb.Metadata{
 					Items: metadata,
@@ -201,6 +204,22 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 		},
 	}
 
+	if params.Spot {
+		// Spot VMs cannot be restarted or live migrated, preempted instances are deleted
+		req.BulkInsertInstanceResourceResource.InstanceProperties.Scheduling = &computepb.Scheduling{
+			ProvisioningModel:         ptr.To(computepb.Scheduling_SPOT.String()),
+			InstanceTerminationAction: ptr.To(computepb.Scheduling_DELETE.String()),
+			AutomaticRestart:          ptr.To(false),
+			OnHostMaintenance:         ptr.To(computepb.Scheduling_TERMINATE.String()),
+		}
+	}
+
+	if len(params.NetworkTags) > 0 {
+		req.BulkInsertInstanceResourceResource.InstanceProperties.Tags = &computepb.Tags{
+			Items: params.NetworkTags,
+		}
+	}
+
 	if params.LaunchTemplateID != "" {
 		template := fmt.Sprintf("global/instanceTemplates/%s", params.LaunchTemplateID)
 		req.BulkInsertInstanceResourceResource.SourceInstanceTemplate = &template
@@ -211,28 +230,19 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 	}
 
 	if params.ImageName != "" {
-		req.BulkInsertInstanceResourceResource.InstanceProperties.Disks = []*computepb.AttachedDisk{
-			{
-				InitializeParams: &computepb.AttachedDiskInitializeParams{
-					SourceImage: &params.ImageName,
-				},
-				AutoDelete: ptr.To(true),
-				Boot:       ptr.To(true),
-				Type:       ptr.To(computepb.AttachedDisk_PERSISTENT.String()),
-			},
-		}
+		req.BulkInsertInstanceResourceResource.InstanceProperties.Disks = attachedDisks(params, labels)
 	}
 
 	op, err := client.BulkInsert(ctx, req)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		logger.Error().Err(err).Msg("Bulk insert operation failed")
-		return nil, nil, fmt.Errorf("cannot bulk insert instances: %w", err)
+		return nil, nil, fmt.Errorf("cannot bulk insert instances: %w", capacityError(err, nil))
 	}
 	if err = op.Wait(ctx); err != nil {
 		logger.Error().Err(err).Msg("Bulk wait operation failed")
 		span.SetStatus(codes.Error, err.Error())
-		return nil, nil, fmt.Errorf("cannot bulk insert instances: %w", err)
+		return nil, nil, fmt.Errorf("cannot bulk insert instances: %w", capacityError(err, op.Proto()))
 	}
 
 	if !op.Done() {
@@ -246,6 +256,29 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 	return ids, ptr.To(op.Name()), nil
 }
 
+// networkInterface returns the primary network interface of the instance, the default network is
+// used unless network or subnetwork is set.
+func networkInterface(params *clients.GCPInstanceParams) *computepb.NetworkInterface {
+	nic := &computepb.NetworkInterface{
+		Name: ptr.To("global/networks/default"),
+	}
+	if !params.NoPublicIP {
+		nic.AccessConfigs = []*computepb.AccessConfig{
+			{
+				Name: ptr.To("External NAT"),
+				Type: ptr.To("ONE_TO_ONE_NAT"),
+			},
+		}
+	}
+	if params.Network != "" {
+		nic.Network = ptr.To("global/networks/" + params.Network)
+	}
+	if params.Subnetwork != "" {
+		nic.Subnetwork = ptr.To(fmt.Sprintf("regions/%s/subnetworks/%s", clients.GCPRegion(params.Zone), params.Subnetwork))
+	}
+	return nic
+}
+
 func (c *gcpClient) ListInstancesIDsByLabel(ctx context.Context, uuid string) ([]*string, error) {
 	ctx, span := telemetry.StartSpan(ctx, "ListInstancesIDsByLabel")
 	defer span.End()
@@ -316,3 +349,54 @@ func (c *gcpClient) GetInstanceDescriptionByID(ctx context.Context, id, zone str
 	}
 	return &instanceDesc, nil
 }
+
+// attachedDisks returns the boot disk created from the image followed by empty data disks. Disk
+// types are names ("pd-ssd") as the disks are created in the zone of the bulk insert request.
+func attachedDisks(params *clients.GCPInstanceParams, labels map[string]string) []*computepb.AttachedDisk {
+	boot := &computepb.AttachedDisk{
+		InitializeParams: &computepb.AttachedDiskInitializeParams{
+			SourceImage: &params.ImageName,
+			Labels:      labels,
+		},
+		AutoDelete: ptr.To(true),
+		Boot:       ptr.To(true),
+		Type:       ptr.To(computepb.AttachedDisk_PERSISTENT.String()),
+	}
+	if params.RootVolumeGB > 0 {
+		boot.InitializeParams.DiskSizeGb = ptr.To(params.RootVolumeGB)
+	}
+	if params.VolumeType != "" {
+		boot.InitializeParams.DiskType = ptr.To(params.VolumeType)
+	}
+
+	disks := []*computepb.AttachedDisk{boot}
+	for _, dataDisk := range params.DataDisks {
+		diskType := dataDisk.VolumeType
+		if diskType == "" {
+			diskType = params.VolumeType
+		}
+		disk := &computepb.AttachedDisk{
+			InitializeParams: &computepb.AttachedDiskInitializeParams{
+				DiskSizeGb: ptr.To(dataDisk.SizeGB),
+				Labels:     labels,
+			},
+			AutoDelete: ptr.To(true),
+			Boot:       ptr.To(false),
+			Type:       ptr.To(computepb.AttachedDisk_PERSISTENT.String()),
+		}
+		if diskType != "" {
+			disk.InitializeParams.DiskType = ptr.To(diskType)
+		}
+		disks = append(disks, disk)
+	}
+	return disks
+}
+
+// capacityError wraps errors caused by exhausted zone resources or quota with clients.ErrCapacityUnavailable.
+// Operation errors carry the reason only in the operation, pass nil when there is none.
+func capacityError(err error, op *computepb.Operation) error {
+	if isGCPCapacityError(err) || isOperationCapacityError(op) {
+		return fmt.Errorf("%w: %w", clients.ErrCapacityUnavailable, err)
+	}
+	return err
+}
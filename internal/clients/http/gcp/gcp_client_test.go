@@ -0,0 +1,106 @@
+package gcp
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+)
+
+func TestAttachedDisks(t *testing.T) {
+	labels := map[string]string{"rid": "1"}
+
+	t.Run("image defaults", func(t *testing.T) {
+		disks := attachedDisks(&clients.GCPInstanceParams{ImageName: "projects/rhel/global/images/rhel-9"}, labels)
+
+		require.Len(t, disks, 1)
+		boot := disks[0]
+		assert.True(t, boot.GetBoot())
+		assert.True(t, boot.GetAutoDelete())
+		assert.Equal(t, "PERSISTENT", boot.GetType())
+		assert.Equal(t, "projects/rhel/global/images/rhel-9", boot.GetInitializeParams().GetSourceImage())
+		assert.Equal(t, labels, boot.GetInitializeParams().GetLabels())
+		assert.Nil(t, boot.GetInitializeParams().DiskSizeGb, "Expected the image disk size")
+		assert.Nil(t, boot.GetInitializeParams().DiskType, "Expected the default disk type")
+	})
+
+	t.Run("data disks", func(t *testing.T) {
+		disks := attachedDisks(&clients.GCPInstanceParams{
+			ImageName:    "projects/rhel/global/images/rhel-9",
+			RootVolumeGB: 30,
+			VolumeType:   "pd-ssd",
+			DataDisks: []models.DataDisk{
+				{SizeGB: 100},
+				{SizeGB: 500, VolumeType: "pd-standard"},
+			},
+		}, labels)
+
+		require.Len(t, disks, 3)
+		assert.Equal(t, int64(30), disks[0].GetInitializeParams().GetDiskSizeGb())
+		assert.Equal(t, "pd-ssd", disks[0].GetInitializeParams().GetDiskType())
+
+		for _, disk := range disks[1:] {
+			assert.False(t, disk.GetBoot())
+			assert.True(t, disk.GetAutoDelete(), "Expected data disks to be deleted with the instance")
+			assert.Equal(t, "PERSISTENT", disk.GetType())
+			assert.Empty(t, disk.GetInitializeParams().GetSourceImage(), "Expected an empty data disk")
+			assert.Equal(t, labels, disk.GetInitializeParams().GetLabels())
+		}
+		assert.Equal(t, int64(100), disks[1].GetInitializeParams().GetDiskSizeGb())
+		assert.Equal(t, "pd-ssd", disks[1].GetInitializeParams().GetDiskType(), "Expected the reservation volume type")
+		assert.Equal(t, int64(500), disks[2].GetInitializeParams().GetDiskSizeGb())
+		assert.Equal(t, "pd-standard", disks[2].GetInitializeParams().GetDiskType())
+	})
+
+	t.Run("data disk default type", func(t *testing.T) {
+		disks := attachedDisks(&clients.GCPInstanceParams{
+			ImageName: "projects/rhel/global/images/rhel-9",
+			DataDisks: []models.DataDisk{{SizeGB: 10}},
+		}, labels)
+
+		require.Len(t, disks, 2)
+		assert.Nil(t, disks[1].GetInitializeParams().DiskType, "Expected the default disk type")
+	})
+}
+
+func TestCapacityError(t *testing.T) {
+	apiError := func(reason string) error {
+		return fmt.Errorf("rpc: %w", &googleapi.Error{
+			Code:   503,
+			Errors: []googleapi.ErrorItem{{Reason: reason, Message: "message"}},
+		})
+	}
+	operation := func(code string) *computepb.Operation {
+		return &computepb.Operation{Error: &computepb.Error{Errors: []*computepb.Errors{{Code: &code}}}}
+	}
+	// operation errors do not carry the reason, only the message
+	operationErr := &googleapi.Error{Code: 503, Message: "Service Unavailable"}
+
+	tests := []struct {
+		name     string
+		err      error
+		op       *computepb.Operation
+		capacity bool
+	}{
+		{"zone exhausted", apiError("ZONE_RESOURCE_POOL_EXHAUSTED"), nil, true},
+		{"quota exceeded", apiError("QUOTA_EXCEEDED"), nil, true},
+		{"other reason", apiError("notFound"), nil, false},
+		{"not an API error", errors.New("ZONE_RESOURCE_POOL_EXHAUSTED"), nil, false},
+		{"operation zone exhausted", operationErr, operation("ZONE_RESOURCE_POOL_EXHAUSTED_WITH_DETAILS"), true},
+		{"operation other code", operationErr, operation("RESOURCE_NOT_FOUND"), false},
+		{"operation without errors", operationErr, &computepb.Operation{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := capacityError(tt.err, tt.op)
+			assert.Equal(t, tt.capacity, errors.Is(err, clients.ErrCapacityUnavailable))
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
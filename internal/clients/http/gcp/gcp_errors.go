@@ -1,5 +1,43 @@
 package gcp
 
-import "errors"
+import (
+	"errors"
+	"slices"
 
-var ErrOperationFailed = errors.New("operation has failed to finish within expected time")
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"google.golang.org/api/googleapi"
+)
+
+var (
+	ErrOperationFailed    = errors.New("operation has failed to finish within expected time")
+	ErrPermissionsMissing = errors.New("GCP permissions missing")
+)
+
+// gcpCapacityErrorReasons are returned when the zone cannot provide the instances at the moment
+var gcpCapacityErrorReasons = []string{
+	"ZONE_RESOURCE_POOL_EXHAUSTED",
+	"ZONE_RESOURCE_POOL_EXHAUSTED_WITH_DETAILS",
+	"QUOTA_EXCEEDED",
+}
+
+func isGCPCapacityError(err error) bool {
+	var gErr *googleapi.Error
+	if !errors.As(err, &gErr) {
+		return false
+	}
+	for _, item := range gErr.Errors {
+		if slices.Contains(gcpCapacityErrorReasons, item.Reason) {
+			return true
+		}
+	}
+	return false
+}
+
+func isOperationCapacityError(op *computepb.Operation) bool {
+	for _, opErr := range op.GetError().GetErrors() {
+		if slices.Contains(gcpCapacityErrorReasons, opErr.GetCode()) {
+			return true
+		}
+	}
+	return false
+}
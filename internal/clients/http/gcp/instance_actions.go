@@ -0,0 +1,127 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// instanceOperation starts a single instance operation which is then awaited
+type instanceOperation func(ctx context.Context, client *compute.InstancesClient, project, zone, instanceId string) (*compute.Operation, error)
+
+// forEachInstance performs the operation on every instance and waits for all of them to finish
+func (c *gcpClient) forEachInstance(ctx context.Context, zone string, instanceIds []string, op instanceOperation) error {
+	logger := logger(ctx)
+
+	client, err := c.newInstancesClient(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Could not get instances client")
+		return fmt.Errorf("unable to get instances client: %w", err)
+	}
+	defer client.Close()
+
+	if zone == "" {
+		zone = config.GCP.DefaultZone
+	}
+
+	operations := make([]*compute.Operation, 0, len(instanceIds))
+	for _, instanceId := range instanceIds {
+		operation, err := op(ctx, client, c.auth.Payload, zone, instanceId)
+		if err != nil {
+			return fmt.Errorf("operation on instance %s failed to start: %w", instanceId, err)
+		}
+		operations = append(operations, operation)
+	}
+
+	for _, operation := range operations {
+		if err = operation.Wait(ctx); err != nil {
+			return fmt.Errorf("operation %s failed: %w", operation.Name(), err)
+		}
+		if !operation.Done() {
+			return fmt.Errorf("an error occured on operation %s: %w", operation.Name(), ErrOperationFailed)
+		}
+	}
+
+	return nil
+}
+
+func (c *gcpClient) StartInstances(ctx context.Context, zone string, instanceIds []string) error {
+	ctx, span := telemetry.StartSpan(ctx, "StartInstances")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msgf("Starting GCP instances %v", instanceIds)
+
+	//nolint:wrapcheck
+	err := c.forEachInstance(ctx, zone, instanceIds, func(ctx context.Context, client *compute.InstancesClient, project, zone, instanceId string) (*compute.Operation, error) {
+		return client.Start(ctx, &computepb.StartInstanceRequest{Project: project, Zone: zone, Instance: instanceId})
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot start instances: %w", err)
+	}
+
+	return nil
+}
+
+func (c *gcpClient) StopInstances(ctx context.Context, zone string, instanceIds []string) error {
+	ctx, span := telemetry.StartSpan(ctx, "StopInstances")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msgf("Stopping GCP instances %v", instanceIds)
+
+	//nolint:wrapcheck
+	err := c.forEachInstance(ctx, zone, instanceIds, func(ctx context.Context, client *compute.InstancesClient, project, zone, instanceId string) (*compute.Operation, error) {
+		return client.Stop(ctx, &computepb.StopInstanceRequest{Project: project, Zone: zone, Instance: instanceId})
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot stop instances: %w", err)
+	}
+
+	return nil
+}
+
+func (c *gcpClient) RebootInstances(ctx context.Context, zone string, instanceIds []string) error {
+	ctx, span := telemetry.StartSpan(ctx, "RebootInstances")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msgf("Resetting GCP instances %v", instanceIds)
+
+	//nolint:wrapcheck
+	err := c.forEachInstance(ctx, zone, instanceIds, func(ctx context.Context, client *compute.InstancesClient, project, zone, instanceId string) (*compute.Operation, error) {
+		return client.Reset(ctx, &computepb.ResetInstanceRequest{Project: project, Zone: zone, Instance: instanceId})
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot reset instances: %w", err)
+	}
+
+	return nil
+}
+
+func (c *gcpClient) TerminateInstances(ctx context.Context, zone string, instanceIds []string) error {
+	ctx, span := telemetry.StartSpan(ctx, "TerminateInstances")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msgf("Deleting GCP instances %v", instanceIds)
+
+	//nolint:wrapcheck
+	err := c.forEachInstance(ctx, zone, instanceIds, func(ctx context.Context, client *compute.InstancesClient, project, zone, instanceId string) (*compute.Operation, error) {
+		return client.Delete(ctx, &computepb.DeleteInstanceRequest{Project: project, Zone: zone, Instance: instanceId})
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot delete instances: %w", err)
+	}
+
+	return nil
+}
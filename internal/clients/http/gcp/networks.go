@@ -0,0 +1,110 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/api/iterator"
+)
+
+// defaultNetwork is the auto-mode network created in new projects
+const defaultNetwork = "default"
+
+// ListNetworks returns VPC networks of the project with their subnetworks in the region. Target tags
+// of ingress firewall rules are returned as security groups of the network.
+func (c *gcpClient) ListNetworks(ctx context.Context, region string) ([]*clients.Network, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ListNetworks")
+	defer span.End()
+
+	networkClient, err := compute.NewNetworksRESTClient(ctx, c.options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GCP networks client: %w", err)
+	}
+	defer networkClient.Close()
+
+	networks := make([]*clients.Network, 0)
+	byName := make(map[string]*clients.Network)
+	networkIter := networkClient.List(ctx, &computepb.ListNetworksRequest{Project: c.auth.Payload})
+	for {
+		network, iterErr := networkIter.Next()
+		if errors.Is(iterErr, iterator.Done) {
+			break
+		} else if iterErr != nil {
+			span.SetStatus(codes.Error, iterErr.Error())
+			return nil, fmt.Errorf("unable to list GCP networks: %w", iterErr)
+		}
+		result := &clients.Network{
+			ID:             network.GetName(),
+			Name:           network.GetName(),
+			Default:        network.GetName() == defaultNetwork,
+			Subnets:        make([]*clients.Subnet, 0),
+			SecurityGroups: make([]*clients.SecurityGroup, 0),
+		}
+		networks = append(networks, result)
+		byName[result.ID] = result
+	}
+
+	subnetClient, err := compute.NewSubnetworksRESTClient(ctx, c.options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GCP subnetworks client: %w", err)
+	}
+	defer subnetClient.Close()
+
+	subnetIter := subnetClient.List(ctx, &computepb.ListSubnetworksRequest{Project: c.auth.Payload, Region: region})
+	for {
+		subnet, iterErr := subnetIter.Next()
+		if errors.Is(iterErr, iterator.Done) {
+			break
+		} else if iterErr != nil {
+			span.SetStatus(codes.Error, iterErr.Error())
+			return nil, fmt.Errorf("unable to list GCP subnetworks: %w", iterErr)
+		}
+		if network, ok := byName[path.Base(subnet.GetNetwork())]; ok {
+			network.Subnets = append(network.Subnets, &clients.Subnet{
+				ID:   subnet.GetName(),
+				Name: subnet.GetName(),
+				CIDR: subnet.GetIpCidrRange(),
+			})
+		}
+	}
+
+	firewallClient, err := compute.NewFirewallsRESTClient(ctx, c.options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GCP firewalls client: %w", err)
+	}
+	defer firewallClient.Close()
+
+	tags := make(map[string]*clients.SecurityGroup)
+	firewallIter := firewallClient.List(ctx, &computepb.ListFirewallsRequest{Project: c.auth.Payload})
+	for {
+		firewall, iterErr := firewallIter.Next()
+		if errors.Is(iterErr, iterator.Done) {
+			break
+		} else if iterErr != nil {
+			span.SetStatus(codes.Error, iterErr.Error())
+			return nil, fmt.Errorf("unable to list GCP firewalls: %w", iterErr)
+		}
+		network, ok := byName[path.Base(firewall.GetNetwork())]
+		if !ok || firewall.GetDisabled() || firewall.GetDirection() != computepb.Firewall_INGRESS.String() {
+			continue
+		}
+		for _, tag := range firewall.GetTargetTags() {
+			key := network.ID + "/" + tag
+			if group, found := tags[key]; found {
+				group.Name += ", " + firewall.GetName()
+				continue
+			}
+			tags[key] = &clients.SecurityGroup{ID: tag, Name: firewall.GetName()}
+			network.SecurityGroups = append(network.SecurityGroups, tags[key])
+		}
+	}
+
+	return networks, nil
+}
@@ -0,0 +1,64 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/api/cloudresourcemanager/v3"
+)
+
+// requiredPermissions are IAM permissions the service uses in the customer project
+var requiredPermissions = []string{
+	"compute.disks.create",
+	"compute.disks.setLabels",
+	"compute.instanceTemplates.list",
+	"compute.instanceTemplates.useReadOnly",
+	"compute.instances.create",
+	"compute.instances.delete",
+	"compute.instances.get",
+	"compute.instances.list",
+	"compute.instances.reset",
+	"compute.instances.setLabels",
+	"compute.instances.setMetadata",
+	"compute.instances.start",
+	"compute.instances.stop",
+	"compute.projects.get",
+	"compute.projects.setCommonInstanceMetadata",
+	"compute.regions.list",
+	"compute.subnetworks.use",
+	"compute.subnetworks.useExternalIp",
+	"compute.zoneOperations.get",
+}
+
+func (c *gcpClient) CheckPermission(ctx context.Context) ([]string, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CheckPermission")
+	defer span.End()
+
+	service, err := cloudresourcemanager.NewService(ctx, c.options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GCP resource manager client: %w", err)
+	}
+
+	req := &cloudresourcemanager.TestIamPermissionsRequest{Permissions: requiredPermissions}
+	resp, err := service.Projects.TestIamPermissions("projects/"+c.auth.Payload, req).Context(ctx).Do()
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot test IAM permissions")
+		return nil, fmt.Errorf("unable to test GCP IAM permissions: %w", err)
+	}
+
+	var missing []string
+	for _, permission := range requiredPermissions {
+		if !slices.Contains(resp.Permissions, permission) {
+			missing = append(missing, permission)
+		}
+	}
+	if len(missing) != 0 {
+		return missing, fmt.Errorf("%w: %s", ErrPermissionsMissing, strings.Join(missing, ", "))
+	}
+
+	return nil, nil
+}
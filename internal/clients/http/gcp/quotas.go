@@ -0,0 +1,79 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	cpusMetric       = "CPUS"
+	cpusMetricSuffix = "_CPUS"
+)
+
+// cpusMetricFamilies are machine series counted into the generic CPUS quota, other series
+// have their own <SERIES>_CPUS quota
+var cpusMetricFamilies = []string{"e2", "n1", "f1", "g1"}
+
+// skippedMetricPrefixes are CPU quotas not used by on-demand instances
+var skippedMetricPrefixes = []string{"PREEMPTIBLE_", "COMMITTED_", "RESERVED_"}
+
+// ListVCPUQuotas returns regional CPU quotas of the project.
+func (c *gcpClient) ListVCPUQuotas(ctx context.Context, region string) ([]*clients.Quota, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ListVCPUQuotas")
+	defer span.End()
+
+	client, err := compute.NewRegionsRESTClient(ctx, c.options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GCP regions client: %w", err)
+	}
+	defer client.Close()
+
+	req := &computepb.GetRegionRequest{
+		Project: c.auth.Payload,
+		Region:  region,
+	}
+	resp, err := client.Get(ctx, req)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("unable to get GCP region %s: %w", region, err)
+	}
+
+	quotas := make([]*clients.Quota, 0)
+	for _, q := range resp.GetQuotas() {
+		families := metricFamilies(q.GetMetric())
+		if len(families) == 0 {
+			continue
+		}
+		quotas = append(quotas, &clients.Quota{
+			Name:     q.GetMetric(),
+			Limit:    int64(q.GetLimit()),
+			Usage:    int64(q.GetUsage()),
+			Families: families,
+		})
+	}
+
+	return quotas, nil
+}
+
+// metricFamilies returns machine series limited by the quota metric or nil for non-CPU metrics
+func metricFamilies(metric string) []string {
+	if metric == cpusMetric {
+		return cpusMetricFamilies
+	}
+	if !strings.HasSuffix(metric, cpusMetricSuffix) {
+		return nil
+	}
+	for _, prefix := range skippedMetricPrefixes {
+		if strings.HasPrefix(metric, prefix) {
+			return nil
+		}
+	}
+	return []string{strings.ToLower(strings.TrimSuffix(metric, cpusMetricSuffix))}
+}
@@ -0,0 +1,101 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const sshKeysMetadataKey = "ssh-keys"
+
+// keyFields returns key type and key data of an OpenSSH public key line, optionally prefixed
+// with "username:" as used in GCP metadata. Comments are ignored.
+func keyFields(line string) string {
+	line = strings.TrimSpace(line)
+	if prefix, rest, found := strings.Cut(line, ":"); found && !strings.Contains(prefix, " ") {
+		line = rest
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return line
+	}
+	return fields[0] + " " + fields[1]
+}
+
+// removeSSHKey removes all lines with the given public key from ssh-keys metadata value.
+func removeSSHKey(value, handle string) (string, bool) {
+	key := keyFields(handle)
+	lines := strings.Split(value, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" && keyFields(line) == key {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n"), len(kept) != len(lines)
+}
+
+// DeleteSSHKey removes the public key from project-wide ssh-keys metadata. The handle is the
+// public key body, comments and username prefix are not compared.
+func (c *gcpClient) DeleteSSHKey(ctx context.Context, handle string) error {
+	ctx, span := telemetry.StartSpan(ctx, "DeleteSSHKey")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Debug().Msg("Deleting GCP project SSH key")
+
+	client, err := compute.NewProjectsRESTClient(ctx, c.options...)
+	if err != nil {
+		return fmt.Errorf("unable to create GCP projects client: %w", err)
+	}
+	defer client.Close()
+
+	project, err := client.Get(ctx, &computepb.GetProjectRequest{Project: c.auth.Payload})
+	if err != nil {
+		span.SetStatus(codes.Error, "unable to get GCP project")
+		return fmt.Errorf("unable to get GCP project %s: %w", c.auth.Payload, err)
+	}
+
+	metadata := project.GetCommonInstanceMetadata()
+	if metadata == nil {
+		return nil
+	}
+
+	changed := false
+	for _, item := range metadata.GetItems() {
+		if item.GetKey() != sshKeysMetadataKey {
+			continue
+		}
+		value, removed := removeSSHKey(item.GetValue(), handle)
+		if removed {
+			item.Value = &value
+			changed = true
+		}
+	}
+	if !changed {
+		logger.Debug().Msg("GCP project SSH key not found, nothing to delete")
+		return nil
+	}
+
+	req := &computepb.SetCommonInstanceMetadataProjectRequest{
+		Project:          c.auth.Payload,
+		MetadataResource: metadata,
+	}
+	operation, err := client.SetCommonInstanceMetadata(ctx, req)
+	if err != nil {
+		span.SetStatus(codes.Error, "unable to set GCP project metadata")
+		return fmt.Errorf("unable to set GCP project metadata: %w", err)
+	}
+	if err = operation.Wait(ctx); err != nil {
+		span.SetStatus(codes.Error, "GCP project metadata operation failed")
+		return fmt.Errorf("operation %s failed: %w", operation.Name(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveSSHKey(t *testing.T) {
+	const key = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC8 user@example.com"
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+		removed  bool
+	}{
+		{"only key", "cloud-user:" + key, "", true},
+		{"different comment", "admin:ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC8 other", "", true},
+		{"keeps other keys", "a:ssh-ed25519 AAAAC3Nza x\ncloud-user:" + key + "\nb:ssh-rsa AAAAB3Nzb y", "a:ssh-ed25519 AAAAC3Nza x\nb:ssh-rsa AAAAB3Nzb y", true},
+		{"all occurrences", "a:" + key + "\nb:" + key, "", true},
+		{"not present", "a:ssh-ed25519 AAAAC3Nza x", "a:ssh-ed25519 AAAAC3Nza x", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, removed := removeSSHKey(tt.value, key)
+			assert.Equal(t, tt.expected, value)
+			assert.Equal(t, tt.removed, removed)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package image_builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// imageCacheExpiration is expiration of successful image lookups, images of finished composes
+// and clones do not change
+const imageCacheExpiration = 24 * time.Hour
+
+// cachedImage is a cloud image name (AMI, Azure image name, GCP image path) of a compose. Resource
+// group is only set for Azure.
+type cachedImage struct {
+	ResourceGroup string
+	Name          string
+}
+
+func (cachedImage) CacheKeyName() string {
+	return "image_builder_image"
+}
+
+// imageCacheKey returns cache key of an image lookup. Composes are only accessible by the
+// organization that owns them, lookups also validate architecture of the instance type.
+func imageCacheKey(ctx context.Context, provider models.ProviderType, composeUUID uuid.UUID, instanceType clients.InstanceType) string {
+	return fmt.Sprintf("%s:%s:%s:%s", identity.Identity(ctx).Identity.OrgID, provider, composeUUID, instanceType.Architecture)
+}
+
+// findImage returns a cached image or calls fetch and caches the result when it succeeds. Cache
+// errors are logged and treated as a miss.
+func findImage(ctx context.Context, key string, fetch func() (*cachedImage, error)) (*cachedImage, error) {
+	logger := logger(ctx)
+	result := &cachedImage{}
+	err := cache.Find(ctx, key, result)
+	if err == nil {
+		return result, nil
+	} else if !errors.Is(err, cache.ErrNotFound) {
+		logger.Warn().Err(err).Msg("Image cache find error")
+	}
+
+	result, err = fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cache.SetExpires(ctx, key, result, imageCacheExpiration)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Image cache set error")
+	}
+
+	return result, nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/headers"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
@@ -60,6 +61,39 @@ func (c *ibClient) Ready(ctx context.Context) error {
 }
 
 func (c *ibClient) GetAWSAmi(ctx context.Context, composeUUID uuid.UUID, instanceType clients.InstanceType) (string, error) {
+	image, err := findImage(ctx, imageCacheKey(ctx, models.ProviderTypeAWS, composeUUID, instanceType), func() (*cachedImage, error) {
+		ami, err := c.fetchAWSAmi(ctx, composeUUID, instanceType)
+		return &cachedImage{Name: ami}, err
+	})
+	if err != nil {
+		return "", err
+	}
+	return image.Name, nil
+}
+
+func (c *ibClient) GetAzureImageInfo(ctx context.Context, composeUUID uuid.UUID, instanceType clients.InstanceType) (string, string, error) {
+	image, err := findImage(ctx, imageCacheKey(ctx, models.ProviderTypeAzure, composeUUID, instanceType), func() (*cachedImage, error) {
+		resourceGroup, name, err := c.fetchAzureImageInfo(ctx, composeUUID, instanceType)
+		return &cachedImage{ResourceGroup: resourceGroup, Name: name}, err
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return image.ResourceGroup, image.Name, nil
+}
+
+func (c *ibClient) GetGCPImageName(ctx context.Context, composeUUID uuid.UUID, instanceType clients.InstanceType) (string, error) {
+	image, err := findImage(ctx, imageCacheKey(ctx, models.ProviderTypeGCP, composeUUID, instanceType), func() (*cachedImage, error) {
+		name, err := c.fetchGCPImageName(ctx, composeUUID, instanceType)
+		return &cachedImage{Name: name}, err
+	})
+	if err != nil {
+		return "", err
+	}
+	return image.Name, nil
+}
+
+func (c *ibClient) fetchAWSAmi(ctx context.Context, composeUUID uuid.UUID, instanceType clients.InstanceType) (string, error) {
 	logger := logger(ctx)
 	logger.Trace().Msgf("Getting AMI of compose ID %s", composeUUID.String())
 
@@ -84,7 +118,7 @@ func (c *ibClient) GetAWSAmi(ctx context.Context, composeUUID uuid.UUID, instanc
 	return uploadStatus.Ami, nil
 }
 
-func (c *ibClient) GetAzureImageInfo(ctx context.Context, composeUUID uuid.UUID, instanceType clients.InstanceType) (string, string, error) {
+func (c *ibClient) fetchAzureImageInfo(ctx context.Context, composeUUID uuid.UUID, instanceType clients.InstanceType) (string, string, error) {
 	logger := logger(ctx)
 	logger.Trace().Msgf("Getting Azure ID of image %v", composeUUID.String())
 
@@ -126,7 +160,7 @@ func (c *ibClient) GetAzureImageInfo(ctx context.Context, composeUUID uuid.UUID,
 	return azureUploadRequest.ResourceGroup, uploadOptions.ImageName, nil
 }
 
-func (c *ibClient) GetGCPImageName(ctx context.Context, composeUUID uuid.UUID, instanceType clients.InstanceType) (string, error) {
+func (c *ibClient) fetchGCPImageName(ctx context.Context, composeUUID uuid.UUID, instanceType clients.InstanceType) (string, error) {
 	logger := logger(ctx)
 	logger.Trace().Msgf("Getting Google image id of compose %s", composeUUID)
 
@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
 	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http/image_builder"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/preload"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -52,6 +55,25 @@ func composeStatusServer(t *testing.T) *httptest.Server {
 	}))
 }
 
+// unavailableRedisCache configures redis cache on a port nobody listens on.
+func unavailableRedisCache(t *testing.T) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	orig := config.Application.Cache
+	config.Application.Cache.Type = "redis"
+	config.Application.Cache.Redis.Host = "127.0.0.1"
+	config.Application.Cache.Redis.Port = port
+	cache.Initialize()
+	t.Cleanup(func() {
+		config.Application.Cache = orig
+		cache.Initialize()
+	})
+}
+
 func Test_GetAWSAmi(t *testing.T) {
 	t.Run("fails to resolve AMI for mismatching architecture image", func(t *testing.T) {
 		composeUUID, err := uuid.NewRandom()
@@ -88,4 +110,53 @@ func Test_GetAWSAmi(t *testing.T) {
 		require.NoError(t, amiErr, "expected to resolve AMI correctly")
 		assert.Equal(t, "ami-1234-test", ami)
 	})
+
+	t.Run("resolves AMI from cache", func(t *testing.T) {
+		config.Application.Cache.Type = "memory"
+		cache.Initialize()
+		t.Cleanup(func() {
+			config.Application.Cache.Type = "none"
+			cache.Initialize()
+		})
+
+		composeUUID, err := uuid.NewRandom()
+		require.NoError(t, err)
+		instanceType := preload.EC2InstanceType.FindInstanceType("t4g.nano")
+		require.NotNil(t, instanceType, "failed to find instance type")
+
+		ts := composeStatusServer(t)
+
+		ctx := context.Background()
+		client, err := image_builder.NewImageBuilderClientWithUrl(ctx, ts.URL)
+		require.NoError(t, err, "failed to initialize sources client with test server")
+
+		_, amiErr := client.GetAWSAmi(ctx, composeUUID, *instanceType)
+		require.NoError(t, amiErr, "expected to resolve AMI correctly")
+
+		// image builder is no longer needed
+		ts.Close()
+		ami, amiErr := client.GetAWSAmi(ctx, composeUUID, *instanceType)
+		require.NoError(t, amiErr, "expected to resolve AMI from cache")
+		assert.Equal(t, "ami-1234-test", ami)
+	})
+
+	t.Run("resolves AMI when cache is unavailable", func(t *testing.T) {
+		unavailableRedisCache(t)
+
+		composeUUID, err := uuid.NewRandom()
+		require.NoError(t, err)
+		instanceType := preload.EC2InstanceType.FindInstanceType("t4g.nano")
+		require.NotNil(t, instanceType, "failed to find instance type")
+
+		ts := composeStatusServer(t)
+		defer ts.Close()
+
+		ctx := context.Background()
+		client, err := image_builder.NewImageBuilderClientWithUrl(ctx, ts.URL)
+		require.NoError(t, err, "failed to initialize sources client with test server")
+
+		ami, amiErr := client.GetAWSAmi(ctx, composeUUID, *instanceType)
+		require.NoError(t, amiErr, "cache errors should be treated as a miss")
+		assert.Equal(t, "ami-1234-test", ami)
+	})
 }
@@ -3,7 +3,12 @@ package sources
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
+
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
 )
 
 var ErrCacheMiss = errors.New("source constants requested, before populating")
@@ -36,3 +41,19 @@ func setCachedConstants(_ context.Context, value sourcesConstantsType) {
 
 	sourcesConstantsValues = value
 }
+
+// authenticationCacheKey returns cache key of source authentication. Sources are only accessible
+// by the organization that owns them, therefore the organization is part of the key.
+func authenticationCacheKey(ctx context.Context, sourceId string) string {
+	return identity.Identity(ctx).Identity.OrgID + ":" + sourceId
+}
+
+// InvalidateAuthentication deletes cached authentication of the source, the next call of
+// GetAuthentication will fetch it from Sources. Use when a source changes or becomes unavailable.
+func InvalidateAuthentication(ctx context.Context, sourceId string) error {
+	err := cache.Delete(ctx, authenticationCacheKey(ctx, sourceId), &clients.Authentication{})
+	if err != nil {
+		return fmt.Errorf("authentication cache delete error: %w", err)
+	}
+	return nil
+}
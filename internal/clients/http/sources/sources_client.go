@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
@@ -163,11 +164,43 @@ func (c *sourcesClient) listAllProvisioningSourcesByQuery(ctx context.Context, p
 	return result, total, nil
 }
 
+// GetAuthentication returns provisioning authentication of the source, successful results are cached
+// until the source is invalidated via InvalidateAuthentication or the cache expires. Authentications
+// are only cached in a shared cache, since the statuser invalidates them from a different process.
 func (c *sourcesClient) GetAuthentication(ctx context.Context, sourceId string) (*clients.Authentication, error) {
-	logger := logger(ctx)
 	ctx, span := telemetry.StartSpan(ctx, "GetAuthentication")
 	defer span.End()
 
+	if !cache.Shared() {
+		return c.fetchAuthentication(ctx, sourceId)
+	}
+
+	logger := logger(ctx)
+	key := authenticationCacheKey(ctx, sourceId)
+	result := &clients.Authentication{}
+	err := cache.Find(ctx, key, result)
+	if err == nil {
+		return result, nil
+	} else if !errors.Is(err, cache.ErrNotFound) {
+		logger.Warn().Err(err).Msg("Authentication cache find error")
+	}
+
+	result, err = c.fetchAuthentication(ctx, sourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cache.Set(ctx, key, result)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Authentication cache set error")
+	}
+
+	return result, nil
+}
+
+func (c *sourcesClient) fetchAuthentication(ctx context.Context, sourceId string) (*clients.Authentication, error) {
+	logger := logger(ctx)
+
 	// Get all the authentications linked to a specific source
 	resp, err := c.client.ListSourceAuthenticationsWithResponse(ctx, sourceId, &ListSourceAuthenticationsParams{}, headers.AddSourcesIdentityHeader, headers.AddEdgeRequestIdHeader)
 	if err != nil {
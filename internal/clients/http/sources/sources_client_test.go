@@ -5,12 +5,15 @@ import (
 	_ "embed"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
 
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http/sources"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -26,6 +29,25 @@ var (
 	provisioningSources string
 )
 
+// unavailableRedisCache configures redis cache on a port nobody listens on.
+func unavailableRedisCache(t *testing.T) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	orig := config.Application.Cache
+	config.Application.Cache.Type = "redis"
+	config.Application.Cache.Redis.Host = "127.0.0.1"
+	config.Application.Cache.Redis.Port = port
+	cache.Initialize()
+	t.Cleanup(func() {
+		config.Application.Cache = orig
+		cache.Initialize()
+	})
+}
+
 func TestSourcesClient_GetAuthentication(t *testing.T) {
 	t.Run("source with missing Provisioning auth", func(t *testing.T) {
 		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -87,6 +109,56 @@ func TestSourcesClient_GetAuthentication(t *testing.T) {
 
 		assert.Equal(t, "arn:aws:iam::123456789999:role/redhat-provisioning-role-2f6d01c", authentication.Payload)
 	})
+
+	t.Run("authentication not cached in memory", func(t *testing.T) {
+		config.Application.Cache.Type = "memory"
+		cache.Initialize()
+		t.Cleanup(func() {
+			config.Application.Cache.Type = "none"
+			cache.Initialize()
+		})
+
+		requests := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, err := io.WriteString(w, `{"data":[{"id":"256144","authtype":"provisioning-arn","username":"arn:aws:cached","availability_status":"in_progress","resource_type":"Application","resource_id":"304935"}],"meta":{"count":1,"limit":100,"offset":0}}`)
+			require.NoError(t, err, "failed to write http body for stubbed server")
+		}))
+		defer ts.Close()
+
+		ctx := context.Background()
+		client, err := sources.NewSourcesClientWithUrl(ctx, ts.URL)
+		require.NoError(t, err, "failed to initialize sources client with test server")
+
+		for i := 0; i < 2; i++ {
+			authentication, clientErr := client.GetAuthentication(ctx, "256144")
+			require.NoError(t, clientErr)
+			assert.Equal(t, "arn:aws:cached", authentication.Payload)
+		}
+		assert.Equal(t, 2, requests, "in-memory cache cannot be invalidated by the statuser")
+	})
+
+	t.Run("cache unavailable", func(t *testing.T) {
+		unavailableRedisCache(t)
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, err := io.WriteString(w, `{"data":[{"id":"256144","authtype":"provisioning-arn","username":"arn:aws:uncached","availability_status":"in_progress","resource_type":"Application","resource_id":"304935"}],"meta":{"count":1,"limit":100,"offset":0}}`)
+			require.NoError(t, err, "failed to write http body for stubbed server")
+		}))
+		defer ts.Close()
+
+		ctx := context.Background()
+		client, err := sources.NewSourcesClientWithUrl(ctx, ts.URL)
+		require.NoError(t, err, "failed to initialize sources client with test server")
+
+		authentication, clientErr := client.GetAuthentication(ctx, "256144")
+		require.NoError(t, clientErr, "cache errors should be treated as a miss")
+		assert.Equal(t, "arn:aws:uncached", authentication.Payload)
+	})
 }
 
 func TestSourcesClient_ListAllProvisioningSources(t *testing.T) {
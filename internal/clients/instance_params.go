@@ -32,6 +32,36 @@ type GCPInstanceParams struct {
 
 	// StartupScript contains metadata startup script (GCP tools must be installed on the image)
 	StartupScript string
+
+	// UserData contains custom cloud-config passed via user-data metadata (cloud-init must be installed on the image)
+	UserData string
+
+	// Labels are user labels of instances and disks
+	Labels map[string]string
+
+	// Network name or empty string for the default network
+	Network string
+
+	// Subnetwork name or empty string
+	Subnetwork string
+
+	// NetworkTags are matched by target tags of firewall rules
+	NetworkTags []string
+
+	// NoPublicIP skips the external IP access configuration
+	NoPublicIP bool
+
+	// RootVolumeGB is the boot disk size or zero for the image default
+	RootVolumeGB int64
+
+	// VolumeType is the disk type of the boot disk and data disks or empty for the default
+	VolumeType string
+
+	// DataDisks are empty disks attached to every instance
+	DataDisks []models.DataDisk
+
+	// Spot uses the Spot VM provisioning model, instances are deleted when preempted
+	Spot bool
 }
 
 type AWSInstanceParams struct {
@@ -52,6 +82,33 @@ type AWSInstanceParams struct {
 
 	// UserData for the instance launch
 	UserData []byte
+
+	// Tags are user tags of instances, volumes and network interfaces
+	Tags map[string]string
+
+	// SubnetID or empty string for the default subnet
+	SubnetID string
+
+	// SecurityGroupIDs or empty for the default security group
+	SecurityGroupIDs []string
+
+	// NoPublicIP disables public IP address assignment
+	NoPublicIP bool
+
+	// RootVolumeGB is the root volume size or zero for the AMI default
+	RootVolumeGB int64
+
+	// VolumeType is the EBS volume type of the root volume and data disks or empty for the defaults
+	VolumeType string
+
+	// DataDisks are empty EBS volumes attached to every instance
+	DataDisks []models.DataDisk
+
+	// Spot launches one-time spot instances terminated on interruption
+	Spot bool
+
+	// SpotMaxPrice is the maximum hourly price of spot instances or zero for the on-demand price
+	SpotMaxPrice float64
 }
 
 // AzureInstanceParams define parameters for a single instance launch on Azure.
@@ -75,6 +132,41 @@ type AzureInstanceParams struct {
 	// UserData for the instance launch
 	UserData []byte
 
-	// Tags carries list of key-value tags
+	// Tags carries list of key-value tags of the VM, disk, network interface and public IP
 	Tags map[string]*string
+
+	// SubnetID is full resource ID of a subnet, shared network is created when empty
+	SubnetID string
+
+	// SecurityGroupID is full resource ID of a network security group or empty
+	SecurityGroupID string
+
+	// NoPublicIP skips creation of public IP addresses
+	NoPublicIP bool
+
+	// RootVolumeGB is the OS disk size or zero for the image default
+	RootVolumeGB int64
+
+	// VolumeType is the storage account type of the OS disk and data disks or empty for Standard_LRS
+	VolumeType string
+
+	// DataDisks are empty managed disks attached to the VM
+	DataDisks []models.DataDisk
+
+	// Spot launches Azure Spot VMs deleted on eviction
+	Spot bool
+
+	// SpotMaxPrice is the maximum hourly price of spot VMs or zero for the pay-as-you-go price
+	SpotMaxPrice float64
+
+	// ResourceCreated is called with full Azure resource ID for every resource created by the
+	// launch, so it can be deleted when the launch fails. Optional.
+	ResourceCreated func(resourceType models.ReservationResourceType, id string)
+}
+
+// RecordResource calls the ResourceCreated callback when it is set.
+func (p *AzureInstanceParams) RecordResource(resourceType models.ReservationResourceType, id string) {
+	if p.ResourceCreated != nil {
+		p.ResourceCreated(resourceType, id)
+	}
 }
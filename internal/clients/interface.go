@@ -105,12 +105,34 @@ type EC2 interface {
 	//
 	RunInstances(ctx context.Context, details *AWSInstanceParams, amount int32, name string, reservation *models.AWSReservation) ([]*string, *string, error)
 
+	// DryRunInstances checks whether RunInstances would succeed without launching anything,
+	// permission and request validation errors are returned. Key name and user data are ignored.
+	DryRunInstances(ctx context.Context, details *AWSInstanceParams, amount int32) error
+
+	// ListVCPUQuotas returns On-Demand vCPU quotas of the region with their current usage.
+	ListVCPUQuotas(ctx context.Context) ([]*Quota, error)
+
+	// ListNetworks returns VPCs of the region with their subnets and security groups.
+	ListNetworks(ctx context.Context) ([]*Network, error)
+
 	// GetAccountId returns AWS account number.
 	GetAccountId(ctx context.Context) (string, error)
 
 	CheckPermission(ctx context.Context, auth *Authentication) ([]string, error)
 
 	DescribeInstanceDetails(ctx context.Context, InstanceIds []string) ([]*InstanceDescription, error)
+
+	// StartInstances starts one or more stopped instances.
+	StartInstances(ctx context.Context, instanceIds []string) error
+
+	// StopInstances stops one or more running instances.
+	StopInstances(ctx context.Context, instanceIds []string) error
+
+	// RebootInstances reboots one or more running instances.
+	RebootInstances(ctx context.Context, instanceIds []string) error
+
+	// TerminateInstances terminates one or more instances, this cannot be undone.
+	TerminateInstances(ctx context.Context, instanceIds []string) error
 }
 
 // GetAzureClient returns an Azure client with customer's subscription ID.
@@ -137,6 +159,36 @@ type Azure interface {
 	CreateVMs(ctx context.Context, instanceParams AzureInstanceParams, amount int64, vmNamePrefix string) (vmIds []InstanceDescription, err error)
 
 	ListResourceGroups(ctx context.Context) ([]string, error)
+
+	// StartInstances starts one or more deallocated virtual machines identified by full Azure resource IDs.
+	StartInstances(ctx context.Context, instanceIds []string) error
+
+	// StopInstances deallocates one or more virtual machines identified by full Azure resource IDs.
+	// Deallocated machines are not billed for compute resources.
+	StopInstances(ctx context.Context, instanceIds []string) error
+
+	// RebootInstances restarts one or more virtual machines identified by full Azure resource IDs.
+	RebootInstances(ctx context.Context, instanceIds []string) error
+
+	// TerminateInstances deletes one or more virtual machines identified by full Azure resource IDs
+	// together with their network interfaces, public IP addresses and disks.
+	TerminateInstances(ctx context.Context, instanceIds []string) error
+	// DeleteSSHKey deletes an SSH public key resource identified by full Azure resource ID.
+	DeleteSSHKey(ctx context.Context, handle string) error
+
+	// CheckPermission returns Azure RBAC actions used by the service which are not granted
+	// in the subscription. Error is returned together with the list when some are missing.
+	CheckPermission(ctx context.Context) ([]string, error)
+
+	// ListVCPUQuotas returns vCPU quotas of the location with their current usage.
+	ListVCPUQuotas(ctx context.Context, location string) ([]*Quota, error)
+
+	// ListNetworks returns virtual networks of the location with their subnets and network security groups.
+	ListNetworks(ctx context.Context, location string) ([]*Network, error)
+
+	// DeleteResource deletes a virtual machine, disk, network interface or public IP address
+	// identified by full Azure resource ID. Resources which do not exist are ignored.
+	DeleteResource(ctx context.Context, id string) error
 }
 
 type ServiceAzure interface {
@@ -177,4 +229,28 @@ type GCP interface {
 
 	// ListLaunchTemplates lists all launch templates and returns the next page token.
 	ListLaunchTemplates(ctx context.Context) ([]*LaunchTemplate, string, error)
+
+	// StartInstances starts one or more stopped instances in a zone.
+	StartInstances(ctx context.Context, zone string, instanceIds []string) error
+
+	// StopInstances stops one or more running instances in a zone.
+	StopInstances(ctx context.Context, zone string, instanceIds []string) error
+
+	// RebootInstances resets one or more running instances in a zone.
+	RebootInstances(ctx context.Context, zone string, instanceIds []string) error
+
+	// TerminateInstances deletes one or more instances in a zone, this cannot be undone.
+	TerminateInstances(ctx context.Context, zone string, instanceIds []string) error
+	// DeleteSSHKey removes the public key from project-wide ssh-keys metadata.
+	DeleteSSHKey(ctx context.Context, handle string) error
+
+	// CheckPermission returns IAM permissions used by the service which are not granted in
+	// the project. Error is returned together with the list when some are missing.
+	CheckPermission(ctx context.Context) ([]string, error)
+
+	// ListVCPUQuotas returns CPU quotas of the region with their current usage.
+	ListVCPUQuotas(ctx context.Context, region string) ([]*Quota, error)
+
+	// ListNetworks returns VPC networks with their subnetworks in the region and firewall target tags.
+	ListNetworks(ctx context.Context, region string) ([]*Network, error)
 }
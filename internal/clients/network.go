@@ -0,0 +1,51 @@
+package clients
+
+// Network is an AWS VPC, Azure virtual network or GCP VPC network of a cloud account.
+type Network struct {
+	// Network ID: AWS VPC ID, full Azure resource ID or GCP network name.
+	ID string `json:"id" yaml:"id"`
+
+	// Network name, AWS "Name" tag or empty when not tagged.
+	Name string `json:"name" yaml:"name"`
+
+	// Address range(s) of the network, empty for GCP networks.
+	CIDR []string `json:"cidr,omitempty" yaml:"cidr,omitempty"`
+
+	// Default network (AWS default VPC or GCP "default" network).
+	Default bool `json:"default" yaml:"default"`
+
+	// Subnets of the network in the region.
+	Subnets []*Subnet `json:"subnets" yaml:"subnets"`
+
+	// Security groups (AWS), network security groups (Azure) or firewall target tags (GCP) which
+	// can be used for instances in the network. Azure security groups are regional, all groups of
+	// the location are listed for every network.
+	SecurityGroups []*SecurityGroup `json:"security_groups" yaml:"security_groups"`
+}
+
+// Subnet is a subnet of a Network.
+type Subnet struct {
+	// Subnet ID: AWS subnet ID, full Azure resource ID or GCP subnetwork name.
+	ID string `json:"id" yaml:"id"`
+
+	// Subnet name, AWS "Name" tag or empty when not tagged.
+	Name string `json:"name" yaml:"name"`
+
+	// Address range of the subnet.
+	CIDR string `json:"cidr" yaml:"cidr"`
+
+	// Availability zone of AWS subnets, empty for other providers.
+	Zone string `json:"zone,omitempty" yaml:"zone,omitempty"`
+}
+
+// SecurityGroup is an AWS security group, Azure network security group or GCP firewall target tag.
+type SecurityGroup struct {
+	// Security group ID: AWS group ID, full Azure resource ID or GCP network tag.
+	ID string `json:"id" yaml:"id"`
+
+	// Security group name, for GCP names of firewall rules applied to the tag.
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the security group, empty for Azure and GCP.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
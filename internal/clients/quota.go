@@ -0,0 +1,65 @@
+package clients
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// Quota is a vCPU limit of a cloud account within a region together with its current usage.
+type Quota struct {
+	// Quota name as reported by the cloud provider.
+	Name string `json:"name" yaml:"name"`
+
+	// Maximum amount of vCPUs.
+	Limit int64 `json:"limit" yaml:"limit"`
+
+	// Amount of vCPUs currently in use.
+	Usage int64 `json:"usage" yaml:"usage"`
+
+	// Instance families the quota applies to (see InstanceFamily), empty when it applies to all
+	// instance types in the region.
+	Families []string `json:"families,omitempty" yaml:"families,omitempty"`
+}
+
+// Available returns amount of vCPUs which can be still launched, never negative.
+func (q *Quota) Available() int64 {
+	return max(q.Limit-q.Usage, 0)
+}
+
+// AppliesTo returns true when the quota limits instances of the given family.
+func (q *Quota) AppliesTo(family string) bool {
+	return len(q.Families) == 0 || slices.Contains(q.Families, family)
+}
+
+// awsFamilyAliases are AWS families which count into quota of a different family.
+var awsFamilyAliases = map[string]string{
+	"im": "i",
+	"is": "i",
+}
+
+// InstanceFamily returns lower-case family of the instance type as used by quotas: leading letters
+// of AWS types ("m" for "m5.large") and the series of GCP machine types ("n2" for "n2-standard-4").
+// Azure quota families cannot be derived from the size name, empty string is returned.
+func InstanceFamily(provider models.ProviderType, name InstanceTypeName) string {
+	str := strings.ToLower(string(name))
+
+	switch provider {
+	case models.ProviderTypeAWS:
+		end := strings.IndexFunc(str, func(r rune) bool { return !unicode.IsLetter(r) })
+		if end >= 0 {
+			str = str[:end]
+		}
+		if alias, ok := awsFamilyAliases[str]; ok {
+			return alias
+		}
+		return str
+	case models.ProviderTypeGCP:
+		series, _, _ := strings.Cut(str, "-")
+		return series
+	default:
+		return ""
+	}
+}
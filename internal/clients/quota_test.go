@@ -0,0 +1,40 @@
+package clients
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuota_Available(t *testing.T) {
+	assert.Equal(t, int64(6), (&Quota{Limit: 10, Usage: 4}).Available())
+	assert.Equal(t, int64(0), (&Quota{Limit: 10, Usage: 12}).Available())
+}
+
+func TestQuota_AppliesTo(t *testing.T) {
+	assert.True(t, (&Quota{}).AppliesTo("m"))
+	assert.True(t, (&Quota{Families: []string{"g", "vt"}}).AppliesTo("vt"))
+	assert.False(t, (&Quota{Families: []string{"g", "vt"}}).AppliesTo("m"))
+}
+
+func TestInstanceFamily(t *testing.T) {
+	tests := []struct {
+		provider models.ProviderType
+		name     InstanceTypeName
+		family   string
+	}{
+		{models.ProviderTypeAWS, "m5.large", "m"},
+		{models.ProviderTypeAWS, "inf2.xlarge", "inf"},
+		{models.ProviderTypeAWS, "u-6tb1.metal", "u"},
+		{models.ProviderTypeAWS, "im4gn.large", "i"},
+		{models.ProviderTypeGCP, "n2-standard-4", "n2"},
+		{models.ProviderTypeGCP, "e2-micro", "e2"},
+		{models.ProviderTypeAzure, "Standard_B2s", ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.name), func(t *testing.T) {
+			assert.Equal(t, tt.family, InstanceFamily(tt.provider, tt.name))
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package clients
 
+import "strings"
+
 // Region represents a provider's region (e.g. 'us-east-1' for EC2 or 'eastus' for Azure)
 type Region string
 
@@ -15,3 +17,12 @@ type Zone string
 func (z Zone) String() string {
 	return string(z)
 }
+
+// GCPRegion returns region of a GCP zone ("us-central1" for "us-central1-a"), regions are returned
+// unchanged.
+func GCPRegion(zone string) string {
+	if strings.Count(zone, "-") < 2 {
+		return zone
+	}
+	return zone[:strings.LastIndex(zone, "-")]
+}
@@ -0,0 +1,13 @@
+package clients
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCPRegion(t *testing.T) {
+	assert.Equal(t, "us-central1", GCPRegion("us-central1-a"))
+	assert.Equal(t, "us-central1", GCPRegion("us-central1"))
+	assert.Equal(t, "northamerica-northeast1", GCPRegion("northamerica-northeast1-b"))
+}
@@ -4,19 +4,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 )
 
-var ErrNotStartedVM = errors.New("the VM under given resumeToken not started")
+var (
+	ErrNotStartedVM = errors.New("the VM under given resumeToken not started")
+	ErrFailedVM     = errors.New("the VM failed to provision")
+)
+
+// FailingAzureImageID is an image which starts VM creation but the VM fails to provision
+const FailingAzureImageID = "/subscriptions/subUUID/resourceGroups/rgName/providers/Microsoft.Compute/images/failing"
 
 type AzureClientStub struct {
-	startedVms []*armcompute.VirtualMachine
-	createdVms []*armcompute.VirtualMachine
-	createdRgs []*armresources.ResourceGroup
+	startedVms  []*armcompute.VirtualMachine
+	createdVms  []*armcompute.VirtualMachine
+	createdRgs  []*armresources.ResourceGroup
+	failingVms  []string
+	deleted     []string
+	deletedKeys []string
+	actions     []InstanceActionCall
+	vmCounter   int
 }
 
 func DidCreateAzureResourceGroup(ctx context.Context, name string) bool {
@@ -40,6 +53,33 @@ func CountStubAzureVMs(ctx context.Context) int {
 	return len(client.createdVms)
 }
 
+// DeletedStubAzureResources returns IDs of resources deleted via DeleteResource in order
+func DeletedStubAzureResources(ctx context.Context) []string {
+	client, err := getAzureClientStub(ctx)
+	if err != nil {
+		return nil
+	}
+	return client.deleted
+}
+
+// DeletedStubAzureSSHKeys returns IDs of SSH public keys deleted via DeleteSSHKey in order
+func DeletedStubAzureSSHKeys(ctx context.Context) []string {
+	client, err := getAzureClientStub(ctx)
+	if err != nil {
+		return nil
+	}
+	return client.deletedKeys
+}
+
+// StubAzureInstanceActions returns instance actions performed via the stub in order
+func StubAzureInstanceActions(ctx context.Context) []InstanceActionCall {
+	client, err := getAzureClientStub(ctx)
+	if err != nil {
+		return nil
+	}
+	return client.actions
+}
+
 func (stub *AzureClientStub) Status(ctx context.Context) error {
 	return nil
 }
@@ -50,7 +90,10 @@ func (stub *AzureClientStub) CreateVMs(ctx context.Context, vmParams clients.Azu
 	var i int64
 	var err error
 	for i = 0; i < amount; i++ {
-		vmName := fmt.Sprintf("%s-%d", vmNamePrefix, int64(len(stub.startedVms))+i)
+		stub.vmCounter++
+		vmName := fmt.Sprintf("%s-%d", vmNamePrefix, stub.vmCounter)
+		vmParams.RecordResource(models.ResourceTypeAzurePublicIP, "/subscriptions/subUUID/resourceGroups/rgName/providers/Microsoft.Network/publicIPAddresses/"+vmName+"_ip")
+		vmParams.RecordResource(models.ResourceTypeAzureNIC, "/subscriptions/subUUID/resourceGroups/rgName/providers/Microsoft.Network/networkInterfaces/"+vmName+"_nic")
 		resumeTokens[i], err = stub.BeginCreateVM(ctx, vmParams, vmName)
 		if err != nil {
 			return vmIds, err
@@ -69,7 +112,7 @@ func (stub *AzureClientStub) CreateVMs(ctx context.Context, vmParams clients.Azu
 }
 
 func (stub *AzureClientStub) BeginCreateVM(ctx context.Context, vmParams clients.AzureInstanceParams, vmName string) (string, error) {
-	id := "with-polling-" + strconv.Itoa(len(stub.startedVms)+1)
+	id := "/subscriptions/subUUID/resourceGroups/rgName/providers/Microsoft.Compute/virtualMachines/" + vmName
 
 	vm := armcompute.VirtualMachine{
 		ID:       &id,
@@ -77,6 +120,11 @@ func (stub *AzureClientStub) BeginCreateVM(ctx context.Context, vmParams clients
 		Location: &vmParams.Location,
 	}
 	stub.startedVms = append(stub.startedVms, &vm)
+	vmParams.RecordResource(models.ResourceTypeAzureDisk, "/subscriptions/subUUID/resourceGroups/rgName/providers/Microsoft.Compute/disks/"+vmName+"_disk")
+	vmParams.RecordResource(models.ResourceTypeAzureVM, id)
+	if vmParams.ImageID == FailingAzureImageID {
+		stub.failingVms = append(stub.failingVms, id)
+	}
 	// we use the id as a resume token
 	return id, nil
 }
@@ -84,6 +132,9 @@ func (stub *AzureClientStub) BeginCreateVM(ctx context.Context, vmParams clients
 func (stub *AzureClientStub) WaitForVM(ctx context.Context, resumeToken string) (clients.AzureInstanceID, error) {
 	for i, vm := range stub.startedVms {
 		if *vm.ID == resumeToken {
+			if slices.Contains(stub.failingVms, resumeToken) {
+				return "", ErrFailedVM
+			}
 			stub.createdVms = append(stub.createdVms, vm)
 			stub.startedVms = append(stub.startedVms[:i], stub.startedVms[i+1:]...)
 			return clients.AzureInstanceID(*vm.ID), nil
@@ -115,3 +166,75 @@ func (stub *AzureClientStub) TenantId(ctx context.Context) (clients.AzureTenantI
 func (stub *AzureClientStub) ListResourceGroups(ctx context.Context) ([]string, error) {
 	return []string{"firstGroup", "secondGroup", "test"}, nil
 }
+
+func (stub *AzureClientStub) StartInstances(ctx context.Context, instanceIds []string) error {
+	stub.actions = append(stub.actions, InstanceActionCall{Action: models.InstanceActionStart, InstanceIDs: instanceIds})
+	return nil
+}
+
+func (stub *AzureClientStub) StopInstances(ctx context.Context, instanceIds []string) error {
+	stub.actions = append(stub.actions, InstanceActionCall{Action: models.InstanceActionStop, InstanceIDs: instanceIds})
+	return nil
+}
+
+func (stub *AzureClientStub) RebootInstances(ctx context.Context, instanceIds []string) error {
+	stub.actions = append(stub.actions, InstanceActionCall{Action: models.InstanceActionReboot, InstanceIDs: instanceIds})
+	return nil
+}
+
+func (stub *AzureClientStub) TerminateInstances(ctx context.Context, instanceIds []string) error {
+	stub.actions = append(stub.actions, InstanceActionCall{Action: models.InstanceActionTerminate, InstanceIDs: instanceIds})
+	for _, instanceId := range instanceIds {
+		for i, vm := range stub.createdVms {
+			if *vm.ID == instanceId {
+				stub.createdVms = append(stub.createdVms[:i], stub.createdVms[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (stub *AzureClientStub) DeleteSSHKey(ctx context.Context, handle string) error {
+	stub.deletedKeys = append(stub.deletedKeys, handle)
+	return nil
+}
+
+func (stub *AzureClientStub) CheckPermission(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (stub *AzureClientStub) ListVCPUQuotas(ctx context.Context, location string) ([]*clients.Quota, error) {
+	return []*clients.Quota{
+		{
+			Name:  "Total Regional vCPUs",
+			Limit: 20,
+			Usage: 4,
+		},
+	}, nil
+}
+
+func (stub *AzureClientStub) DeleteResource(ctx context.Context, id string) error {
+	stub.startedVms = slices.DeleteFunc(stub.startedVms, func(vm *armcompute.VirtualMachine) bool { return *vm.ID == id })
+	stub.createdVms = slices.DeleteFunc(stub.createdVms, func(vm *armcompute.VirtualMachine) bool { return *vm.ID == id })
+	stub.deleted = append(stub.deleted, id)
+	return nil
+}
+
+func (stub *AzureClientStub) ListNetworks(ctx context.Context, location string) ([]*clients.Network, error) {
+	return []*clients.Network{
+		{
+			ID:   "/subscriptions/4b9d213f-712f-4d17-a483-8a10bbe9df3a/resourceGroups/redhat-deployed/providers/Microsoft.Network/virtualNetworks/redhat-vnet",
+			Name: "redhat-vnet",
+			CIDR: []string{"10.0.0.0/16"},
+			Subnets: []*clients.Subnet{
+				{
+					ID:   "/subscriptions/4b9d213f-712f-4d17-a483-8a10bbe9df3a/resourceGroups/redhat-deployed/providers/Microsoft.Network/virtualNetworks/redhat-vnet/subnets/redhat-subnet",
+					Name: "redhat-subnet",
+					CIDR: "10.0.0.0/24",
+				},
+			},
+			SecurityGroups: []*clients.SecurityGroup{},
+		},
+	}, nil
+}
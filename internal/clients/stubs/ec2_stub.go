@@ -20,6 +20,13 @@ const ec2CtxKey ec2CtxKeyType = iota
 
 type EC2ClientStub struct {
 	Imported []*types.KeyPairInfo
+	actions  []InstanceActionCall
+}
+
+// InstanceActionCall is a lifecycle action performed on instances via a client stub
+type InstanceActionCall struct {
+	Action      models.InstanceAction
+	InstanceIDs []string
 }
 
 func WithEC2Client(parent context.Context) context.Context {
@@ -36,6 +43,15 @@ func AddStubbedEC2KeyPair(ctx context.Context, info *types.KeyPairInfo) error {
 	return nil
 }
 
+// StubEC2InstanceActions returns instance actions performed via the stub in order
+func StubEC2InstanceActions(ctx context.Context) []InstanceActionCall {
+	si, err := getEC2StubFromContext(ctx)
+	if err != nil {
+		return nil
+	}
+	return si.actions
+}
+
 func newEC2ServiceClientStubWithRegion(ctx context.Context, region string) (clients.EC2, error) {
 	return nil, nil
 }
@@ -177,10 +193,25 @@ func (mock *EC2ClientStub) CheckPermission(ctx context.Context, auth *clients.Au
 	return nil, nil
 }
 
+func (mock *EC2ClientStub) ListVCPUQuotas(ctx context.Context) ([]*clients.Quota, error) {
+	return []*clients.Quota{
+		{
+			Name:     "Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances",
+			Limit:    32,
+			Usage:    8,
+			Families: []string{"a", "c", "d", "h", "i", "m", "r", "t", "z"},
+		},
+	}, nil
+}
+
 func (mock *EC2ClientStub) RunInstances(ctx context.Context, details *clients.AWSInstanceParams, amount int32, name string, reservation *models.AWSReservation) ([]*string, *string, error) {
 	return nil, nil, nil
 }
 
+func (mock *EC2ClientStub) DryRunInstances(ctx context.Context, details *clients.AWSInstanceParams, amount int32) error {
+	return nil
+}
+
 func (mock *EC2ClientStub) GetAccountId(ctx context.Context) (string, error) {
 	return "", nil
 }
@@ -197,3 +228,47 @@ func (mock *EC2ClientStub) DescribeInstanceDetails(ctx context.Context, Instance
 		},
 	}, nil
 }
+
+func (mock *EC2ClientStub) StartInstances(ctx context.Context, instanceIds []string) error {
+	mock.actions = append(mock.actions, InstanceActionCall{Action: models.InstanceActionStart, InstanceIDs: instanceIds})
+	return nil
+}
+
+func (mock *EC2ClientStub) StopInstances(ctx context.Context, instanceIds []string) error {
+	mock.actions = append(mock.actions, InstanceActionCall{Action: models.InstanceActionStop, InstanceIDs: instanceIds})
+	return nil
+}
+
+func (mock *EC2ClientStub) RebootInstances(ctx context.Context, instanceIds []string) error {
+	mock.actions = append(mock.actions, InstanceActionCall{Action: models.InstanceActionReboot, InstanceIDs: instanceIds})
+	return nil
+}
+
+func (mock *EC2ClientStub) TerminateInstances(ctx context.Context, instanceIds []string) error {
+	mock.actions = append(mock.actions, InstanceActionCall{Action: models.InstanceActionTerminate, InstanceIDs: instanceIds})
+	return nil
+}
+
+func (mock *EC2ClientStub) ListNetworks(ctx context.Context) ([]*clients.Network, error) {
+	return []*clients.Network{
+		{
+			ID:      "vpc-0a1b2c3d",
+			CIDR:    []string{"172.31.0.0/16"},
+			Default: true,
+			Subnets: []*clients.Subnet{
+				{
+					ID:   "subnet-0a1b2c3d",
+					CIDR: "172.31.0.0/20",
+					Zone: "us-east-1a",
+				},
+			},
+			SecurityGroups: []*clients.SecurityGroup{
+				{
+					ID:          "sg-0a1b2c3d",
+					Name:        "default",
+					Description: "default VPC security group",
+				},
+			},
+		},
+	}, nil
+}
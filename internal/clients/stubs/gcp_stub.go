@@ -20,7 +20,8 @@ var (
 
 type (
 	GCPClientStub struct {
-		Instances []*string
+		Instances   []*string
+		DeletedKeys []string
 	}
 	GCPServiceClientStub struct{}
 )
@@ -67,6 +68,15 @@ func CountStubInstancesGCP(ctx context.Context) int {
 	return len(client.Instances)
 }
 
+// DeletedStubGCPSSHKeys returns public keys removed via DeleteSSHKey in order
+func DeletedStubGCPSSHKeys(ctx context.Context) []string {
+	client, err := getCustomerGCPClientStub(ctx, &clients.Authentication{})
+	if err != nil {
+		return nil
+	}
+	return client.DeletedKeys
+}
+
 func (mock *GCPClientStub) ListAllRegions(ctx context.Context) ([]clients.Region, error) {
 	return nil, nil
 }
@@ -126,3 +136,93 @@ func (mock *GCPServiceClientStub) ListAllRegionsAndZones(ctx context.Context) ([
 	}
 	return regions, zones, nil
 }
+
+func (mock *GCPClientStub) StartInstances(ctx context.Context, zone string, instanceIds []string) error {
+	return mock.findInstances(instanceIds)
+}
+
+func (mock *GCPClientStub) StopInstances(ctx context.Context, zone string, instanceIds []string) error {
+	return mock.findInstances(instanceIds)
+}
+
+func (mock *GCPClientStub) RebootInstances(ctx context.Context, zone string, instanceIds []string) error {
+	return mock.findInstances(instanceIds)
+}
+
+func (mock *GCPClientStub) TerminateInstances(ctx context.Context, zone string, instanceIds []string) error {
+	if err := mock.findInstances(instanceIds); err != nil {
+		return err
+	}
+	for _, id := range instanceIds {
+		for i, instanceID := range mock.Instances {
+			if ptr.From(instanceID) == id {
+				mock.Instances = append(mock.Instances[:i], mock.Instances[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (mock *GCPClientStub) findInstances(instanceIds []string) error {
+	for _, id := range instanceIds {
+		found := false
+		for _, instanceID := range mock.Instances {
+			if ptr.From(instanceID) == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrMissingInstanceID
+		}
+	}
+	return nil
+}
+
+func (mock *GCPClientStub) DeleteSSHKey(ctx context.Context, handle string) error {
+	mock.DeletedKeys = append(mock.DeletedKeys, handle)
+	return nil
+}
+
+func (mock *GCPClientStub) CheckPermission(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (mock *GCPClientStub) ListVCPUQuotas(ctx context.Context, region string) ([]*clients.Quota, error) {
+	return []*clients.Quota{
+		{
+			Name:     "CPUS",
+			Limit:    24,
+			Families: []string{"e2", "n1", "f1", "g1"},
+		},
+		{
+			Name:     "N2_CPUS",
+			Limit:    8,
+			Families: []string{"n2"},
+		},
+	}, nil
+}
+
+func (mock *GCPClientStub) ListNetworks(ctx context.Context, region string) ([]*clients.Network, error) {
+	return []*clients.Network{
+		{
+			ID:      "default",
+			Name:    "default",
+			Default: true,
+			Subnets: []*clients.Subnet{
+				{
+					ID:   "default",
+					Name: "default",
+					CIDR: "10.128.0.0/20",
+				},
+			},
+			SecurityGroups: []*clients.SecurityGroup{
+				{
+					ID:   "http-server",
+					Name: "default-allow-http",
+				},
+			},
+		},
+	}, nil
+}
@@ -18,15 +18,19 @@ type proxy struct {
 
 var config struct {
 	App struct {
-		Port           int    `env:"PORT" env-default:"8000" env-description:"HTTP port of the API service"`
-		InstancePrefix string `env:"INSTANCE_PREFIX" env-default:"" env-description:"prefix for all VMs names"`
-		RbacEnabled    bool   `env:"RBAC_ENABLED" env-default:"false" env-description:"RBAC checking (REST_ENDPOINTS_RBAC_URL must be present)"`
+		Port           int      `env:"PORT" env-default:"8000" env-description:"HTTP port of the API service"`
+		InstancePrefix string   `env:"INSTANCE_PREFIX" env-default:"" env-description:"prefix for all VMs names"`
+		RbacEnabled    bool     `env:"RBAC_ENABLED" env-default:"false" env-description:"RBAC checking (REST_ENDPOINTS_RBAC_URL must be present)"`
+		AdminOrgIDs    []string `env:"ADMIN_ORG_IDS" env-description:"organization IDs whose org admins can use admin endpoints (comma separated)"`
 		Notifications  struct {
 			Enabled bool `env:"ENABLED" env-default:"false" env-description:"notifications enabled"`
 		} `env-prefix:"NOTIFICATIONS_"`
+		StatusEvents struct {
+			Enabled bool `env:"ENABLED" env-default:"false" env-description:"reservation status events enabled"`
+		} `env-prefix:"STATUS_EVENTS_"`
 		Cache struct {
-			Type       string        `env:"TYPE" env-default:"none" env-description:"application cache (none, redis)"`
-			Expiration time.Duration `env:"EXPIRATION" env-default:"10m" env-description:"expiration for Redis application cache (time interval syntax)"`
+			Type       string        `env:"TYPE" env-default:"none" env-description:"application cache (none, redis, memory)"`
+			Expiration time.Duration `env:"EXPIRATION" env-default:"10m" env-description:"expiration for application cache (time interval syntax)"`
 			Redis      struct {
 				Host     string `env:"HOST" env-default:"localhost" env-description:"redis hostname"`
 				Port     int    `env:"PORT" env-default:"6379" env-description:"redis port"`
@@ -36,6 +40,7 @@ var config struct {
 			} `env-prefix:"REDIS_"`
 			Memory struct {
 				CleanupInterval time.Duration `env:"CLEANUP_INTERVAL" env-default:"5m" env-description:"in-memory expiration interval (time interval syntax)"`
+				MaxEntries      int           `env:"MAX_ENTRIES" env-default:"10000" env-description:"maximum number of in-memory cache items, items expiring first are evicted (0 for no limit)"`
 			} `env-prefix:"MEM_"`
 		} `env-prefix:"CACHE_"`
 	} `env-prefix:"APP_"`
@@ -47,6 +52,9 @@ var config struct {
 		CleanupEnabled  bool          `env:"CLEANUP_ENABLED" env-default:"false" env-description:"reservation cleanup enabled"`
 		Lifetime        time.Duration `env:"LIFETIME" env-default:"8760h" env-description:"how old reservation should be deleted, default equal to 365 days"`
 		CleanupInterval time.Duration `env:"CLEANUP_INTERVAL" env-default:"1h" env-description:"how often to cleanup the reservation"`
+		MaxLaunchDelay  time.Duration `env:"MAX_LAUNCH_DELAY" env-default:"720h" env-description:"how far in the future a reservation can be scheduled (zero means no limit), default equal to 30 days"`
+		ExpiryInterval  time.Duration `env:"EXPIRY_INTERVAL" env-default:"5m" env-description:"how often to check for expired reservations"`
+		ExpiryWarning   time.Duration `env:"EXPIRY_WARNING" env-default:"1h" env-description:"how long before the expiry a warning notification is sent"`
 	} `env-prefix:"RESERVATION_"`
 	Database struct {
 		Host         string        `env:"HOST" env-default:"localhost" env-description:"main database hostname"`
@@ -148,6 +156,9 @@ var config struct {
 		PollInterval time.Duration `env:"POLL_INTERVAL" env-default:"5s" env-description:"polling interval (network timeout)"`
 		Concurrency  int           `env:"CONCURRENCY" env-default:"33" env-description:"amount of worker polling goroutines (effective concurrency)"`
 		Timeout      time.Duration `env:"TIMEOUT" env-default:"30m" env-description:"total timeout for a single job to complete (duration)"`
+		MaxRetries   int           `env:"MAX_RETRIES" env-default:"3" env-description:"retries of a failed job unless set by the job (zero disables retries)"`
+		RetryBackoff time.Duration `env:"RETRY_BACKOFF" env-default:"10s" env-description:"delay before the first retry, doubled with every further retry (duration)"`
+		Visibility   time.Duration `env:"VISIBILITY_TIMEOUT" env-default:"35m" env-description:"time after which an unfinished job is redelivered, must be longer than timeout (duration)"`
 	} `env-prefix:"WORKER_"`
 	Unleash struct {
 		Enabled     bool   `env:"ENABLED" env-default:"false" env-description:"unleash service (feature flags)"`
@@ -201,6 +212,7 @@ var (
 var (
 	ErrValidateMissingSecret = errors.New("config error: Cloudwatch enabled but Region or Key or Secret are blank")
 	ErrValidateGroupStream   = errors.New("config error: Cloudwatch enabled but Group or Stream is blank")
+	ErrValidateVisibility    = errors.New("config error: Worker visibility timeout must be longer than timeout")
 )
 
 var hostname string
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -21,3 +22,15 @@ func TestBlankNon1(t *testing.T) {
 func TestBlankNon2(t *testing.T) {
 	require.True(t, present("x", "x"))
 }
+
+func TestValidateVisibility(t *testing.T) {
+	orig := *Worker
+	defer func() { *Worker = orig }()
+
+	Worker.Timeout = 30 * time.Minute
+	Worker.Visibility = 30 * time.Minute
+	require.ErrorIs(t, validate(), ErrValidateVisibility)
+
+	Worker.Visibility = 35 * time.Minute
+	require.NotErrorIs(t, validate(), ErrValidateVisibility)
+}
@@ -26,6 +26,10 @@ func validate() error {
 		}
 	}
 
+	if Worker.Visibility <= Worker.Timeout {
+		return ErrValidateVisibility
+	}
+
 	slice, err := base64.StdEncoding.DecodeString(config.GCP.JSON)
 	config.GCP.JSON = string(slice)
 	if err != nil {
@@ -2,6 +2,8 @@ package dao
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/usrerr"
 
@@ -39,6 +41,42 @@ var (
 	// ErrReservationRateExceeded is returned when SQL constraint does not allow to insert more reservations
 	ErrReservationRateExceeded = usrerr.New(429, "rate limit exceeded", "too many reservations, wait and retry")
 
+	// ErrReservationLimitExceeded is returned when a reservation exceeds a limit of the account which
+	// cannot be satisfied by waiting
+	ErrReservationLimitExceeded = usrerr.New(422, "reservation limit exceeded", "reservation exceeds limits of the account")
+
 	// ErrPubkeyNotFound is returned when a nil pointer to a pubkey is used for reservation detail
 	ErrPubkeyNotFound = usrerr.New(404, "pubkey not found", "no pubkey found, it may have been already deleted")
 )
+
+// Names of account limits, see models.AccountLimits
+const (
+	LimitReservationsPerSecond      = "reservations_per_second"
+	LimitMaxPendingReservations     = "max_pending_reservations"
+	LimitMaxInstancesPerReservation = "max_instances_per_reservation"
+	LimitMaxInstancesPerDay         = "max_instances_per_day"
+)
+
+// LimitError is returned when an account limit is exceeded. It wraps ErrReservationRateExceeded
+// when the request can be retried later, ErrReservationLimitExceeded otherwise.
+type LimitError struct {
+	// Name of the exceeded limit
+	Limit string
+
+	// Maximum value of the limit
+	Maximum int64
+
+	// Time after the request can be retried, zero when retrying does not help
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s maximum is %d", e.Unwrap(), e.Limit, e.Maximum)
+}
+
+func (e *LimitError) Unwrap() error {
+	if e.RetryAfter > 0 {
+		return ErrReservationRateExceeded
+	}
+	return ErrReservationLimitExceeded
+}
@@ -0,0 +1,76 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// SortDirection is ordering of list results.
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+// ReservationResult is a state of a reservation derived from its success flag.
+type ReservationResult string
+
+const (
+	// ReservationPending matches reservations which have not finished yet.
+	ReservationPending ReservationResult = "pending"
+
+	// ReservationSuccess matches reservations which finished successfully.
+	ReservationSuccess ReservationResult = "success"
+
+	// ReservationFailure matches reservations which finished with an error.
+	ReservationFailure ReservationResult = "failure"
+)
+
+var (
+	// ReservationSortFields are fields reservation lists can be sorted by, the first one is the default.
+	ReservationSortFields = []string{"id", "created_at", "finished_at", "provider"}
+
+	// PubkeySortFields are fields pubkey lists can be sorted by, the first one is the default.
+	PubkeySortFields = []string{"id", "name", "type"}
+)
+
+// ReservationFilter limits and orders reservation lists. Zero values do not filter.
+type ReservationFilter struct {
+	// Provider type or ProviderTypeUnknown for all providers.
+	Provider models.ProviderType
+
+	// Status of the reservation.
+	Status ReservationResult
+
+	// Only reservations created at or after this time.
+	CreatedAfter time.Time
+
+	// Only reservations created before this time.
+	CreatedBefore time.Time
+
+	// Source ID the reservation was launched from.
+	SourceID string
+
+	// Case-insensitive substring of the instance name or name pattern.
+	Name string
+
+	// One of ReservationSortFields, sorted by ID when blank.
+	SortBy string
+
+	// Sort direction, ascending when blank.
+	SortDir SortDirection
+}
+
+// PubkeyFilter limits and orders pubkey lists. Zero values do not filter.
+type PubkeyFilter struct {
+	// Case-insensitive substring of the pubkey name.
+	Name string
+
+	// One of PubkeySortFields, sorted by ID when blank.
+	SortBy string
+
+	// Sort direction, ascending when blank.
+	SortDir SortDirection
+}
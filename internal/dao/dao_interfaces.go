@@ -12,9 +12,11 @@ package dao
 
 import (
 	"context"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/page"
 )
 
 var GetAccountDao = func(ctx context.Context) AccountDao {
@@ -29,6 +31,17 @@ type AccountDao interface {
 	GetOrCreateByIdentity(ctx context.Context, orgId string, accountNumber string) (*models.Account, error)
 	GetByOrgId(ctx context.Context, orgId string) (*models.Account, error)
 	List(ctx context.Context, limit, offset int64) ([]*models.Account, error)
+
+	// GetLimits returns reservation limits for a particular account, all limits are NULL when
+	// none were set.
+	GetLimits(ctx context.Context) (*models.AccountLimits, error)
+
+	// UnscopedGetLimits returns reservation limits of an account, all limits are NULL when none
+	// were set. UNSCOPED.
+	UnscopedGetLimits(ctx context.Context, accountId int64) (*models.AccountLimits, error)
+
+	// UnscopedUpdateLimits creates or replaces reservation limits of an account. UNSCOPED.
+	UnscopedUpdateLimits(ctx context.Context, limits *models.AccountLimits) error
 }
 
 var GetPubkeyDao = func(ctx context.Context) PubkeyDao {
@@ -40,15 +53,23 @@ var GetPubkeyDao = func(ctx context.Context) PubkeyDao {
 type PubkeyDao interface {
 	Create(ctx context.Context, pk *models.Pubkey) error
 	Update(ctx context.Context, pk *models.Pubkey) error
+	// UpdateAndDeleteResources updates the pubkey and deletes records of it uploaded to clouds
+	// in one transaction, the deleted records are returned. Keys in the cloud are not deleted.
+	UpdateAndDeleteResources(ctx context.Context, pk *models.Pubkey) ([]*models.PubkeyResource, error)
 	GetById(ctx context.Context, id int64) (*models.Pubkey, error)
-	List(ctx context.Context, limit, offset int64) ([]*models.Pubkey, error)
-	Count(ctx context.Context) (int, error)
+	List(ctx context.Context, filter *PubkeyFilter, limit, offset int64) ([]*models.Pubkey, error)
+	ListKeyset(ctx context.Context, filter *PubkeyFilter, after *page.Cursor, limit int64) ([]*models.Pubkey, error)
+	Count(ctx context.Context, filter *PubkeyFilter) (int, error)
 	Delete(ctx context.Context, id int64) error
 
 	UnscopedCreateResource(ctx context.Context, pkr *models.PubkeyResource) error
 	UnscopedGetResourceBySourceAndRegion(ctx context.Context, pubkeyId int64, sourceId string, region string) (*models.PubkeyResource, error)
 	UnscopedListResourcesByPubkeyId(ctx context.Context, pkId int64) ([]*models.PubkeyResource, error)
 	UnscopedDeleteResource(ctx context.Context, id int64) error
+
+	// DeleteResourcesBySourceId deletes records of pubkeys uploaded via a source for a particular
+	// account and returns the amount of deleted records. Keys in the cloud are not deleted.
+	DeleteResourcesBySourceId(ctx context.Context, sourceId string) (int64, error)
 }
 
 var GetReservationDao = func(ctx context.Context) ReservationDao {
@@ -86,16 +107,35 @@ type ReservationDao interface {
 	// GetGCPById returns reservation for a particular account.
 	GetGCPById(ctx context.Context, id int64) (*models.GCPReservation, error)
 
-	// Count returns total reservations for a particular account.
-	Count(ctx context.Context) (int, error)
+	// Count returns total reservations matching the filter for a particular account.
+	Count(ctx context.Context, filter *ReservationFilter) (int, error)
+
+	// List returns reservations matching the filter for a particular account.
+	List(ctx context.Context, filter *ReservationFilter, limit, offset int64) ([]*models.Reservation, error)
+
+	// ListKeyset returns reservations matching the filter for a particular account ordered by
+	// creation time and ID, starting after the cursor or from the beginning when it is nil.
+	// Sort field of the filter is ignored, sort direction is respected.
+	ListKeyset(ctx context.Context, filter *ReservationFilter, after *page.Cursor, limit int64) ([]*models.Reservation, error)
 
-	// List returns reservation for a particular account.
-	List(ctx context.Context, limit, offset int64) ([]*models.Reservation, error)
+	// CountScheduled returns total reservations waiting for their launch time for a particular account.
+	CountScheduled(ctx context.Context) (int, error)
+
+	// ListScheduled returns reservations waiting for their launch time for a particular account,
+	// ordered by the launch time.
+	ListScheduled(ctx context.Context, limit, offset int64) ([]*models.Reservation, error)
+
+	// CountInstancesSince returns the amount of instances of reservations created since the given
+	// time which did not fail for a particular account.
+	CountInstancesSince(ctx context.Context, since time.Time) (int64, error)
 
 	// ListInstances returns instances associated to a reservation. UNSCOPED.
 	// It currently lists all instances and not instances for a reservation, this is a TODO.
 	ListInstances(ctx context.Context, reservationId int64) ([]*models.ReservationInstance, error)
 
+	// GetInstance returns an instance associated to a reservation for a particular account.
+	GetInstance(ctx context.Context, reservationId int64, instanceId string) (*models.ReservationInstance, error)
+
 	// UpdateStatus sets status field and increment step counter by addSteps. UNSCOPED.
 	UpdateStatus(ctx context.Context, id int64, status string, addSteps int32) error
 
@@ -111,17 +151,65 @@ type ReservationDao interface {
 	// UpdateReservationInstance updates an instance with its description
 	UpdateReservationInstance(ctx context.Context, reservationID int64, instance *clients.InstanceDescription) error
 
-	// FinishWithSuccess sets Success flag. UNSCOPED.
+	// UpdateInstanceAction records the last lifecycle action, its status and error of an instance
+	// and sets the action timestamp. UNSCOPED.
+	UpdateInstanceAction(ctx context.Context, instance *models.ReservationInstance) error
+
+	// Cancel marks an unfinished reservation as cancelled, the background job is responsible for
+	// rolling back created resources and finishing the reservation. Scheduled reservations are
+	// finished immediately.
+	Cancel(ctx context.Context, id int64) error
+
+	// UnscopedIsCancelled returns true when reservation was cancelled. UNSCOPED.
+	UnscopedIsCancelled(ctx context.Context, id int64) (bool, error)
+
+	// UnscopedListExpiring returns successful reservations expiring within the warning period
+	// which were not notified yet, up to limit records. UNSCOPED.
+	UnscopedListExpiring(ctx context.Context, warning time.Duration, limit int64) ([]*models.Reservation, error)
+
+	// UnscopedMarkExpiryNotified records that the expiry warning was sent. UNSCOPED.
+	UnscopedMarkExpiryNotified(ctx context.Context, id int64) error
+
+	// UnscopedListExpired returns successful reservations past their expiry time which were not
+	// expired yet, up to limit records. UNSCOPED.
+	UnscopedListExpired(ctx context.Context, limit int64) ([]*models.Reservation, error)
+
+	// UnscopedMarkExpired records that termination of the expired instances was enqueued. UNSCOPED.
+	UnscopedMarkExpired(ctx context.Context, id int64) error
+
+	// UnscopedCreateResource records a cloud resource created by a launch job in the resource
+	// ledger of the reservation. UNSCOPED.
+	UnscopedCreateResource(ctx context.Context, resource *models.ReservationResource) error
+
+	// UnscopedListResources returns resources from the ledger of the reservation which were not
+	// deleted yet, in the order they were created. UNSCOPED.
+	UnscopedListResources(ctx context.Context, reservationId int64) ([]*models.ReservationResource, error)
+
+	// UnscopedMarkResourceDeleted records that the resource was deleted from the cloud. UNSCOPED.
+	UnscopedMarkResourceDeleted(ctx context.Context, id int64) error
+
+	// FinishWithSuccess sets Success flag. Finished and cancelled reservations are not updated and
+	// ErrAffectedMismatch is returned. UNSCOPED.
 	FinishWithSuccess(ctx context.Context, id int64) error
 
-	// FinishWithError sets Success flag and Error flag. UNSCOPED.
+	// FinishWithError sets Success flag and Error flag. Status of cancelled reservations is set to "Cancelled".
+	// Finished reservations are not updated and ErrAffectedMismatch is returned. UNSCOPED.
 	FinishWithError(ctx context.Context, id int64, errorString string) error
 
+	// FailUnfinishedBySourceId finishes all unfinished reservations of a source with an error for
+	// a particular account and returns IDs of updated reservations. Reservations are also
+	// marked as cancelled, so their launch jobs stop.
+	FailUnfinishedBySourceId(ctx context.Context, sourceId string, errorString string) ([]int64, error)
+
 	// Delete deletes a reservation. Only used in tests and background cleanup job. UNSCOPED.
 	Delete(ctx context.Context, id int64) error
 
 	// Cleanup old reservations
 	Cleanup(ctx context.Context) error
+
+	// CleanupResources deletes ledger records of deleted resources and of successful reservations
+	// which do not expire, those are never torn down by the ledger. UNSCOPED.
+	CleanupResources(ctx context.Context) error
 }
 
 var GetStatDao = func(ctx context.Context) StatDao {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/jackc/pgx/v5"
@@ -113,3 +114,45 @@ func (x *accountDao) List(ctx context.Context, limit, offset int64) ([]*models.A
 	}
 	return result, nil
 }
+
+func (x *accountDao) GetLimits(ctx context.Context) (*models.AccountLimits, error) {
+	return x.UnscopedGetLimits(ctx, identity.AccountId(ctx))
+}
+
+func (x *accountDao) UnscopedGetLimits(ctx context.Context, accountId int64) (*models.AccountLimits, error) {
+	query := `SELECT * FROM account_limits WHERE account_id = $1`
+	result := &models.AccountLimits{}
+
+	err := pgxscan.Get(ctx, db.Pool, result, query, accountId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &models.AccountLimits{AccountID: accountId}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *accountDao) UnscopedUpdateLimits(ctx context.Context, limits *models.AccountLimits) error {
+	query := `INSERT INTO account_limits (account_id, reservations_per_second, max_pending_reservations,
+			max_instances_per_reservation, max_instances_per_day)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (account_id) DO UPDATE SET
+			reservations_per_second = EXCLUDED.reservations_per_second,
+			max_pending_reservations = EXCLUDED.max_pending_reservations,
+			max_instances_per_reservation = EXCLUDED.max_instances_per_reservation,
+			max_instances_per_day = EXCLUDED.max_instances_per_day,
+			updated_at = current_timestamp
+		RETURNING updated_at`
+
+	err := db.Pool.QueryRow(ctx, query,
+		limits.AccountID,
+		limits.ReservationsPerSecond,
+		limits.MaxPendingReservations,
+		limits.MaxInstancesPerReservation,
+		limits.MaxInstancesPerDay).Scan(&limits.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package pgx
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/page"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// whereBuilder collects SQL conditions joined by AND together with their positional arguments.
+type whereBuilder struct {
+	conditions []string
+	args       []any
+}
+
+// add appends a condition with a single argument, the "?" placeholder is replaced by its position.
+func (w *whereBuilder) add(condition string, arg any) {
+	w.args = append(w.args, arg)
+	w.conditions = append(w.conditions, strings.Replace(condition, "?", fmt.Sprintf("$%d", len(w.args)), 1))
+}
+
+// addRaw appends a condition without arguments.
+func (w *whereBuilder) addRaw(condition string) {
+	w.conditions = append(w.conditions, condition)
+}
+
+// where returns the WHERE clause including the keyword.
+func (w *whereBuilder) where() string {
+	return "WHERE " + strings.Join(w.conditions, " AND ")
+}
+
+// next returns placeholder for an argument appended after all conditions.
+func (w *whereBuilder) next(arg any) string {
+	w.args = append(w.args, arg)
+	return fmt.Sprintf("$%d", len(w.args))
+}
+
+// containsPattern returns ILIKE pattern matching the substring literally.
+func containsPattern(substring string) string {
+	return "%" + likeEscaper.Replace(substring) + "%"
+}
+
+// orderBy returns the ORDER BY clause, only whitelisted fields are accepted and ID is used
+// as a tiebreaker so pagination is stable.
+func orderBy(sortBy string, sortDir dao.SortDirection, fields []string) string {
+	if !slices.Contains(fields, sortBy) {
+		sortBy = fields[0]
+	}
+	dir := "ASC"
+	if sortDir == dao.SortDesc {
+		dir = "DESC"
+	}
+	if sortBy == "id" {
+		return fmt.Sprintf("ORDER BY id %s", dir)
+	}
+	return fmt.Sprintf("ORDER BY %s %s, id %s", sortBy, dir, dir)
+}
+
+// keysetAfter appends condition skipping rows up to the cursor in the given order, an empty created_at
+// column name means the list is ordered by ID only.
+func (w *whereBuilder) keysetAfter(after *page.Cursor, createdAtColumn string, sortDir dao.SortDirection) {
+	if after == nil {
+		return
+	}
+	op := ">"
+	if sortDir == dao.SortDesc {
+		op = "<"
+	}
+	if createdAtColumn == "" {
+		w.add("id "+op+" ?", after.ID)
+		return
+	}
+	w.args = append(w.args, after.CreatedAt, after.ID)
+	w.addRaw(fmt.Sprintf("(%s, id) %s ($%d, $%d)", createdAtColumn, op, len(w.args)-1, len(w.args)))
+}
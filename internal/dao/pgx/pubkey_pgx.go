@@ -8,7 +8,9 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/db"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/page"
 	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
 )
 
 type pubkeyDao struct{}
@@ -36,7 +38,7 @@ func (x *pubkeyDao) validate(ctx context.Context, pubkey *models.Pubkey) error {
 func (x *pubkeyDao) Create(ctx context.Context, pubkey *models.Pubkey) error {
 	query := `
 		INSERT INTO pubkeys (account_id, type, name, body, fingerprint, fingerprint_legacy)
-		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
 
 	pubkey.AccountID = identity.AccountId(ctx)
 
@@ -44,7 +46,7 @@ func (x *pubkeyDao) Create(ctx context.Context, pubkey *models.Pubkey) error {
 		return fmt.Errorf("pubkey validation: %w", vError)
 	}
 
-	err := db.Pool.QueryRow(ctx, query, pubkey.AccountID, pubkey.Type, pubkey.Name, pubkey.Body, pubkey.Fingerprint, pubkey.FingerprintLegacy).Scan(&pubkey.ID)
+	err := db.Pool.QueryRow(ctx, query, pubkey.AccountID, pubkey.Type, pubkey.Name, pubkey.Body, pubkey.Fingerprint, pubkey.FingerprintLegacy).Scan(&pubkey.ID, &pubkey.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -65,6 +67,51 @@ func (x *pubkeyDao) GetById(ctx context.Context, id int64) (*models.Pubkey, erro
 }
 
 func (x *pubkeyDao) Update(ctx context.Context, pubkey *models.Pubkey) error {
+	if vError := x.validate(ctx, pubkey); vError != nil {
+		return fmt.Errorf("pubkey validation: %w", vError)
+	}
+
+	txErr := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+		return x.update(ctx, tx, pubkey)
+	})
+	if txErr != nil {
+		return fmt.Errorf("pgx tx error: %w", txErr)
+	}
+	return nil
+}
+
+func (x *pubkeyDao) UpdateAndDeleteResources(ctx context.Context, pubkey *models.Pubkey) ([]*models.PubkeyResource, error) {
+	query := `DELETE FROM pubkey_resources WHERE pubkey_id = $1 RETURNING *`
+	var result []*models.PubkeyResource
+
+	if vError := x.validate(ctx, pubkey); vError != nil {
+		return nil, fmt.Errorf("pubkey validation: %w", vError)
+	}
+
+	txErr := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+		// the update is scoped to the account, the resources of a foreign pubkey are never reached
+		if err := x.update(ctx, tx, pubkey); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, query, pubkey.ID)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+
+		err = pgxscan.ScanAll(&result, rows)
+		if err != nil {
+			return fmt.Errorf("pgx error: %w", err)
+		}
+		return nil
+	})
+	if txErr != nil {
+		return nil, fmt.Errorf("pgx tx error: %w", txErr)
+	}
+	return result, nil
+}
+
+func (x *pubkeyDao) update(ctx context.Context, tx pgx.Tx, pubkey *models.Pubkey) error {
 	query := `
 		UPDATE pubkeys SET
 			type = $3,
@@ -75,11 +122,7 @@ func (x *pubkeyDao) Update(ctx context.Context, pubkey *models.Pubkey) error {
 		WHERE account_id = $1 AND id = $2`
 	accountId := identity.AccountId(ctx)
 
-	if vError := x.validate(ctx, pubkey); vError != nil {
-		return fmt.Errorf("pubkey validation: %w", vError)
-	}
-
-	tag, err := db.Pool.Exec(ctx, query, accountId, pubkey.ID, pubkey.Type, pubkey.Name, pubkey.Body, pubkey.Fingerprint, pubkey.FingerprintLegacy)
+	tag, err := tx.Exec(ctx, query, accountId, pubkey.ID, pubkey.Type, pubkey.Name, pubkey.Body, pubkey.Fingerprint, pubkey.FingerprintLegacy)
 	if err != nil {
 		return fmt.Errorf("pgx error: %w", err)
 	}
@@ -89,12 +132,25 @@ func (x *pubkeyDao) Update(ctx context.Context, pubkey *models.Pubkey) error {
 	return nil
 }
 
-func (x *pubkeyDao) List(ctx context.Context, limit, offset int64) ([]*models.Pubkey, error) {
-	query := `SELECT * FROM pubkeys WHERE account_id = $1 ORDER BY id LIMIT $2 OFFSET $3`
-	accountId := identity.AccountId(ctx)
+func pubkeyFilterWhere(ctx context.Context, filter *dao.PubkeyFilter) *whereBuilder {
+	w := &whereBuilder{}
+	w.add("account_id = ?", identity.AccountId(ctx))
+	if filter != nil && filter.Name != "" {
+		w.add("name ILIKE ?", containsPattern(filter.Name))
+	}
+	return w
+}
+
+func (x *pubkeyDao) List(ctx context.Context, filter *dao.PubkeyFilter, limit, offset int64) ([]*models.Pubkey, error) {
+	if filter == nil {
+		filter = &dao.PubkeyFilter{}
+	}
+	w := pubkeyFilterWhere(ctx, filter)
+	order := orderBy(filter.SortBy, filter.SortDir, dao.PubkeySortFields)
+	query := fmt.Sprintf(`SELECT * FROM pubkeys %s %s LIMIT %s OFFSET %s`, w.where(), order, w.next(limit), w.next(offset))
 	var result []*models.Pubkey
 
-	rows, err := db.Pool.Query(ctx, query, accountId, limit, offset)
+	rows, err := db.Pool.Query(ctx, query, w.args...)
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -106,12 +162,34 @@ func (x *pubkeyDao) List(ctx context.Context, limit, offset int64) ([]*models.Pu
 	return result, nil
 }
 
-func (x *pubkeyDao) Count(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM pubkeys WHERE account_id = $1`
-	accountId := identity.AccountId(ctx)
+func (x *pubkeyDao) ListKeyset(ctx context.Context, filter *dao.PubkeyFilter, after *page.Cursor, limit int64) ([]*models.Pubkey, error) {
+	if filter == nil {
+		filter = &dao.PubkeyFilter{}
+	}
+	w := pubkeyFilterWhere(ctx, filter)
+	w.keysetAfter(after, "created_at", filter.SortDir)
+	order := orderBy("created_at", filter.SortDir, []string{"created_at"})
+	query := fmt.Sprintf(`SELECT * FROM pubkeys %s %s LIMIT %s`, w.where(), order, w.next(limit))
+	var result []*models.Pubkey
+
+	rows, err := db.Pool.Query(ctx, query, w.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *pubkeyDao) Count(ctx context.Context, filter *dao.PubkeyFilter) (int, error) {
+	w := pubkeyFilterWhere(ctx, filter)
+	query := `SELECT COUNT(*) FROM pubkeys ` + w.where()
 
 	var result int
-	err := db.Pool.QueryRow(ctx, query, accountId).Scan(&result)
+	err := db.Pool.QueryRow(ctx, query, w.args...).Scan(&result)
 	if err != nil {
 		return 0, fmt.Errorf("pgx error: %w", err)
 	}
@@ -191,3 +269,15 @@ func (x *pubkeyDao) UnscopedDeleteResource(ctx context.Context, id int64) error
 	}
 	return nil
 }
+
+func (x *pubkeyDao) DeleteResourcesBySourceId(ctx context.Context, sourceId string) (int64, error) {
+	query := `DELETE FROM pubkey_resources
+		WHERE source_id = $2 AND pubkey_id IN (SELECT id FROM pubkeys WHERE account_id = $1)`
+	accountId := identity.AccountId(ctx)
+
+	tag, err := db.Pool.Exec(ctx, query, accountId, sourceId)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -2,8 +2,10 @@ package pgx
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"strconv"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
@@ -11,8 +13,10 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/db"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/page"
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/rs/zerolog"
 )
 
@@ -145,19 +149,21 @@ func (x *reservationDao) CreateGCP(ctx context.Context, reservation *models.GCPR
 
 func (x *reservationDao) createGenericReservation(ctx context.Context, tx pgx.Tx, reservation *models.Reservation) error {
 	reservation.AccountID = identity.AccountId(ctx)
-	reservation.Status = "Created"
+	reservation.Status = reservation.InitialStatus()
 
-	reservationQuery := `INSERT INTO reservations (provider, account_id, steps, step_titles, status)
-		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	reservationQuery := `INSERT INTO reservations (provider, account_id, steps, step_titles, status, launch_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
 	err := tx.QueryRow(ctx, reservationQuery,
 		reservation.Provider,
 		reservation.AccountID,
 		reservation.Steps,
 		reservation.StepTitles,
-		reservation.Status).Scan(&reservation.ID, &reservation.CreatedAt)
+		reservation.Status,
+		reservation.LaunchAt,
+		reservation.ExpiresAt).Scan(&reservation.ID, &reservation.CreatedAt)
 	if err != nil {
-		if strings.Contains(err.Error(), "too many pending reservations") {
-			return fmt.Errorf("%w: %s", dao.ErrReservationRateExceeded, err.Error())
+		if limitErr := limitError(err); limitErr != nil {
+			return limitErr
 		}
 		return fmt.Errorf("failed to create reservation record: %w", err)
 	}
@@ -165,6 +171,30 @@ func (x *reservationDao) createGenericReservation(ctx context.Context, tx pgx.Tx
 	return nil
 }
 
+// Retry periods of limits enforced by the reservations_rate trigger
+const (
+	retryAfterRate    = time.Second
+	retryAfterPending = 30 * time.Second
+)
+
+// limitError returns LimitError for errors raised by the reservations_rate trigger, the maximum
+// is passed in the error detail. Returns nil for other errors.
+func limitError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	maximum, _ := strconv.ParseInt(pgErr.Detail, 10, 64)
+	switch pgErr.Code {
+	case "PL429":
+		return &dao.LimitError{Limit: dao.LimitReservationsPerSecond, Maximum: maximum, RetryAfter: retryAfterRate}
+	case "PL430":
+		return &dao.LimitError{Limit: dao.LimitMaxPendingReservations, Maximum: maximum, RetryAfter: retryAfterPending}
+	}
+	return nil
+}
+
 func (x *reservationDao) CreateInstance(ctx context.Context, instance *models.ReservationInstance) error {
 	query := `INSERT INTO reservation_instances (reservation_id, instance_id, detail) VALUES ($1, $2, $3)`
 
@@ -201,6 +231,26 @@ func (x *reservationDao) UpdateReservationInstance(ctx context.Context, reservat
 	return nil
 }
 
+func (x *reservationDao) UpdateInstanceAction(ctx context.Context, instance *models.ReservationInstance) error {
+	query := `UPDATE reservation_instances SET last_action = $3, last_action_status = $4, last_action_error = $5, last_action_at = now()
+		WHERE reservation_id = $1 AND instance_id = $2`
+
+	tag, err := db.Pool.Exec(ctx, query,
+		instance.ReservationID,
+		instance.InstanceID,
+		instance.LastAction,
+		instance.LastActionStatus,
+		instance.LastActionError)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row, got %d: %w", tag.RowsAffected(), dao.ErrAffectedMismatch)
+	}
+
+	return nil
+}
+
 func (x *reservationDao) GetById(ctx context.Context, id int64) (*models.Reservation, error) {
 	query := `SELECT * FROM reservations WHERE account_id = $1 AND id = $2 LIMIT 1`
 	accountId := identity.AccountId(ctx)
@@ -214,7 +264,7 @@ func (x *reservationDao) GetById(ctx context.Context, id int64) (*models.Reserva
 }
 
 func (x *reservationDao) GetAWSById(ctx context.Context, id int64) (*models.AWSReservation, error) {
-	query := `SELECT id, provider, account_id, created_at, steps, step, status, error, finished_at, success,
+	query := `SELECT id, provider, account_id, created_at, steps, step, status, error, finished_at, success, cancelled_at, launch_at, expires_at, expiry_notified_at, expired_at,
     	pubkey_id, source_id, image_id, aws_reservation_id, detail
 		FROM reservations, aws_reservation_details
 		WHERE account_id = $1 AND id = $2 AND id = reservation_id AND provider = provider_type_aws() LIMIT 1`
@@ -229,7 +279,7 @@ func (x *reservationDao) GetAWSById(ctx context.Context, id int64) (*models.AWSR
 }
 
 func (x *reservationDao) GetAzureById(ctx context.Context, id int64) (*models.AzureReservation, error) {
-	query := `SELECT id, reservations.provider, account_id, created_at, steps, step, status, error, finished_at, success,
+	query := `SELECT id, reservations.provider, account_id, created_at, steps, step, status, error, finished_at, success, cancelled_at, launch_at, expires_at, expiry_notified_at, expired_at,
     	pubkey_id, source_id, image_id, detail
 		FROM reservations, azure_reservation_details
 		WHERE account_id = $1 AND id = $2 AND id = reservation_id AND reservations.provider = provider_type_azure() LIMIT 1`
@@ -244,7 +294,7 @@ func (x *reservationDao) GetAzureById(ctx context.Context, id int64) (*models.Az
 }
 
 func (x *reservationDao) GetGCPById(ctx context.Context, id int64) (*models.GCPReservation, error) {
-	query := `SELECT id, provider, account_id, created_at, steps, step, status, error, finished_at, success,
+	query := `SELECT id, provider, account_id, created_at, steps, step, status, error, finished_at, success, cancelled_at, launch_at, expires_at, expiry_notified_at, expired_at,
     	pubkey_id, source_id, image_id, detail
 		FROM reservations, gcp_reservation_details
 		WHERE account_id = $1 AND id = $2 AND id = reservation_id AND provider = provider_type_gcp() LIMIT 1`
@@ -258,12 +308,107 @@ func (x *reservationDao) GetGCPById(ctx context.Context, id int64) (*models.GCPR
 	return result, nil
 }
 
-func (x *reservationDao) Count(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM reservations WHERE account_id = $1`
+// reservationDetails is a union of provider details used for filtering by source and name.
+const reservationDetails = `SELECT reservation_id, source_id, detail->>'name' AS name FROM aws_reservation_details
+	UNION ALL SELECT reservation_id, source_id, detail->>'name' AS name FROM azure_reservation_details
+	UNION ALL SELECT reservation_id, source_id, detail->>'name_pattern' AS name FROM gcp_reservation_details`
+
+func reservationFilterWhere(ctx context.Context, filter *dao.ReservationFilter) *whereBuilder {
+	w := &whereBuilder{}
+	w.add("account_id = ?", identity.AccountId(ctx))
+	if filter == nil {
+		return w
+	}
+
+	if filter.Provider != models.ProviderTypeUnknown {
+		w.add("provider = ?", filter.Provider)
+	}
+	switch filter.Status {
+	case dao.ReservationPending:
+		w.addRaw("success IS NULL")
+	case dao.ReservationSuccess:
+		w.addRaw("success = TRUE")
+	case dao.ReservationFailure:
+		w.addRaw("success = FALSE")
+	}
+	if !filter.CreatedAfter.IsZero() {
+		w.add("created_at >= ?", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		w.add("created_at < ?", filter.CreatedBefore)
+	}
+	if filter.SourceID != "" {
+		w.add("EXISTS (SELECT 1 FROM ("+reservationDetails+") d WHERE d.reservation_id = reservations.id AND d.source_id = ?)", filter.SourceID)
+	}
+	if filter.Name != "" {
+		w.add("EXISTS (SELECT 1 FROM ("+reservationDetails+") d WHERE d.reservation_id = reservations.id AND d.name ILIKE ?)", containsPattern(filter.Name))
+	}
+	return w
+}
+
+func (x *reservationDao) Count(ctx context.Context, filter *dao.ReservationFilter) (int, error) {
+	w := reservationFilterWhere(ctx, filter)
+	query := `SELECT COUNT(*) FROM reservations ` + w.where()
+
+	var result int
+	err := db.Pool.QueryRow(ctx, query, w.args...).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+
+	return result, nil
+}
+
+func (x *reservationDao) List(ctx context.Context, filter *dao.ReservationFilter, limit, offset int64) ([]*models.Reservation, error) {
+	if filter == nil {
+		filter = &dao.ReservationFilter{}
+	}
+	w := reservationFilterWhere(ctx, filter)
+	order := orderBy(filter.SortBy, filter.SortDir, dao.ReservationSortFields)
+	query := fmt.Sprintf(`SELECT * FROM reservations %s %s LIMIT %s OFFSET %s`, w.where(), order, w.next(limit), w.next(offset))
+
+	var result []*models.Reservation
+	rows, err := db.Pool.Query(ctx, query, w.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) ListKeyset(ctx context.Context, filter *dao.ReservationFilter, after *page.Cursor, limit int64) ([]*models.Reservation, error) {
+	if filter == nil {
+		filter = &dao.ReservationFilter{}
+	}
+	w := reservationFilterWhere(ctx, filter)
+	w.keysetAfter(after, "created_at", filter.SortDir)
+	order := orderBy("created_at", filter.SortDir, []string{"created_at"})
+	query := fmt.Sprintf(`SELECT * FROM reservations %s %s LIMIT %s`, w.where(), order, w.next(limit))
+
+	var result []*models.Reservation
+	rows, err := db.Pool.Query(ctx, query, w.args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) CountScheduled(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM reservations
+		WHERE account_id = $1 AND launch_at IS NOT NULL AND finished_at IS NULL AND status = $2`
 	accountId := identity.AccountId(ctx)
 
 	var result int
-	err := db.Pool.QueryRow(ctx, query, accountId).Scan(&result)
+	err := db.Pool.QueryRow(ctx, query, accountId, models.ReservationStatusScheduled).Scan(&result)
 	if err != nil {
 		return 0, fmt.Errorf("pgx error: %w", err)
 	}
@@ -271,13 +416,15 @@ func (x *reservationDao) Count(ctx context.Context) (int, error) {
 	return result, nil
 }
 
-func (x *reservationDao) List(ctx context.Context, limit, offset int64) ([]*models.Reservation, error) {
-	query := `SELECT * FROM reservations WHERE account_id = $1 ORDER BY id LIMIT $2 OFFSET $3`
+func (x *reservationDao) ListScheduled(ctx context.Context, limit, offset int64) ([]*models.Reservation, error) {
+	query := `SELECT * FROM reservations
+		WHERE account_id = $1 AND launch_at IS NOT NULL AND finished_at IS NULL AND status = $2
+		ORDER BY launch_at, id LIMIT $3 OFFSET $4`
 
 	accountId := identity.AccountId(ctx)
 	var result []*models.Reservation
 
-	rows, err := db.Pool.Query(ctx, query, accountId, limit, offset)
+	rows, err := db.Pool.Query(ctx, query, accountId, models.ReservationStatusScheduled, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -289,9 +436,28 @@ func (x *reservationDao) List(ctx context.Context, limit, offset int64) ([]*mode
 	return result, nil
 }
 
+func (x *reservationDao) CountInstancesSince(ctx context.Context, since time.Time) (int64, error) {
+	query := `SELECT COALESCE(SUM((detail->>'amount')::BIGINT), 0) FROM reservations, (
+			SELECT reservation_id, detail FROM aws_reservation_details
+			UNION ALL SELECT reservation_id, detail FROM azure_reservation_details
+			UNION ALL SELECT reservation_id, detail FROM gcp_reservation_details
+		) AS details
+		WHERE reservation_id = reservations.id AND account_id = $1 AND created_at >= $2 AND success IS NOT FALSE`
+	accountId := identity.AccountId(ctx)
+
+	var result int64
+	err := db.Pool.QueryRow(ctx, query, accountId, since).Scan(&result)
+	if err != nil {
+		return 0, fmt.Errorf("pgx error: %w", err)
+	}
+
+	return result, nil
+}
+
 func (x *reservationDao) ListInstances(ctx context.Context, reservationId int64) ([]*models.ReservationInstance, error) {
-	query := `SELECT reservation_id, instance_id, detail FROM reservation_instances, reservations
-         WHERE reservation_id = reservations.id AND account_id = $1 AND reservation_id = $2`
+	query := `SELECT reservation_id, instance_id, detail, last_action, last_action_status, last_action_error, last_action_at
+		FROM reservation_instances, reservations
+		WHERE reservation_id = reservations.id AND account_id = $1 AND reservation_id = $2`
 
 	accountId := identity.AccountId(ctx)
 	var result []*models.ReservationInstance
@@ -308,6 +474,20 @@ func (x *reservationDao) ListInstances(ctx context.Context, reservationId int64)
 	return result, nil
 }
 
+func (x *reservationDao) GetInstance(ctx context.Context, reservationId int64, instanceId string) (*models.ReservationInstance, error) {
+	query := `SELECT reservation_id, instance_id, detail, last_action, last_action_status, last_action_error, last_action_at
+		FROM reservation_instances, reservations
+		WHERE reservation_id = reservations.id AND account_id = $1 AND reservation_id = $2 AND instance_id = $3 LIMIT 1`
+	accountId := identity.AccountId(ctx)
+	result := &models.ReservationInstance{}
+
+	err := pgxscan.Get(ctx, db.Pool, result, query, accountId, reservationId, instanceId)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
 func (x *reservationDao) UpdateStatus(ctx context.Context, id int64, status string, addSteps int32) error {
 	query := `UPDATE reservations SET status = $2, step = step + $3 WHERE id = $1`
 
@@ -360,8 +540,148 @@ func (x *reservationDao) UpdateOperationNameForGCP(ctx context.Context, id int64
 	return nil
 }
 
+func (x *reservationDao) Cancel(ctx context.Context, id int64) error {
+	// scheduled reservations have no resources to roll back, they are finished right away
+	query := `UPDATE reservations SET cancelled_at = now(),
+			success = CASE WHEN status = $3 THEN false ELSE success END,
+			error = CASE WHEN status = $3 THEN 'reservation was cancelled' ELSE error END,
+			finished_at = CASE WHEN status = $3 THEN now() ELSE finished_at END,
+			status = CASE WHEN status = $3 THEN 'Cancelled' ELSE status END
+		WHERE account_id = $1 AND id = $2 AND finished_at IS NULL AND cancelled_at IS NULL`
+	accountId := identity.AccountId(ctx)
+
+	tag, err := db.Pool.Exec(ctx, query, accountId, id, models.ReservationStatusScheduled)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row, got %d: %w", tag.RowsAffected(), dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *reservationDao) UnscopedIsCancelled(ctx context.Context, id int64) (bool, error) {
+	query := `SELECT cancelled_at IS NOT NULL FROM reservations WHERE id = $1`
+
+	var result bool
+	err := db.Pool.QueryRow(ctx, query, id).Scan(&result)
+	if err != nil {
+		return false, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) UnscopedListExpiring(ctx context.Context, warning time.Duration, limit int64) ([]*models.Reservation, error) {
+	query := `SELECT * FROM reservations
+		WHERE success = true AND expired_at IS NULL AND expiry_notified_at IS NULL
+			AND expires_at <= now() + make_interval(secs => $1)
+		ORDER BY expires_at LIMIT $2`
+
+	var result []*models.Reservation
+	rows, err := db.Pool.Query(ctx, query, warning.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) UnscopedMarkExpiryNotified(ctx context.Context, id int64) error {
+	query := `UPDATE reservations SET expiry_notified_at = now() WHERE id = $1`
+
+	tag, err := db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row, got %d: %w", tag.RowsAffected(), dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *reservationDao) UnscopedListExpired(ctx context.Context, limit int64) ([]*models.Reservation, error) {
+	query := `SELECT * FROM reservations
+		WHERE success = true AND expired_at IS NULL AND expires_at <= now()
+		ORDER BY expires_at LIMIT $1`
+
+	var result []*models.Reservation
+	rows, err := db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) UnscopedMarkExpired(ctx context.Context, id int64) error {
+	query := `UPDATE reservations SET expired_at = now() WHERE id = $1`
+
+	tag, err := db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row, got %d: %w", tag.RowsAffected(), dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *reservationDao) UnscopedCreateResource(ctx context.Context, resource *models.ReservationResource) error {
+	query := `INSERT INTO reservation_resources (reservation_id, type, resource_id, region)
+		VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+
+	err := db.Pool.QueryRow(ctx, query,
+		resource.ReservationID,
+		resource.Type,
+		resource.ResourceID,
+		resource.Region).Scan(&resource.ID, &resource.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+
+	return nil
+}
+
+func (x *reservationDao) UnscopedListResources(ctx context.Context, reservationId int64) ([]*models.ReservationResource, error) {
+	query := `SELECT * FROM reservation_resources WHERE reservation_id = $1 AND deleted_at IS NULL ORDER BY id`
+
+	var result []*models.ReservationResource
+	rows, err := db.Pool.Query(ctx, query, reservationId)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) UnscopedMarkResourceDeleted(ctx context.Context, id int64) error {
+	query := `UPDATE reservation_resources SET deleted_at = now() WHERE id = $1`
+
+	tag, err := db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row, got %d: %w", tag.RowsAffected(), dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
 func (x *reservationDao) FinishWithSuccess(ctx context.Context, id int64) error {
-	query := `UPDATE reservations SET success = true, finished_at = now() WHERE id = $1`
+	query := `UPDATE reservations SET success = true, finished_at = now()
+		WHERE id = $1 AND finished_at IS NULL AND cancelled_at IS NULL`
 
 	tag, err := db.Pool.Exec(ctx, query, id)
 	if err != nil {
@@ -374,7 +694,9 @@ func (x *reservationDao) FinishWithSuccess(ctx context.Context, id int64) error
 }
 
 func (x *reservationDao) FinishWithError(ctx context.Context, id int64, errorString string) error {
-	query := `UPDATE reservations SET success = false, error = $2, finished_at = now() WHERE id = $1`
+	query := `UPDATE reservations SET success = false, error = $2, finished_at = now(),
+		status = CASE WHEN cancelled_at IS NULL THEN status ELSE 'Cancelled' END
+		WHERE id = $1 AND finished_at IS NULL`
 
 	tag, err := db.Pool.Exec(ctx, query, id, errorString)
 	if err != nil {
@@ -386,6 +708,29 @@ func (x *reservationDao) FinishWithError(ctx context.Context, id int64, errorStr
 	return nil
 }
 
+func (x *reservationDao) FailUnfinishedBySourceId(ctx context.Context, sourceId string, errorString string) ([]int64, error) {
+	query := `UPDATE reservations SET success = false, error = $3, finished_at = now(),
+			cancelled_at = COALESCE(cancelled_at, now())
+		WHERE account_id = $1 AND finished_at IS NULL AND id IN (
+			SELECT reservation_id FROM aws_reservation_details WHERE source_id = $2
+			UNION ALL SELECT reservation_id FROM azure_reservation_details WHERE source_id = $2
+			UNION ALL SELECT reservation_id FROM gcp_reservation_details WHERE source_id = $2)
+		RETURNING id`
+	accountId := identity.AccountId(ctx)
+	var result []int64
+
+	rows, err := db.Pool.Query(ctx, query, accountId, sourceId, errorString)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
 func (x *reservationDao) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM reservations WHERE id = $1`
 
@@ -412,3 +757,17 @@ func (x *reservationDao) Cleanup(ctx context.Context) error {
 
 	return nil
 }
+
+func (x *reservationDao) CleanupResources(ctx context.Context) error {
+	logger := zerolog.Ctx(ctx)
+	query := `DELETE FROM reservation_resources WHERE deleted_at IS NOT NULL
+		OR reservation_id IN (SELECT id FROM reservations WHERE success = true AND expires_at IS NULL)`
+
+	tag, err := db.Pool.Exec(ctx, query)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	logger.Trace().Msgf("Deleted %d reservation resource record(s)", tag.RowsAffected())
+
+	return nil
+}
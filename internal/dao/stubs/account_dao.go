@@ -3,6 +3,7 @@ package stubs
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
@@ -12,6 +13,7 @@ import (
 type accountDaoStub struct {
 	store  []*models.Account
 	lastId int64
+	limits map[int64]*models.AccountLimits
 }
 
 func buildAccountDaoWithOneAccount() *accountDaoStub {
@@ -98,3 +100,23 @@ func (stub *accountDaoStub) getByAccountNumber(ctx context.Context, number strin
 func (stub *accountDaoStub) List(ctx context.Context, limit, offset int64) ([]*models.Account, error) {
 	return stub.store, nil
 }
+
+func (stub *accountDaoStub) GetLimits(ctx context.Context) (*models.AccountLimits, error) {
+	return stub.UnscopedGetLimits(ctx, ctxAccountId(ctx))
+}
+
+func (stub *accountDaoStub) UnscopedGetLimits(ctx context.Context, accountId int64) (*models.AccountLimits, error) {
+	if limits, ok := stub.limits[accountId]; ok {
+		return limits, nil
+	}
+	return &models.AccountLimits{AccountID: accountId}, nil
+}
+
+func (stub *accountDaoStub) UnscopedUpdateLimits(ctx context.Context, limits *models.AccountLimits) error {
+	if stub.limits == nil {
+		stub.limits = make(map[int64]*models.AccountLimits)
+	}
+	limits.UpdatedAt = time.Now()
+	stub.limits[limits.AccountID] = limits
+	return nil
+}
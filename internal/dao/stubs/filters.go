@@ -0,0 +1,63 @@
+package stubs
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/page"
+)
+
+// containsFold reports whether substr is within s, case-insensitively.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// sortStable sorts items by the compare function with ID as a tiebreaker, like the pgx DAO does.
+func sortStable[T any](items []T, dir dao.SortDirection, compare func(a, b T) int, id func(T) int64) {
+	slices.SortStableFunc(items, func(a, b T) int {
+		result := compare(a, b)
+		if result == 0 {
+			result = cmp.Compare(id(a), id(b))
+		}
+		if dir == dao.SortDesc {
+			return -result
+		}
+		return result
+	})
+}
+
+// paginate returns the page of items.
+func paginate[T any](items []T, limit, offset int64) []T {
+	if offset >= int64(len(items)) {
+		return nil
+	}
+	items = items[offset:]
+	if limit < int64(len(items)) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// keysetPage returns items following the cursor, items must be already sorted in the direction.
+func keysetPage[T any](items []T, after *page.Cursor, dir dao.SortDirection, limit int64, cursor func(T) *page.Cursor) []T {
+	if after != nil {
+		idx := slices.IndexFunc(items, func(item T) bool {
+			c := cursor(item)
+			result := c.CreatedAt.Compare(after.CreatedAt)
+			if result == 0 {
+				result = cmp.Compare(c.ID, after.ID)
+			}
+			if dir == dao.SortDesc {
+				return result < 0
+			}
+			return result > 0
+		})
+		if idx < 0 {
+			return nil
+		}
+		items = items[idx:]
+	}
+	return paginate(items, limit, 0)
+}
@@ -15,3 +15,8 @@ func AddAWSReservation(ctx context.Context, reservation *models.AWSReservation)
 	reservationDao := getReservationDaoStub(ctx)
 	return reservationDao.CreateAWS(ctx, reservation)
 }
+
+func AddReservationInstance(ctx context.Context, instance *models.ReservationInstance) error {
+	reservationDao := getReservationDaoStub(ctx)
+	return reservationDao.CreateInstance(ctx, instance)
+}
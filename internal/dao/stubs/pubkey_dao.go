@@ -3,15 +3,19 @@ package stubs
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/page"
 )
 
 type pubkeyDaoStub struct {
-	lastId        int64
-	store         []*models.Pubkey
-	resourceStore []*models.PubkeyResource
+	lastId         int64
+	lastResourceId int64
+	store          []*models.Pubkey
+	resourceStore  []*models.PubkeyResource
 }
 
 func PubkeyStubCount(ctx context.Context) int {
@@ -51,6 +55,7 @@ func (stub *pubkeyDaoStub) Create(ctx context.Context, pubkey *models.Pubkey) er
 	}
 
 	pubkey.ID = stub.lastId + 1
+	pubkey.CreatedAt = time.Now()
 	stub.store = append(stub.store, pubkey)
 	stub.lastId++
 	return nil
@@ -76,6 +81,24 @@ func (stub *pubkeyDaoStub) Update(ctx context.Context, pubkey *models.Pubkey) er
 	return dao.ErrNoRows
 }
 
+func (stub *pubkeyDaoStub) UpdateAndDeleteResources(ctx context.Context, pubkey *models.Pubkey) ([]*models.PubkeyResource, error) {
+	if err := stub.Update(ctx, pubkey); err != nil {
+		return nil, err
+	}
+
+	var deleted []*models.PubkeyResource
+	kept := stub.resourceStore[:0]
+	for _, pkr := range stub.resourceStore {
+		if pkr.PubkeyID == pubkey.ID {
+			deleted = append(deleted, pkr)
+			continue
+		}
+		kept = append(kept, pkr)
+	}
+	stub.resourceStore = kept
+	return deleted, nil
+}
+
 func (stub *pubkeyDaoStub) GetById(ctx context.Context, id int64) (*models.Pubkey, error) {
 	for _, pk := range stub.store {
 		if pk.AccountID == ctxAccountId(ctx) && pk.ID == id {
@@ -85,18 +108,51 @@ func (stub *pubkeyDaoStub) GetById(ctx context.Context, id int64) (*models.Pubke
 	return nil, dao.ErrNoRows
 }
 
-func (stub *pubkeyDaoStub) List(ctx context.Context, limit, offset int64) ([]*models.Pubkey, error) {
-	var filtered []*models.Pubkey
+func (stub *pubkeyDaoStub) filtered(ctx context.Context, filter *dao.PubkeyFilter) []*models.Pubkey {
+	if filter == nil {
+		filter = &dao.PubkeyFilter{}
+	}
+	var result []*models.Pubkey
 	for _, pk := range stub.store {
-		if pk.AccountID == ctxAccountId(ctx) {
-			filtered = append(filtered, pk)
+		if pk.AccountID != ctxAccountId(ctx) {
+			continue
+		}
+		if filter.Name != "" && !containsFold(pk.Name, filter.Name) {
+			continue
 		}
+		result = append(result, pk)
 	}
-	return filtered, nil
+
+	sortStable(result, filter.SortDir, func(a, b *models.Pubkey) int {
+		switch filter.SortBy {
+		case "name":
+			return strings.Compare(a.Name, b.Name)
+		case "type":
+			return strings.Compare(a.Type, b.Type)
+		default:
+			return 0
+		}
+	}, func(pk *models.Pubkey) int64 { return pk.ID })
+	return result
 }
 
-func (stub *pubkeyDaoStub) Count(ctx context.Context) (int, error) {
-	return len(stub.store), nil
+func (stub *pubkeyDaoStub) List(ctx context.Context, filter *dao.PubkeyFilter, limit, offset int64) ([]*models.Pubkey, error) {
+	return paginate(stub.filtered(ctx, filter), limit, offset), nil
+}
+
+func (stub *pubkeyDaoStub) ListKeyset(ctx context.Context, filter *dao.PubkeyFilter, after *page.Cursor, limit int64) ([]*models.Pubkey, error) {
+	keysetFilter := dao.PubkeyFilter{}
+	if filter != nil {
+		keysetFilter = *filter
+	}
+	keysetFilter.SortBy = "id"
+	return keysetPage(stub.filtered(ctx, &keysetFilter), after, keysetFilter.SortDir, limit, func(pk *models.Pubkey) *page.Cursor {
+		return &page.Cursor{CreatedAt: pk.CreatedAt, ID: pk.ID}
+	}), nil
+}
+
+func (stub *pubkeyDaoStub) Count(ctx context.Context, filter *dao.PubkeyFilter) (int, error) {
+	return len(stub.filtered(ctx, filter)), nil
 }
 
 func (stub *pubkeyDaoStub) Delete(ctx context.Context, id int64) error {
@@ -119,13 +175,36 @@ func (stub *pubkeyDaoStub) UnscopedGetResourceBySourceAndRegion(ctx context.Cont
 }
 
 func (stub *pubkeyDaoStub) UnscopedCreateResource(ctx context.Context, pkr *models.PubkeyResource) error {
-	pkr.ID = int64(len(stub.resourceStore)) + 1
+	pkr.ID = stub.lastResourceId + 1
 	stub.resourceStore = append(stub.resourceStore, pkr)
+	stub.lastResourceId++
 	return nil
 }
 
 func (stub *pubkeyDaoStub) UnscopedDeleteResource(ctx context.Context, id int64) error {
-	return nil
+	for idx, pkr := range stub.resourceStore {
+		if pkr.ID == id {
+			stub.resourceStore = append(stub.resourceStore[:idx], stub.resourceStore[idx+1:]...)
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
+
+func (stub *pubkeyDaoStub) DeleteResourcesBySourceId(ctx context.Context, sourceId string) (int64, error) {
+	var deleted int64
+	kept := stub.resourceStore[:0]
+	for _, pkr := range stub.resourceStore {
+		if pkr.SourceID == sourceId {
+			if _, err := stub.GetById(ctx, pkr.PubkeyID); err == nil {
+				deleted++
+				continue
+			}
+		}
+		kept = append(kept, pkr)
+	}
+	stub.resourceStore = kept
+	return deleted, nil
 }
 
 func (stub *pubkeyDaoStub) UnscopedListResourcesByPubkeyId(ctx context.Context, pkId int64) ([]*models.PubkeyResource, error) {
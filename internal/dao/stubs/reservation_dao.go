@@ -1,12 +1,18 @@
 package stubs
 
 import (
+	"cmp"
 	"context"
+	"database/sql"
 	"fmt"
+	"math"
+	"slices"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/page"
 )
 
 type reservationDaoStub struct {
@@ -14,6 +20,7 @@ type reservationDaoStub struct {
 	storeAzure []*models.AzureReservation
 	storeGCP   []*models.GCPReservation
 	instances  map[int64][]*models.ReservationInstance
+	resources  []*models.ReservationResource
 }
 
 func AWSReservationStubCount(ctx context.Context) int {
@@ -64,12 +71,32 @@ func (stub *reservationDaoStub) CreateInstance(ctx context.Context, resInstance
 }
 
 func (stub *reservationDaoStub) GetById(ctx context.Context, id int64) (*models.Reservation, error) {
+	reservation := stub.unscopedFind(id)
+	if reservation == nil || reservation.AccountID != ctxAccountId(ctx) {
+		return nil, dao.ErrNoRows
+	}
+	return reservation, nil
+}
+
+// unscopedFind searches all provider stores, stubbed IDs are not unique across
+// providers so AWS reservations take precedence.
+func (stub *reservationDaoStub) unscopedFind(id int64) *models.Reservation {
 	for _, awsReservation := range stub.storeAWS {
-		if awsReservation.AccountID == ctxAccountId(ctx) && awsReservation.ID == id {
-			return &awsReservation.Reservation, nil
+		if awsReservation.ID == id {
+			return &awsReservation.Reservation
 		}
 	}
-	return nil, dao.ErrNoRows
+	for _, azureReservation := range stub.storeAzure {
+		if azureReservation.ID == id {
+			return &azureReservation.Reservation
+		}
+	}
+	for _, gcpReservation := range stub.storeGCP {
+		if gcpReservation.ID == id {
+			return &gcpReservation.Reservation
+		}
+	}
+	return nil
 }
 
 func (stub *reservationDaoStub) GetAWSById(ctx context.Context, id int64) (*models.AWSReservation, error) {
@@ -99,12 +126,138 @@ func (stub *reservationDaoStub) GetGCPById(ctx context.Context, id int64) (*mode
 	return nil, dao.ErrNoRows
 }
 
-func (stub *reservationDaoStub) Count(ctx context.Context) (int, error) {
-	return len(stub.storeAWS) + len(stub.storeAzure) + len(stub.storeGCP), nil
+func (stub *reservationDaoStub) filtered(ctx context.Context, filter *dao.ReservationFilter) []*models.Reservation {
+	if filter == nil {
+		filter = &dao.ReservationFilter{}
+	}
+	var result []*models.Reservation
+	add := func(reservation *models.Reservation, sourceId, name string) {
+		if reservation.AccountID != ctxAccountId(ctx) {
+			return
+		}
+		if filter.Provider != models.ProviderTypeUnknown && reservation.Provider != filter.Provider {
+			return
+		}
+		switch filter.Status {
+		case dao.ReservationPending:
+			if reservation.Success.Valid {
+				return
+			}
+		case dao.ReservationSuccess:
+			if !reservation.Success.Valid || !reservation.Success.Bool {
+				return
+			}
+		case dao.ReservationFailure:
+			if !reservation.Success.Valid || reservation.Success.Bool {
+				return
+			}
+		}
+		if !filter.CreatedAfter.IsZero() && reservation.CreatedAt.Before(filter.CreatedAfter) {
+			return
+		}
+		if !filter.CreatedBefore.IsZero() && !reservation.CreatedAt.Before(filter.CreatedBefore) {
+			return
+		}
+		if filter.SourceID != "" && sourceId != filter.SourceID {
+			return
+		}
+		if filter.Name != "" && !containsFold(name, filter.Name) {
+			return
+		}
+		result = append(result, reservation)
+	}
+	for _, awsReservation := range stub.storeAWS {
+		var name string
+		if awsReservation.Detail != nil {
+			name = awsReservation.Detail.Name
+		}
+		add(&awsReservation.Reservation, awsReservation.SourceID, name)
+	}
+	for _, azureReservation := range stub.storeAzure {
+		var name string
+		if azureReservation.Detail != nil {
+			name = azureReservation.Detail.Name
+		}
+		add(&azureReservation.Reservation, azureReservation.SourceID, name)
+	}
+	for _, gcpReservation := range stub.storeGCP {
+		var name string
+		if gcpReservation.Detail != nil && gcpReservation.Detail.NamePattern != nil {
+			name = *gcpReservation.Detail.NamePattern
+		}
+		add(&gcpReservation.Reservation, gcpReservation.SourceID, name)
+	}
+
+	sortStable(result, filter.SortDir, func(a, b *models.Reservation) int {
+		switch filter.SortBy {
+		case "created_at":
+			return a.CreatedAt.Compare(b.CreatedAt)
+		case "finished_at":
+			return a.FinishedAt.Time.Compare(b.FinishedAt.Time)
+		case "provider":
+			return cmp.Compare(a.Provider, b.Provider)
+		default:
+			return 0
+		}
+	}, func(reservation *models.Reservation) int64 { return reservation.ID })
+	return result
 }
 
-func (stub *reservationDaoStub) List(ctx context.Context, limit, offset int64) ([]*models.Reservation, error) {
-	return nil, nil
+func (stub *reservationDaoStub) Count(ctx context.Context, filter *dao.ReservationFilter) (int, error) {
+	return len(stub.filtered(ctx, filter)), nil
+}
+
+func (stub *reservationDaoStub) List(ctx context.Context, filter *dao.ReservationFilter, limit, offset int64) ([]*models.Reservation, error) {
+	return paginate(stub.filtered(ctx, filter), limit, offset), nil
+}
+
+func (stub *reservationDaoStub) ListKeyset(ctx context.Context, filter *dao.ReservationFilter, after *page.Cursor, limit int64) ([]*models.Reservation, error) {
+	keysetFilter := dao.ReservationFilter{}
+	if filter != nil {
+		keysetFilter = *filter
+	}
+	keysetFilter.SortBy = "created_at"
+	return keysetPage(stub.filtered(ctx, &keysetFilter), after, keysetFilter.SortDir, limit, func(reservation *models.Reservation) *page.Cursor {
+		return &page.Cursor{CreatedAt: reservation.CreatedAt, ID: reservation.ID}
+	}), nil
+}
+
+func (stub *reservationDaoStub) CountScheduled(ctx context.Context) (int, error) {
+	return len(stub.scheduled(ctx)), nil
+}
+
+func (stub *reservationDaoStub) ListScheduled(ctx context.Context, limit, offset int64) ([]*models.Reservation, error) {
+	return paginate(stub.scheduled(ctx), limit, offset), nil
+}
+
+func (stub *reservationDaoStub) scheduled(ctx context.Context) []*models.Reservation {
+	return stub.unscopedFilter(math.MaxInt64, func(reservation *models.Reservation) bool {
+		return reservation.AccountID == ctxAccountId(ctx) && reservation.LaunchAt.Valid && reservation.Scheduled()
+	})
+}
+
+func (stub *reservationDaoStub) CountInstancesSince(ctx context.Context, since time.Time) (int64, error) {
+	var result int64
+	counted := func(reservation *models.Reservation) bool {
+		return reservation.AccountID == ctxAccountId(ctx) && !reservation.CreatedAt.Before(since) &&
+			!(reservation.Success.Valid && !reservation.Success.Bool)
+	}
+	for _, reservation := range stub.storeAWS {
+		if counted(&reservation.Reservation) {
+			result += int64(reservation.Detail.Amount)
+		}
+	}
+	for _, reservation := range stub.storeAzure {
+		if counted(&reservation.Reservation) {
+			result += reservation.Detail.Amount
+		}
+	}
+	for _, reservation := range stub.storeGCP {
+		if counted(&reservation.Reservation) {
+			result += reservation.Detail.Amount
+		}
+	}
+	return result, nil
 }
 
 func (stub *reservationDaoStub) ListInstances(ctx context.Context, reservationId int64) ([]*models.ReservationInstance, error) {
@@ -112,6 +265,12 @@ func (stub *reservationDaoStub) ListInstances(ctx context.Context, reservationId
 }
 
 func (stub *reservationDaoStub) UpdateStatus(ctx context.Context, id int64, status string, addSteps int32) error {
+	reservation := stub.unscopedFind(id)
+	if reservation == nil {
+		return nil
+	}
+	reservation.Status = status
+	reservation.Step += addSteps
 	return nil
 }
 
@@ -132,14 +291,177 @@ func (stub *reservationDaoStub) UpdateOperationNameForGCP(ctx context.Context, i
 	return nil
 }
 
+func (stub *reservationDaoStub) Cancel(ctx context.Context, id int64) error {
+	reservation, err := stub.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if reservation.FinishedAt.Valid || reservation.Cancelled() {
+		return dao.ErrAffectedMismatch
+	}
+	reservation.CancelledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if reservation.Scheduled() {
+		reservation.Success = sql.NullBool{Bool: false, Valid: true}
+		reservation.Error = "reservation was cancelled"
+		reservation.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		reservation.Status = "Cancelled"
+	}
+	return nil
+}
+
+func (stub *reservationDaoStub) UnscopedIsCancelled(ctx context.Context, id int64) (bool, error) {
+	reservation := stub.unscopedFind(id)
+	if reservation == nil {
+		return false, dao.ErrNoRows
+	}
+	return reservation.Cancelled(), nil
+}
+
+func (stub *reservationDaoStub) UnscopedListExpiring(ctx context.Context, warning time.Duration, limit int64) ([]*models.Reservation, error) {
+	deadline := time.Now().Add(warning)
+	return stub.unscopedFilter(limit, func(reservation *models.Reservation) bool {
+		return reservation.Success.Bool && !reservation.ExpiredAt.Valid && !reservation.ExpiryNotifiedAt.Valid &&
+			reservation.ExpiresAt.Valid && !reservation.ExpiresAt.Time.After(deadline)
+	}), nil
+}
+
+func (stub *reservationDaoStub) UnscopedMarkExpiryNotified(ctx context.Context, id int64) error {
+	reservation := stub.unscopedFind(id)
+	if reservation == nil {
+		return dao.ErrAffectedMismatch
+	}
+	reservation.ExpiryNotifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (stub *reservationDaoStub) UnscopedListExpired(ctx context.Context, limit int64) ([]*models.Reservation, error) {
+	now := time.Now()
+	return stub.unscopedFilter(limit, func(reservation *models.Reservation) bool {
+		return reservation.Success.Bool && !reservation.ExpiredAt.Valid &&
+			reservation.ExpiresAt.Valid && !reservation.ExpiresAt.Time.After(now)
+	}), nil
+}
+
+func (stub *reservationDaoStub) UnscopedMarkExpired(ctx context.Context, id int64) error {
+	reservation := stub.unscopedFind(id)
+	if reservation == nil {
+		return dao.ErrAffectedMismatch
+	}
+	reservation.ExpiredAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+// unscopedFilter returns up to limit reservations of all providers matching the filter.
+func (stub *reservationDaoStub) unscopedFilter(limit int64, filter func(*models.Reservation) bool) []*models.Reservation {
+	var result []*models.Reservation
+	add := func(reservation *models.Reservation) {
+		if int64(len(result)) < limit && filter(reservation) {
+			result = append(result, reservation)
+		}
+	}
+	for _, awsReservation := range stub.storeAWS {
+		add(&awsReservation.Reservation)
+	}
+	for _, azureReservation := range stub.storeAzure {
+		add(&azureReservation.Reservation)
+	}
+	for _, gcpReservation := range stub.storeGCP {
+		add(&gcpReservation.Reservation)
+	}
+	return result
+}
+
+func (stub *reservationDaoStub) UnscopedCreateResource(ctx context.Context, resource *models.ReservationResource) error {
+	resource.ID = int64(len(stub.resources) + 1)
+	resource.CreatedAt = time.Now()
+	stub.resources = append(stub.resources, resource)
+	return nil
+}
+
+func (stub *reservationDaoStub) UnscopedListResources(ctx context.Context, reservationId int64) ([]*models.ReservationResource, error) {
+	var result []*models.ReservationResource
+	for _, resource := range stub.resources {
+		if resource.ReservationID == reservationId && !resource.DeletedAt.Valid {
+			result = append(result, resource)
+		}
+	}
+	return result, nil
+}
+
+func (stub *reservationDaoStub) UnscopedMarkResourceDeleted(ctx context.Context, id int64) error {
+	for _, resource := range stub.resources {
+		if resource.ID == id {
+			resource.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
+
 func (stub *reservationDaoStub) FinishWithSuccess(ctx context.Context, id int64) error {
+	reservation := stub.unscopedFind(id)
+	if reservation == nil {
+		return nil
+	}
+	if reservation.FinishedAt.Valid || reservation.Cancelled() {
+		return dao.ErrAffectedMismatch
+	}
+	reservation.Success = sql.NullBool{Bool: true, Valid: true}
+	reservation.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	return nil
 }
 
 func (stub *reservationDaoStub) FinishWithError(ctx context.Context, id int64, errorString string) error {
+	reservation := stub.unscopedFind(id)
+	if reservation == nil {
+		return nil
+	}
+	if reservation.FinishedAt.Valid {
+		return dao.ErrAffectedMismatch
+	}
+	reservation.Success = sql.NullBool{Bool: false, Valid: true}
+	reservation.Error = errorString
+	reservation.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if reservation.Cancelled() {
+		reservation.Status = "Cancelled"
+	}
 	return nil
 }
 
+func (stub *reservationDaoStub) FailUnfinishedBySourceId(ctx context.Context, sourceId string, errorString string) ([]int64, error) {
+	var reservations []*models.Reservation
+	for _, r := range stub.storeAWS {
+		if r.SourceID == sourceId {
+			reservations = append(reservations, &r.Reservation)
+		}
+	}
+	for _, r := range stub.storeAzure {
+		if r.SourceID == sourceId {
+			reservations = append(reservations, &r.Reservation)
+		}
+	}
+	for _, r := range stub.storeGCP {
+		if r.SourceID == sourceId {
+			reservations = append(reservations, &r.Reservation)
+		}
+	}
+
+	var updated []int64
+	for _, reservation := range reservations {
+		if reservation.AccountID != ctxAccountId(ctx) || reservation.FinishedAt.Valid {
+			continue
+		}
+		reservation.Success = sql.NullBool{Bool: false, Valid: true}
+		reservation.Error = errorString
+		reservation.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		if !reservation.CancelledAt.Valid {
+			reservation.CancelledAt = reservation.FinishedAt
+		}
+		updated = append(updated, reservation.ID)
+	}
+	return updated, nil
+}
+
 func (stub *reservationDaoStub) Delete(ctx context.Context, id int64) error {
 	return nil
 }
@@ -148,6 +470,17 @@ func (stub *reservationDaoStub) Cleanup(ctx context.Context) error {
 	return nil
 }
 
+func (stub *reservationDaoStub) CleanupResources(ctx context.Context) error {
+	stub.resources = slices.DeleteFunc(stub.resources, func(resource *models.ReservationResource) bool {
+		if resource.DeletedAt.Valid {
+			return true
+		}
+		reservation := stub.unscopedFind(resource.ReservationID)
+		return reservation != nil && reservation.Success.Bool && !reservation.ExpiresAt.Valid
+	})
+	return nil
+}
+
 func (stub *reservationDaoStub) UpdateReservationInstance(ctx context.Context, reservationID int64, instance *clients.InstanceDescription) error {
 	for _, instRes := range stub.instances[reservationID] {
 		if instRes.InstanceID == instance.ID {
@@ -156,3 +489,24 @@ func (stub *reservationDaoStub) UpdateReservationInstance(ctx context.Context, r
 	}
 	return nil
 }
+
+func (stub *reservationDaoStub) GetInstance(ctx context.Context, reservationId int64, instanceId string) (*models.ReservationInstance, error) {
+	for _, instRes := range stub.instances[reservationId] {
+		if instRes.InstanceID == instanceId {
+			return instRes, nil
+		}
+	}
+	return nil, dao.ErrNoRows
+}
+
+func (stub *reservationDaoStub) UpdateInstanceAction(ctx context.Context, instance *models.ReservationInstance) error {
+	for _, instRes := range stub.instances[instance.ReservationID] {
+		if instRes.InstanceID == instance.InstanceID {
+			instRes.LastAction = instance.LastAction
+			instRes.LastActionStatus = instance.LastActionStatus
+			instRes.LastActionError = instance.LastActionError
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
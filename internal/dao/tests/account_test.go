@@ -154,3 +154,37 @@ func TestAccountGetOrCreateByIdentity(t *testing.T) {
 		assert.Equal(t, "1", account.AccountNumber.String)
 	})
 }
+
+func TestAccountLimits(t *testing.T) {
+	accDao, ctx := setupAccount(t)
+	defer reset()
+
+	t.Run("defaults", func(t *testing.T) {
+		limits, err := accDao.GetLimits(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), limits.AccountID)
+		assert.False(t, limits.ReservationsPerSecond.Valid)
+		assert.False(t, limits.MaxInstancesPerDay.Valid)
+	})
+
+	t.Run("create and replace", func(t *testing.T) {
+		err := accDao.UnscopedUpdateLimits(ctx, &models.AccountLimits{
+			AccountID:             1,
+			ReservationsPerSecond: sql.NullInt32{Int32: 2, Valid: true},
+			MaxInstancesPerDay:    sql.NullInt32{Int32: 100, Valid: true},
+		})
+		require.NoError(t, err)
+
+		err = accDao.UnscopedUpdateLimits(ctx, &models.AccountLimits{
+			AccountID:          1,
+			MaxInstancesPerDay: sql.NullInt32{Int32: 50, Valid: true},
+		})
+		require.NoError(t, err)
+
+		limits, err := accDao.UnscopedGetLimits(ctx, 1)
+		require.NoError(t, err)
+		assert.False(t, limits.ReservationsPerSecond.Valid)
+		assert.Equal(t, sql.NullInt32{Int32: 50, Valid: true}, limits.MaxInstancesPerDay)
+		assert.False(t, limits.UpdatedAt.IsZero())
+	})
+}
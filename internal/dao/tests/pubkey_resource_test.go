@@ -156,3 +156,77 @@ func TestPubkeyResourceDelete(t *testing.T) {
 		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
 	})
 }
+
+func TestPubkeyResourceDeleteBySourceId(t *testing.T) {
+	pubkeyDao, ctx := setupPubkeyResource(t)
+	defer reset()
+
+	t.Run("success", func(t *testing.T) {
+		resource := newPubkeyResourceNoop()
+		err := pubkeyDao.UnscopedCreateResource(ctx, resource)
+		require.NoError(t, err)
+
+		otherSource := newPubkeyResourceNoop()
+		otherSource.SourceID = "5"
+		err = pubkeyDao.UnscopedCreateResource(ctx, otherSource)
+		require.NoError(t, err)
+
+		deleted, err := pubkeyDao.DeleteResourcesBySourceId(ctx, resource.SourceID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		resources, err := pubkeyDao.UnscopedListResourcesByPubkeyId(ctx, resource.PubkeyID)
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, otherSource.ID, resources[0].ID)
+	})
+
+	t.Run("other account", func(t *testing.T) {
+		otherCtx := identity.WithTenantOrgId(t, context.Background(), "2")
+		deleted, err := dao.GetPubkeyDao(otherCtx).DeleteResourcesBySourceId(otherCtx, "5")
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), deleted)
+	})
+}
+
+func TestPubkeyUpdateAndDeleteResources(t *testing.T) {
+	pubkeyDao, ctx := setupPubkeyResource(t)
+	defer reset()
+
+	resource := newPubkeyResourceNoop()
+	err := pubkeyDao.UnscopedCreateResource(ctx, resource)
+	require.NoError(t, err)
+
+	t.Run("other account", func(t *testing.T) {
+		pk, err := pubkeyDao.GetById(ctx, resource.PubkeyID)
+		require.NoError(t, err)
+		pk.Name = "Renamed"
+
+		otherCtx := identity.WithTenantOrgId(t, context.Background(), "2")
+		deleted, err := dao.GetPubkeyDao(otherCtx).UpdateAndDeleteResources(otherCtx, pk)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+		assert.Empty(t, deleted)
+
+		resources, err := pubkeyDao.UnscopedListResourcesByPubkeyId(ctx, resource.PubkeyID)
+		require.NoError(t, err)
+		assert.Len(t, resources, 1, "Expected the resources to be kept when the update fails")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		pk, err := pubkeyDao.GetById(ctx, resource.PubkeyID)
+		require.NoError(t, err)
+		pk.Name = "Renamed"
+
+		deleted, err := pubkeyDao.UpdateAndDeleteResources(ctx, pk)
+		require.NoError(t, err)
+		assert.Len(t, deleted, 1)
+
+		updated, err := pubkeyDao.GetById(ctx, resource.PubkeyID)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", updated.Name)
+
+		resources, err := pubkeyDao.UnscopedListResourcesByPubkeyId(ctx, resource.PubkeyID)
+		require.NoError(t, err)
+		assert.Empty(t, resources)
+	})
+}
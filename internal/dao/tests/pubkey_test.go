@@ -5,9 +5,11 @@ package tests
 import (
 	"context"
 	"math"
+	"strings"
 	"testing"
 
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/page"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
 	"github.com/go-playground/validator/v10"
@@ -61,7 +63,7 @@ func TestPubkeyList(t *testing.T) {
 	defer reset()
 
 	t.Run("success", func(t *testing.T) {
-		pubkeys, err := pkDao.List(ctx, 1, 0)
+		pubkeys, err := pkDao.List(ctx, nil, 1, 0)
 		require.NoError(t, err)
 		assert.Equal(t, 1, len(pubkeys))
 	})
@@ -71,15 +73,42 @@ func TestPubkeyList(t *testing.T) {
 		err := pkDao.Create(ctx, newKey)
 		require.NoError(t, err)
 
-		pubkeys, err := pkDao.List(ctx, 1, 0)
+		pubkeys, err := pkDao.List(ctx, nil, 1, 0)
 		require.NoError(t, err)
 		assert.Equal(t, 1, len(pubkeys))
 
-		pubkeys, err = pkDao.List(ctx, 1, 1)
+		pubkeys, err = pkDao.List(ctx, nil, 1, 1)
 		require.NoError(t, err)
 		assert.Equal(t, 1, len(pubkeys))
 		require.Contains(t, pubkeys, newKey)
 	})
+
+	t.Run("filtered and sorted", func(t *testing.T) {
+		pubkeys, err := pkDao.List(ctx, &dao.PubkeyFilter{SortBy: "id", SortDir: dao.SortDesc}, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(pubkeys))
+		assert.Greater(t, pubkeys[0].ID, pubkeys[1].ID)
+
+		filter := &dao.PubkeyFilter{Name: strings.ToUpper(pubkeys[0].Name)}
+		pubkeys, err = pkDao.List(ctx, filter, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(pubkeys))
+
+		count, err := pkDao.Count(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("keyset", func(t *testing.T) {
+		pubkeys, err := pkDao.ListKeyset(ctx, nil, nil, 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(pubkeys))
+
+		next, err := pkDao.ListKeyset(ctx, nil, &page.Cursor{CreatedAt: pubkeys[0].CreatedAt, ID: pubkeys[0].ID}, 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(next))
+		assert.Greater(t, next[0].ID, pubkeys[0].ID)
+	})
 }
 
 func TestPubkeyUpdate(t *testing.T) {
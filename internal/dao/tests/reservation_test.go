@@ -4,6 +4,7 @@ package tests
 
 import (
 	"context"
+	"database/sql"
 	"math"
 	"testing"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/db"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/page"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
 	"github.com/stretchr/testify/assert"
@@ -180,12 +182,107 @@ func TestReservationCreateAWSInstance(t *testing.T) {
 	})
 }
 
+func TestReservationResources(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	t.Run("success", func(t *testing.T) {
+		reservation := newAWSReservation()
+		err := reservationDao.CreateAWS(ctx, reservation)
+		require.NoError(t, err)
+
+		keyPair := &models.ReservationResource{
+			ReservationID: reservation.ID,
+			Type:          models.ResourceTypeAWSKeyPair,
+			ResourceID:    "key-0123456789abcdef0",
+			Region:        "us-east-1",
+		}
+		err = reservationDao.UnscopedCreateResource(ctx, keyPair)
+		require.NoError(t, err)
+		instance := &models.ReservationResource{
+			ReservationID: reservation.ID,
+			Type:          models.ResourceTypeAWSInstance,
+			ResourceID:    "i-0123456789abcdef0",
+			Region:        "us-east-1",
+		}
+		err = reservationDao.UnscopedCreateResource(ctx, instance)
+		require.NoError(t, err)
+
+		resources, err := reservationDao.UnscopedListResources(ctx, reservation.ID)
+		require.NoError(t, err)
+		require.Len(t, resources, 2)
+		assert.Equal(t, keyPair.ID, resources[0].ID)
+		assert.Equal(t, models.ResourceTypeAWSInstance, resources[1].Type)
+		assert.Equal(t, "i-0123456789abcdef0", resources[1].ResourceID)
+
+		err = reservationDao.UnscopedMarkResourceDeleted(ctx, instance.ID)
+		require.NoError(t, err)
+
+		resources, err = reservationDao.UnscopedListResources(ctx, reservation.ID)
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, keyPair.ID, resources[0].ID)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		err := reservationDao.UnscopedMarkResourceDeleted(ctx, math.MaxInt64)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	})
+
+	t.Run("cleanup", func(t *testing.T) {
+		createResource := func(reservation *models.AWSReservation) {
+			err := reservationDao.UnscopedCreateResource(ctx, &models.ReservationResource{
+				ReservationID: reservation.ID,
+				Type:          models.ResourceTypeAWSInstance,
+				ResourceID:    "i-0123456789abcdef0",
+				Region:        "us-east-1",
+			})
+			require.NoError(t, err)
+		}
+
+		succeeded := newAWSReservation()
+		err := reservationDao.CreateAWS(ctx, succeeded)
+		require.NoError(t, err)
+		createResource(succeeded)
+		err = reservationDao.FinishWithSuccess(ctx, succeeded.ID)
+		require.NoError(t, err)
+
+		expiring := newAWSReservation()
+		expiring.ExpiresAt = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+		err = reservationDao.CreateAWS(ctx, expiring)
+		require.NoError(t, err)
+		createResource(expiring)
+		err = reservationDao.FinishWithSuccess(ctx, expiring.ID)
+		require.NoError(t, err)
+
+		running := newAWSReservation()
+		err = reservationDao.CreateAWS(ctx, running)
+		require.NoError(t, err)
+		createResource(running)
+
+		err = reservationDao.CleanupResources(ctx)
+		require.NoError(t, err)
+
+		resources, err := reservationDao.UnscopedListResources(ctx, succeeded.ID)
+		require.NoError(t, err)
+		assert.Empty(t, resources, "resources of successful reservations should be pruned")
+
+		resources, err = reservationDao.UnscopedListResources(ctx, expiring.ID)
+		require.NoError(t, err)
+		assert.Len(t, resources, 1, "resources of expiring reservations are needed for the teardown")
+
+		resources, err = reservationDao.UnscopedListResources(ctx, running.ID)
+		require.NoError(t, err)
+		assert.Len(t, resources, 1, "resources of running reservations are needed for the rollback")
+	})
+}
+
 func TestReservationList(t *testing.T) {
 	reservationDao, ctx := setupReservation(t)
 	defer reset()
 
 	t.Run("empty", func(t *testing.T) {
-		reservations, err := reservationDao.List(ctx, 10, 0)
+		reservations, err := reservationDao.List(ctx, nil, 10, 0)
 		require.NoError(t, err)
 		require.Empty(t, reservations)
 	})
@@ -199,9 +296,169 @@ func TestReservationList(t *testing.T) {
 		err = reservationDao.CreateNoop(ctx, noopReservation)
 		require.NoError(t, err)
 
-		reservations, err := reservationDao.List(ctx, 10, 0)
+		reservations, err := reservationDao.List(ctx, nil, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(reservations))
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		awsReservation := newAWSReservation()
+		awsReservation.SourceID = "42"
+		awsReservation.Detail = &models.AWSDetail{Name: "web_server-1"}
+		err := reservationDao.CreateAWS(ctx, awsReservation)
+		require.NoError(t, err)
+		err = reservationDao.FinishWithSuccess(ctx, awsReservation.ID)
+		require.NoError(t, err)
+
+		filter := &dao.ReservationFilter{Provider: models.ProviderTypeAWS}
+		reservations, err := reservationDao.List(ctx, filter, 10, 0)
 		require.NoError(t, err)
 		assert.Equal(t, 2, len(reservations))
+
+		filter = &dao.ReservationFilter{SourceID: "42", Name: "_SERVER", Status: dao.ReservationSuccess}
+		reservations, err = reservationDao.List(ctx, filter, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(reservations))
+		assert.Equal(t, awsReservation.ID, reservations[0].ID)
+
+		count, err := reservationDao.Count(ctx, &dao.ReservationFilter{Status: dao.ReservationPending})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		count, err = reservationDao.Count(ctx, &dao.ReservationFilter{Name: "server%"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		count, err = reservationDao.Count(ctx, &dao.ReservationFilter{CreatedBefore: time.Now().Add(-time.Hour)})
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("sorted", func(t *testing.T) {
+		reservations, err := reservationDao.List(ctx, &dao.ReservationFilter{SortBy: "provider", SortDir: dao.SortDesc}, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, 3, len(reservations))
+		assert.Equal(t, models.ProviderTypeAWS, reservations[0].Provider)
+		assert.Greater(t, reservations[0].ID, reservations[1].ID)
+		assert.Equal(t, models.ProviderTypeNoop, reservations[2].Provider)
+	})
+
+	t.Run("keyset", func(t *testing.T) {
+		reservations, err := reservationDao.ListKeyset(ctx, nil, nil, 2)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(reservations))
+
+		last := reservations[1]
+		after := &page.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		reservations, err = reservationDao.ListKeyset(ctx, nil, after, 2)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(reservations))
+		assert.Greater(t, reservations[0].ID, last.ID)
+
+		reservations, err = reservationDao.ListKeyset(ctx, &dao.ReservationFilter{SortDir: dao.SortDesc}, after, 2)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(reservations))
+		assert.Less(t, reservations[0].ID, last.ID)
+	})
+}
+
+func TestReservationScheduled(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	t.Run("empty", func(t *testing.T) {
+		reservations, err := reservationDao.ListScheduled(ctx, 10, 0)
+		require.NoError(t, err)
+		require.Empty(t, reservations)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		awsReservation := newAWSReservation()
+		err := reservationDao.CreateAWS(ctx, awsReservation)
+		require.NoError(t, err)
+
+		scheduledReservation := newAWSReservation()
+		scheduledReservation.LaunchAt = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+		err = reservationDao.CreateAWS(ctx, scheduledReservation)
+		require.NoError(t, err)
+		assert.Equal(t, models.ReservationStatusScheduled, scheduledReservation.Status)
+
+		reservations, err := reservationDao.ListScheduled(ctx, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(reservations))
+		assert.Equal(t, scheduledReservation.ID, reservations[0].ID)
+		assert.True(t, reservations[0].LaunchAt.Valid)
+
+		count, err := reservationDao.CountScheduled(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("cancel finishes scheduled", func(t *testing.T) {
+		res := newAWSReservation()
+		res.LaunchAt = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+		err := reservationDao.CreateAWS(ctx, res)
+		require.NoError(t, err)
+
+		err = reservationDao.Cancel(ctx, res.ID)
+		require.NoError(t, err)
+
+		newRes, err := reservationDao.GetById(ctx, res.ID)
+		require.NoError(t, err)
+		assert.True(t, newRes.Cancelled())
+		assert.True(t, newRes.FinishedAt.Valid)
+		assert.False(t, newRes.Success.Bool)
+		assert.Equal(t, "Cancelled", newRes.Status)
+	})
+}
+
+func TestReservationExpiry(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	res := newAWSReservation()
+	res.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+	err := reservationDao.CreateAWS(ctx, res)
+	require.NoError(t, err)
+
+	t.Run("not finished", func(t *testing.T) {
+		reservations, err := reservationDao.UnscopedListExpired(ctx, 10)
+		require.NoError(t, err)
+		require.Empty(t, reservations)
+	})
+
+	t.Run("expiring", func(t *testing.T) {
+		err := reservationDao.FinishWithSuccess(ctx, res.ID)
+		require.NoError(t, err)
+
+		reservations, err := reservationDao.UnscopedListExpiring(ctx, time.Hour, 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(reservations))
+
+		err = reservationDao.UnscopedMarkExpiryNotified(ctx, res.ID)
+		require.NoError(t, err)
+
+		reservations, err = reservationDao.UnscopedListExpiring(ctx, time.Hour, 10)
+		require.NoError(t, err)
+		require.Empty(t, reservations)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		reservations, err := reservationDao.UnscopedListExpired(ctx, 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(reservations))
+		assert.Equal(t, res.ID, reservations[0].ID)
+
+		err = reservationDao.UnscopedMarkExpired(ctx, res.ID)
+		require.NoError(t, err)
+
+		reservations, err = reservationDao.UnscopedListExpired(ctx, 10)
+		require.NoError(t, err)
+		require.Empty(t, reservations)
+
+		newRes, err := reservationDao.GetById(ctx, res.ID)
+		require.NoError(t, err)
+		assert.True(t, newRes.ExpiredAt.Valid)
 	})
 }
 
@@ -344,6 +601,42 @@ func TestReservationFinish(t *testing.T) {
 		assert.Equal(t, "error", newRes.Error)
 	})
 
+	t.Run("already finished", func(t *testing.T) {
+		res := newNoopReservation()
+		err := reservationDao.CreateNoop(ctx, res)
+		require.NoError(t, err)
+
+		err = reservationDao.FinishWithError(ctx, res.ID, "error")
+		require.NoError(t, err)
+		err = reservationDao.FinishWithSuccess(ctx, res.ID)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+		err = reservationDao.FinishWithError(ctx, res.ID, "another error")
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+
+		newRes, err := reservationDao.GetById(ctx, res.ID)
+		require.NoError(t, err)
+		assert.False(t, newRes.Success.Bool)
+		assert.Equal(t, "error", newRes.Error)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		res := newNoopReservation()
+		err := reservationDao.CreateNoop(ctx, res)
+		require.NoError(t, err)
+
+		err = reservationDao.Cancel(ctx, res.ID)
+		require.NoError(t, err)
+		err = reservationDao.FinishWithSuccess(ctx, res.ID)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+		err = reservationDao.FinishWithError(ctx, res.ID, "cancelled")
+		require.NoError(t, err)
+
+		newRes, err := reservationDao.GetById(ctx, res.ID)
+		require.NoError(t, err)
+		assert.False(t, newRes.Success.Bool)
+		assert.Equal(t, "Cancelled", newRes.Status)
+	})
+
 	t.Run("mismatch success", func(t *testing.T) {
 		err := reservationDao.FinishWithSuccess(ctx, math.MaxInt64)
 		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
@@ -355,6 +648,48 @@ func TestReservationFinish(t *testing.T) {
 	})
 }
 
+func TestReservationFailUnfinishedBySourceId(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	t.Run("success", func(t *testing.T) {
+		res := newAWSReservation()
+		res.SourceID = "42"
+		err := reservationDao.CreateAWS(ctx, res)
+		require.NoError(t, err)
+
+		finished := newAWSReservation()
+		finished.SourceID = "42"
+		err = reservationDao.CreateAWS(ctx, finished)
+		require.NoError(t, err)
+		err = reservationDao.FinishWithSuccess(ctx, finished.ID)
+		require.NoError(t, err)
+
+		otherSource := newGCPReservation()
+		otherSource.SourceID = "43"
+		err = reservationDao.CreateGCP(ctx, otherSource)
+		require.NoError(t, err)
+
+		updated, err := reservationDao.FailUnfinishedBySourceId(ctx, "42", "source was deleted")
+		require.NoError(t, err)
+		assert.Equal(t, []int64{res.ID}, updated)
+
+		newRes, err := reservationDao.GetById(ctx, res.ID)
+		require.NoError(t, err)
+		assert.False(t, newRes.Success.Bool)
+		assert.Equal(t, "source was deleted", newRes.Error)
+		assert.True(t, newRes.Cancelled(), "launch job must stop")
+
+		newRes, err = reservationDao.GetById(ctx, finished.ID)
+		require.NoError(t, err)
+		assert.True(t, newRes.Success.Bool)
+
+		newRes, err = reservationDao.GetById(ctx, otherSource.ID)
+		require.NoError(t, err)
+		assert.False(t, newRes.Success.Valid)
+	})
+}
+
 func TestReservationRate(t *testing.T) {
 	rdao, ctx := setupReservation(t)
 	t.Run("allows slow reservations", func(t *testing.T) {
@@ -384,4 +719,59 @@ func TestReservationRate(t *testing.T) {
 		err := rdao2.CreateNoop(ctx2, res)
 		require.NoError(t, err)
 	})
+
+	t.Run("account limits", func(t *testing.T) {
+		defer reset()
+		err := dao.GetAccountDao(ctx).UnscopedUpdateLimits(ctx, &models.AccountLimits{
+			AccountID:              1,
+			ReservationsPerSecond:  sql.NullInt32{Int32: 2, Valid: true},
+			MaxPendingReservations: sql.NullInt32{Int32: 3, Valid: true},
+		})
+		require.NoError(t, err)
+
+		for i := 1; i <= 2; i++ {
+			err = rdao.CreateNoop(ctx, newNoopReservation())
+			require.NoError(t, err)
+		}
+
+		var limitErr *dao.LimitError
+		err = rdao.CreateNoop(ctx, newNoopReservation())
+		require.ErrorAs(t, err, &limitErr)
+		require.ErrorIs(t, err, dao.ErrReservationRateExceeded)
+		assert.Equal(t, dao.LimitReservationsPerSecond, limitErr.Limit)
+		assert.Equal(t, int64(2), limitErr.Maximum)
+
+		err = rdao.CreateAWS(ctx, newAWSReservation())
+		require.NoError(t, err)
+
+		err = rdao.CreateGCP(ctx, newGCPReservation())
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, dao.LimitMaxPendingReservations, limitErr.Limit)
+		assert.Equal(t, int64(3), limitErr.Maximum)
+	})
+}
+
+func TestReservationCountInstancesSince(t *testing.T) {
+	rdao, ctx := setupReservation(t)
+	defer reset()
+
+	res := newAWSReservation()
+	res.Detail = &models.AWSDetail{Amount: 3}
+	err := rdao.CreateAWS(ctx, res)
+	require.NoError(t, err)
+
+	failed := newGCPReservation()
+	failed.Detail = &models.GCPDetail{Amount: 5}
+	err = rdao.CreateGCP(ctx, failed)
+	require.NoError(t, err)
+	err = rdao.FinishWithError(ctx, failed.ID, "failed")
+	require.NoError(t, err)
+
+	count, err := rdao.CountInstancesSince(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	count, err = rdao.CountInstancesSince(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, count)
 }
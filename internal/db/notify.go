@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Notify sends a notification with the payload to all sessions listening on the channel. The
+// payload must be shorter than 8000 bytes.
+func Notify(ctx context.Context, channel, payload string) error {
+	_, err := Pool.Exec(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+	if err != nil {
+		return fmt.Errorf("pgx notify error: %w", err)
+	}
+	return nil
+}
+
+// Listen acquires a dedicated connection from the pool, listens on the channel and calls the
+// handler for every notification. It blocks until the context is cancelled or the connection
+// fails, therefore it should be called from a separate goroutine.
+func Listen(ctx context.Context, channel string, handler func(payload string)) error {
+	conn, err := Pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("pgx acquire error: %w", err)
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize())
+	if err != nil {
+		return fmt.Errorf("pgx listen error: %w", err)
+	}
+	defer func() {
+		// the connection returns to the pool, it must not receive notifications anymore
+		_, _ = conn.Exec(context.Background(), "UNLISTEN *")
+	}()
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("pgx wait for notification error: %w", err)
+		}
+		handler(notification.Payload)
+	}
+}
@@ -41,3 +41,31 @@ func WithIdentityFrom64(ctx context.Context, id string) (context.Context, error)
 
 	return WithIdentity(ctx, jsonData), nil
 }
+
+// PrincipalName returns username of the user or service account, or the identity type for other
+// principals. Used for audit logging.
+func PrincipalName(principal Principal) string {
+	switch {
+	case principal.Identity.User != nil:
+		return principal.Identity.User.Username
+	case principal.Identity.ServiceAccount != nil:
+		return principal.Identity.ServiceAccount.Username
+	default:
+		return principal.Identity.Type
+	}
+}
+
+// NewSystemPrincipal returns identity of the application itself acting on behalf of the
+// account. Used by background routines which have no incoming request identity.
+func NewSystemPrincipal(orgId, accountNumber string) Principal {
+	return Principal{
+		Identity: identity.Identity{
+			AccountNumber: accountNumber,
+			OrgID:         orgId,
+			Type:          "System",
+			Internal: identity.Internal{
+				OrgID: orgId,
+			},
+		},
+	}
+}
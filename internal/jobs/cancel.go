@@ -0,0 +1,227 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var (
+	ErrReservationCancelled = errors.New("reservation was cancelled")
+	ErrUnknownResourceType  = errors.New("unknown resource type")
+	ErrLaunchInterrupted    = errors.New("launch job was interrupted and it is not run again")
+)
+
+// HandleCancelledJob is registered as the worker cancel handler. Launch jobs of cancelled
+// reservations are finished right away since no resources were created yet. Launch jobs are
+// not idempotent, redelivered jobs are only dispatched again when it is safe (see checkInterrupted).
+func HandleCancelledJob(ctx context.Context, job *worker.Job) bool {
+	if job == nil {
+		return false
+	}
+
+	var reservationId int64
+	var failed func(ctx context.Context, jobErr error)
+	switch args := job.Args.(type) {
+	case LaunchInstanceAWSTaskArgs:
+		reservationId = args.ReservationID
+		failed = func(ctx context.Context, jobErr error) { failedAWS(ctx, &args, jobErr) }
+	case LaunchInstanceAzureTaskArgs:
+		reservationId = args.ReservationID
+		failed = func(ctx context.Context, jobErr error) { failedAzure(ctx, &args, jobErr) }
+	case LaunchInstanceGCPTaskArgs:
+		reservationId = args.ReservationID
+		failed = func(ctx context.Context, jobErr error) { failedGCP(ctx, &args, jobErr) }
+	case NoopJobArgs:
+		reservationId = args.ReservationID
+		failed = func(ctx context.Context, jobErr error) { finishWithError(ctx, args.ReservationID, jobErr) }
+	default:
+		return false
+	}
+
+	ctx, _ = reservationContextLogger(ctx, reservationId)
+	if job.Attempt > 1 && checkInterrupted(ctx, reservationId, failed) {
+		return true
+	}
+
+	if checkCancelled(ctx, reservationId) == nil {
+		return false
+	}
+
+	finishCancelled(ctx, reservationId, nil, nil)
+	return true
+}
+
+// checkInterrupted is called for launch jobs which were delivered again, the previous attempt was
+// interrupted (e.g. the worker pod was killed) and it could have created resources already. The job
+// is skipped when the reservation was finished, when resources were created they are rolled back
+// and the reservation is finished with an error. Returns true when the job must not be dispatched.
+func checkInterrupted(ctx context.Context, reservationId int64, failed func(ctx context.Context, jobErr error)) bool {
+	logger := zerolog.Ctx(ctx)
+	rDao := dao.GetReservationDao(ctx)
+
+	reservation, err := rDao.GetById(ctx, reservationId)
+	if err != nil {
+		logger.Warn().Err(err).Msg("unable to check interrupted reservation")
+		return false
+	}
+
+	if reservation.FinishedAt.Valid {
+		logger.Warn().Msg("Launch job was delivered again but the reservation is already finished, skipping")
+		return true
+	}
+
+	resources, err := rDao.UnscopedListResources(ctx, reservationId)
+	if err != nil {
+		logger.Warn().Err(err).Msg("unable to list resources of interrupted reservation")
+		return false
+	}
+
+	if len(resources) == 0 {
+		logger.Info().Msg("Launch job was interrupted before any resources were created, running it again")
+		return false
+	}
+
+	logger.Warn().Int("resources", len(resources)).Msg("Launch job was interrupted after resources were created, rolling back")
+	failed(ctx, ErrLaunchInterrupted)
+	return true
+}
+
+// checkCancelled returns ErrReservationCancelled when the reservation was cancelled by the user. It
+// is called between job steps. Database errors are only logged so the job can continue.
+func checkCancelled(ctx context.Context, reservationId int64) error {
+	logger := zerolog.Ctx(ctx)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// the original context is expired and unusable at this point
+		ctx = copyContext(ctx)
+	}
+
+	cancelled, err := dao.GetReservationDao(ctx).UnscopedIsCancelled(ctx, reservationId)
+	if err != nil {
+		logger.Warn().Err(err).Msg("unable to check reservation cancellation")
+		return nil
+	}
+
+	if cancelled {
+		logger.Info().Msg("Reservation was cancelled, stopping the job")
+		return ErrReservationCancelled
+	}
+	return nil
+}
+
+// finishCancelled closes a cancelled reservation, rolled back resources and rollback error are
+// appended to the error message.
+func finishCancelled(ctx context.Context, reservationId int64, deleted []*models.ReservationResource, rollbackErr error) {
+	finishWithError(ctx, reservationId, rollbackError(ErrReservationCancelled, deleted, rollbackErr))
+}
+
+// RollbackLaunchInstanceAWS terminates instances and deletes the pubkey imported by the job. All
+// steps are attempted, errors are joined.
+func RollbackLaunchInstanceAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) ([]*models.ReservationResource, error) {
+	ctx = rollbackContext(ctx)
+	ctx, span := telemetry.StartSpan(ctx, "RollbackLaunchInstanceAWS")
+	defer span.End()
+
+	updateStatusBefore(ctx, args.ReservationID, "Rolling back")
+
+	ec2Client, err := clients.GetEC2Client(ctx, args.ARN, args.Region)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot create new ec2 client from config")
+		return nil, fmt.Errorf("cannot create new ec2 client from config: %w", err)
+	}
+
+	pkDao := dao.GetPubkeyDao(ctx)
+	deleted, err := rollbackResources(ctx, args.ReservationID, func(ctx context.Context, resource *models.ReservationResource) error {
+		switch resource.Type {
+		case models.ResourceTypeAWSInstance:
+			if err := ec2Client.TerminateInstances(ctx, []string{resource.ResourceID}); err != nil {
+				return fmt.Errorf("cannot terminate instance: %w", err)
+			}
+			return nil
+		case models.ResourceTypeAWSKeyPair:
+			if err := ec2Client.DeleteSSHKey(ctx, resource.ResourceID); err != nil {
+				return fmt.Errorf("cannot delete imported pubkey: %w", err)
+			}
+			pkr, err := pkDao.UnscopedGetResourceBySourceAndRegion(ctx, args.PubkeyID, args.SourceID, resource.Region)
+			if errors.Is(err, dao.ErrNoRows) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("cannot get pubkey resource: %w", err)
+			}
+			if err = pkDao.UnscopedDeleteResource(ctx, pkr.ID); err != nil {
+				return fmt.Errorf("cannot delete pubkey resource: %w", err)
+			}
+			return nil
+		default:
+			return fmt.Errorf("%w: %s", ErrUnknownResourceType, resource.Type)
+		}
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "rollback failed")
+	}
+	return deleted, err
+}
+
+// RollbackLaunchInstanceAzure deletes virtual machines, disks and networking created by the job.
+// Resource group and shared networking are used across reservations and they are kept.
+func RollbackLaunchInstanceAzure(ctx context.Context, args *LaunchInstanceAzureTaskArgs) ([]*models.ReservationResource, error) {
+	ctx = rollbackContext(ctx)
+	ctx, span := telemetry.StartSpan(ctx, "RollbackLaunchInstanceAzure")
+	defer span.End()
+
+	updateStatusBefore(ctx, args.ReservationID, "Rolling back")
+
+	azureClient, err := clients.GetAzureClient(ctx, args.Subscription)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot instantiate Azure client")
+		return nil, fmt.Errorf("failed to instantiate Azure client: %w", err)
+	}
+
+	deleted, err := rollbackResources(ctx, args.ReservationID, func(ctx context.Context, resource *models.ReservationResource) error {
+		if err := azureClient.DeleteResource(ctx, resource.ResourceID); err != nil {
+			return fmt.Errorf("cannot delete Azure resource: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "rollback failed")
+	}
+	return deleted, err
+}
+
+// RollbackLaunchInstanceGCP deletes instances created by the job.
+func RollbackLaunchInstanceGCP(ctx context.Context, args *LaunchInstanceGCPTaskArgs) ([]*models.ReservationResource, error) {
+	ctx = rollbackContext(ctx)
+	ctx, span := telemetry.StartSpan(ctx, "RollbackLaunchInstanceGCP")
+	defer span.End()
+
+	updateStatusBefore(ctx, args.ReservationID, "Rolling back")
+
+	gcpClient, err := clients.GetGCPClient(ctx, args.ProjectID)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot create new GCP client")
+		return nil, fmt.Errorf("cannot create new GCP client: %w", err)
+	}
+
+	deleted, err := rollbackResources(ctx, args.ReservationID, func(ctx context.Context, resource *models.ReservationResource) error {
+		if resource.Type != models.ResourceTypeGCPInstance {
+			return fmt.Errorf("%w: %s", ErrUnknownResourceType, resource.Type)
+		}
+		if err := gcpClient.TerminateInstances(ctx, resource.Region, []string{resource.ResourceID}); err != nil {
+			return fmt.Errorf("cannot terminate instance: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "rollback failed")
+	}
+	return deleted, err
+}
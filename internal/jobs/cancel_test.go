@@ -0,0 +1,310 @@
+package jobs_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCancelledJob(t *testing.T) {
+	ctx := prepareGCPContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareGCPReservation(t, ctx, pk)
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateGCP(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	job := &worker.Job{
+		Type: jobs.TypeLaunchInstanceGcp,
+		Args: jobs.LaunchInstanceGCPTaskArgs{ReservationID: res.ID},
+	}
+
+	t.Run("not cancelled", func(t *testing.T) {
+		assert.False(t, jobs.HandleCancelledJob(ctx, job))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		err = rDao.Cancel(ctx, res.ID)
+		require.NoError(t, err, "failed to cancel reservation")
+
+		assert.True(t, jobs.HandleCancelledJob(ctx, job))
+		assert.True(t, res.FinishedAt.Valid, "reservation should be finished")
+		assert.Equal(t, "Cancelled", res.Status)
+		assert.Contains(t, res.Error, jobs.ErrReservationCancelled.Error())
+	})
+
+	t.Run("unrelated job", func(t *testing.T) {
+		actionJob := &worker.Job{
+			Type: jobs.TypeInstanceActionGcp,
+			Args: jobs.InstanceActionTaskArgs{ReservationID: res.ID},
+		}
+		assert.False(t, jobs.HandleCancelledJob(ctx, actionJob))
+	})
+}
+
+func TestHandleCancelledScheduledJob(t *testing.T) {
+	ctx := prepareGCPContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareGCPReservation(t, ctx, pk)
+	res.LaunchAt = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+	res.Status = res.InitialStatus()
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateGCP(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	err = rDao.Cancel(ctx, res.ID)
+	require.NoError(t, err, "failed to cancel reservation")
+	require.True(t, res.FinishedAt.Valid, "scheduled reservation should be finished right away")
+	finishedAt := res.FinishedAt.Time
+
+	job := &worker.Job{
+		Type:  jobs.TypeLaunchInstanceGcp,
+		Args:  jobs.LaunchInstanceGCPTaskArgs{ReservationID: res.ID},
+		RunAt: res.LaunchAt.Time,
+	}
+	assert.True(t, jobs.HandleCancelledJob(ctx, job))
+	assert.Equal(t, "Cancelled", res.Status)
+	assert.Equal(t, finishedAt, res.FinishedAt.Time, "finished reservation must not be finished again")
+}
+
+func TestHandleRedeliveredJob(t *testing.T) {
+	ctx := prepareGCPContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareGCPReservation(t, ctx, pk)
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateGCP(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	args := jobs.LaunchInstanceGCPTaskArgs{
+		ImageName:     "composer-api-3b6225fc-d55a-4dcc-9d0a-b478ae152a",
+		Zone:          "europe-west8-c",
+		PubkeyID:      pk.ID,
+		ReservationID: res.ID,
+		ProjectID:     clients.NewAuthentication("example-project-id", models.ProviderTypeGCP),
+		Detail:        res.Detail,
+	}
+	job := &worker.Job{
+		Type:    jobs.TypeLaunchInstanceGcp,
+		Args:    args,
+		Attempt: 2,
+	}
+
+	t.Run("no resources created", func(t *testing.T) {
+		assert.False(t, jobs.HandleCancelledJob(ctx, job))
+	})
+
+	t.Run("resources created", func(t *testing.T) {
+		err = jobs.DoLaunchInstanceGCP(ctx, &args)
+		require.NoError(t, err, "launch instances failed to run")
+		require.Equal(t, 1, clientStubs.CountStubInstancesGCP(ctx))
+
+		assert.True(t, jobs.HandleCancelledJob(ctx, job))
+		assert.Equal(t, 0, clientStubs.CountStubInstancesGCP(ctx), "instances should be rolled back")
+		assert.True(t, res.FinishedAt.Valid, "reservation should be finished")
+		assert.False(t, res.Success.Bool)
+		assert.Contains(t, res.Error, jobs.ErrLaunchInterrupted.Error())
+	})
+
+	t.Run("finished reservation", func(t *testing.T) {
+		finishedAt := res.FinishedAt.Time
+		assert.True(t, jobs.HandleCancelledJob(ctx, job))
+		assert.Equal(t, finishedAt, res.FinishedAt.Time, "finished reservation must not be finished again")
+	})
+}
+
+func TestRollbackLaunchInstanceGCP(t *testing.T) {
+	ctx := prepareGCPContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareGCPReservation(t, ctx, pk)
+	res.Detail.Amount = 2
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateGCP(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	args := &jobs.LaunchInstanceGCPTaskArgs{
+		ImageName:     "composer-api-3b6225fc-d55a-4dcc-9d0a-b478ae152a",
+		Zone:          "europe-west8-c",
+		PubkeyID:      pk.ID,
+		ReservationID: res.ID,
+		ProjectID:     clients.NewAuthentication("example-project-id", models.ProviderTypeGCP),
+		Detail:        res.Detail,
+	}
+
+	err = jobs.DoLaunchInstanceGCP(ctx, args)
+	require.NoError(t, err, "launch instances failed to run")
+	require.Equal(t, 2, clientStubs.CountStubInstancesGCP(ctx))
+
+	deleted, err := jobs.RollbackLaunchInstanceGCP(ctx, args)
+	require.NoError(t, err, "rollback failed to run")
+	assert.Len(t, deleted, 2)
+	assert.Equal(t, 0, clientStubs.CountStubInstancesGCP(ctx))
+
+	resources, err := rDao.UnscopedListResources(ctx, res.ID)
+	require.NoError(t, err, "failed to list resources")
+	assert.Empty(t, resources, "all resources should be marked as deleted")
+}
+
+func TestHandleLaunchInstanceAWSCancelled(t *testing.T) {
+	ctx := prepareEC2Context(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareAWSReservation(t, ctx, pk)
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAWS(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	job := &worker.Job{
+		Type: jobs.TypeLaunchInstanceAws,
+		Args: jobs.LaunchInstanceAWSTaskArgs{
+			ReservationID: res.ID,
+			Region:        res.Detail.Region,
+			PubkeyID:      pk.ID,
+			SourceID:      res.SourceID,
+			Detail:        res.Detail,
+			ARN:           clients.NewAuthentication("arn:aws:123123123123", models.ProviderTypeAWS),
+		},
+	}
+
+	t.Run("cancel handler", func(t *testing.T) {
+		assert.False(t, jobs.HandleCancelledJob(ctx, job), "not cancelled job should be dispatched")
+	})
+
+	t.Run("cancelled during launch", func(t *testing.T) {
+		err = rDao.Cancel(ctx, res.ID)
+		require.NoError(t, err, "failed to cancel reservation")
+
+		err = jobs.HandleLaunchInstanceAWS(ctx, job)
+		require.NoError(t, err, "launch job failed to run")
+
+		assert.True(t, res.FinishedAt.Valid, "reservation should be finished")
+		assert.False(t, res.Success.Bool)
+		assert.Equal(t, "Cancelled", res.Status)
+		assert.Contains(t, res.Error, jobs.ErrReservationCancelled.Error())
+		assert.Contains(t, res.Error, "rolled back 1 resource(s): aws_key_pair")
+
+		resources, err := rDao.UnscopedListResources(ctx, res.ID)
+		require.NoError(t, err, "failed to list resources")
+		assert.Empty(t, resources, "imported pubkey should be rolled back")
+	})
+}
+
+func TestHandleLaunchInstanceAzureCancelled(t *testing.T) {
+	ctx := prepareAzureContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareAzureReservation(t, ctx, pk)
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAzure(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	job := &worker.Job{
+		Type: jobs.TypeLaunchInstanceAzure,
+		Args: jobs.LaunchInstanceAzureTaskArgs{
+			AzureImageID:  "/subscriptions/subUUID/resourceGroups/redhat-deployed/providers/Microsoft.Compute/images/composer-api-123",
+			Location:      "useast",
+			PubkeyID:      pk.ID,
+			ReservationID: res.ID,
+			SourceID:      "2",
+			Subscription:  clients.NewAuthentication("subUUID", models.ProviderTypeAzure),
+		},
+	}
+
+	t.Run("cancel handler", func(t *testing.T) {
+		assert.False(t, jobs.HandleCancelledJob(ctx, job), "not cancelled job should be dispatched")
+
+		err = rDao.Cancel(ctx, res.ID)
+		require.NoError(t, err, "failed to cancel reservation")
+		assert.True(t, jobs.HandleCancelledJob(ctx, job), "cancelled job should be skipped")
+		assert.True(t, res.FinishedAt.Valid, "reservation should be finished")
+		assert.Equal(t, "Cancelled", res.Status)
+	})
+
+	t.Run("cancelled during launch", func(t *testing.T) {
+		res := prepareAzureReservation(t, ctx, pk)
+		err = rDao.CreateAzure(ctx, res)
+		require.NoError(t, err, "failed to add stubbed reservation")
+		args := job.Args.(jobs.LaunchInstanceAzureTaskArgs)
+		args.ReservationID = res.ID
+
+		err = rDao.Cancel(ctx, res.ID)
+		require.NoError(t, err, "failed to cancel reservation")
+
+		err = jobs.HandleLaunchInstanceAzure(ctx, &worker.Job{Type: jobs.TypeLaunchInstanceAzure, Args: args})
+		require.NoError(t, err, "launch job failed to run")
+
+		assert.Equal(t, 0, clientStubs.CountStubAzureVMs(ctx), "no VM should be launched")
+		assert.True(t, res.FinishedAt.Valid, "reservation should be finished")
+		assert.False(t, res.Success.Bool)
+		assert.Equal(t, "Cancelled", res.Status)
+		assert.Contains(t, res.Error, jobs.ErrReservationCancelled.Error())
+	})
+}
+
+// TestFinishCancelledReservation uses the noop job which does not check cancellation between
+// steps, the success update of a cancelled reservation is rejected.
+func TestFinishCancelledReservation(t *testing.T) {
+	ctx := prepareGCPContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+	rDao := dao.GetReservationDao(ctx)
+
+	t.Run("not cancelled", func(t *testing.T) {
+		res := prepareGCPReservation(t, ctx, pk)
+		err = rDao.CreateGCP(ctx, res)
+		require.NoError(t, err, "failed to add stubbed reservation")
+
+		err = jobs.HandleNoop(ctx, &worker.Job{Type: jobs.TypeNoop, Args: jobs.NoopJobArgs{ReservationID: res.ID}})
+		require.NoError(t, err, "noop job failed to run")
+		assert.True(t, res.FinishedAt.Valid, "reservation should be finished")
+		assert.True(t, res.Success.Bool, "reservation should succeed")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		res := prepareGCPReservation(t, ctx, pk)
+		err = rDao.CreateGCP(ctx, res)
+		require.NoError(t, err, "failed to add stubbed reservation")
+		err = rDao.Cancel(ctx, res.ID)
+		require.NoError(t, err, "failed to cancel reservation")
+
+		err = jobs.HandleNoop(ctx, &worker.Job{Type: jobs.TypeNoop, Args: jobs.NoopJobArgs{ReservationID: res.ID}})
+		require.NoError(t, err, "noop job failed to run")
+		assert.True(t, res.FinishedAt.Valid, "reservation should be finished")
+		assert.False(t, res.Success.Bool, "cancelled reservation must not succeed")
+		assert.Equal(t, "Cancelled", res.Status)
+	})
+}
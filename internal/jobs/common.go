@@ -8,29 +8,57 @@ import (
 
 	"github.com/RHEnVision/provisioning-backend/internal/notifications"
 
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
 	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/rs/zerolog"
 )
 
 var (
-	ErrTypeAssertion = errors.New("type assert error")
+	ErrTypeAssertion = fmt.Errorf("%w: type assert error", worker.ErrPermanent)
 	ErrPanicInJob    = errors.New("panic during job")
+	ErrNoJob         = fmt.Errorf("%w: no job to handle", worker.ErrPermanent)
+
+	ErrLaunchCapacityUnavailable = errors.New("the cloud provider has no capacity for the instance type at the moment, try again later or choose another instance type or region")
+	ErrSpotCapacityUnavailable   = errors.New("the cloud provider has no spot capacity for the instance type at the moment, try again later, choose another instance type or region, or launch on-demand instances")
 )
 
-func finishJob(ctx context.Context, reservationId int64, jobErr error) {
+// userCapacityError prefixes launch errors caused by missing cloud provider capacity with a user
+// error, the message is stored as the reservation error.
+func userCapacityError(err error, spot bool) error {
+	if !errors.Is(err, clients.ErrCapacityUnavailable) {
+		return err
+	}
+	if spot {
+		return fmt.Errorf("%w: %w", ErrSpotCapacityUnavailable, err)
+	}
+	return fmt.Errorf("%w: %w", ErrLaunchCapacityUnavailable, err)
+}
+
+// finishJob finishes the reservation and sends a notification. ErrReservationCancelled is returned
+// when the reservation was cancelled right before it was finished with success, the caller must
+// roll back and finish it as cancelled.
+func finishJob(ctx context.Context, reservationId int64, jobErr error) error {
 	nc := notifications.GetNotificationClient(ctx)
 
 	if jobErr != nil {
 		nc.FailedLaunch(ctx, reservationId, jobErr)
 		finishWithError(ctx, reservationId, jobErr)
-	} else {
-		nc.SuccessfulLaunch(ctx, reservationId)
-		finishWithSuccess(ctx, reservationId)
+		return nil
+	}
+
+	if err := finishWithSuccess(ctx, reservationId); err != nil {
+		return err
 	}
+	nc.SuccessfulLaunch(ctx, reservationId)
+	return nil
 }
 
-func finishWithSuccess(ctx context.Context, reservationId int64) {
+// finishWithSuccess closes a reservation with success. ErrReservationCancelled is returned when
+// the reservation was cancelled, other errors are only logged.
+func finishWithSuccess(ctx context.Context, reservationId int64) error {
 	logger := zerolog.Ctx(ctx)
 	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 		// the original context is expired and unusable at this point
@@ -42,7 +70,16 @@ func finishWithSuccess(ctx context.Context, reservationId int64) {
 	reservation, err := rDao.GetById(ctx, reservationId)
 	if err != nil {
 		logger.Warn().Err(err).Msg("unable to update job status: get by id")
-		return
+		return nil
+	}
+	if reservation.FinishedAt.Valid {
+		// e.g. reservations of deleted sources are failed by the statuser
+		logger.Warn().Msg("Reservation was already finished, not finishing with success")
+		return nil
+	}
+	if reservation.Cancelled() {
+		logger.Info().Msg("Reservation was cancelled, not finishing with success")
+		return ErrReservationCancelled
 	}
 	if reservation.Step == reservation.Steps {
 		logger.Info().Msgf("Finishing reservation with success at step %d/%d", reservation.Step, reservation.Steps)
@@ -50,18 +87,27 @@ func finishWithSuccess(ctx context.Context, reservationId int64) {
 		logger.Error().Msgf("Finishing reservation with success at step %d/%d", reservation.Step, reservation.Steps)
 	}
 
-	// total count of reservations
-	metrics.IncReservationCount(reservation.Provider.String(), "success")
-
-	// and finish
+	// and finish, cancelled reservations are not updated
 	err = rDao.FinishWithSuccess(ctx, reservationId)
+	if errors.Is(err, dao.ErrAffectedMismatch) {
+		if cancelled, cErr := rDao.UnscopedIsCancelled(ctx, reservationId); cErr == nil && cancelled {
+			logger.Info().Msg("Reservation was cancelled while finishing, not finishing with success")
+			return ErrReservationCancelled
+		}
+	}
 	if err != nil {
 		logger.Warn().Err(err).Msg("unable to update job status: finish")
+		return nil
 	}
+
+	// total count of reservations
+	metrics.IncReservationCount(reservation.Provider.String(), "success")
+	statusChanged(ctx, reservationId, kafka.ReservationSuccessEventType)
+	return nil
 }
 
 // finishWithError closes a reservation and sets it into error state. Error message is also
-// stored into the reservation.
+// stored into the reservation. Cancelled reservations are reported as cancelled.
 func finishWithError(ctx context.Context, reservationId int64, jobError error) {
 	logger := zerolog.Ctx(ctx)
 	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
@@ -76,16 +122,34 @@ func finishWithError(ctx context.Context, reservationId int64, jobError error) {
 		logger.Warn().Err(err).Msg("unable to update job status: get by id")
 		return
 	}
-	logger.Error().Err(jobError).Msgf("Finishing reservation with error at step %d/%d", reservation.Step, reservation.Steps)
+	if reservation.FinishedAt.Valid {
+		// scheduled reservations are finished right away when cancelled and reservations of
+		// deleted sources by the statuser
+		logger.Info().Err(jobError).Msg("Reservation was already finished")
+		return
+	}
+	result := "failure"
+	if reservation.Cancelled() {
+		// errors from interrupted steps of cancelled reservations are reported as cancellation
+		if !errors.Is(jobError, ErrReservationCancelled) {
+			jobError = fmt.Errorf("%w: %w", ErrReservationCancelled, jobError)
+		}
+		result = "cancelled"
+		logger.Info().Err(jobError).Msgf("Finishing cancelled reservation at step %d/%d", reservation.Step, reservation.Steps)
+	} else {
+		logger.Error().Err(jobError).Msgf("Finishing reservation with error at step %d/%d", reservation.Step, reservation.Steps)
+	}
 
 	// total count of reservations
-	metrics.IncReservationCount(reservation.Provider.String(), "failure")
+	metrics.IncReservationCount(reservation.Provider.String(), result)
 
 	// and finish
 	err = rDao.FinishWithError(ctx, reservationId, jobError.Error())
 	if err != nil {
 		logger.Warn().Err(err).Msg("unable to update job status: finish")
+		return
 	}
+	statusChanged(ctx, reservationId, kafka.ReservationFailureEventType)
 }
 
 // updateStatusBefore is called after every step function within a job. It updates reservation status
@@ -114,7 +178,9 @@ func updateStatusAfter(ctx context.Context, id int64, status string, addSteps in
 	err := rDao.UpdateStatus(ctx, id, status, int32(addSteps))
 	if err != nil {
 		logger.Warn().Err(err).Msg("unable to update step number: update")
+		return
 	}
+	statusChanged(ctx, id, kafka.ReservationStatusEventType)
 }
 
 func nilUnlessTimeout(ctx context.Context) error {
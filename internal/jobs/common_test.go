@@ -2,9 +2,13 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/stretchr/testify/require"
 )
 
@@ -52,3 +56,16 @@ func TestWaitAndRetryErrSecond(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 2, calls)
 }
+
+func TestUserCapacityError(t *testing.T) {
+	launchErr := fmt.Errorf("cannot run instances: %w", clients.ErrCapacityUnavailable)
+
+	err := userCapacityError(launchErr, true)
+	require.ErrorIs(t, err, ErrSpotCapacityUnavailable)
+	require.True(t, strings.HasPrefix(err.Error(), ErrSpotCapacityUnavailable.Error()))
+
+	require.ErrorIs(t, userCapacityError(launchErr, false), ErrLaunchCapacityUnavailable)
+
+	otherErr := errors.New("other")
+	require.Equal(t, otherErr, userCapacityError(otherErr, true))
+}
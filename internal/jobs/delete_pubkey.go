@@ -0,0 +1,236 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var (
+	ErrPubkeyResourcesNotDeleted = errors.New("some pubkey resources were not deleted")
+	ErrPubkeyDeleteNotSupported  = errors.New("delete not implemented for this provider")
+)
+
+type DeletePubkeyTaskArgs struct {
+	// Pubkey to delete together with all its resources
+	PubkeyID int64
+}
+
+type DeletePubkeyResourcesTaskArgs struct {
+	// Pubkey the resources belonged to
+	PubkeyID int64
+
+	// Resources already removed from the database which must be deleted from the clouds
+	Resources []*models.PubkeyResource
+}
+
+// HandleDeletePubkey deletes the pubkey from all clouds, errors are returned so the worker
+// retries the resources which were not deleted.
+func HandleDeletePubkey(ctx context.Context, job *worker.Job) error {
+	logger := zerolog.Ctx(ctx)
+	if job == nil {
+		logger.Error().Msg("No job for DeletePubkeyJob")
+		return ErrNoJob
+	}
+
+	args, ok := job.Args.(DeletePubkeyTaskArgs)
+	if !ok {
+		err := fmt.Errorf("%w: job %s, pubkey: %#v", ErrTypeAssertion, job.ID, job.Args)
+		logger.Error().Err(err).Msg("Type assertion error for job")
+		return err
+	}
+
+	logger = ptr.To(logger.With().Int64("pubkey_id", args.PubkeyID).Logger())
+	ctx = logger.WithContext(ctx)
+	logger.Info().Msg("Started delete pubkey job")
+
+	ctx, span := telemetry.StartSpan(ctx, "DeletePubkeyJob")
+	defer span.End()
+
+	err := DoDeletePubkey(ctx, &args)
+	if err != nil {
+		span.SetStatus(codes.Error, "delete pubkey failed")
+		logger.Error().Err(err).Msg("Delete pubkey job failed")
+		return err
+	}
+
+	logger.Info().Msg("Delete pubkey job finished")
+	return nil
+}
+
+// HandleDeletePubkeyResources deletes outdated pubkey resources from clouds, errors are
+// returned so the worker retries the deletion.
+func HandleDeletePubkeyResources(ctx context.Context, job *worker.Job) error {
+	logger := zerolog.Ctx(ctx)
+	if job == nil {
+		logger.Error().Msg("No job for DeletePubkeyResourcesJob")
+		return ErrNoJob
+	}
+
+	args, ok := job.Args.(DeletePubkeyResourcesTaskArgs)
+	if !ok {
+		err := fmt.Errorf("%w: job %s, pubkey: %#v", ErrTypeAssertion, job.ID, job.Args)
+		logger.Error().Err(err).Msg("Type assertion error for job")
+		return err
+	}
+
+	logger = ptr.To(logger.With().Int64("pubkey_id", args.PubkeyID).Logger())
+	ctx = logger.WithContext(ctx)
+	logger.Info().Msgf("Started delete %d pubkey resources job", len(args.Resources))
+
+	ctx, span := telemetry.StartSpan(ctx, "DeletePubkeyResourcesJob")
+	defer span.End()
+
+	err := DoDeletePubkeyResources(ctx, &args)
+	if err != nil {
+		span.SetStatus(codes.Error, "delete pubkey resources failed")
+		logger.Error().Err(err).Msg("Delete pubkey resources job failed")
+		return err
+	}
+
+	logger.Info().Msg("Delete pubkey resources job finished")
+	return nil
+}
+
+// DoDeletePubkey deletes every resource of the pubkey from its cloud and then the pubkey itself.
+// Resources which were deleted are removed from the database immediately, when some of them
+// fail, the pubkey is kept and the error lists all failed resources.
+func DoDeletePubkey(ctx context.Context, args *DeletePubkeyTaskArgs) error {
+	logger := zerolog.Ctx(ctx)
+	ctx, span := telemetry.StartSpan(ctx, "DeletePubkeyStep")
+	defer span.End()
+
+	pkDao := dao.GetPubkeyDao(ctx)
+	pubkey, err := pkDao.GetById(ctx, args.PubkeyID)
+	if errors.Is(err, dao.ErrNoRows) {
+		logger.Warn().Msg("Pubkey was already deleted")
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot get pubkey: %w", err)
+	}
+
+	resources, err := pkDao.UnscopedListResourcesByPubkeyId(ctx, pubkey.ID)
+	if err != nil {
+		return fmt.Errorf("cannot list pubkey resources: %w", err)
+	}
+
+	sourcesClient, err := clients.GetSourcesClient(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get sources client: %w", err)
+	}
+
+	var errs []error
+	for _, res := range resources {
+		err = deletePubkeyResource(ctx, sourcesClient, res)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resource %d (%s, source %s): %w", res.ID, res.Provider, res.SourceID, err))
+			continue
+		}
+
+		err = pkDao.UnscopedDeleteResource(ctx, res.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resource %d: %w", res.ID, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		span.SetStatus(codes.Error, "pubkey resources not deleted")
+		return fmt.Errorf("%w: %w", ErrPubkeyResourcesNotDeleted, errors.Join(errs...))
+	}
+
+	err = pkDao.Delete(ctx, pubkey.ID)
+	if err != nil {
+		return fmt.Errorf("cannot delete pubkey: %w", err)
+	}
+
+	return nil
+}
+
+// DoDeletePubkeyResources deletes resources of a pubkey which was changed from clouds. Resources
+// are not in the database anymore, the error lists all resources which failed.
+func DoDeletePubkeyResources(ctx context.Context, args *DeletePubkeyResourcesTaskArgs) error {
+	ctx, span := telemetry.StartSpan(ctx, "DeletePubkeyResourcesStep")
+	defer span.End()
+
+	sourcesClient, err := clients.GetSourcesClient(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get sources client: %w", err)
+	}
+
+	var errs []error
+	for _, res := range args.Resources {
+		err = deletePubkeyResource(ctx, sourcesClient, res)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resource %d (%s, source %s): %w", res.ID, res.Provider, res.SourceID, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		span.SetStatus(codes.Error, "pubkey resources not deleted")
+		return fmt.Errorf("%w: %w", ErrPubkeyResourcesNotDeleted, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// deletePubkeyResource deletes a single uploaded pubkey from the cloud. Resources without
+// handle or with source which no longer exists are skipped.
+func deletePubkeyResource(ctx context.Context, sourcesClient clients.Sources, res *models.PubkeyResource) error {
+	logger := zerolog.Ctx(ctx)
+	if res.Handle == "" {
+		logger.Warn().Msgf("Skipping pubkey resource %d with empty handle", res.ID)
+		return nil
+	}
+
+	authentication, err := sourcesClient.GetAuthentication(ctx, res.SourceID)
+	if errors.Is(err, http.ErrAuthenticationForSourcesNotFound) {
+		logger.Warn().Msgf("Skipping source %s authorization which is no longer available", res.SourceID)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot get authentication: %w", err)
+	}
+
+	logger.Info().Msgf("Deleting pubkey resource ID %v with handle %s", res.ID, res.Handle)
+	switch res.Provider {
+	case models.ProviderTypeAWS:
+		ec2Client, err := clients.GetEC2Client(ctx, authentication, res.Region)
+		if err != nil {
+			return fmt.Errorf("cannot create new ec2 client from config: %w", err)
+		}
+		if err = ec2Client.DeleteSSHKey(ctx, res.Handle); err != nil {
+			return fmt.Errorf("unable to delete AWS public key: %w", err)
+		}
+	case models.ProviderTypeAzure:
+		azureClient, err := clients.GetAzureClient(ctx, authentication)
+		if err != nil {
+			return fmt.Errorf("failed to instantiate Azure client: %w", err)
+		}
+		if err = azureClient.DeleteSSHKey(ctx, res.Handle); err != nil {
+			return fmt.Errorf("unable to delete Azure public key: %w", err)
+		}
+	case models.ProviderTypeGCP:
+		gcpClient, err := clients.GetGCPClient(ctx, authentication)
+		if err != nil {
+			return fmt.Errorf("cannot create new GCP client: %w", err)
+		}
+		if err = gcpClient.DeleteSSHKey(ctx, res.Handle); err != nil {
+			return fmt.Errorf("unable to delete GCP public key: %w", err)
+		}
+	case models.ProviderTypeNoop, models.ProviderTypeUnknown:
+		return ErrPubkeyDeleteNotSupported
+	default:
+		return ErrPubkeyDeleteNotSupported
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package jobs_test
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoDeletePubkey(t *testing.T) {
+	ctx := prepareGCPContext(t)
+	ctx = clientStubs.WithEC2Client(ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	pkDao := dao.GetPubkeyDao(ctx)
+
+	awsSource, err := clientStubs.AddAuth(ctx, clients.NewAuthentication("arn:aws:iam::230214684733:role/Test", models.ProviderTypeAWS))
+	require.NoError(t, err, "failed to add stubbed source")
+
+	pk := factories.NewPubkeyRSA()
+	err = daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	addResource := func(t *testing.T, provider models.ProviderType, sourceId string) {
+		t.Helper()
+		err := pkDao.UnscopedCreateResource(ctx, &models.PubkeyResource{
+			PubkeyID: pk.ID,
+			Provider: provider,
+			SourceID: sourceId,
+			Handle:   "irrelevant",
+		})
+		require.NoError(t, err, "failed to add stubbed resource")
+	}
+	args := &jobs.DeletePubkeyTaskArgs{PubkeyID: pk.ID}
+
+	t.Run("reports failed resources", func(t *testing.T) {
+		addResource(t, models.ProviderTypeAWS, awsSource.ID)
+		addResource(t, models.ProviderTypeNoop, awsSource.ID)
+
+		err := jobs.DoDeletePubkey(ctx, args)
+		require.ErrorIs(t, err, jobs.ErrPubkeyResourcesNotDeleted)
+		require.ErrorIs(t, err, jobs.ErrPubkeyDeleteNotSupported)
+
+		resources, err := pkDao.UnscopedListResourcesByPubkeyId(ctx, pk.ID)
+		require.NoError(t, err, "failed to list resources")
+		require.Len(t, resources, 1, "Deleted resource must be removed")
+		assert.Equal(t, models.ProviderTypeNoop, resources[0].Provider)
+		assert.Equal(t, 1, daoStubs.PubkeyStubCount(ctx), "Pubkey must be kept")
+
+		err = pkDao.UnscopedDeleteResource(ctx, resources[0].ID)
+		require.NoError(t, err, "failed to delete stubbed resource")
+	})
+
+	t.Run("deletes pubkey", func(t *testing.T) {
+		addResource(t, models.ProviderTypeAWS, awsSource.ID)
+
+		err := jobs.DoDeletePubkey(ctx, args)
+		require.NoError(t, err, "delete pubkey failed to run")
+		assert.Equal(t, 0, daoStubs.PubkeyStubCount(ctx))
+
+		resources, err := pkDao.UnscopedListResourcesByPubkeyId(ctx, pk.ID)
+		require.NoError(t, err, "failed to list resources")
+		assert.Empty(t, resources)
+	})
+
+	t.Run("already deleted", func(t *testing.T) {
+		err := jobs.DoDeletePubkey(ctx, args)
+		require.NoError(t, err, "delete of missing pubkey must succeed")
+	})
+}
+
+func TestDoDeletePubkeyResources(t *testing.T) {
+	ctx := prepareGCPContext(t)
+	ctx = clientStubs.WithEC2Client(ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+
+	awsSource, err := clientStubs.AddAuth(ctx, clients.NewAuthentication("arn:aws:iam::230214684733:role/Test", models.ProviderTypeAWS))
+	require.NoError(t, err, "failed to add stubbed source")
+
+	args := &jobs.DeletePubkeyResourcesTaskArgs{
+		PubkeyID: 1,
+		Resources: []*models.PubkeyResource{
+			{ID: 1, PubkeyID: 1, Provider: models.ProviderTypeAWS, SourceID: awsSource.ID, Handle: "irrelevant"},
+			{ID: 2, PubkeyID: 1, Provider: models.ProviderTypeAWS, SourceID: awsSource.ID},
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		err := jobs.DoDeletePubkeyResources(ctx, args)
+		require.NoError(t, err, "delete pubkey resources failed to run")
+	})
+
+	t.Run("reports failed resources", func(t *testing.T) {
+		failingArgs := &jobs.DeletePubkeyResourcesTaskArgs{
+			PubkeyID:  1,
+			Resources: append(args.Resources, &models.PubkeyResource{ID: 3, PubkeyID: 1, Provider: models.ProviderTypeNoop, SourceID: awsSource.ID, Handle: "irrelevant"}),
+		}
+		err := jobs.DoDeletePubkeyResources(ctx, failingArgs)
+		require.ErrorIs(t, err, jobs.ErrPubkeyResourcesNotDeleted)
+		assert.Contains(t, err.Error(), "resource 3")
+	})
+}
+
+func TestDoDeletePubkeyResourcesAzureGCP(t *testing.T) {
+	ctx := prepareGCPContext(t)
+	ctx = clientStubs.WithAzureClient(ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+
+	azureSource, err := clientStubs.AddAuth(ctx, clients.NewAuthentication("4b9d213f-712f-4d17-a3a9-ea5fbe8b6e0a", models.ProviderTypeAzure))
+	require.NoError(t, err, "failed to add stubbed source")
+	gcpSource, err := clientStubs.AddAuth(ctx, clients.NewAuthentication("example-project-id", models.ProviderTypeGCP))
+	require.NoError(t, err, "failed to add stubbed source")
+
+	azureKey := "/subscriptions/4b9d213f-712f-4d17-a3a9-ea5fbe8b6e0a/resourceGroups/redhat-deployed/providers/Microsoft.Compute/sshPublicKeys/key"
+	gcpKey := factories.NewPubkeyRSA().Body
+	args := &jobs.DeletePubkeyResourcesTaskArgs{
+		PubkeyID: 1,
+		Resources: []*models.PubkeyResource{
+			{ID: 1, PubkeyID: 1, Provider: models.ProviderTypeAzure, SourceID: azureSource.ID, Handle: azureKey},
+			{ID: 2, PubkeyID: 1, Provider: models.ProviderTypeGCP, SourceID: gcpSource.ID, Handle: gcpKey},
+		},
+	}
+
+	err = jobs.DoDeletePubkeyResources(ctx, args)
+	require.NoError(t, err, "delete pubkey resources failed to run")
+	assert.Equal(t, []string{azureKey}, clientStubs.DeletedStubAzureSSHKeys(ctx))
+	assert.Equal(t, []string{gcpKey}, clientStubs.DeletedStubGCPSSHKeys(ctx))
+}
@@ -0,0 +1,146 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var ErrExpiryNotSupported = errors.New("expiry is not supported for the provider")
+
+type ExpireReservationTaskArgs struct {
+	// Associated reservation
+	ReservationID int64
+}
+
+// HandleExpireReservation terminates all instances of an expired reservation, errors are
+// returned so the worker retries the teardown.
+func HandleExpireReservation(ctx context.Context, job *worker.Job) error {
+	logger := zerolog.Ctx(ctx)
+	if job == nil {
+		logger.Error().Msg("No job for ExpireReservationJob")
+		return ErrNoJob
+	}
+
+	args, ok := job.Args.(ExpireReservationTaskArgs)
+	if !ok {
+		err := fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
+		logger.Error().Err(err).Msg("Type assertion error for job")
+		return err
+	}
+
+	// context and logger
+	ctx, logger = reservationContextLogger(ctx, args.ReservationID)
+	logger.Info().Msg("Started expire reservation job")
+
+	ctx, span := telemetry.StartSpan(ctx, "ExpireReservationJob")
+	defer span.End()
+
+	err := DoExpireReservation(ctx, &args)
+	if err != nil {
+		span.SetStatus(codes.Error, "expire reservation failed")
+		logger.Error().Err(err).Msg("Expire reservation job failed")
+		return err
+	}
+
+	logger.Info().Msg("Expire reservation job finished")
+	return nil
+}
+
+// DoExpireReservation terminates instances of the reservation which were not terminated yet.
+// Each termination is recorded as an instance action, all failures are returned.
+func DoExpireReservation(ctx context.Context, args *ExpireReservationTaskArgs) error {
+	ctx, span := telemetry.StartSpan(ctx, "ExpireReservationStep")
+	defer span.End()
+
+	rDao := dao.GetReservationDao(ctx)
+	reservation, err := rDao.GetById(ctx, args.ReservationID)
+	if err != nil {
+		return fmt.Errorf("cannot get reservation: %w", err)
+	}
+
+	var sourceId, location string
+	var do func(context.Context, *InstanceActionTaskArgs) error
+	switch reservation.Provider {
+	case models.ProviderTypeAWS:
+		reservationAws, err := rDao.GetAWSById(ctx, args.ReservationID)
+		if err != nil {
+			return fmt.Errorf("cannot get AWS reservation: %w", err)
+		}
+		sourceId = reservationAws.SourceID
+		location = reservationAws.Detail.Region
+		do = DoInstanceActionAWS
+	case models.ProviderTypeAzure:
+		reservationAzure, err := rDao.GetAzureById(ctx, args.ReservationID)
+		if err != nil {
+			return fmt.Errorf("cannot get Azure reservation: %w", err)
+		}
+		sourceId = reservationAzure.SourceID
+		do = DoInstanceActionAzure
+	case models.ProviderTypeGCP:
+		reservationGCP, err := rDao.GetGCPById(ctx, args.ReservationID)
+		if err != nil {
+			return fmt.Errorf("cannot get GCP reservation: %w", err)
+		}
+		sourceId = reservationGCP.SourceID
+		location = reservationGCP.Detail.Zone
+		do = DoInstanceActionGCP
+	case models.ProviderTypeNoop, models.ProviderTypeUnknown:
+		return fmt.Errorf("%w: %s", ErrExpiryNotSupported, reservation.Provider)
+	default:
+		return fmt.Errorf("%w: %s", ErrExpiryNotSupported, reservation.Provider)
+	}
+
+	sourcesClient, err := clients.GetSourcesClient(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get sources client: %w", err)
+	}
+
+	authentication, err := sourcesClient.GetAuthentication(ctx, sourceId)
+	if err != nil {
+		return fmt.Errorf("cannot get authentication: %w", err)
+	}
+
+	if typeErr := authentication.MustBe(reservation.Provider); typeErr != nil {
+		return fmt.Errorf("unexpected authentication: %w", typeErr)
+	}
+
+	instances, err := rDao.ListInstances(ctx, args.ReservationID)
+	if err != nil {
+		return fmt.Errorf("cannot list reservation instances: %w", err)
+	}
+
+	var errs []error
+	for _, instance := range instances {
+		if instance.LastAction == models.InstanceActionTerminate && instance.LastActionStatus == models.InstanceActionStatusSuccess {
+			continue
+		}
+
+		actionArgs := &InstanceActionTaskArgs{
+			ReservationID:  args.ReservationID,
+			InstanceID:     instance.InstanceID,
+			Action:         models.InstanceActionTerminate,
+			Location:       location,
+			Authentication: authentication,
+		}
+		actionErr := do(ctx, actionArgs)
+		finishInstanceAction(ctx, actionArgs, actionErr)
+		if actionErr != nil {
+			errs = append(errs, actionErr)
+		}
+	}
+
+	if len(errs) > 0 {
+		span.SetStatus(codes.Error, "instance termination failed")
+		return errors.Join(errs...)
+	}
+	return nil
+}
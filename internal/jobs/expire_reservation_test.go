@@ -0,0 +1,106 @@
+package jobs_test
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoExpireReservation(t *testing.T) {
+	ctx := prepareGCPContext(t)
+	ctx = clientStubs.WithSourcesClient(ctx)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	authentication := clients.NewAuthentication("example-project-id", models.ProviderTypeGCP)
+	source, err := clientStubs.AddAuth(ctx, authentication)
+	require.NoError(t, err, "failed to add stubbed source")
+
+	res := prepareGCPReservation(t, ctx, pk)
+	res.SourceID = source.ID
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateGCP(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	launchArgs := &jobs.LaunchInstanceGCPTaskArgs{
+		ImageName:     "composer-api-3b6225fc-d55a-4dcc-9d0a-b478ae152a",
+		Zone:          res.Detail.Zone,
+		PubkeyID:      pk.ID,
+		ReservationID: res.ID,
+		ProjectID:     authentication,
+		Detail:        res.Detail,
+	}
+	err = jobs.DoLaunchInstanceGCP(ctx, launchArgs)
+	require.NoError(t, err, "launch instances failed to run")
+	require.Equal(t, 1, clientStubs.CountStubInstancesGCP(ctx))
+
+	args := &jobs.ExpireReservationTaskArgs{ReservationID: res.ID}
+
+	t.Run("terminates instances", func(t *testing.T) {
+		err = jobs.DoExpireReservation(ctx, args)
+		require.NoError(t, err, "expire reservation failed to run")
+		assert.Equal(t, 0, clientStubs.CountStubInstancesGCP(ctx))
+
+		instances, err := rDao.ListInstances(ctx, res.ID)
+		require.NoError(t, err, "failed to fetch instances")
+		require.Len(t, instances, 1)
+		assert.Equal(t, models.InstanceActionTerminate, instances[0].LastAction)
+		assert.Equal(t, models.InstanceActionStatusSuccess, instances[0].LastActionStatus)
+	})
+
+	t.Run("skips terminated instances", func(t *testing.T) {
+		err = jobs.DoExpireReservation(ctx, args)
+		require.NoError(t, err, "expire reservation must be idempotent")
+	})
+}
+
+func TestDoExpireReservationAzure(t *testing.T) {
+	ctx := prepareAzureContext(t)
+	ctx = clientStubs.WithSourcesClient(ctx)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	authentication := clients.NewAuthentication("subUUID", models.ProviderTypeAzure)
+	source, err := clientStubs.AddAuth(ctx, authentication)
+	require.NoError(t, err, "failed to add stubbed source")
+
+	res := prepareAzureReservation(t, ctx, pk)
+	res.SourceID = source.ID
+	res.Detail.Amount = 2
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAzure(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	launchArgs := &jobs.LaunchInstanceAzureTaskArgs{
+		AzureImageID:  "/subscriptions/subUUID/rgName/images/uuid2",
+		Location:      res.Detail.Location,
+		PubkeyID:      pk.ID,
+		ReservationID: res.ID,
+		SourceID:      source.ID,
+		Subscription:  authentication,
+	}
+	err = jobs.DoLaunchInstanceAzure(ctx, launchArgs)
+	require.NoError(t, err, "launch instances failed to run")
+	require.Equal(t, 2, clientStubs.CountStubAzureVMs(ctx))
+
+	err = jobs.DoExpireReservation(ctx, &jobs.ExpireReservationTaskArgs{ReservationID: res.ID})
+	require.NoError(t, err, "expire reservation failed to run")
+	assert.Equal(t, 0, clientStubs.CountStubAzureVMs(ctx))
+	assert.Len(t, clientStubs.DeletedStubAzureResources(ctx), 8, "VM, disk, NIC and public IP of both instances should be deleted")
+
+	resources, err := rDao.UnscopedListResources(ctx, res.ID)
+	require.NoError(t, err, "failed to list resources")
+	assert.Empty(t, resources, "all resources should be marked as deleted")
+}
@@ -0,0 +1,221 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var ErrUnknownInstanceAction = errors.New("unknown instance action")
+
+type InstanceActionTaskArgs struct {
+	// Associated reservation
+	ReservationID int64
+
+	// Instance ID as stored in the reservation instances
+	InstanceID string
+
+	// Action to perform on the instance
+	Action models.InstanceAction
+
+	// AWS region or GCP zone of the instance, not used for Azure
+	Location string
+
+	// Authentication fetched from Sources for the reservation source
+	Authentication *clients.Authentication
+}
+
+func HandleInstanceActionAWS(ctx context.Context, job *worker.Job) error {
+	return handleInstanceAction(ctx, job, "InstanceActionAWSJob", DoInstanceActionAWS)
+}
+
+func HandleInstanceActionAzure(ctx context.Context, job *worker.Job) error {
+	return handleInstanceAction(ctx, job, "InstanceActionAzureJob", DoInstanceActionAzure)
+}
+
+func HandleInstanceActionGCP(ctx context.Context, job *worker.Job) error {
+	return handleInstanceAction(ctx, job, "InstanceActionGCPJob", DoInstanceActionGCP)
+}
+
+// handleInstanceAction records the action result, errors are returned so the worker retries the action.
+func handleInstanceAction(ctx context.Context, job *worker.Job, spanName string, do func(context.Context, *InstanceActionTaskArgs) error) (jobErr error) {
+	logger := zerolog.Ctx(ctx)
+	if job == nil {
+		logger.Error().Msgf("No job for %s", spanName)
+		return ErrNoJob
+	}
+
+	args, ok := job.Args.(InstanceActionTaskArgs)
+	if !ok {
+		err := fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
+		logger.Error().Err(err).Msg("Type assertion error for job")
+		return err
+	}
+
+	// context and logger
+	ctx, logger = reservationContextLogger(ctx, args.ReservationID)
+	logger = ptr.To(logger.With().Str("instance_id", args.InstanceID).Str("instance_action", args.Action.String()).Logger())
+	ctx = logger.WithContext(ctx)
+	logger.Info().Msgf("Started instance action %s job", args.Action)
+
+	// ensure panic records the action as failed
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := fmt.Errorf("%w: %s", ErrPanicInJob, r)
+			finishInstanceAction(ctx, &args, panicErr)
+			jobErr = panicErr
+		}
+	}()
+
+	ctx, span := telemetry.StartSpan(ctx, spanName)
+	defer span.End()
+
+	jobErr = do(ctx, &args)
+	finishInstanceAction(ctx, &args, jobErr)
+	return jobErr
+}
+
+// DoInstanceActionAWS performs the lifecycle action on an EC2 instance.
+func DoInstanceActionAWS(ctx context.Context, args *InstanceActionTaskArgs) error {
+	ctx, span := telemetry.StartSpan(ctx, "InstanceActionAWSStep")
+	defer span.End()
+
+	ec2Client, err := clients.GetEC2Client(ctx, args.Authentication, args.Location)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot create new ec2 client from config")
+		return fmt.Errorf("cannot create new ec2 client from config: %w", err)
+	}
+
+	ids := []string{args.InstanceID}
+	switch args.Action {
+	case models.InstanceActionStart:
+		err = ec2Client.StartInstances(ctx, ids)
+	case models.InstanceActionStop:
+		err = ec2Client.StopInstances(ctx, ids)
+	case models.InstanceActionReboot:
+		err = ec2Client.RebootInstances(ctx, ids)
+	case models.InstanceActionTerminate:
+		err = terminateInstanceResources(ctx, args.ReservationID, args.InstanceID, func(ctx context.Context, resource *models.ReservationResource) error {
+			return ec2Client.TerminateInstances(ctx, []string{resource.ResourceID})
+		}, func(ctx context.Context) error {
+			return ec2Client.TerminateInstances(ctx, ids)
+		})
+	default:
+		err = ErrUnknownInstanceAction
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, "instance action failed")
+		return fmt.Errorf("cannot %s AWS instance: %w", args.Action, err)
+	}
+
+	return nil
+}
+
+// DoInstanceActionAzure performs the lifecycle action on an Azure virtual machine.
+func DoInstanceActionAzure(ctx context.Context, args *InstanceActionTaskArgs) error {
+	ctx, span := telemetry.StartSpan(ctx, "InstanceActionAzureStep")
+	defer span.End()
+
+	azureClient, err := clients.GetAzureClient(ctx, args.Authentication)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot instantiate Azure client")
+		return fmt.Errorf("failed to instantiate Azure client: %w", err)
+	}
+
+	ids := []string{args.InstanceID}
+	switch args.Action {
+	case models.InstanceActionStart:
+		err = azureClient.StartInstances(ctx, ids)
+	case models.InstanceActionStop:
+		err = azureClient.StopInstances(ctx, ids)
+	case models.InstanceActionReboot:
+		err = azureClient.RebootInstances(ctx, ids)
+	case models.InstanceActionTerminate:
+		err = terminateInstanceResources(ctx, args.ReservationID, args.InstanceID, func(ctx context.Context, resource *models.ReservationResource) error {
+			return azureClient.DeleteResource(ctx, resource.ResourceID)
+		}, func(ctx context.Context) error {
+			return azureClient.TerminateInstances(ctx, ids)
+		})
+	default:
+		err = ErrUnknownInstanceAction
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, "instance action failed")
+		return fmt.Errorf("cannot %s Azure instance: %w", args.Action, err)
+	}
+
+	return nil
+}
+
+// DoInstanceActionGCP performs the lifecycle action on a GCP instance.
+func DoInstanceActionGCP(ctx context.Context, args *InstanceActionTaskArgs) error {
+	ctx, span := telemetry.StartSpan(ctx, "InstanceActionGCPStep")
+	defer span.End()
+
+	gcpClient, err := clients.GetGCPClient(ctx, args.Authentication)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot create new GCP client")
+		return fmt.Errorf("cannot create new GCP client: %w", err)
+	}
+
+	ids := []string{args.InstanceID}
+	switch args.Action {
+	case models.InstanceActionStart:
+		err = gcpClient.StartInstances(ctx, args.Location, ids)
+	case models.InstanceActionStop:
+		err = gcpClient.StopInstances(ctx, args.Location, ids)
+	case models.InstanceActionReboot:
+		err = gcpClient.RebootInstances(ctx, args.Location, ids)
+	case models.InstanceActionTerminate:
+		err = terminateInstanceResources(ctx, args.ReservationID, args.InstanceID, func(ctx context.Context, resource *models.ReservationResource) error {
+			return gcpClient.TerminateInstances(ctx, resource.Region, []string{resource.ResourceID})
+		}, func(ctx context.Context) error {
+			return gcpClient.TerminateInstances(ctx, args.Location, ids)
+		})
+	default:
+		err = ErrUnknownInstanceAction
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, "instance action failed")
+		return fmt.Errorf("cannot %s GCP instance: %w", args.Action, err)
+	}
+
+	return nil
+}
+
+// finishInstanceAction records the result of the action against the reservation instance.
+func finishInstanceAction(ctx context.Context, args *InstanceActionTaskArgs, jobErr error) {
+	logger := zerolog.Ctx(ctx)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// the original context is expired and unusable at this point
+		ctx = copyContext(ctx)
+	}
+
+	instance := &models.ReservationInstance{
+		ReservationID:    args.ReservationID,
+		InstanceID:       args.InstanceID,
+		LastAction:       args.Action,
+		LastActionStatus: models.InstanceActionStatusSuccess,
+	}
+	if jobErr != nil {
+		logger.Error().Err(jobErr).Msgf("Instance action %s failed", args.Action)
+		instance.LastActionStatus = models.InstanceActionStatusFailure
+		instance.LastActionError = jobErr.Error()
+	} else {
+		logger.Info().Msgf("Instance action %s finished", args.Action)
+	}
+
+	err := dao.GetReservationDao(ctx).UpdateInstanceAction(ctx, instance)
+	if err != nil {
+		logger.Warn().Err(err).Msg("unable to record instance action")
+	}
+}
@@ -0,0 +1,193 @@
+package jobs_test
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoInstanceActionGCP(t *testing.T) {
+	ctx := prepareGCPContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareGCPReservation(t, ctx, pk)
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateGCP(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	launchArgs := &jobs.LaunchInstanceGCPTaskArgs{
+		ImageName:     "composer-api-3b6225fc-d55a-4dcc-9d0a-b478ae152a",
+		Zone:          "europe-west8-c",
+		PubkeyID:      pk.ID,
+		ReservationID: res.ID,
+		ProjectID:     clients.NewAuthentication("example-project-id", models.ProviderTypeGCP),
+		Detail:        res.Detail,
+	}
+	err = jobs.DoLaunchInstanceGCP(ctx, launchArgs)
+	require.NoError(t, err, "launch instances failed to run")
+
+	instances, err := rDao.ListInstances(ctx, res.ID)
+	require.NoError(t, err, "failed to fetch created instances")
+	require.Len(t, instances, 1)
+
+	args := &jobs.InstanceActionTaskArgs{
+		ReservationID:  res.ID,
+		InstanceID:     instances[0].InstanceID,
+		Location:       res.Detail.Zone,
+		Authentication: launchArgs.ProjectID,
+	}
+
+	t.Run("stop", func(t *testing.T) {
+		args.Action = models.InstanceActionStop
+		err = jobs.DoInstanceActionGCP(ctx, args)
+		require.NoError(t, err, "stop instance failed to run")
+		assert.Equal(t, 1, clientStubs.CountStubInstancesGCP(ctx))
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		args.Action = models.InstanceActionUnknown
+		err = jobs.DoInstanceActionGCP(ctx, args)
+		require.ErrorIs(t, err, jobs.ErrUnknownInstanceAction)
+	})
+
+	t.Run("terminate", func(t *testing.T) {
+		args.Action = models.InstanceActionTerminate
+		err = jobs.DoInstanceActionGCP(ctx, args)
+		require.NoError(t, err, "terminate instance failed to run")
+		assert.Equal(t, 0, clientStubs.CountStubInstancesGCP(ctx))
+
+		resources, err := rDao.UnscopedListResources(ctx, res.ID)
+		require.NoError(t, err, "failed to list resources")
+		assert.Empty(t, resources, "the instance should be marked as deleted")
+	})
+
+	t.Run("missing instance", func(t *testing.T) {
+		args.Action = models.InstanceActionStart
+		err = jobs.DoInstanceActionGCP(ctx, args)
+		require.ErrorIs(t, err, clientStubs.ErrMissingInstanceID)
+	})
+}
+
+func TestDoInstanceActionAWS(t *testing.T) {
+	ctx := prepareEC2Context(t)
+
+	args := &jobs.InstanceActionTaskArgs{
+		ReservationID:  1,
+		InstanceID:     "i-0a4caa2cf5b097ce1",
+		Location:       "us-east-1",
+		Authentication: clients.NewAuthentication("arn:aws:123123123123", models.ProviderTypeAWS),
+	}
+
+	actions := []models.InstanceAction{
+		models.InstanceActionStop,
+		models.InstanceActionStart,
+		models.InstanceActionReboot,
+		models.InstanceActionTerminate,
+	}
+	var expected []clientStubs.InstanceActionCall
+	for _, action := range actions {
+		args.Action = action
+		err := jobs.DoInstanceActionAWS(ctx, args)
+		require.NoError(t, err, "%s instance failed to run", action)
+		expected = append(expected, clientStubs.InstanceActionCall{Action: action, InstanceIDs: []string{args.InstanceID}})
+	}
+	assert.Equal(t, expected, clientStubs.StubEC2InstanceActions(ctx))
+
+	t.Run("unknown action", func(t *testing.T) {
+		args.Action = models.InstanceActionUnknown
+		err := jobs.DoInstanceActionAWS(ctx, args)
+		require.ErrorIs(t, err, jobs.ErrUnknownInstanceAction)
+		assert.Len(t, clientStubs.StubEC2InstanceActions(ctx), len(actions), "Expected no call to the client")
+	})
+}
+
+func TestDoInstanceActionAzure(t *testing.T) {
+	ctx := prepareAzureContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareAzureReservation(t, ctx, pk)
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAzure(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	launchArgs := &jobs.LaunchInstanceAzureTaskArgs{
+		AzureImageID:  "/subscriptions/subUUID/rgName/images/uuid2",
+		Location:      "useast",
+		PubkeyID:      pk.ID,
+		ReservationID: res.ID,
+		SourceID:      "2",
+		Subscription:  clients.NewAuthentication("subUUID", models.ProviderTypeAzure),
+	}
+	err = jobs.DoLaunchInstanceAzure(ctx, launchArgs)
+	require.NoError(t, err, "launch instances failed to run")
+
+	instances, err := rDao.ListInstances(ctx, res.ID)
+	require.NoError(t, err, "failed to fetch created instances")
+	require.Len(t, instances, 1)
+
+	args := &jobs.InstanceActionTaskArgs{
+		ReservationID:  res.ID,
+		InstanceID:     instances[0].InstanceID,
+		Authentication: launchArgs.Subscription,
+	}
+
+	t.Run("stop", func(t *testing.T) {
+		args.Action = models.InstanceActionStop
+		err = jobs.DoInstanceActionAzure(ctx, args)
+		require.NoError(t, err, "stop instance failed to run")
+		assert.Equal(t,
+			[]clientStubs.InstanceActionCall{{Action: models.InstanceActionStop, InstanceIDs: []string{args.InstanceID}}},
+			clientStubs.StubAzureInstanceActions(ctx))
+		assert.Equal(t, 1, clientStubs.CountStubAzureVMs(ctx))
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		args.Action = models.InstanceActionUnknown
+		err = jobs.DoInstanceActionAzure(ctx, args)
+		require.ErrorIs(t, err, jobs.ErrUnknownInstanceAction)
+		assert.Len(t, clientStubs.StubAzureInstanceActions(ctx), 1, "Expected no call to the client")
+	})
+
+	t.Run("terminate", func(t *testing.T) {
+		args.Action = models.InstanceActionTerminate
+		err = jobs.DoInstanceActionAzure(ctx, args)
+		require.NoError(t, err, "terminate instance failed to run")
+		assert.Len(t, clientStubs.StubAzureInstanceActions(ctx), 1, "Expected resources from the ledger to be deleted")
+		assert.Equal(t, 0, clientStubs.CountStubAzureVMs(ctx))
+
+		deleted := clientStubs.DeletedStubAzureResources(ctx)
+		require.Len(t, deleted, 4, "VM, disk, NIC and public IP should be deleted")
+		assert.Equal(t, args.InstanceID, deleted[0])
+		assert.Contains(t, deleted[1], "/disks/")
+		assert.Contains(t, deleted[2], "/networkInterfaces/")
+		assert.Contains(t, deleted[3], "/publicIPAddresses/")
+
+		resources, err := rDao.UnscopedListResources(ctx, res.ID)
+		require.NoError(t, err, "failed to list resources")
+		assert.Empty(t, resources, "all resources should be marked as deleted")
+	})
+
+	t.Run("terminate without ledger", func(t *testing.T) {
+		args.Action = models.InstanceActionTerminate
+		args.InstanceID = "/subscriptions/subUUID/resourceGroups/rgName/providers/Microsoft.Compute/virtualMachines/unrecorded"
+		err = jobs.DoInstanceActionAzure(ctx, args)
+		require.NoError(t, err, "terminate instance failed to run")
+		actions := clientStubs.StubAzureInstanceActions(ctx)
+		require.Len(t, actions, 2)
+		assert.Equal(t, clientStubs.InstanceActionCall{Action: models.InstanceActionTerminate, InstanceIDs: []string{args.InstanceID}}, actions[1])
+	})
+}
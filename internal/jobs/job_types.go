@@ -3,8 +3,14 @@ package jobs
 import "github.com/RHEnVision/provisioning-backend/pkg/worker"
 
 const (
-	TypeNoop                worker.JobType = "no_operation"
-	TypeLaunchInstanceAws   worker.JobType = "launch_instances_aws"
-	TypeLaunchInstanceAzure worker.JobType = "launch_instances_azure"
-	TypeLaunchInstanceGcp   worker.JobType = "launch_instances_gcp"
+	TypeNoop                  worker.JobType = "no_operation"
+	TypeLaunchInstanceAws     worker.JobType = "launch_instances_aws"
+	TypeLaunchInstanceAzure   worker.JobType = "launch_instances_azure"
+	TypeLaunchInstanceGcp     worker.JobType = "launch_instances_gcp"
+	TypeInstanceActionAws     worker.JobType = "instance_action_aws"
+	TypeInstanceActionAzure   worker.JobType = "instance_action_azure"
+	TypeInstanceActionGcp     worker.JobType = "instance_action_gcp"
+	TypeExpireReservation     worker.JobType = "expire_reservation"
+	TypeDeletePubkey          worker.JobType = "delete_pubkey"
+	TypeDeletePubkeyResources worker.JobType = "delete_pubkey_resources"
 )
@@ -41,21 +41,25 @@ type LaunchInstanceAWSTaskArgs struct {
 
 	// The ARN fetched from Sources which is linked to a specific source
 	ARN *clients.Authentication
+
+	// Optional custom cloud-config or script passed by the user, validated by the service
+	UserData []byte
 }
 
 // HandleLaunchInstanceAWS unmarshalls arguments and handles error
-func HandleLaunchInstanceAWS(ctx context.Context, job *worker.Job) {
+// Launch errors are stored in the reservation and the job is not retried since it is not idempotent.
+func HandleLaunchInstanceAWS(ctx context.Context, job *worker.Job) error {
 	logger := zerolog.Ctx(ctx)
 	if job == nil {
 		logger.Error().Msg("No job for HandleLaunchInstanceAWS")
-		return
+		return ErrNoJob
 	}
 
 	args, ok := job.Args.(LaunchInstanceAWSTaskArgs)
 	if !ok {
 		err := fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
 		logger.Error().Err(err).Msg("Type assertion error for job")
-		return
+		return err
 	}
 
 	// context and logger
@@ -72,22 +76,53 @@ func HandleLaunchInstanceAWS(ctx context.Context, job *worker.Job) {
 
 	jobErr := DoEnsurePubkeyOnAWS(ctx, &args)
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		return
+		failedAWS(ctx, &args, jobErr)
+		return nil
+	}
+
+	if cancelledAWS(ctx, &args) {
+		return nil
 	}
 
 	jobErr = DoLaunchInstanceAWS(ctx, &args)
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		return
+		failedAWS(ctx, &args, jobErr)
+		return nil
+	}
+
+	if cancelledAWS(ctx, &args) {
+		return nil
 	}
 
 	jobErr = FetchInstancesDescriptionAWS(ctx, &args)
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
+		failedAWS(ctx, &args, jobErr)
+	} else if cancelledAWS(ctx, &args) {
+		return nil
 	}
 
-	finishJob(ctx, args.ReservationID, jobErr)
+	if errors.Is(finishJob(ctx, args.ReservationID, jobErr), ErrReservationCancelled) {
+		// cancelled right before the reservation was finished
+		cancelledAWS(ctx, &args)
+	}
+	return nil
+}
+
+// cancelledAWS rolls back and finishes the reservation when it was cancelled
+func cancelledAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) bool {
+	if checkCancelled(ctx, args.ReservationID) == nil {
+		return false
+	}
+
+	deleted, rollbackErr := RollbackLaunchInstanceAWS(ctx, args)
+	finishCancelled(ctx, args.ReservationID, deleted, rollbackErr)
+	return true
+}
+
+// failedAWS rolls back resources created so far and finishes the reservation with error
+func failedAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs, jobErr error) {
+	deleted, rollbackErr := RollbackLaunchInstanceAWS(ctx, args)
+	finishWithError(ctx, args.ReservationID, rollbackError(jobErr, deleted, rollbackErr))
 }
 
 // DoEnsurePubkeyOnAWS is a job logic, when error is returned the job status is updated accordingly
@@ -171,6 +206,7 @@ func DoEnsurePubkeyOnAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) e
 		}
 
 		ec2Name = pubkey.Name
+		recordResource(ctx, args.ReservationID, models.ResourceTypeAWSKeyPair, pkr.Handle, args.Region)
 	} else if err != nil {
 		span.SetStatus(codes.Error, "import key error")
 		logger.Error().Err(err).Str("pubkey_fingerprint", fingerprint).Msg("Cannot fetch name of pubkey by its fingerprint")
@@ -225,6 +261,7 @@ func DoLaunchInstanceAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) e
 		Type:         models.ProviderTypeAWS,
 		PowerOff:     args.Detail.PowerOff,
 		InsightsTags: true,
+		Custom:       args.UserData,
 	}
 	userData, err := userdata.GenerateUserData(ctx, &userDataInput)
 	if err != nil {
@@ -244,13 +281,26 @@ func DoLaunchInstanceAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) e
 		AMI:              args.AMI,
 		KeyName:          reservation.Detail.PubkeyName,
 		UserData:         userData,
+		Tags:             args.Detail.Tags,
+		SubnetID:         args.Detail.SubnetID,
+		SecurityGroupIDs: args.Detail.SecurityGroupIDs,
+		NoPublicIP:       args.Detail.NoPublicIP,
+		RootVolumeGB:     args.Detail.RootVolumeGB,
+		VolumeType:       args.Detail.VolumeType,
+		DataDisks:        args.Detail.DataDisks,
+		Spot:             args.Detail.MarketType == models.MarketTypeSpot,
+		SpotMaxPrice:     args.Detail.SpotMaxPrice,
 	}
 
 	logger.Trace().Msg("Executing RunInstances")
 	instances, awsReservationId, err := ec2Client.RunInstances(ctx, req, args.Detail.Amount, args.Detail.Name, reservation)
 	if err != nil {
 		span.SetStatus(codes.Error, "cannot run instances")
-		return fmt.Errorf("cannot run instances: %w", err)
+		return userCapacityError(fmt.Errorf("cannot run instances: %w", err), req.Spot)
+	}
+
+	for _, instanceId := range instances {
+		recordResource(ctx, args.ReservationID, models.ResourceTypeAWSInstance, *instanceId, args.Region)
 	}
 
 	// For each instance that was created in AWS, add it as a DB record
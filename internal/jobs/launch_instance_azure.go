@@ -2,6 +2,7 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -52,20 +53,23 @@ type LaunchInstanceAzureTaskArgs struct {
 
 	// The Name is used as prefix for a final name, for uniqueness we add uuid suffix to each instance name
 	Name string
+
+	// Optional custom cloud-config or script passed by the user, validated by the service
+	UserData []byte
 }
 
-func HandleLaunchInstanceAzure(ctx context.Context, job *worker.Job) {
+func HandleLaunchInstanceAzure(ctx context.Context, job *worker.Job) error {
 	logger := zerolog.Ctx(ctx)
 	if job == nil {
 		logger.Error().Msg("No job for HandleLaunchInstanceAzure")
-		return
+		return ErrNoJob
 	}
 
 	args, ok := job.Args.(LaunchInstanceAzureTaskArgs)
 	if !ok {
 		err := fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
 		logger.Error().Err(err).Msg("Type assertion error for job")
-		return
+		return err
 	}
 
 	// context and logger
@@ -103,16 +107,45 @@ func HandleLaunchInstanceAzure(ctx context.Context, job *worker.Job) {
 	jobErr := DoEnsureAzureResourceGroup(ctx, &args)
 	if jobErr != nil {
 		finishWithError(ctx, args.ReservationID, jobErr)
-		return
+		return nil
+	}
+
+	if cancelledAzure(ctx, &args) {
+		return nil
 	}
 
 	jobErr = DoLaunchInstanceAzure(ctx, &args)
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		return
+		failedAzure(ctx, &args, jobErr)
+		return nil
 	}
 
-	finishJob(ctx, args.ReservationID, jobErr)
+	if cancelledAzure(ctx, &args) {
+		return nil
+	}
+
+	if errors.Is(finishJob(ctx, args.ReservationID, jobErr), ErrReservationCancelled) {
+		// cancelled right before the reservation was finished
+		cancelledAzure(ctx, &args)
+	}
+	return nil
+}
+
+// cancelledAzure rolls back and finishes the reservation when it was cancelled
+func cancelledAzure(ctx context.Context, args *LaunchInstanceAzureTaskArgs) bool {
+	if checkCancelled(ctx, args.ReservationID) == nil {
+		return false
+	}
+
+	deleted, rollbackErr := RollbackLaunchInstanceAzure(ctx, args)
+	finishCancelled(ctx, args.ReservationID, deleted, rollbackErr)
+	return true
+}
+
+// failedAzure rolls back resources created so far and finishes the reservation with error
+func failedAzure(ctx context.Context, args *LaunchInstanceAzureTaskArgs, jobErr error) {
+	deleted, rollbackErr := RollbackLaunchInstanceAzure(ctx, args)
+	finishWithError(ctx, args.ReservationID, rollbackError(jobErr, deleted, rollbackErr))
 }
 
 func DoEnsureAzureResourceGroup(ctx context.Context, args *LaunchInstanceAzureTaskArgs) error {
@@ -181,6 +214,7 @@ func DoLaunchInstanceAzure(ctx context.Context, args *LaunchInstanceAzureTaskArg
 		Type:         models.ProviderTypeAzure,
 		PowerOff:     reservation.Detail.PowerOff,
 		InsightsTags: true,
+		Custom:       args.UserData,
 	}
 	userData, err := userdata.GenerateUserData(ctx, &userDataInput)
 	if err != nil {
@@ -188,6 +222,14 @@ func DoLaunchInstanceAzure(ctx context.Context, args *LaunchInstanceAzureTaskArg
 		return fmt.Errorf("cannot generate user data: %w", err)
 	}
 
+	tags := map[string]*string{
+		"rh-rid": ptr.To(config.EnvironmentPrefix("r", strconv.FormatInt(reservation.ID, 10))),
+		"rh-org": ptr.To(identity.Identity(ctx).Identity.OrgID),
+	}
+	for key, value := range reservation.Detail.Tags {
+		tags[key] = ptr.To(value)
+	}
+
 	vmParams := clients.AzureInstanceParams{
 		Location:          args.Location,
 		ResourceGroupName: args.ResourceGroupName,
@@ -195,16 +237,24 @@ func DoLaunchInstanceAzure(ctx context.Context, args *LaunchInstanceAzureTaskArg
 		Pubkey:            pubkey,
 		InstanceType:      clients.InstanceTypeName(reservation.Detail.InstanceSize),
 		UserData:          userData,
-		Tags: map[string]*string{
-			"rh-rid": ptr.To(config.EnvironmentPrefix("r", strconv.FormatInt(reservation.ID, 10))),
-			"rh-org": ptr.To(identity.Identity(ctx).Identity.OrgID),
+		Tags:              tags,
+		SubnetID:          reservation.Detail.SubnetID,
+		SecurityGroupID:   reservation.Detail.SecurityGroupID,
+		NoPublicIP:        reservation.Detail.NoPublicIP,
+		RootVolumeGB:      reservation.Detail.RootVolumeGB,
+		VolumeType:        reservation.Detail.VolumeType,
+		DataDisks:         reservation.Detail.DataDisks,
+		Spot:              reservation.Detail.MarketType == models.MarketTypeSpot,
+		SpotMaxPrice:      reservation.Detail.SpotMaxPrice,
+		ResourceCreated: func(resourceType models.ReservationResourceType, id string) {
+			recordResource(ctx, args.ReservationID, resourceType, id, args.Location)
 		},
 	}
 
 	instanceDescriptions, err := azureClient.CreateVMs(ctx, vmParams, reservation.Detail.Amount, args.Name)
 	if err != nil {
 		span.SetStatus(codes.Error, "failed to create instances")
-		return fmt.Errorf("cannot create Azure instance: %w", err)
+		return userCapacityError(fmt.Errorf("cannot create Azure instance: %w", err), vmParams.Spot)
 	}
 
 	for _, instanceDescription := range instanceDescriptions {
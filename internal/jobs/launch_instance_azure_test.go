@@ -12,6 +12,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -142,3 +143,48 @@ func TestDoLaunchInstanceAzure(t *testing.T) {
 	assert.Len(t, resultInstances, 2)
 	assert.NotEmpty(t, resultInstances[0].Detail.PublicIPv4)
 }
+
+func TestHandleLaunchInstanceAzureRollback(t *testing.T) {
+	ctx := prepareAzureContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareAzureReservation(t, ctx, pk)
+	res.Detail.Amount = 2
+
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAzure(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	job := &worker.Job{
+		Type: jobs.TypeLaunchInstanceAzure,
+		Args: jobs.LaunchInstanceAzureTaskArgs{
+			AzureImageID:  clientStubs.FailingAzureImageID,
+			Location:      "useast",
+			PubkeyID:      pk.ID,
+			ReservationID: res.ID,
+			SourceID:      "2",
+			Subscription:  clients.NewAuthentication("subUUID", models.ProviderTypeAzure),
+		},
+	}
+
+	err = jobs.HandleLaunchInstanceAzure(ctx, job)
+	require.NoError(t, err, "launch job failed to run")
+
+	deleted := clientStubs.DeletedStubAzureResources(ctx)
+	require.Len(t, deleted, 8, "public IP, NIC, disk and VM of both instances should be deleted")
+	assert.Contains(t, deleted[0], "/virtualMachines/", "resources should be deleted in reverse order")
+	assert.Contains(t, deleted[1], "/disks/")
+	assert.Contains(t, deleted[7], "/publicIPAddresses/")
+
+	assert.True(t, res.FinishedAt.Valid, "reservation should be finished")
+	assert.False(t, res.Success.Bool)
+	assert.Contains(t, res.Error, clientStubs.ErrFailedVM.Error())
+	assert.Contains(t, res.Error, "rolled back 8 resource(s): azure_vm redhat-vm-2, azure_disk redhat-vm-2_disk")
+
+	resources, err := rDao.UnscopedListResources(ctx, res.ID)
+	require.NoError(t, err, "failed to list resources")
+	assert.Empty(t, resources, "all resources should be marked as deleted")
+}
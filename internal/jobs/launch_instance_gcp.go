@@ -2,6 +2,7 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
@@ -39,20 +40,24 @@ type LaunchInstanceGCPTaskArgs struct {
 
 	// Launch template id or empty string when no template in use
 	LaunchTemplateID string
+
+	// Optional custom cloud-config or script passed by the user, validated by the service
+	UserData []byte
 }
 
 // HandleLaunchInstanceGCP unmarshalls arguments and handles error
-func HandleLaunchInstanceGCP(ctx context.Context, job *worker.Job) {
+// Launch errors are stored in the reservation and the job is not retried since it is not idempotent.
+func HandleLaunchInstanceGCP(ctx context.Context, job *worker.Job) error {
 	logger := zerolog.Ctx(ctx)
 	if job == nil {
 		logger.Error().Msg("No job for HandleLaunchInstanceGCP")
-		return
+		return ErrNoJob
 	}
 	args, ok := job.Args.(LaunchInstanceGCPTaskArgs)
 	if !ok {
 		err := fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
 		logger.Error().Err(err).Msg("Type assertion error for job")
-		return
+		return err
 	}
 
 	// context and logger
@@ -69,17 +74,46 @@ func HandleLaunchInstanceGCP(ctx context.Context, job *worker.Job) {
 
 	jobErr := DoLaunchInstanceGCP(ctx, &args)
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		return
+		failedGCP(ctx, &args, jobErr)
+		return nil
+	}
+
+	if cancelledGCP(ctx, &args) {
+		return nil
 	}
 
 	jobErr = FetchInstancesDescriptionGCP(ctx, &args)
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		return
+		failedGCP(ctx, &args, jobErr)
+		return nil
+	}
+
+	if cancelledGCP(ctx, &args) {
+		return nil
 	}
 
-	finishJob(ctx, args.ReservationID, jobErr)
+	if errors.Is(finishJob(ctx, args.ReservationID, jobErr), ErrReservationCancelled) {
+		// cancelled right before the reservation was finished
+		cancelledGCP(ctx, &args)
+	}
+	return nil
+}
+
+// cancelledGCP rolls back and finishes the reservation when it was cancelled
+func cancelledGCP(ctx context.Context, args *LaunchInstanceGCPTaskArgs) bool {
+	if checkCancelled(ctx, args.ReservationID) == nil {
+		return false
+	}
+
+	deleted, rollbackErr := RollbackLaunchInstanceGCP(ctx, args)
+	finishCancelled(ctx, args.ReservationID, deleted, rollbackErr)
+	return true
+}
+
+// failedGCP rolls back resources created so far and finishes the reservation with error
+func failedGCP(ctx context.Context, args *LaunchInstanceGCPTaskArgs, jobErr error) {
+	deleted, rollbackErr := RollbackLaunchInstanceGCP(ctx, args)
+	finishWithError(ctx, args.ReservationID, rollbackError(jobErr, deleted, rollbackErr))
 }
 
 // DoLaunchInstanceGCP is a job logic, when error is returned the job status is updated accordingly
@@ -113,6 +147,7 @@ func DoLaunchInstanceGCP(ctx context.Context, args *LaunchInstanceGCPTaskArgs) e
 		Type:         models.ProviderTypeGCP,
 		PowerOff:     args.Detail.PowerOff,
 		InsightsTags: true,
+		Custom:       args.UserData,
 	}
 	userData, err := userdata.GenerateUserData(ctx, &userDataInput)
 	if err != nil {
@@ -130,12 +165,28 @@ func DoLaunchInstanceGCP(ctx context.Context, args *LaunchInstanceGCPTaskArgs) e
 		ReservationID:    args.ReservationID,
 		UUID:             args.Detail.UUID,
 		LaunchTemplateID: args.LaunchTemplateID,
+		Labels:           args.Detail.Tags,
+		Network:          args.Detail.Network,
+		Subnetwork:       args.Detail.Subnetwork,
+		NetworkTags:      args.Detail.NetworkTags,
+		NoPublicIP:       args.Detail.NoPublicIP,
+		RootVolumeGB:     args.Detail.RootVolumeGB,
+		VolumeType:       args.Detail.VolumeType,
+		DataDisks:        args.Detail.DataDisks,
+		Spot:             args.Detail.MarketType == models.MarketTypeSpot,
+	}
+	if userdata.IsCloudConfig(args.UserData) {
+		params.UserData = string(args.UserData)
 	}
 
 	instances, opName, err := gcpClient.InsertInstances(ctx, params, args.Detail.Amount)
 	if err != nil {
 		span.SetStatus(codes.Error, "cannot run instances for gcp client")
-		return fmt.Errorf("cannot run instances for gcp client: %w", err)
+		return userCapacityError(fmt.Errorf("cannot run instances for gcp client: %w", err), params.Spot)
+	}
+
+	for _, instanceId := range instances {
+		recordResource(ctx, args.ReservationID, models.ResourceTypeGCPInstance, *instanceId, args.Zone)
 	}
 
 	rDao := dao.GetReservationDao(ctx)
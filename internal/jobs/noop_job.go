@@ -20,17 +20,17 @@ type NoopJobArgs struct {
 var ErrNoOperationFailure = errors.New("job failed on request")
 
 // HandleNoop unmarshalls arguments and handle error
-func HandleNoop(ctx context.Context, job *worker.Job) {
+func HandleNoop(ctx context.Context, job *worker.Job) error {
 	if job == nil {
 		zerolog.Ctx(ctx).Error().Msg("No job to handle")
-		return
+		return ErrNoJob
 	}
 
 	args, ok := job.Args.(NoopJobArgs)
 	if !ok {
 		err := fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
 		zerolog.Ctx(ctx).Error().Err(err).Msg("Type assertion error for job")
-		return
+		return err
 	}
 
 	// context and logger
@@ -45,7 +45,10 @@ func HandleNoop(ctx context.Context, job *worker.Job) {
 		nc.SuccessfulLaunch(ctx, args.ReservationID)
 	}
 
-	finishJob(ctx, args.ReservationID, jobErr)
+	if errors.Is(finishJob(ctx, args.ReservationID, jobErr), ErrReservationCancelled) {
+		finishCancelled(ctx, args.ReservationID, nil, nil)
+	}
+	return nil
 }
 
 // DoNoop is a job logic, when error is returned the job status is updated accordingly
@@ -0,0 +1,125 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// deleteResourceFunc deletes a single resource from the cloud provider
+type deleteResourceFunc func(ctx context.Context, resource *models.ReservationResource) error
+
+// recordResource stores a cloud resource in the resource ledger of the reservation right after it
+// was created. Errors are only logged so bookkeeping does not interrupt the launch.
+func recordResource(ctx context.Context, reservationId int64, resourceType models.ReservationResourceType, resourceId, region string) {
+	resource := &models.ReservationResource{
+		ReservationID: reservationId,
+		Type:          resourceType,
+		ResourceID:    resourceId,
+		Region:        region,
+	}
+
+	err := dao.GetReservationDao(ctx).UnscopedCreateResource(ctx, resource)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("resource_id", resourceId).Msgf("Unable to record %s resource", resourceType)
+	}
+}
+
+// rollbackContext returns a usable context for the rollback, jobs which timed out are rolled back too.
+func rollbackContext(ctx context.Context) context.Context {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// the original context is expired and unusable at this point
+		return copyContext(ctx)
+	}
+	return ctx
+}
+
+// rollbackResources deletes resources from the ledger of the reservation in reverse order of
+// creation. All resources are attempted, deleted resources are returned together with joined errors.
+func rollbackResources(ctx context.Context, reservationId int64, deleteFn deleteResourceFunc) ([]*models.ReservationResource, error) {
+	resources, err := dao.GetReservationDao(ctx).UnscopedListResources(ctx, reservationId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get reservation resources: %w", err)
+	}
+
+	return deleteResources(ctx, resources, deleteFn)
+}
+
+// terminateInstanceResources deletes resources of the instance recorded in the ledger of the reservation
+// in reverse order of creation. Instances launched without the ledger are terminated by terminateFn.
+func terminateInstanceResources(ctx context.Context, reservationId int64, instanceId string, deleteFn deleteResourceFunc, terminateFn func(ctx context.Context) error) error {
+	resources, err := dao.GetReservationDao(ctx).UnscopedListResources(ctx, reservationId)
+	if err != nil {
+		return fmt.Errorf("cannot get reservation resources: %w", err)
+	}
+
+	resources = slices.DeleteFunc(resources, func(resource *models.ReservationResource) bool {
+		return !instanceResource(resource, instanceId)
+	})
+	if len(resources) == 0 {
+		return terminateFn(ctx)
+	}
+
+	_, err = deleteResources(ctx, resources, deleteFn)
+	return err
+}
+
+// instanceResource returns true when the ledger resource is the instance or, for Azure, a disk
+// or networking of the virtual machine. Azure names them after the machine.
+func instanceResource(resource *models.ReservationResource, instanceId string) bool {
+	switch resource.Type {
+	case models.ResourceTypeAWSInstance, models.ResourceTypeGCPInstance:
+		return resource.ResourceID == instanceId
+	case models.ResourceTypeAzureVM, models.ResourceTypeAzureDisk, models.ResourceTypeAzureNIC, models.ResourceTypeAzurePublicIP:
+		vmName := strings.ToLower(path.Base(instanceId))
+		name := strings.ToLower(path.Base(resource.ResourceID))
+		return name == vmName || strings.HasPrefix(name, vmName+"_")
+	}
+	return false
+}
+
+// deleteResources deletes the resources in reverse order and marks them deleted in the ledger.
+func deleteResources(ctx context.Context, resources []*models.ReservationResource, deleteFn deleteResourceFunc) ([]*models.ReservationResource, error) {
+	logger := zerolog.Ctx(ctx)
+	rDao := dao.GetReservationDao(ctx)
+
+	var deleted []*models.ReservationResource
+	var errs []error
+	for i := len(resources) - 1; i >= 0; i-- {
+		resource := resources[i]
+		logger.Info().Str("resource_id", resource.ResourceID).Msgf("Deleting %s resource", resource.Type)
+		if err := deleteFn(ctx, resource); err != nil {
+			errs = append(errs, fmt.Errorf("cannot delete %s: %w", resource, err))
+			continue
+		}
+
+		deleted = append(deleted, resource)
+		if err := rDao.UnscopedMarkResourceDeleted(ctx, resource.ID); err != nil {
+			logger.Warn().Err(err).Str("resource_id", resource.ResourceID).Msg("unable to mark resource as deleted")
+		}
+	}
+
+	return deleted, errors.Join(errs...)
+}
+
+// rollbackError appends resources which were rolled back and rollback failure to the job error.
+func rollbackError(jobErr error, deleted []*models.ReservationResource, rollbackErr error) error {
+	if len(deleted) > 0 {
+		names := make([]string, len(deleted))
+		for i, resource := range deleted {
+			names[i] = resource.String()
+		}
+		jobErr = fmt.Errorf("%w, rolled back %d resource(s): %s", jobErr, len(deleted), strings.Join(names, ", "))
+	}
+	if rollbackErr != nil {
+		jobErr = fmt.Errorf("%w, rollback failed: %w", jobErr, rollbackErr)
+	}
+	return jobErr
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/progress"
+	"github.com/rs/zerolog"
+)
+
+// statusChanged is called after status, step or result of a reservation was updated. It wakes up
+// clients streaming the reservation progress and sends a reservation status event.
+func statusChanged(ctx context.Context, reservationId int64, eventType string) {
+	progress.Publish(ctx, reservationId)
+	sendStatusEvent(ctx, reservationId, eventType)
+}
+
+// ReservationFailed is called when a reservation was finished with an error outside of a job, for
+// example a cancelled scheduled reservation or a reservation of a deleted source.
+func ReservationFailed(ctx context.Context, reservationId int64) {
+	statusChanged(ctx, reservationId, kafka.ReservationFailureEventType)
+}
+
+// sendStatusEvent sends the current state of a reservation to the reservation status topic when
+// status events are enabled. Errors are only logged, events must never fail a job.
+func sendStatusEvent(ctx context.Context, reservationId int64, eventType string) {
+	if !config.Application.StatusEvents.Enabled {
+		return
+	}
+
+	logger := zerolog.Ctx(ctx)
+	rDao := dao.GetReservationDao(ctx)
+	reservation, err := rDao.GetById(ctx, reservationId)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to send status event: get by id")
+		return
+	}
+	instances, err := rDao.ListInstances(ctx, reservationId)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to send status event: list instances")
+		return
+	}
+
+	msg, err := kafka.ReservationStatusFromModel(eventType, reservation, instances).GenericMessage(ctx)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to create status event")
+		return
+	}
+
+	err = kafka.Send(ctx, &msg)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to send status event via kafka")
+	}
+}
@@ -0,0 +1,62 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusEvents(t *testing.T) {
+	ctx := prepareGCPContext(t)
+	config.Application.StatusEvents.Enabled = true
+	t.Cleanup(func() { config.Application.StatusEvents.Enabled = false })
+	require.NoError(t, kafka.InitializeStubBroker(16))
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareGCPReservation(t, ctx, pk)
+	res.Steps = 1
+	res.StepTitles = []string{"No operation"}
+	err = dao.GetReservationDao(ctx).CreateGCP(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	events := make(chan *kafka.ReservationStatusMessage, 4)
+	consumeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go kafka.Consume(consumeCtx, kafka.ReservationStatusTopic, time.Now(), func(_ context.Context, msg *kafka.GenericMessage) {
+		rsm, msgErr := kafka.NewReservationStatusMessage(msg)
+		if msgErr == nil {
+			events <- rsm
+		}
+	})
+
+	err = jobs.DoNoop(ctx, &jobs.NoopJobArgs{ReservationID: res.ID})
+	require.NoError(t, err)
+
+	started := <-events
+	assert.Equal(t, kafka.ReservationStatusMessageVersion, started.Version)
+	assert.Equal(t, kafka.ReservationStatusEventType, started.EventType)
+	assert.Equal(t, res.ID, started.ReservationID)
+	assert.Equal(t, "gcp", started.Provider)
+	assert.Equal(t, "No operation started", started.Status)
+	assert.Equal(t, int32(0), started.Step)
+	assert.Equal(t, "No operation", started.StepTitle)
+	assert.Nil(t, started.Success)
+	assert.Empty(t, started.Instances)
+
+	finished := <-events
+	assert.Equal(t, "No operation finished", finished.Status)
+	assert.Equal(t, int32(1), finished.Step)
+	assert.Equal(t, int32(1), finished.Steps)
+}
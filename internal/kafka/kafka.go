@@ -208,7 +208,6 @@ func (b *kafkaBroker) Consume(ctx context.Context, topic string, since time.Time
 	}
 
 	for {
-		var span trace.Span
 		msg, err := r.ReadMessage(ctx)
 		if err != nil && errors.Is(err, io.EOF) {
 			logger.Warn().Err(err).Msg("Kafka receiver has been closed")
@@ -222,38 +221,44 @@ func (b *kafkaBroker) Consume(ctx context.Context, topic string, since time.Time
 			logger.Trace().Bytes("payload", msg.Value).Msgf("Received message with key: %s, topic: %s, offset: %d, partition: %d",
 				msg.Key, msg.Topic, msg.Offset, msg.Partition)
 
-			// build new context - identity and trace id
-			logCtx := logger.With().Str("msg_id", random.TraceID().String())
-			newCtx, msgErr := identity.WithIdentityFrom64(ctx, header("X-RH-Identity", msg.Headers))
-			if msgErr != nil {
-				errLogger := logCtx.Logger()
-				errLogger.Warn().Err(msgErr).Msgf("Could not extract identity from context to Kafka message")
-			} else {
-				id := identity.Identity(newCtx)
-				logCtx = logCtx.
-					Str("account_number", id.Identity.AccountNumber).
-					Str("org_id", id.Identity.OrgID)
-			}
-
-			gMsg := NewMessageFromKafka(&msg)
+			newCtx, gMsg, span := messageContext(ctx, logger, topic, &msg)
+			handler(newCtx, gMsg)
 
-			if config.Telemetry.Enabled {
-				newCtx = otel.GetTextMapPropagator().Extract(newCtx, propagation.MapCarrier(headersMap(gMsg.Headers)))
-				newCtx, span = telemetry.StartSpan(newCtx, fmt.Sprintf("Processing message on topic %s", topic))
+			span.End()
+		}
+	}
+}
 
-				logCtx.Str("trace_id", span.SpanContext().TraceID().String())
-			} else {
-				// noopSpan from empty context
-				span = trace.SpanFromContext(context.Background())
-			}
+// messageContext builds context of a received message with identity, logger and trace span. Messages
+// without a valid identity header keep the parent context, handlers may use other message headers.
+func messageContext(ctx context.Context, logger *zerolog.Logger, topic string, msg *kafka.Message) (context.Context, *GenericMessage, trace.Span) {
+	var span trace.Span
+	logCtx := logger.With().Str("msg_id", random.TraceID().String())
+	newCtx, msgErr := identity.WithIdentityFrom64(ctx, header("X-RH-Identity", msg.Headers))
+	if msgErr != nil {
+		newCtx = ctx
+		errLogger := logCtx.Logger()
+		errLogger.Warn().Err(msgErr).Msgf("Could not extract identity from context to Kafka message")
+	} else {
+		id := identity.Identity(newCtx)
+		logCtx = logCtx.
+			Str("account_number", id.Identity.AccountNumber).
+			Str("org_id", id.Identity.OrgID)
+	}
 
-			newCtx = logCtx.Logger().WithContext(newCtx)
+	gMsg := NewMessageFromKafka(msg)
 
-			handler(newCtx, gMsg)
+	if config.Telemetry.Enabled {
+		newCtx = otel.GetTextMapPropagator().Extract(newCtx, propagation.MapCarrier(headersMap(gMsg.Headers)))
+		newCtx, span = telemetry.StartSpan(newCtx, fmt.Sprintf("Processing message on topic %s", topic))
 
-			span.End()
-		}
+		logCtx = logCtx.Str("trace_id", span.SpanContext().TraceID().String())
+	} else {
+		// noopSpan from empty context
+		span = trace.SpanFromContext(context.Background())
 	}
+
+	return logCtx.Logger().WithContext(newCtx), gMsg, span
 }
 
 func header(name string, headers []protocol.Header) string {
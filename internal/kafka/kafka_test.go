@@ -0,0 +1,32 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/rs/zerolog"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageContextWithoutIdentity(t *testing.T) {
+	ctx := context.Background()
+	logger := zerolog.Nop()
+	msg := &kafka.Message{
+		Topic:   SourcesEventStreamTopic,
+		Value:   []byte(`{"id": 1}`),
+		Headers: []protocol.Header{{Key: "x-rh-sources-org-id", Value: []byte("000013")}},
+	}
+
+	require.NotPanics(t, func() {
+		msgCtx, gMsg, span := messageContext(ctx, &logger, SourcesEventStreamTopic, msg)
+		defer span.End()
+
+		require.NotNil(t, msgCtx)
+		assert.Empty(t, identity.Identity(msgCtx).Identity.OrgID)
+		assert.Equal(t, "000013", gMsg.Header("x-rh-sources-org-id"))
+	})
+}
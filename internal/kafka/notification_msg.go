@@ -16,6 +16,7 @@ const (
 	notificationMessageVersion   = "v2.0.0"
 	NotificationSuccessEventType = "launch-success"
 	NotificationFailureEventType = "launch-failed"
+	NotificationExpiryEventType  = "launch-expiring"
 )
 
 type NotificationEvent struct {
@@ -31,6 +32,10 @@ type NotificationError struct {
 	Error string `json:"error"`
 }
 
+type NotificationExpiry struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 type notificationRecipients struct {
 	OnlyAdmins            bool     `json:"only_admins"`
 	IgnoreUserPreferences bool     `json:"ignore_user_preferences"`
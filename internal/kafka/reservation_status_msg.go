@@ -0,0 +1,111 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// ReservationStatusMessageVersion is increased on incompatible changes of the message, new fields
+// can be added without a version change.
+const ReservationStatusMessageVersion = "v1"
+
+const (
+	// ReservationStatusEventType is sent when status or step of a reservation changes.
+	ReservationStatusEventType = "status"
+
+	// ReservationSuccessEventType is sent when a reservation finished successfully.
+	ReservationSuccessEventType = "success"
+
+	// ReservationFailureEventType is sent when a reservation finished with an error or was cancelled.
+	ReservationFailureEventType = "failure"
+)
+
+type ReservationStatusInstance struct {
+	InstanceID string                           `json:"instance_id"`
+	Detail     models.ReservationInstanceDetail `json:"detail"`
+}
+
+// ReservationStatusMessage is a progress event of a reservation, it carries the whole state of the
+// reservation so consumers do not need to poll the reservation API.
+type ReservationStatusMessage struct {
+	Version       string                      `json:"version"`
+	EventType     string                      `json:"event_type"`
+	Timestamp     time.Time                   `json:"timestamp"`
+	OrgID         string                      `json:"org_id"`
+	ReservationID int64                       `json:"reservation_id"`
+	Provider      string                      `json:"provider"`
+	Status        string                      `json:"status"`
+	Step          int32                       `json:"step"`
+	Steps         int32                       `json:"steps"`
+	StepTitle     string                      `json:"step_title"`
+	Success       *bool                       `json:"success"`
+	Error         string                      `json:"error,omitempty"`
+	Instances     []ReservationStatusInstance `json:"instances"`
+}
+
+// ReservationStatusFromModel creates an event from the current state of a reservation.
+func ReservationStatusFromModel(eventType string, reservation *models.Reservation, instances []*models.ReservationInstance) ReservationStatusMessage {
+	m := ReservationStatusMessage{
+		EventType:     eventType,
+		ReservationID: reservation.ID,
+		Provider:      reservation.Provider.String(),
+		Status:        reservation.Status,
+		Step:          reservation.Step,
+		Steps:         reservation.Steps,
+		StepTitle:     reservation.StepTitle(),
+		Error:         reservation.Error,
+		Instances:     make([]ReservationStatusInstance, 0, len(instances)),
+	}
+	if reservation.Success.Valid {
+		m.Success = &reservation.Success.Bool
+	}
+	for _, instance := range instances {
+		m.Instances = append(m.Instances, ReservationStatusInstance{
+			InstanceID: instance.InstanceID,
+			Detail:     instance.Detail,
+		})
+	}
+	return m
+}
+
+func NewReservationStatusMessage(msg *GenericMessage) (*ReservationStatusMessage, error) {
+	rsm := ReservationStatusMessage{}
+	err := json.Unmarshal(msg.Value, &rsm)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal message: %w", err)
+	}
+
+	return &rsm, nil
+}
+
+// GenericMessage returns message keyed by the reservation ID, events of a reservation are
+// therefore delivered in order.
+func (m ReservationStatusMessage) GenericMessage(ctx context.Context) (GenericMessage, error) {
+	id := identity.Identity(ctx)
+
+	m.Version = ReservationStatusMessageVersion
+	m.OrgID = id.Identity.OrgID
+	m.Timestamp = time.Now().UTC()
+
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return GenericMessage{}, fmt.Errorf("unable to marshal reservation status message: %w", err)
+	}
+
+	return GenericMessage{
+		Topic: ReservationStatusTopic,
+		Key:   []byte(strconv.FormatInt(m.ReservationID, 10)),
+		Value: payload,
+		Headers: GenericHeaders(
+			"content-type", "application/json",
+			"x-rh-identity", identity.IdentityHeader(ctx),
+			"event_type", m.EventType,
+		),
+	}, nil
+}
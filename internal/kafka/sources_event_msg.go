@@ -0,0 +1,61 @@
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Sources event types the service reacts to, all other events of the event stream are ignored.
+const (
+	SourceDestroyEventType        = "Source.destroy"
+	SourcePauseEventType          = "Source.pause"
+	SourceUnpauseEventType        = "Source.unpause"
+	AuthenticationUpdateEventType = "Authentication.update"
+)
+
+var ErrMissingSourceID = errors.New("sources event without source id")
+
+// SourcesEventMessage is a lifecycle event from the Sources event stream. The event type is sent
+// in the "event_type" header, the payload is the changed Sources resource.
+type SourcesEventMessage struct {
+	EventType string `json:"-"`
+
+	// ID of the resource, source ID for Source events
+	ID json.Number `json:"id"`
+
+	// ResourceType and ResourceID of authentications, the resource is a Source or an Application
+	ResourceType string      `json:"resource_type"`
+	ResourceID   json.Number `json:"resource_id"`
+
+	// SourceID is sent for applications and authentications of applications
+	SourceID json.Number `json:"source_id"`
+}
+
+func NewSourcesEventMessage(msg *GenericMessage) (*SourcesEventMessage, error) {
+	sem := SourcesEventMessage{EventType: msg.Header("event_type")}
+	err := json.Unmarshal(msg.Value, &sem)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal message: %w", err)
+	}
+
+	return &sem, nil
+}
+
+// SourceIdentifier returns ID of the source the event belongs to or ErrMissingSourceID.
+func (m SourcesEventMessage) SourceIdentifier() (string, error) {
+	var id json.Number
+	switch {
+	case m.EventType == SourceDestroyEventType || m.EventType == SourcePauseEventType || m.EventType == SourceUnpauseEventType:
+		id = m.ID
+	case m.ResourceType == "Source":
+		id = m.ResourceID
+	default:
+		id = m.SourceID
+	}
+
+	if id == "" {
+		return "", ErrMissingSourceID
+	}
+	return id.String(), nil
+}
@@ -0,0 +1,51 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sourcesEvent(eventType, payload string) *GenericMessage {
+	return &GenericMessage{
+		Value:   []byte(payload),
+		Headers: GenericHeaders("event_type", eventType),
+	}
+}
+
+func TestSourcesEventMessageSourceDestroy(t *testing.T) {
+	sem, err := NewSourcesEventMessage(sourcesEvent(SourceDestroyEventType, `{"id":42,"name":"aws","source_type_id":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, SourceDestroyEventType, sem.EventType)
+
+	id, err := sem.SourceIdentifier()
+	require.NoError(t, err)
+	assert.Equal(t, "42", id)
+}
+
+func TestSourcesEventMessageAuthenticationOfSource(t *testing.T) {
+	sem, err := NewSourcesEventMessage(sourcesEvent(AuthenticationUpdateEventType, `{"id":"7","resource_type":"Source","resource_id":"42"}`))
+	require.NoError(t, err)
+
+	id, err := sem.SourceIdentifier()
+	require.NoError(t, err)
+	assert.Equal(t, "42", id)
+}
+
+func TestSourcesEventMessageAuthenticationOfApplication(t *testing.T) {
+	sem, err := NewSourcesEventMessage(sourcesEvent(AuthenticationUpdateEventType, `{"id":7,"resource_type":"Application","resource_id":3,"source_id":42}`))
+	require.NoError(t, err)
+
+	id, err := sem.SourceIdentifier()
+	require.NoError(t, err)
+	assert.Equal(t, "42", id)
+}
+
+func TestSourcesEventMessageMissingSource(t *testing.T) {
+	sem, err := NewSourcesEventMessage(sourcesEvent(AuthenticationUpdateEventType, `{"id":7,"resource_type":"Application","resource_id":3}`))
+	require.NoError(t, err)
+
+	_, err = sem.SourceIdentifier()
+	require.ErrorIs(t, err, ErrMissingSourceID)
+}
@@ -11,6 +11,8 @@ var (
 	availabilityStatusRequestTopicReq = "platform.provisioning.internal.availability-check"
 	sendStatusToSourcesTopicReq       = "platform.sources.status"
 	sendNotificationMessage           = "platform.notifications.ingress"
+	sourcesEventStreamTopicReq        = "platform.sources.event-stream"
+	reservationStatusTopicReq         = "platform.provisioning.reservation-status"
 )
 
 // topics after clowder mapping
@@ -18,6 +20,8 @@ var (
 	AvailabilityStatusRequestTopic string
 	SourcesStatusTopic             string
 	NotificationTopic              string
+	SourcesEventStreamTopic        string
+	ReservationStatusTopic         string
 )
 
 // InitializeTopicRequests performs clowder mapping of topics.
@@ -25,4 +29,6 @@ func InitializeTopicRequests(ctx context.Context) {
 	AvailabilityStatusRequestTopic = config.TopicName(ctx, availabilityStatusRequestTopicReq)
 	SourcesStatusTopic = config.TopicName(ctx, sendStatusToSourcesTopicReq)
 	NotificationTopic = config.TopicName(ctx, sendNotificationMessage)
+	SourcesEventStreamTopic = config.TopicName(ctx, sourcesEventStreamTopicReq)
+	ReservationStatusTopic = config.TopicName(ctx, reservationStatusTopicReq)
 }
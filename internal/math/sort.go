@@ -1,6 +1,10 @@
 package math
 
-import "golang.org/x/exp/constraints"
+import (
+	"sort"
+
+	"golang.org/x/exp/constraints"
+)
 
 func Min[T constraints.Ordered](a, b T) T {
 	if a < b {
@@ -15,3 +19,13 @@ func Max[T constraints.Ordered](a, b T) T {
 	}
 	return a
 }
+
+// SortedKeys returns keys of the map in ascending order.
+func SortedKeys[K constraints.Ordered, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
@@ -37,6 +37,12 @@ var JobQueueSize = prometheus.NewGauge(prometheus.GaugeOpts{
 	ConstLabels: prometheus.Labels{"service": "provisioning", "component": "stats"},
 })
 
+var JobQueueDeadSize = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name:        "provisioning_job_queue_dead_size",
+	Help:        "background job dead-letter queue size (total jobs which failed all attempts)",
+	ConstLabels: prometheus.Labels{"service": "provisioning", "component": "stats"},
+})
+
 var JobQueueInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 	Name:        "provisioning_job_queue_inflight",
 	Help:        "number of in-flight jobs (total jobs which are currently processing)",
@@ -159,6 +165,10 @@ func SetJobQueueSize(size uint64) {
 	JobQueueSize.Set(float64(size))
 }
 
+func SetJobQueueDeadSize(size uint64) {
+	JobQueueDeadSize.Set(float64(size))
+}
+
 func SetJobQueueInFlight(workerName string, inflight int64) {
 	JobQueueInFlight.WithLabelValues(workerName).Set(float64(inflight))
 }
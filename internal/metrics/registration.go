@@ -15,6 +15,7 @@ func RegisterStatuserMetrics() {
 func RegisterStatsMetrics() {
 	prometheus.MustRegister(
 		JobQueueSize,
+		JobQueueDeadSize,
 		JobQueueInFlight,
 		DbStatsDuration,
 		Reservations24hCount,
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog"
+)
+
+// EnforceAdmin allows only organization administrators of organizations configured in
+// APP_ADMIN_ORG_IDS. Principals which are not users (e.g. service accounts) need the "admin"
+// "write" RBAC permission instead and they are denied when RBAC is disabled. It requires that identity is present in the context, make
+// sure to chain EnforceIdentity middleware before this one.
+func EnforceAdmin(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		logger := zerolog.Ctx(r.Context())
+		principal := identity.Identity(r.Context())
+		orgId := principal.Identity.OrgID
+		if orgId == "" {
+			panic(ErrEnforceIdentityFirst)
+		}
+
+		if !slices.Contains(config.Application.AdminOrgIDs, orgId) {
+			permErr := fmt.Errorf("%w: admin on organization %s", ErrMissingPermission, orgId)
+			renderAdminError(w, r, payloads.NewMissingPermissionError(r.Context(), "organization", "admin", permErr))
+			return
+		}
+
+		if user := principal.Identity.User; user != nil {
+			if !user.OrgAdmin {
+				permErr := fmt.Errorf("%w: user %s is not organization admin", ErrMissingPermission, user.Username)
+				renderAdminError(w, r, payloads.NewMissingPermissionError(r.Context(), "organization", "admin", permErr))
+				return
+			}
+		} else if !config.Application.RbacEnabled {
+			// without RBAC every principal would be allowed
+			permErr := fmt.Errorf("%w: RBAC is disabled, only organization admins are allowed", ErrMissingPermission)
+			renderAdminError(w, r, payloads.NewMissingPermissionError(r.Context(), "admin", "write", permErr))
+			return
+		} else {
+			acl, err := clients.GetRbacClient(r.Context()).GetPrincipalAccess(r.Context())
+			if err != nil {
+				renderAdminError(w, r, payloads.NewClientError(r.Context(), fmt.Errorf("unable to get ACL: %w", err)))
+				return
+			}
+			if !acl.IsAllowed("admin", "write") {
+				permErr := fmt.Errorf("%w: write on admin", ErrMissingPermission)
+				renderAdminError(w, r, payloads.NewMissingPermissionError(r.Context(), "admin", "write", permErr))
+				return
+			}
+		}
+
+		logger.Info().
+			Str("principal", identity.PrincipalName(principal)).
+			Str("principal_type", principal.Identity.Type).
+			Msgf("Admin request %s %s", r.Method, r.URL.Path)
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+func renderAdminError(w http.ResponseWriter, r *http.Request, payload render.Renderer) {
+	if errRender := render.Render(w, r, payload); errRender != nil {
+		zerolog.Ctx(r.Context()).Warn().Err(errRender).Msg("Cannot render admin middleware error")
+	}
+}
@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/middleware"
+	_ "github.com/RHEnVision/provisioning-backend/internal/testing/initialization"
+	rhidentity "github.com/redhatinsights/platform-go-middlewares/v2/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnforceAdmin(t *testing.T) {
+	adminOrgIDs, rbacEnabled := config.Application.AdminOrgIDs, config.Application.RbacEnabled
+	config.Application.AdminOrgIDs = []string{"1"}
+	t.Cleanup(func() {
+		config.Application.AdminOrgIDs = adminOrgIDs
+		config.Application.RbacEnabled = rbacEnabled
+	})
+
+	serve := func(t *testing.T, principal identity.Principal) int {
+		t.Helper()
+		ctx := identity.WithIdentity(context.Background(), principal)
+		req, err := http.NewRequestWithContext(ctx, "PUT", "/admin/accounts/2/limits", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		middleware.EnforceAdmin(next).ServeHTTP(rr, req)
+		return rr.Code
+	}
+	user := func(orgId string, orgAdmin bool) identity.Principal {
+		return identity.Principal{Identity: rhidentity.Identity{
+			OrgID: orgId,
+			Type:  "User",
+			User:  &rhidentity.User{Username: "admin", OrgAdmin: orgAdmin},
+		}}
+	}
+
+	t.Run("org admin", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(t, user("1", true)))
+	})
+
+	t.Run("not org admin", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve(t, user("1", false)))
+	})
+
+	t.Run("org admin of other organization", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve(t, user("2", true)))
+	})
+
+	serviceAccount := identity.Principal{Identity: rhidentity.Identity{
+		OrgID:          "1",
+		Type:           "ServiceAccount",
+		ServiceAccount: &rhidentity.ServiceAccount{Username: "service-account-1"},
+	}}
+
+	t.Run("service account with RBAC permission", func(t *testing.T) {
+		config.Application.RbacEnabled = true
+		assert.Equal(t, http.StatusOK, serve(t, serviceAccount))
+	})
+
+	t.Run("service account with RBAC disabled", func(t *testing.T) {
+		config.Application.RbacEnabled = false
+		assert.Equal(t, http.StatusForbidden, serve(t, serviceAccount))
+	})
+}
@@ -50,7 +50,7 @@ func TestMigrationUpdateFingerprint(t *testing.T) {
 	})
 
 	t.Run("migrate ed key", func(t *testing.T) {
-		pks, err := pkDao.List(ctx, 1, 0) // the key from seed
+		pks, err := pkDao.List(ctx, nil, 1, 0) // the key from seed
 		require.NoError(t, err)
 		pks[0].Type = "test"
 		err = pkDao.Update(ctx, pks[0])
@@ -67,7 +67,7 @@ func TestMigrationUpdateFingerprint(t *testing.T) {
 	})
 
 	t.Run("migrate both rsa and ed keys", func(t *testing.T) {
-		pks, err := pkDao.List(ctx, 2, 0)
+		pks, err := pkDao.List(ctx, nil, 2, 0)
 		require.NoError(t, err)
 		for _, pk := range pks {
 			pk.Type = "test"
@@ -0,0 +1,5 @@
+ALTER TABLE reservation_instances
+  ADD COLUMN last_action TEXT NOT NULL DEFAULT '',
+  ADD COLUMN last_action_status TEXT NOT NULL DEFAULT '',
+  ADD COLUMN last_action_error TEXT NOT NULL DEFAULT '',
+  ADD COLUMN last_action_at TIMESTAMP;
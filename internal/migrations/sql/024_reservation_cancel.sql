@@ -0,0 +1 @@
+ALTER TABLE reservations ADD COLUMN cancelled_at TIMESTAMP;
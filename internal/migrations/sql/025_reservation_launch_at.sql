@@ -0,0 +1,3 @@
+ALTER TABLE reservations ADD COLUMN launch_at TIMESTAMP;
+
+CREATE INDEX reservations_scheduled_idx ON reservations(account_id, launch_at) WHERE launch_at IS NOT NULL AND finished_at IS NULL;
@@ -0,0 +1,6 @@
+ALTER TABLE reservations
+  ADD COLUMN expires_at TIMESTAMP,
+  ADD COLUMN expiry_notified_at TIMESTAMP,
+  ADD COLUMN expired_at TIMESTAMP;
+
+CREATE INDEX reservations_expiry_idx ON reservations(expires_at) WHERE expires_at IS NOT NULL AND expired_at IS NULL;
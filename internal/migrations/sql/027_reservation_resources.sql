@@ -0,0 +1,12 @@
+CREATE TABLE reservation_resources
+(
+  id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  reservation_id BIGINT NOT NULL REFERENCES reservations(id) ON DELETE CASCADE,
+  type TEXT NOT NULL CHECK (NOT empty(type)),
+  resource_id TEXT NOT NULL CHECK (NOT empty(resource_id)),
+  region TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  deleted_at TIMESTAMP
+);
+
+CREATE INDEX reservation_resources_reservation_id_idx ON reservation_resources(reservation_id);
@@ -0,0 +1,57 @@
+--
+-- Per-account reservation limits. NULL values fall back to defaults: 5 reservations per second and provider
+-- (the AWS launch bucket), no limit for the others. Limits of reservations per second and pending reservations
+-- are enforced by the rate trigger, instance limits are enforced by the application.
+--
+-- Errors raised by the trigger carry custom SQLSTATE codes (PL429 for the rate, PL430 for pending reservations)
+-- and the maximum in the DETAIL field, so they can be returned as structured errors to the user or UI.
+--
+CREATE TABLE account_limits
+(
+  account_id BIGINT PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+  reservations_per_second INTEGER CHECK (reservations_per_second > 0),
+  max_pending_reservations INTEGER CHECK (max_pending_reservations > 0),
+  max_instances_per_reservation INTEGER CHECK (max_instances_per_reservation > 0),
+  max_instances_per_day INTEGER CHECK (max_instances_per_day > 0),
+  updated_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+
+-- Reservation limit per second for an account and provider type
+CREATE OR REPLACE FUNCTION reservations_rate_limit(account BIGINT) RETURNS INTEGER AS
+$reservations_rate_limit$
+BEGIN
+  RETURN COALESCE((SELECT reservations_per_second FROM account_limits WHERE account_id = account), 5);
+END;
+$reservations_rate_limit$ LANGUAGE plpgsql;
+
+-- Rate limiting function (throws exception when exceeded)
+CREATE OR REPLACE FUNCTION reservations_rate() RETURNS TRIGGER AS
+$reservations_rate$
+DECLARE
+  maximum INTEGER := reservations_rate_limit(NEW.account_id);
+  max_pending INTEGER;
+  current INTEGER;
+BEGIN
+  SELECT COUNT(*) INTO current FROM reservations
+    WHERE account_id = NEW.account_id AND provider = NEW.provider AND success IS NULL AND created_at >= now() - INTERVAL '1 second';
+  IF current >= maximum THEN
+    RAISE EXCEPTION 'too many pending reservations (%) for this provider (maximum % per second)', current, maximum
+      USING ERRCODE = 'PL429', DETAIL = maximum::TEXT;
+  END IF;
+
+  SELECT max_pending_reservations INTO max_pending FROM account_limits WHERE account_id = NEW.account_id;
+  IF max_pending IS NOT NULL THEN
+    SELECT COUNT(*) INTO current FROM reservations WHERE account_id = NEW.account_id AND success IS NULL;
+    IF current >= max_pending THEN
+      RAISE EXCEPTION 'too many pending reservations (%) for this account (maximum %)', current, max_pending
+        USING ERRCODE = 'PL430', DETAIL = max_pending::TEXT;
+    END IF;
+  END IF;
+
+  RETURN NEW;
+END;
+$reservations_rate$ LANGUAGE plpgsql;
+
+DROP FUNCTION reservations_rate_limit();
+
+CREATE INDEX reservations_pending_idx ON reservations(account_id) WHERE success IS NULL;
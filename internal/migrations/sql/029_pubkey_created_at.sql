@@ -0,0 +1,4 @@
+-- Creation time of pubkeys for keyset pagination. Existing keys get the migration time, ties are ordered by ID.
+ALTER TABLE pubkeys ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT current_timestamp;
+
+CREATE INDEX pubkeys_account_id_created_at_idx ON pubkeys(account_id, created_at, id);
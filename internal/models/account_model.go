@@ -1,6 +1,9 @@
 package models
 
-import "database/sql"
+import (
+	"database/sql"
+	"time"
+)
 
 // Account represents a Red Hat Console account
 type Account struct {
@@ -17,3 +20,25 @@ type Account struct {
 func (a Account) CacheKeyName() string {
 	return "account"
 }
+
+// AccountLimits are reservation limits of an account. NULL values mean the default limit
+// applies: 5 reservations per second and provider, no limit for the others.
+type AccountLimits struct {
+	// Account ID, also the primary key.
+	AccountID int64 `db:"account_id"`
+
+	// Maximum reservations created per second and provider.
+	ReservationsPerSecond sql.NullInt32 `db:"reservations_per_second"`
+
+	// Maximum reservations which are not finished yet.
+	MaxPendingReservations sql.NullInt32 `db:"max_pending_reservations"`
+
+	// Maximum amount of instances of a single reservation.
+	MaxInstancesPerReservation sql.NullInt32 `db:"max_instances_per_reservation"`
+
+	// Maximum amount of instances launched in the last 24 hours.
+	MaxInstancesPerDay sql.NullInt32 `db:"max_instances_per_day"`
+
+	// Time of the last change.
+	UpdatedAt time.Time `db:"updated_at"`
+}
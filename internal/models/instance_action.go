@@ -0,0 +1,59 @@
+package models
+
+import "strings"
+
+// InstanceAction is a lifecycle operation performed on an already launched instance.
+type InstanceAction string
+
+const (
+	// InstanceActionUnknown is reserved
+	InstanceActionUnknown InstanceAction = ""
+
+	// Stop (power off or deallocate) a running instance
+	InstanceActionStop InstanceAction = "stop"
+
+	// Start a stopped instance
+	InstanceActionStart InstanceAction = "start"
+
+	// Reboot a running instance
+	InstanceActionReboot InstanceAction = "reboot"
+
+	// Terminate (delete) an instance, this cannot be undone
+	InstanceActionTerminate InstanceAction = "terminate"
+)
+
+func InstanceActionFromString(str string) InstanceAction {
+	switch strings.ToLower(str) {
+	case "stop":
+		return InstanceActionStop
+	case "start":
+		return InstanceActionStart
+	case "reboot":
+		return InstanceActionReboot
+	case "terminate":
+		return InstanceActionTerminate
+	default:
+		return InstanceActionUnknown
+	}
+}
+
+func (a InstanceAction) String() string {
+	return string(a)
+}
+
+// InstanceActionStatus is the state of the last action performed on an instance.
+type InstanceActionStatus string
+
+const (
+	// No action was ever requested for the instance
+	InstanceActionStatusNone InstanceActionStatus = ""
+
+	// Action was enqueued and it is being processed
+	InstanceActionStatusPending InstanceActionStatus = "pending"
+
+	// Action was performed successfully
+	InstanceActionStatusSuccess InstanceActionStatus = "success"
+
+	// Action failed, see the error field
+	InstanceActionStatusFailure InstanceActionStatus = "failure"
+)
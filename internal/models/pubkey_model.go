@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/ssh"
 	"github.com/rs/zerolog"
@@ -42,6 +43,9 @@ type Pubkey struct {
 	// such fingerprint: ssh-keygen -l -E md5 -f $HOME/.ssh/key.pub
 	// Example: "89:c5:99:b5:33:48:1c:84:be:da:cb:97:45:b0:4a:ee"
 	FingerprintLegacy string `db:"fingerprint_legacy" validate:"omitempty,len=47"`
+
+	// Time when the pubkey was created.
+	CreatedAt time.Time `db:"created_at"`
 }
 
 // FindAwsFingerprint returns suitable fingerprint for searching AWS key-pairs.
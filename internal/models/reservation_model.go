@@ -5,6 +5,33 @@ import (
 	"time"
 )
 
+const (
+	// ReservationStatusCreated is the initial status of a reservation which is launched immediately.
+	ReservationStatusCreated = "Created"
+
+	// ReservationStatusScheduled is the initial status of a reservation with a launch time set.
+	// The status changes once the launch job starts.
+	ReservationStatusScheduled = "Scheduled"
+)
+
+const (
+	// MarketTypeOnDemand is the market type of regular instances, also reported for reservations
+	// created before the market type was stored.
+	MarketTypeOnDemand = "on-demand"
+
+	// MarketTypeSpot is the market type of AWS and Azure spot and GCP Spot VM instances, which can be
+	// interrupted by the cloud provider at any time.
+	MarketTypeSpot = "spot"
+)
+
+// NewMarketType returns the market type of a reservation.
+func NewMarketType(spot bool) string {
+	if spot {
+		return MarketTypeSpot
+	}
+	return MarketTypeOnDemand
+}
+
 // Reservation represents an instance launch reservation. They are associated with a background
 // job system with a particular job with its own ID. The function handlers update the reservation
 // Status, Success and FinishedAt attributes until the job is considered finished.
@@ -44,6 +71,61 @@ type Reservation struct {
 
 	// Flag indicating success, error or unknown state (NULL). See Status for the actual error.
 	Success sql.NullBool `db:"success" json:"success"`
+
+	// Time when reservation was cancelled by the user or nil when it was not cancelled. Cancelled
+	// reservations are finished with an error by the background job after rolling back created resources.
+	CancelledAt sql.NullTime `db:"cancelled_at" json:"cancelled_at"`
+
+	// Time when the launch job is scheduled or nil when reservation was launched immediately.
+	LaunchAt sql.NullTime `db:"launch_at" json:"launch_at"`
+
+	// Time when instances of the reservation are terminated or nil when they never expire.
+	ExpiresAt sql.NullTime `db:"expires_at" json:"expires_at"`
+
+	// Time when the expiry warning notification was sent or nil when it was not sent yet.
+	ExpiryNotifiedAt sql.NullTime `db:"expiry_notified_at" json:"-"`
+
+	// Time when termination of expired instances was enqueued or nil when not expired yet.
+	ExpiredAt sql.NullTime `db:"expired_at" json:"expired_at"`
+}
+
+// Cancelled returns true when the reservation was cancelled by the user.
+func (r *Reservation) Cancelled() bool {
+	return r.CancelledAt.Valid
+}
+
+// Scheduled returns true when the reservation waits for its launch time.
+func (r *Reservation) Scheduled() bool {
+	return r.Status == ReservationStatusScheduled && !r.FinishedAt.Valid
+}
+
+// StepTitle returns title of the step in progress, or title of the last step when all steps
+// were finished. Empty string is returned for reservations without step titles.
+func (r *Reservation) StepTitle() string {
+	if len(r.StepTitles) == 0 || r.Step < 0 {
+		return ""
+	}
+	if int(r.Step) >= len(r.StepTitles) {
+		return r.StepTitles[len(r.StepTitles)-1]
+	}
+	return r.StepTitles[r.Step]
+}
+
+// InitialStatus returns the status of a newly created reservation.
+func (r *Reservation) InitialStatus() string {
+	if r.LaunchAt.Valid {
+		return ReservationStatusScheduled
+	}
+	return ReservationStatusCreated
+}
+
+// DataDisk is an additional empty disk attached to every instance of a reservation.
+type DataDisk struct {
+	// Size of the disk in GiB.
+	SizeGB int64 `json:"size_gb" yaml:"size_gb"`
+
+	// Optional volume type, the volume type of the reservation is used when empty.
+	VolumeType string `json:"volume_type,omitempty" yaml:"volume_type,omitempty"`
 }
 
 type NoopReservation struct {
@@ -70,6 +152,33 @@ type AWSDetail struct {
 
 	// PubkeyName on AWS in given region. Found by the EnsurePubkey job.
 	PubkeyName string `json:"pubkey_name"`
+
+	// Optional user tags applied to the created resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Optional subnet ID, the default subnet of the default VPC is used when empty
+	SubnetID string `json:"subnet_id,omitempty"`
+
+	// Optional security group IDs, the default group of the VPC is used when empty
+	SecurityGroupIDs []string `json:"security_group_ids,omitempty"`
+
+	// Do not assign public IP addresses to the instances
+	NoPublicIP bool `json:"no_public_ip,omitempty"`
+
+	// Optional root volume size in GiB, the image default is used when zero
+	RootVolumeGB int64 `json:"root_volume_gb,omitempty"`
+
+	// Optional volume type of the root volume and data disks
+	VolumeType string `json:"volume_type,omitempty"`
+
+	// Optional empty data disks attached to every instance
+	DataDisks []DataDisk `json:"data_disks,omitempty"`
+
+	// Market type of the instances: on-demand or spot
+	MarketType string `json:"market_type,omitempty"`
+
+	// Optional maximum hourly price of spot instances in USD, the on-demand price when zero
+	SpotMaxPrice float64 `json:"spot_max_price,omitempty"`
 }
 
 type AWSReservation struct {
@@ -112,6 +221,33 @@ type GCPDetail struct {
 
 	// Immediately power off the system after initialization
 	PowerOff bool `json:"poweroff"`
+
+	// Optional user tags (GCP labels) applied to the created resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Optional network name, the default network is used when empty
+	Network string `json:"network,omitempty"`
+
+	// Optional subnetwork name in the region of the zone
+	Subnetwork string `json:"subnetwork,omitempty"`
+
+	// Optional network tags matching target tags of firewall rules
+	NetworkTags []string `json:"network_tags,omitempty"`
+
+	// Do not assign external IP addresses to the instances
+	NoPublicIP bool `json:"no_public_ip,omitempty"`
+
+	// Optional root volume size in GiB, the image default is used when zero
+	RootVolumeGB int64 `json:"root_volume_gb,omitempty"`
+
+	// Optional volume type of the root volume and data disks
+	VolumeType string `json:"volume_type,omitempty"`
+
+	// Optional empty data disks attached to every instance
+	DataDisks []DataDisk `json:"data_disks,omitempty"`
+
+	// Market type of the instances: on-demand or spot (Spot VM provisioning model)
+	MarketType string `json:"market_type,omitempty"`
 }
 
 type GCPReservation struct {
@@ -152,6 +288,33 @@ type AzureDetail struct {
 
 	// ResourceGroup is name of Resource Group to put the created resources into
 	ResourceGroup string `json:"resource_group"`
+
+	// Optional user tags applied to the created resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Optional full resource ID of a subnet, shared redhat-vnet network is created when empty
+	SubnetID string `json:"subnet_id,omitempty"`
+
+	// Optional full resource ID of a network security group
+	SecurityGroupID string `json:"security_group_id,omitempty"`
+
+	// Do not create public IP addresses for the instances
+	NoPublicIP bool `json:"no_public_ip,omitempty"`
+
+	// Optional root volume size in GiB, the image default is used when zero
+	RootVolumeGB int64 `json:"root_volume_gb,omitempty"`
+
+	// Optional volume type of the root volume and data disks
+	VolumeType string `json:"volume_type,omitempty"`
+
+	// Optional empty data disks attached to every instance
+	DataDisks []DataDisk `json:"data_disks,omitempty"`
+
+	// Market type of the instances: on-demand or spot
+	MarketType string `json:"market_type,omitempty"`
+
+	// Optional maximum hourly price of spot instances in USD, the on-demand price when zero
+	SpotMaxPrice float64 `json:"spot_max_price,omitempty"`
 }
 
 type AzureReservation struct {
@@ -186,4 +349,16 @@ type ReservationInstance struct {
 
 	// Instance's description, ip and dns
 	Detail ReservationInstanceDetail `db:"detail" json:"detail" yaml:"detail"`
+
+	// Last lifecycle action (stop, start, reboot, terminate) requested for the instance.
+	LastAction InstanceAction `db:"last_action" json:"-"`
+
+	// Status of the last lifecycle action.
+	LastActionStatus InstanceActionStatus `db:"last_action_status" json:"-"`
+
+	// Error message of the last lifecycle action, only set when status is failure.
+	LastActionError string `db:"last_action_error" json:"-"`
+
+	// Time of the last lifecycle action status change.
+	LastActionAt sql.NullTime `db:"last_action_at" json:"-"`
 }
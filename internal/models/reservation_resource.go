@@ -0,0 +1,50 @@
+package models
+
+import (
+	"database/sql"
+	"path"
+	"time"
+)
+
+// ReservationResourceType is a kind of cloud resource created by a launch job.
+type ReservationResourceType string
+
+const (
+	ResourceTypeAWSInstance   ReservationResourceType = "aws_instance"
+	ResourceTypeAWSKeyPair    ReservationResourceType = "aws_key_pair"
+	ResourceTypeAzureVM       ReservationResourceType = "azure_vm"
+	ResourceTypeAzureDisk     ReservationResourceType = "azure_disk"
+	ResourceTypeAzureNIC      ReservationResourceType = "azure_nic"
+	ResourceTypeAzurePublicIP ReservationResourceType = "azure_public_ip"
+	ResourceTypeGCPInstance   ReservationResourceType = "gcp_instance"
+)
+
+// ReservationResource is an entry of the per-reservation resource ledger. Launch jobs record
+// every cloud resource right after it is created so it can be deleted when the launch fails.
+type ReservationResource struct {
+	// Required auto-generated PK.
+	ID int64 `db:"id"`
+
+	// Reservation which created the resource. Required.
+	ReservationID int64 `db:"reservation_id"`
+
+	// Resource type. Required.
+	Type ReservationResourceType `db:"type"`
+
+	// Resource handle (id), format is type-dependant. Azure uses full resource IDs. Required.
+	ResourceID string `db:"resource_id"`
+
+	// Region or zone for resources which are not global.
+	Region string `db:"region"`
+
+	// Time when the resource was recorded.
+	CreatedAt time.Time `db:"created_at"`
+
+	// Time when the resource was deleted by a rollback or nil when it still exists.
+	DeletedAt sql.NullTime `db:"deleted_at"`
+}
+
+// String returns short user-facing description, for Azure resources the last segment of the ID.
+func (r *ReservationResource) String() string {
+	return string(r.Type) + " " + path.Base(r.ResourceID)
+}
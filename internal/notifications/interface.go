@@ -2,6 +2,7 @@ package notifications
 
 import (
 	"context"
+	"time"
 )
 
 var GetNotificationClient = getNoopNotificationClient
@@ -9,4 +10,5 @@ var GetNotificationClient = getNoopNotificationClient
 type NotificationClient interface {
 	SuccessfulLaunch(ctx context.Context, reservationId int64)
 	FailedLaunch(ctx context.Context, reservationId int64, jobError error)
+	ExpiringLaunch(ctx context.Context, reservationId int64, expiresAt time.Time)
 }
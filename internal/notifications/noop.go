@@ -2,6 +2,7 @@ package notifications
 
 import (
 	"context"
+	"time"
 
 	"github.com/rs/zerolog"
 )
@@ -24,3 +25,8 @@ func (s *noopNotificationClient) FailedLaunch(ctx context.Context, reservationId
 	logger := zerolog.Ctx(ctx)
 	logger.Warn().Msg("FailedLaunch not started (Notifications not configured)")
 }
+
+func (s *noopNotificationClient) ExpiringLaunch(ctx context.Context, reservationId int64, expiresAt time.Time) {
+	logger := zerolog.Ctx(ctx)
+	logger.Warn().Msg("ExpiringLaunch not started (Notifications not configured)")
+}
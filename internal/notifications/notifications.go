@@ -3,6 +3,7 @@ package notifications
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
@@ -94,3 +95,34 @@ func (x *client) FailedLaunch(ctx context.Context, reservationId int64, jobError
 		logger.Error().Err(err).Msg("Unable to send notification message via kafka")
 	}
 }
+
+func (x *client) ExpiringLaunch(ctx context.Context, reservationId int64, expiresAt time.Time) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msg("Triggering an expiring launch notification")
+	rDao := dao.GetReservationDao(ctx)
+	reservation, err := rDao.GetById(ctx, reservationId)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to find reservation by id")
+		return
+	}
+	marshalExpiry, err := json.Marshal(kafka.NotificationExpiry{ExpiresAt: expiresAt})
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to marshal expiry")
+		return
+	}
+
+	notificationEvent := []kafka.NotificationEvent{{Payload: marshalExpiry}}
+	notificationMsg, err := kafka.NotificationMessage{
+		Context:   kafka.NotificationContext{Provider: reservation.Provider.String(), LaunchID: reservationId},
+		EventType: kafka.NotificationExpiryEventType, Events: notificationEvent,
+	}.GenericMessage(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to create notification expiry message")
+		return
+	}
+	logger.Info().Msg("Sending notification message")
+	err = kafka.Send(ctx, &notificationMsg)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to send notification message via kafka")
+	}
+}
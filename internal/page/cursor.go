@@ -0,0 +1,54 @@
+package page
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidToken = errors.New("invalid page token")
+
+// Cursor is a position in a list ordered by creation time and ID used for keyset pagination.
+// It is passed to clients as an opaque token.
+type Cursor struct {
+	CreatedAt time.Time `json:"c,omitempty"`
+	ID        int64     `json:"i"`
+}
+
+// EncodeCursor returns opaque token for the cursor.
+func EncodeCursor(cursor *Cursor) string {
+	buf, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// DecodeCursor returns cursor from opaque token, or nil for an empty token which represents
+// the first page.
+func DecodeCursor(token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	cursor := &Cursor{}
+	if err = json.Unmarshal(buf, cursor); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	return cursor, nil
+}
+
+// NextCursor trims items which were fetched with limit + 1 to the limit and returns token
+// for the next page, or an empty token when this is the last page.
+func NextCursor[T any](items []T, limit int, cursor func(T) *Cursor) ([]T, string) {
+	if limit <= 0 || len(items) <= limit {
+		return items, ""
+	}
+
+	items = items[:limit]
+	return items, EncodeCursor(cursor(items[limit-1]))
+}
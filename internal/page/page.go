@@ -106,6 +106,16 @@ func (o limitOffset) String() string {
 	return strconv.Itoa(int(o))
 }
 
+// queryWithout returns a copy of request query parameters without the given keys, this keeps
+// filters and sorting in page links.
+func queryWithout(r *http.Request, keys ...string) url.Values {
+	q := r.URL.Query()
+	for _, key := range keys {
+		q.Del(key)
+	}
+	return q
+}
+
 func NewOffsetMetadata(ctx context.Context, r *http.Request, total int) *Metadata {
 	limit := Limit(ctx).Int()
 	offset := Offset(ctx).Int()
@@ -116,7 +126,7 @@ func NewOffsetMetadata(ctx context.Context, r *http.Request, total int) *Metadat
 		prev = ""
 	} else {
 		prevOffset := math.Max(0, offset-limit)
-		q := url.Values{}
+		q := queryWithout(r, "limit", "offset")
 		q.Add("limit", strconv.Itoa(limit))
 		q.Add("offset", strconv.Itoa(prevOffset))
 		prev = fmt.Sprintf("%v?%v", r.URL.Path, q.Encode())
@@ -127,7 +137,7 @@ func NewOffsetMetadata(ctx context.Context, r *http.Request, total int) *Metadat
 		next = ""
 	} else {
 		nextOffset := offset + limit
-		q := url.Values{}
+		q := queryWithout(r, "limit", "offset")
 		q.Add("limit", strconv.Itoa(limit))
 		q.Add("offset", strconv.Itoa(nextOffset))
 		next = fmt.Sprintf("%v?%v", r.URL.Path, q.Encode())
@@ -149,7 +159,7 @@ func NewTokenMetadata(ctx context.Context, r *http.Request, nextToken string) *M
 	if nextToken == "" {
 		next = ""
 	} else {
-		q := url.Values{}
+		q := queryWithout(r, "limit", "token")
 		q.Add("limit", strconv.Itoa(limit))
 		q.Add("token", nextToken)
 		next = fmt.Sprintf("%v?%v", r.URL.Path, q.Encode())
@@ -0,0 +1,67 @@
+package payloads
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/render"
+)
+
+// AccountLimitsRequest replaces all limits of an account, null or missing values reset the limit
+// to its default. See models.AccountLimits
+type AccountLimitsRequest struct {
+	ReservationsPerSecond      *int32 `json:"reservations_per_second" yaml:"reservations_per_second" description:"Maximum reservations created per second and provider, defaults to 5."`
+	MaxPendingReservations     *int32 `json:"max_pending_reservations" yaml:"max_pending_reservations" description:"Maximum unfinished reservations, unlimited by default."`
+	MaxInstancesPerReservation *int32 `json:"max_instances_per_reservation" yaml:"max_instances_per_reservation" description:"Maximum amount of instances of a single reservation, unlimited by default."`
+	MaxInstancesPerDay         *int32 `json:"max_instances_per_day" yaml:"max_instances_per_day" description:"Maximum amount of instances reserved in the last 24 hours, unlimited by default."`
+}
+
+// AccountLimitsResponse contains limits of an account, null values mean default limits.
+// See models.AccountLimits
+type AccountLimitsResponse struct {
+	OrgID                      string     `json:"org_id" yaml:"org_id"`
+	ReservationsPerSecond      *int32     `json:"reservations_per_second" yaml:"reservations_per_second"`
+	MaxPendingReservations     *int32     `json:"max_pending_reservations" yaml:"max_pending_reservations"`
+	MaxInstancesPerReservation *int32     `json:"max_instances_per_reservation" yaml:"max_instances_per_reservation"`
+	MaxInstancesPerDay         *int32     `json:"max_instances_per_day" yaml:"max_instances_per_day"`
+	UpdatedAt                  *time.Time `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
+}
+
+func (p *AccountLimitsRequest) Bind(_ *http.Request) error {
+	return nil
+}
+
+// Apply sets all limits on the model.
+func (p *AccountLimitsRequest) Apply(limits *models.AccountLimits) {
+	limits.ReservationsPerSecond = int32PtrToSqlNull(p.ReservationsPerSecond)
+	limits.MaxPendingReservations = int32PtrToSqlNull(p.MaxPendingReservations)
+	limits.MaxInstancesPerReservation = int32PtrToSqlNull(p.MaxInstancesPerReservation)
+	limits.MaxInstancesPerDay = int32PtrToSqlNull(p.MaxInstancesPerDay)
+}
+
+func (p *AccountLimitsResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewAccountLimitsResponse(orgId string, limits *models.AccountLimits) render.Renderer {
+	response := &AccountLimitsResponse{
+		OrgID:                      orgId,
+		ReservationsPerSecond:      SqlNullToInt32Ptr(limits.ReservationsPerSecond),
+		MaxPendingReservations:     SqlNullToInt32Ptr(limits.MaxPendingReservations),
+		MaxInstancesPerReservation: SqlNullToInt32Ptr(limits.MaxInstancesPerReservation),
+		MaxInstancesPerDay:         SqlNullToInt32Ptr(limits.MaxInstancesPerDay),
+	}
+	if !limits.UpdatedAt.IsZero() {
+		response.UpdatedAt = &limits.UpdatedAt
+	}
+	return response
+}
+
+func int32PtrToSqlNull(i *int32) sql.NullInt32 {
+	if i == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *i, Valid: true}
+}
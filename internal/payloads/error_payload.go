@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/usrerr"
 	"github.com/aws/smithy-go"
 
@@ -44,9 +47,21 @@ type ResponseError struct {
 
 	// environment (prod or stage or ephemeral)
 	Environment string `json:"environment,omitempty" yaml:"environment"`
+
+	// name of the exceeded account limit (limit errors only)
+	Limit string `json:"limit,omitempty" yaml:"limit,omitempty"`
+
+	// maximum value of the exceeded account limit (limit errors only)
+	Maximum int64 `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+
+	// seconds to wait before retrying, also sent in the Retry-After header (limit errors only)
+	RetryAfter int64 `json:"retry_after,omitempty" yaml:"retry_after,omitempty"`
 }
 
-func (e *ResponseError) Render(_ http.ResponseWriter, r *http.Request) error {
+func (e *ResponseError) Render(w http.ResponseWriter, r *http.Request) error {
+	if e.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(e.RetryAfter, 10))
+	}
 	render.Status(r, e.HTTPStatusCode)
 	return nil
 }
@@ -170,7 +185,27 @@ func NewEnqueueTaskError(ctx context.Context, message string, err error) *Respon
 	return NewResponseError(ctx, http.StatusInternalServerError, message, err)
 }
 
+// NewLimitError returns 429 response with the Retry-After header for limits which can be
+// retried later, 422 response otherwise.
+func NewLimitError(ctx context.Context, err *dao.LimitError) *ResponseError {
+	status := http.StatusUnprocessableEntity
+	if err.RetryAfter > 0 {
+		status = http.StatusTooManyRequests
+	}
+	message := fmt.Sprintf("Limit exceeded: %s (maximum %d)", err.Limit, err.Maximum)
+	response := NewResponseError(ctx, status, message, err)
+	response.Limit = err.Limit
+	response.Maximum = err.Maximum
+	// round up, clients must not retry sooner than allowed
+	response.RetryAfter = int64(math.Ceil(err.RetryAfter.Seconds()))
+	return response
+}
+
 func NewDAOError(ctx context.Context, message string, err error) *ResponseError {
+	var limitErr *dao.LimitError
+	if errors.As(err, &limitErr) {
+		return NewLimitError(ctx, limitErr)
+	}
 	if response := findUserResponse(ctx, "DAO error", err); response != nil {
 		return response
 	}
@@ -193,6 +228,11 @@ func NewStatusError(ctx context.Context, message string, err error) *ResponseErr
 	return NewResponseError(ctx, http.StatusInternalServerError, message, err)
 }
 
+func NewQuotaExceededError(ctx context.Context, err error) *ResponseError {
+	message := fmt.Sprintf("Quota exceeded: %s", err.Error())
+	return NewResponseError(ctx, http.StatusUnprocessableEntity, message, err)
+}
+
 func NewAWSError(ctx context.Context, message string, err error) *ResponseError {
 	var awsAPIErr *smithy.GenericAPIError
 	if errors.As(err, &awsAPIErr) && awsAPIErr.Code == "AccessDenied" {
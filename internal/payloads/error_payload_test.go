@@ -1,11 +1,19 @@
 package payloads
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/usrerr"
 	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
@@ -43,3 +51,24 @@ func TestFindUserPayload(t *testing.T) {
 		}
 	}
 }
+
+func TestNewDAOErrorLimit(t *testing.T) {
+	limitErr := &dao.LimitError{Limit: dao.LimitReservationsPerSecond, Maximum: 5, RetryAfter: 500 * time.Millisecond}
+	err := fmt.Errorf("pgx tx error: %w", limitErr)
+
+	response := NewDAOError(context.Background(), "create reservation", err)
+	assert.Equal(t, http.StatusTooManyRequests, response.HTTPStatusCode)
+	assert.Equal(t, dao.LimitReservationsPerSecond, response.Limit)
+	assert.Equal(t, int64(5), response.Maximum)
+	assert.Equal(t, int64(1), response.RetryAfter)
+	require.ErrorIs(t, err, dao.ErrReservationRateExceeded)
+
+	req := httptest.NewRequest("POST", "/", nil)
+	rr := httptest.NewRecorder()
+	require.NoError(t, response.Render(rr, req))
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+
+	response = NewDAOError(context.Background(), "create reservation", &dao.LimitError{Limit: dao.LimitMaxInstancesPerReservation, Maximum: 2})
+	assert.Equal(t, http.StatusUnprocessableEntity, response.HTTPStatusCode)
+	assert.Zero(t, response.RetryAfter)
+}
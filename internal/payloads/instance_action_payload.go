@@ -0,0 +1,37 @@
+package payloads
+
+import (
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/go-chi/render"
+)
+
+// See models.InstanceAction
+type InstanceActionRequest struct {
+	// Lifecycle action to perform: stop, start, reboot or terminate.
+	Action string `json:"action" yaml:"action" description:"Lifecycle action to perform on the instance: stop, start, reboot or terminate."`
+}
+
+type InstanceActionResponse struct {
+	// Reservation ID the instance belongs to.
+	ReservationID int64 `json:"reservation_id" yaml:"reservation_id"`
+
+	// Instance with the requested action, the action status is pending until the job finishes.
+	Instance InstanceResponse `json:"instance" yaml:"instance"`
+}
+
+func (p *InstanceActionRequest) Bind(_ *http.Request) error {
+	return nil
+}
+
+func (p *InstanceActionResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewInstanceActionResponse(instance *models.ReservationInstance) render.Renderer {
+	return &InstanceActionResponse{
+		ReservationID: instance.ReservationID,
+		Instance:      instanceResponseMapper(instance),
+	}
+}
@@ -0,0 +1,30 @@
+package payloads
+
+import (
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/go-chi/render"
+)
+
+// See clients.Network
+type NetworkResponse clients.Network
+
+type NetworkListResponse struct {
+	// Region, location or zone the networks were read for.
+	Region string `json:"region" yaml:"region"`
+
+	Data []*NetworkResponse `json:"data" yaml:"data"`
+}
+
+func (s *NetworkListResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewNetworkListResponse(region string, networks []*clients.Network) render.Renderer {
+	list := make([]*NetworkResponse, len(networks))
+	for i, network := range networks {
+		list[i] = (*NetworkResponse)(network)
+	}
+	return &NetworkListResponse{Region: region, Data: list}
+}
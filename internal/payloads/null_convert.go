@@ -1,6 +1,9 @@
 package payloads
 
-import "database/sql"
+import (
+	"database/sql"
+	"time"
+)
 
 func SqlNullToStringPtr(s sql.NullString) *string {
 	if !s.Valid {
@@ -10,9 +13,25 @@ func SqlNullToStringPtr(s sql.NullString) *string {
 	return &str
 }
 
+func SqlNullToTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	tm := t.Time
+	return &tm
+}
+
 func StringNullToEmpty(str *string) string {
 	if str == nil {
 		return ""
 	}
 	return *str
 }
+
+func SqlNullToInt32Ptr(i sql.NullInt32) *int32 {
+	if !i.Valid {
+		return nil
+	}
+	n := i.Int32
+	return &n
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/page"
+	"github.com/RHEnVision/provisioning-backend/internal/ssh"
 
 	"github.com/go-chi/render"
 )
@@ -15,6 +16,17 @@ type PubkeyRequest struct {
 	Body string `json:"body" yaml:"body" description:"Add a public part of a SSH key pair."`
 }
 
+// See models.Pubkey
+type PubkeyUpdateRequest struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty" description:"Enter the new name of the pubkey, keeps the current name when empty."`
+	Body string `json:"body,omitempty" yaml:"body,omitempty" description:"Add a new public part of a SSH key pair, keeps the current body when empty."`
+}
+
+type PubkeyGenerateRequest struct {
+	Name string `json:"name" yaml:"name" description:"Enter the name of the newly generated pubkey."`
+	Type string `json:"type,omitempty" yaml:"type,omitempty" description:"Key type to generate: ssh-ed25519 or ssh-rsa. Optional, defaults to ssh-ed25519."`
+}
+
 // See models.Pubkey
 type PubkeyResponse struct {
 	ID                int64  `json:"id" yaml:"id"`
@@ -25,6 +37,22 @@ type PubkeyResponse struct {
 	Fingerprint       string `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
 	FingerprintLegacy string `json:"fingerprint_legacy,omitempty" yaml:"fingerprint_legacy,omitempty"`
 }
+
+// PubkeyGenerateResponse contains the private key which is not stored and only returned once.
+type PubkeyGenerateResponse struct {
+	PubkeyResponse `yaml:",inline"`
+
+	PrivateKeyOpenSSH string `json:"private_key_openssh" yaml:"private_key_openssh" description:"Private key in OpenSSH format. It is not stored and cannot be retrieved again."`
+	PrivateKeyPEM     string `json:"private_key_pem" yaml:"private_key_pem" description:"Private key in PEM format. It is not stored and cannot be retrieved again."`
+}
+
+// PubkeyDeleteResponse is returned when the pubkey deletion from clouds was enqueued.
+type PubkeyDeleteResponse struct {
+	ID        int64  `json:"id" yaml:"id"`
+	JobID     string `json:"job_id" yaml:"job_id" description:"ID of the background job which deletes the uploaded SSH keys and then the pubkey."`
+	Resources int    `json:"resources" yaml:"resources" description:"Number of SSH keys uploaded to clouds which are being deleted."`
+}
+
 type PubkeyListResponse struct {
 	Data     []*PubkeyResponse `json:"data" yaml:"data"`
 	Metadata page.Metadata     `json:"metadata" yaml:"metadata"`
@@ -34,10 +62,36 @@ func (p *PubkeyRequest) Bind(_ *http.Request) error {
 	return nil
 }
 
+func (p *PubkeyUpdateRequest) Bind(_ *http.Request) error {
+	return nil
+}
+
+// Apply sets non-empty fields on the pubkey model.
+func (p *PubkeyUpdateRequest) Apply(pubkey *models.Pubkey) {
+	if p.Name != "" {
+		pubkey.Name = p.Name
+	}
+	if p.Body != "" {
+		pubkey.Body = p.Body
+	}
+}
+
+func (p *PubkeyGenerateRequest) Bind(_ *http.Request) error {
+	return nil
+}
+
+func (p *PubkeyGenerateResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
 func (p *PubkeyResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
 	return nil
 }
 
+func (p *PubkeyDeleteResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
 func (p *PubkeyListResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
 	return nil
 }
@@ -61,6 +115,22 @@ func NewPubkeyResponse(pubkey *models.Pubkey) *PubkeyResponse {
 	}
 }
 
+func NewPubkeyGenerateResponse(pubkey *models.Pubkey, keyPair *ssh.KeyPair) *PubkeyGenerateResponse {
+	return &PubkeyGenerateResponse{
+		PubkeyResponse:    *NewPubkeyResponse(pubkey),
+		PrivateKeyOpenSSH: keyPair.PrivateKeyOpenSSH,
+		PrivateKeyPEM:     keyPair.PrivateKeyPEM,
+	}
+}
+
+func NewPubkeyDeleteResponse(pubkey *models.Pubkey, jobId string, resources int) render.Renderer {
+	return &PubkeyDeleteResponse{
+		ID:        pubkey.ID,
+		JobID:     jobId,
+		Resources: resources,
+	}
+}
+
 func NewPubkeyListResponse(pubkeys []*models.Pubkey, meta *page.Metadata) render.Renderer {
 	list := make([]*PubkeyResponse, len(pubkeys))
 	for i, pubkey := range pubkeys {
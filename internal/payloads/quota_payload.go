@@ -0,0 +1,63 @@
+package payloads
+
+import (
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/go-chi/render"
+)
+
+type QuotaResponse struct {
+	// Quota name as reported by the cloud provider.
+	Name string `json:"name" yaml:"name"`
+
+	// Maximum amount of vCPUs.
+	Limit int64 `json:"limit" yaml:"limit"`
+
+	// Amount of vCPUs currently in use.
+	Usage int64 `json:"usage" yaml:"usage"`
+
+	// Amount of vCPUs which can be still launched.
+	Available int64 `json:"available" yaml:"available"`
+
+	// Instance families the quota applies to, empty when it applies to all instance types.
+	Families []string `json:"families,omitempty" yaml:"families,omitempty"`
+}
+
+type QuotaListResponse struct {
+	// Region, location or zone the quotas were read for.
+	Region string `json:"region" yaml:"region"`
+
+	Quotas []*QuotaResponse `json:"quotas" yaml:"quotas"`
+
+	// Amount of vCPUs needed for the requested instance type and amount, only present when
+	// instance type was provided.
+	RequiredVCPUs int64 `json:"required_vcpus,omitempty" yaml:"required_vcpus,omitempty"`
+
+	// True when the requested instances fit into all applicable quotas, only present when
+	// instance type was provided.
+	Fits *bool `json:"fits,omitempty" yaml:"fits,omitempty"`
+}
+
+func (s *QuotaListResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewQuotaListResponse(region string, quotas []*clients.Quota, requiredVCPUs int64, fits *bool) render.Renderer {
+	list := make([]*QuotaResponse, len(quotas))
+	for i, q := range quotas {
+		list[i] = &QuotaResponse{
+			Name:      q.Name,
+			Limit:     q.Limit,
+			Usage:     q.Usage,
+			Available: q.Available(),
+			Families:  q.Families,
+		}
+	}
+	return &QuotaListResponse{
+		Region:        region,
+		Quotas:        list,
+		RequiredVCPUs: requiredVCPUs,
+		Fits:          fits,
+	}
+}
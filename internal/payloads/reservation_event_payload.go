@@ -0,0 +1,75 @@
+package payloads
+
+import (
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// Reservation event types, same values are used for reservation status events in Kafka.
+const (
+	ReservationEventStatus  = "status"
+	ReservationEventSuccess = "success"
+	ReservationEventFailure = "failure"
+)
+
+// ReservationEventResponse is the data of a server-sent event with progress of a reservation.
+type ReservationEventResponse struct {
+	ID int64 `json:"id" yaml:"id"`
+
+	// Total number of job steps for this reservation.
+	Steps int32 `json:"steps" yaml:"steps"`
+
+	// Active job step for this reservation.
+	Step int32 `json:"step" yaml:"step"`
+
+	// Title of the step in progress, or of the last step when the reservation has finished.
+	StepTitle string `json:"step_title" yaml:"step_title"`
+
+	// Textual status of the reservation.
+	Status string `json:"status" yaml:"status"`
+
+	// Error message when reservation was not successful. Only set when Success if false.
+	Error string `json:"error" yaml:"error"`
+
+	// Time when reservation was finished or nil when it's still processing.
+	FinishedAt *time.Time `json:"finished_at" nullable:"true" yaml:"finished_at"`
+
+	// Flag indicating success, error or unknown state (NULL).
+	Success *bool `json:"success" nullable:"true" yaml:"success"`
+
+	// Instances created so far with their descriptions.
+	Instances []InstanceResponse `json:"instances" yaml:"instances"`
+}
+
+func NewReservationEventResponse(reservation *models.Reservation, instances []*models.ReservationInstance) *ReservationEventResponse {
+	event := &ReservationEventResponse{
+		ID:         reservation.ID,
+		Steps:      reservation.Steps,
+		Step:       reservation.Step,
+		StepTitle:  reservation.StepTitle(),
+		Status:     reservation.Status,
+		Error:      reservation.Error,
+		FinishedAt: SqlNullToTimePtr(reservation.FinishedAt),
+		Instances:  make([]InstanceResponse, 0, len(instances)),
+	}
+	if reservation.Success.Valid {
+		event.Success = &reservation.Success.Bool
+	}
+	for _, instance := range instances {
+		event.Instances = append(event.Instances, instanceResponseMapper(instance))
+	}
+	return event
+}
+
+// EventType returns type of the event, the stream ends with a success or failure event.
+func (p *ReservationEventResponse) EventType() string {
+	switch {
+	case p.FinishedAt == nil:
+		return ReservationEventStatus
+	case p.Success != nil && *p.Success:
+		return ReservationEventSuccess
+	default:
+		return ReservationEventFailure
+	}
+}
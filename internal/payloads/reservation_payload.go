@@ -41,6 +41,18 @@ type GenericReservationResponse struct {
 
 	// Flag indicating success, error or unknown state (NULL). See Status for the actual error.
 	Success *bool `json:"success" nullable:"true" yaml:"success"`
+
+	// Time when reservation was cancelled or nil when it was not cancelled.
+	CancelledAt *time.Time `json:"cancelled_at" nullable:"true" yaml:"cancelled_at"`
+
+	// Time when the reservation is scheduled to launch or nil when it was launched immediately.
+	LaunchAt *time.Time `json:"launch_at" nullable:"true" yaml:"launch_at"`
+
+	// Time when the instance(s) are terminated or nil when the reservation does not expire.
+	ExpiresAt *time.Time `json:"expires_at" nullable:"true" yaml:"expires_at"`
+
+	// Time when the expired instance(s) were scheduled for termination or nil when not expired yet.
+	ExpiredAt *time.Time `json:"expired_at" nullable:"true" yaml:"expired_at"`
 }
 
 type InstanceResponse struct {
@@ -49,6 +61,15 @@ type InstanceResponse struct {
 
 	// Instance's description, ip and dns
 	Detail models.ReservationInstanceDetail `json:"detail" yaml:"detail"`
+
+	// Last lifecycle action (stop, start, reboot or terminate) or missing when no action was requested.
+	LastAction string `json:"last_action,omitempty" yaml:"last_action,omitempty"`
+
+	// Status of the last lifecycle action: pending, success or failure.
+	LastActionStatus string `json:"last_action_status,omitempty" yaml:"last_action_status,omitempty"`
+
+	// Error message of the last lifecycle action, only present on failure.
+	LastActionError string `json:"last_action_error,omitempty" yaml:"last_action_error,omitempty"`
 }
 
 type AWSReservationResponse struct {
@@ -84,6 +105,38 @@ type AWSReservationResponse struct {
 	// Immediately power off the system after initialization
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
 
+	// Scheduled launch time, missing when the reservation was launched immediately.
+	LaunchAt *time.Time `json:"launch_at,omitempty" yaml:"launch_at,omitempty"`
+
+	// Time when the instance(s) are terminated, missing when the reservation does not expire.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+
+	// User tags applied to the created resources.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// Subnet ID, missing when the default subnet was used.
+	SubnetID string `json:"subnet_id,omitempty" yaml:"subnet_id,omitempty"`
+
+	// Security group IDs, missing when the default group was used.
+	SecurityGroupIDs []string `json:"security_group_ids,omitempty" yaml:"security_group_ids,omitempty"`
+
+	// Public IP addresses were not assigned.
+	NoPublicIP bool `json:"no_public_ip,omitempty" yaml:"no_public_ip,omitempty"`
+
+	// Root volume size in GiB, missing when the image default was used.
+	RootVolumeGB int64 `json:"root_volume_gb,omitempty" yaml:"root_volume_gb,omitempty"`
+
+	// Volume type of the root volume and data disks, missing when the default was used.
+	VolumeType string `json:"volume_type,omitempty" yaml:"volume_type,omitempty"`
+
+	// Data disks attached to every instance.
+	DataDisks []models.DataDisk `json:"data_disks,omitempty" yaml:"data_disks,omitempty"`
+
+	// Market type of the instances: on-demand or spot.
+	MarketType string `json:"market_type" yaml:"market_type"`
+	// Maximum hourly price of spot instances in USD, missing when capped at the on-demand price.
+	SpotMaxPrice float64 `json:"spot_max_price,omitempty" yaml:"spot_max_price,omitempty"`
+
 	// Instances array, only present for finished reservations
 	Instances []InstanceResponse `json:"instances,omitempty" yaml:"instances"`
 }
@@ -116,6 +169,38 @@ type AzureReservationResponse struct {
 	// Immediately PowerOff the system after initialization.
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
 
+	// Scheduled launch time, missing when the reservation was launched immediately.
+	LaunchAt *time.Time `json:"launch_at,omitempty" yaml:"launch_at,omitempty"`
+
+	// Time when the instance(s) are terminated, missing when the reservation does not expire.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+
+	// User tags applied to the created resources.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// Subnet resource ID, missing when the shared network was used.
+	SubnetID string `json:"subnet_id,omitempty" yaml:"subnet_id,omitempty"`
+
+	// Network security group resource ID, missing when not provided.
+	SecurityGroupID string `json:"security_group_id,omitempty" yaml:"security_group_id,omitempty"`
+
+	// Public IP addresses were not created.
+	NoPublicIP bool `json:"no_public_ip,omitempty" yaml:"no_public_ip,omitempty"`
+
+	// Root volume size in GiB, missing when the image default was used.
+	RootVolumeGB int64 `json:"root_volume_gb,omitempty" yaml:"root_volume_gb,omitempty"`
+
+	// Volume type of the root volume and data disks, missing when the default was used.
+	VolumeType string `json:"volume_type,omitempty" yaml:"volume_type,omitempty"`
+
+	// Data disks attached to every instance.
+	DataDisks []models.DataDisk `json:"data_disks,omitempty" yaml:"data_disks,omitempty"`
+
+	// Market type of the instances: on-demand or spot.
+	MarketType string `json:"market_type" yaml:"market_type"`
+	// Maximum hourly price of spot instances in USD, missing when capped at the on-demand price.
+	SpotMaxPrice float64 `json:"spot_max_price,omitempty" yaml:"spot_max_price,omitempty"`
+
 	// Instances IDs, only present for finished reservations.
 	Instances []InstanceResponse `json:"instances,omitempty" yaml:"instances"`
 }
@@ -153,6 +238,39 @@ type GCPReservationResponse struct {
 	// Immediately power off the system after initialization
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
 
+	// Scheduled launch time, missing when the reservation was launched immediately.
+	LaunchAt *time.Time `json:"launch_at,omitempty" yaml:"launch_at,omitempty"`
+
+	// Time when the instance(s) are terminated, missing when the reservation does not expire.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+
+	// User tags applied to the created resources.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// Network name, missing when the default network was used.
+	Network string `json:"network,omitempty" yaml:"network,omitempty"`
+
+	// Subnetwork name, missing when not provided.
+	Subnetwork string `json:"subnetwork,omitempty" yaml:"subnetwork,omitempty"`
+
+	// Network tags of the instances.
+	NetworkTags []string `json:"network_tags,omitempty" yaml:"network_tags,omitempty"`
+
+	// External IP addresses were not assigned.
+	NoPublicIP bool `json:"no_public_ip,omitempty" yaml:"no_public_ip,omitempty"`
+
+	// Root volume size in GiB, missing when the image default was used.
+	RootVolumeGB int64 `json:"root_volume_gb,omitempty" yaml:"root_volume_gb,omitempty"`
+
+	// Volume type of the root volume and data disks, missing when the default was used.
+	VolumeType string `json:"volume_type,omitempty" yaml:"volume_type,omitempty"`
+
+	// Data disks attached to every instance.
+	DataDisks []models.DataDisk `json:"data_disks,omitempty" yaml:"data_disks,omitempty"`
+
+	// Market type of the instances: on-demand or spot.
+	MarketType string `json:"market_type" yaml:"market_type"`
+
 	// Instances IDs, only present for finished reservations.
 	Instances []InstanceResponse `json:"instances,omitempty" yaml:"instances"`
 }
@@ -188,6 +306,45 @@ type AWSReservationRequest struct {
 
 	// Immediately power off the system after initialization
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
+
+	// Optional time to launch the instance(s) at, must be in the future. Launched immediately when not set.
+	LaunchAt *time.Time `json:"launch_at,omitempty" yaml:"launch_at,omitempty"`
+
+	// Optional duration ("8h") after which the instance(s) are terminated, counted from the launch time. Cannot be combined with ExpiresAt.
+	ExpiresIn string `json:"expires_in,omitempty" yaml:"expires_in,omitempty"`
+
+	// Optional time to terminate the instance(s) at. Cannot be combined with ExpiresIn.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+
+	// Optional cloud-config ("#cloud-config") or shell script ("#!") executed on the first boot, up to 12 kB.
+	UserData string `json:"user_data,omitempty" yaml:"user_data,omitempty"`
+
+	// Optional tags of instances, volumes and network interfaces. Keys with aws: or rh- prefix and the Name key are reserved.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// Optional subnet ID ("subnet-0a1b2c3d"), the default subnet of the default VPC is used when empty. See the networks endpoint.
+	SubnetID string `json:"subnet_id,omitempty" yaml:"subnet_id,omitempty"`
+
+	// Optional security group IDs ("sg-0a1b2c3d") of the subnet VPC, the default group is used when empty.
+	SecurityGroupIDs []string `json:"security_group_ids,omitempty" yaml:"security_group_ids,omitempty"`
+
+	// Do not assign public IP addresses to the instances.
+	NoPublicIP bool `json:"no_public_ip,omitempty" yaml:"no_public_ip,omitempty"`
+
+	// Optional root volume size in GiB, the AMI default is used when empty. Required for io1 and io2 volumes.
+	RootVolumeGB int64 `json:"root_volume_gb,omitempty" yaml:"root_volume_gb,omitempty"`
+
+	// Optional volume type (gp2, gp3, io1, io2, standard) of the root volume and default type of data disks (also st1 and sc1), gp3 is used for data disks when empty.
+	VolumeType string `json:"volume_type,omitempty" yaml:"volume_type,omitempty"`
+
+	// Optional empty data disks (up to 8) attached to every instance as /dev/sdf, /dev/sdg and so on.
+	DataDisks []models.DataDisk `json:"data_disks,omitempty" yaml:"data_disks,omitempty"`
+
+	// Launch spot instances which are cheaper but can be interrupted by AWS at any time. Spot instances count against spot vCPU quotas.
+	Spot bool `json:"spot,omitempty" yaml:"spot,omitempty"`
+
+	// Optional maximum hourly price in USD per spot instance, capped at the on-demand price when empty. Requires spot.
+	SpotMaxPrice float64 `json:"spot_max_price,omitempty" yaml:"spot_max_price,omitempty"`
 }
 
 type AzureReservationRequest struct {
@@ -217,6 +374,45 @@ type AzureReservationRequest struct {
 
 	// Immediately power off the system after initialization.
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
+
+	// Optional time to launch the instance(s) at, must be in the future. Launched immediately when not set.
+	LaunchAt *time.Time `json:"launch_at,omitempty" yaml:"launch_at,omitempty"`
+
+	// Optional duration ("8h") after which the instance(s) are terminated, counted from the launch time. Cannot be combined with ExpiresAt.
+	ExpiresIn string `json:"expires_in,omitempty" yaml:"expires_in,omitempty"`
+
+	// Optional time to terminate the instance(s) at. Cannot be combined with ExpiresIn.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+
+	// Optional cloud-config ("#cloud-config") or shell script ("#!") executed on the first boot, up to 12 kB.
+	UserData string `json:"user_data,omitempty" yaml:"user_data,omitempty"`
+
+	// Optional tags of the VM, disk, network interface and public IP. Keys with rh-, microsoft, azure or windows prefix are reserved.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// Optional full resource ID of a subnet in the location, shared redhat-vnet network is created when empty. See the networks endpoint.
+	SubnetID string `json:"subnet_id,omitempty" yaml:"subnet_id,omitempty"`
+
+	// Optional full resource ID of a network security group in the location. When empty, a shared group allowing SSH is used for the shared network and no group for custom subnets.
+	SecurityGroupID string `json:"security_group_id,omitempty" yaml:"security_group_id,omitempty"`
+
+	// Do not create public IP addresses for the instances.
+	NoPublicIP bool `json:"no_public_ip,omitempty" yaml:"no_public_ip,omitempty"`
+
+	// Optional OS disk size in GiB up to 4095, the image default is used when empty.
+	RootVolumeGB int64 `json:"root_volume_gb,omitempty" yaml:"root_volume_gb,omitempty"`
+
+	// Optional storage account type (Standard_LRS, StandardSSD_LRS, StandardSSD_ZRS, Premium_LRS, Premium_ZRS) of the OS disk and data disks, defaults to Standard_LRS.
+	VolumeType string `json:"volume_type,omitempty" yaml:"volume_type,omitempty"`
+
+	// Optional empty data disks (up to 8) of up to 32767 GiB attached to every VM.
+	DataDisks []models.DataDisk `json:"data_disks,omitempty" yaml:"data_disks,omitempty"`
+
+	// Launch Azure Spot VMs which are cheaper but can be evicted at any time. Evicted VMs are deleted together with their disks.
+	Spot bool `json:"spot,omitempty" yaml:"spot,omitempty"`
+
+	// Optional maximum hourly price in USD per spot VM, capped at the pay-as-you-go price when empty. Requires spot.
+	SpotMaxPrice float64 `json:"spot_max_price,omitempty" yaml:"spot_max_price,omitempty"`
 }
 
 type GCPReservationRequest struct {
@@ -246,6 +442,59 @@ type GCPReservationRequest struct {
 
 	// Immediately power off the system after initialization.
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
+
+	// Optional time to launch the instance(s) at, must be in the future. Launched immediately when not set.
+	LaunchAt *time.Time `json:"launch_at,omitempty" yaml:"launch_at,omitempty"`
+
+	// Optional duration ("8h") after which the instance(s) are terminated, counted from the launch time. Cannot be combined with ExpiresAt.
+	ExpiresIn string `json:"expires_in,omitempty" yaml:"expires_in,omitempty"`
+
+	// Optional time to terminate the instance(s) at. Cannot be combined with ExpiresIn.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+
+	// Optional cloud-config ("#cloud-config") or shell script ("#!") executed on the first boot, up to 12 kB.
+	UserData string `json:"user_data,omitempty" yaml:"user_data,omitempty"`
+
+	// Optional labels of instances and disks, keys and values must be lowercase. Keys with rh- prefix are reserved.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// Optional network name, the default network is used when empty. See the networks endpoint.
+	Network string `json:"network,omitempty" yaml:"network,omitempty"`
+
+	// Optional subnetwork name in the region of the zone, required for custom-mode networks.
+	Subnetwork string `json:"subnetwork,omitempty" yaml:"subnetwork,omitempty"`
+
+	// Optional network tags of the instances matching target tags of firewall rules.
+	NetworkTags []string `json:"network_tags,omitempty" yaml:"network_tags,omitempty"`
+
+	// Do not assign external IP addresses to the instances.
+	NoPublicIP bool `json:"no_public_ip,omitempty" yaml:"no_public_ip,omitempty"`
+
+	// Optional boot disk size in GB, the image default is used when empty.
+	RootVolumeGB int64 `json:"root_volume_gb,omitempty" yaml:"root_volume_gb,omitempty"`
+
+	// Optional disk type (pd-standard, pd-balanced, pd-ssd) of the boot disk and data disks, defaults to pd-standard.
+	VolumeType string `json:"volume_type,omitempty" yaml:"volume_type,omitempty"`
+
+	// Optional empty data disks (up to 8) of 10 to 65536 GB attached to every instance. Ignored when launched from a launch template without image.
+	DataDisks []models.DataDisk `json:"data_disks,omitempty" yaml:"data_disks,omitempty"`
+
+	// Launch Spot VMs which are cheaper but can be preempted at any time. Preempted instances are deleted. GCP Spot VMs have no maximum price.
+	Spot bool `json:"spot,omitempty" yaml:"spot,omitempty"`
+}
+
+// PreflightResponse is returned when a reservation request passed all launch validations,
+// failed validations are returned as errors.
+type PreflightResponse struct {
+	// Provider of the validated reservation.
+	Provider int `json:"provider" yaml:"provider"`
+
+	// Image the reservation would be launched from: AMI, Azure image ID or GCP image name.
+	// Empty when the image is provided by the launch template.
+	ImageID string `json:"image_id" yaml:"image_id"`
+
+	// True when the launch was also verified by the cloud provider dry run (AWS only).
+	DryRun bool `json:"dry_run" yaml:"dry_run"`
 }
 
 type GenericReservationListResponse struct {
@@ -296,7 +545,7 @@ func (p *GCPReservationRequest) Bind(_ *http.Request) error {
 func NewAWSReservationResponse(reservation *models.AWSReservation, instances []*models.ReservationInstance) render.Renderer {
 	instancesResponse := make([]InstanceResponse, len(instances))
 	for iter, inst := range instances {
-		instancesResponse[iter] = InstanceResponse{InstanceID: inst.InstanceID, Detail: inst.Detail}
+		instancesResponse[iter] = instanceResponseMapper(inst)
 	}
 
 	response := AWSReservationResponse{
@@ -311,6 +560,17 @@ func NewAWSReservationResponse(reservation *models.AWSReservation, instances []*
 		PowerOff:         reservation.Detail.PowerOff,
 		Instances:        instancesResponse,
 		LaunchTemplateID: reservation.Detail.LaunchTemplateID,
+		LaunchAt:         SqlNullToTimePtr(reservation.LaunchAt),
+		ExpiresAt:        SqlNullToTimePtr(reservation.ExpiresAt),
+		Tags:             reservation.Detail.Tags,
+		SubnetID:         reservation.Detail.SubnetID,
+		SecurityGroupIDs: reservation.Detail.SecurityGroupIDs,
+		NoPublicIP:       reservation.Detail.NoPublicIP,
+		RootVolumeGB:     reservation.Detail.RootVolumeGB,
+		VolumeType:       reservation.Detail.VolumeType,
+		DataDisks:        reservation.Detail.DataDisks,
+		MarketType:       marketType(reservation.Detail.MarketType),
+		SpotMaxPrice:     reservation.Detail.SpotMaxPrice,
 	}
 	if reservation.AWSReservationID != nil {
 		response.AWSReservationID = *reservation.AWSReservationID
@@ -321,21 +581,32 @@ func NewAWSReservationResponse(reservation *models.AWSReservation, instances []*
 func NewAzureReservationResponse(reservation *models.AzureReservation, instances []*models.ReservationInstance) render.Renderer {
 	instanceIds := make([]InstanceResponse, len(instances))
 	for iter, inst := range instances {
-		instanceIds[iter] = InstanceResponse{InstanceID: inst.InstanceID, Detail: inst.Detail}
+		instanceIds[iter] = instanceResponseMapper(inst)
 	}
 
 	response := AzureReservationResponse{
-		PubkeyID:      reservation.PubkeyID,
-		ImageID:       reservation.ImageID,
-		SourceID:      reservation.SourceID,
-		ResourceGroup: reservation.Detail.ResourceGroup,
-		Location:      reservation.Detail.Location,
-		Amount:        reservation.Detail.Amount,
-		InstanceSize:  reservation.Detail.InstanceSize,
-		ID:            reservation.ID,
-		Name:          reservation.Detail.Name,
-		PowerOff:      reservation.Detail.PowerOff,
-		Instances:     instanceIds,
+		PubkeyID:        reservation.PubkeyID,
+		ImageID:         reservation.ImageID,
+		SourceID:        reservation.SourceID,
+		ResourceGroup:   reservation.Detail.ResourceGroup,
+		Location:        reservation.Detail.Location,
+		Amount:          reservation.Detail.Amount,
+		InstanceSize:    reservation.Detail.InstanceSize,
+		ID:              reservation.ID,
+		Name:            reservation.Detail.Name,
+		PowerOff:        reservation.Detail.PowerOff,
+		Instances:       instanceIds,
+		LaunchAt:        SqlNullToTimePtr(reservation.LaunchAt),
+		ExpiresAt:       SqlNullToTimePtr(reservation.ExpiresAt),
+		Tags:            reservation.Detail.Tags,
+		SubnetID:        reservation.Detail.SubnetID,
+		SecurityGroupID: reservation.Detail.SecurityGroupID,
+		NoPublicIP:      reservation.Detail.NoPublicIP,
+		RootVolumeGB:    reservation.Detail.RootVolumeGB,
+		VolumeType:      reservation.Detail.VolumeType,
+		DataDisks:       reservation.Detail.DataDisks,
+		MarketType:      marketType(reservation.Detail.MarketType),
+		SpotMaxPrice:    reservation.Detail.SpotMaxPrice,
 	}
 	return &response
 }
@@ -343,10 +614,7 @@ func NewAzureReservationResponse(reservation *models.AzureReservation, instances
 func NewGCPReservationResponse(reservation *models.GCPReservation, instances []*models.ReservationInstance) render.Renderer {
 	instanceIds := make([]InstanceResponse, len(instances))
 	for iter, inst := range instances {
-		instanceIds[iter] = InstanceResponse{
-			InstanceID: inst.InstanceID,
-			Detail:     inst.Detail,
-		}
+		instanceIds[iter] = instanceResponseMapper(inst)
 	}
 
 	response := GCPReservationResponse{
@@ -362,10 +630,42 @@ func NewGCPReservationResponse(reservation *models.GCPReservation, instances []*
 		PowerOff:         reservation.Detail.PowerOff,
 		Instances:        instanceIds,
 		LaunchTemplateID: reservation.Detail.LaunchTemplateID,
+		LaunchAt:         SqlNullToTimePtr(reservation.LaunchAt),
+		ExpiresAt:        SqlNullToTimePtr(reservation.ExpiresAt),
+		Tags:             reservation.Detail.Tags,
+		Network:          reservation.Detail.Network,
+		Subnetwork:       reservation.Detail.Subnetwork,
+		NetworkTags:      reservation.Detail.NetworkTags,
+		NoPublicIP:       reservation.Detail.NoPublicIP,
+		RootVolumeGB:     reservation.Detail.RootVolumeGB,
+		VolumeType:       reservation.Detail.VolumeType,
+		DataDisks:        reservation.Detail.DataDisks,
+		MarketType:       marketType(reservation.Detail.MarketType),
 	}
 	return &response
 }
 
+// marketType returns the stored market type, reservations created before market types were
+// stored were launched on-demand.
+func marketType(stored string) string {
+	if stored == "" {
+		return models.MarketTypeOnDemand
+	}
+	return stored
+}
+
+func (p *PreflightResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewPreflightResponse(provider models.ProviderType, imageID string, dryRun bool) render.Renderer {
+	return &PreflightResponse{
+		Provider: int(provider),
+		ImageID:  imageID,
+		DryRun:   dryRun,
+	}
+}
+
 func NewNoopReservationResponse(reservation *models.NoopReservation) render.Renderer {
 	return &NoopReservationResponse{
 		ID: reservation.ID,
@@ -389,16 +689,34 @@ func reservationResponseMapper(reservation *models.Reservation) *GenericReservat
 	if reservation.Success.Valid {
 		success = &reservation.Success.Bool
 	}
+	var cancelledAt *time.Time
+	if reservation.CancelledAt.Valid {
+		cancelledAt = &reservation.CancelledAt.Time
+	}
 	return &GenericReservationResponse{
-		ID:         reservation.ID,
-		Provider:   int(reservation.Provider),
-		CreatedAt:  reservation.CreatedAt,
-		FinishedAt: finishedAt,
-		Status:     reservation.Status,
-		Success:    success,
-		Steps:      reservation.Steps,
-		Step:       reservation.Step,
-		StepTitles: reservation.StepTitles,
-		Error:      reservation.Error,
+		ID:          reservation.ID,
+		Provider:    int(reservation.Provider),
+		CreatedAt:   reservation.CreatedAt,
+		FinishedAt:  finishedAt,
+		Status:      reservation.Status,
+		Success:     success,
+		Steps:       reservation.Steps,
+		Step:        reservation.Step,
+		StepTitles:  reservation.StepTitles,
+		Error:       reservation.Error,
+		CancelledAt: cancelledAt,
+		LaunchAt:    SqlNullToTimePtr(reservation.LaunchAt),
+		ExpiresAt:   SqlNullToTimePtr(reservation.ExpiresAt),
+		ExpiredAt:   SqlNullToTimePtr(reservation.ExpiredAt),
+	}
+}
+
+func instanceResponseMapper(instance *models.ReservationInstance) InstanceResponse {
+	return InstanceResponse{
+		InstanceID:       instance.InstanceID,
+		Detail:           instance.Detail,
+		LastAction:       instance.LastAction.String(),
+		LastActionStatus: string(instance.LastActionStatus),
+		LastActionError:  instance.LastActionError,
 	}
 }
@@ -0,0 +1,123 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// MaxDataDisks is the maximum amount of data disks attached to a single instance.
+const MaxDataDisks = 8
+
+const (
+	// AWSDefaultVolumeType is used for data disks when no volume type was requested.
+	AWSDefaultVolumeType = "gp3"
+
+	// AzureDefaultVolumeType is used for the OS disk and data disks when no volume type was requested.
+	AzureDefaultVolumeType = "Standard_LRS"
+
+	// GCPDefaultVolumeType is used for the boot disk and data disks when no volume type was requested.
+	GCPDefaultVolumeType = "pd-standard"
+)
+
+var (
+	ErrInvalidVolumeType = errors.New("invalid volume type")
+	ErrInvalidVolumeSize = errors.New("invalid volume size")
+	ErrTooManyDataDisks  = errors.New("too many data disks")
+)
+
+// volumeLimits are size limits of a volume type in GiB
+type volumeLimits struct {
+	min int64
+	max int64
+
+	// maximum size of the root volume, zero when the type cannot be used for root volumes
+	rootMax int64
+
+	// the size of the root volume must be set explicitly (provisioned IOPS are derived from it)
+	sizeRequired bool
+}
+
+var awsVolumeTypes = map[string]volumeLimits{
+	"gp2":      {min: 1, max: 16384, rootMax: 16384},
+	"gp3":      {min: 1, max: 16384, rootMax: 16384},
+	"io1":      {min: 4, max: 16384, rootMax: 16384, sizeRequired: true},
+	"io2":      {min: 4, max: 65536, rootMax: 65536, sizeRequired: true},
+	"st1":      {min: 125, max: 16384},
+	"sc1":      {min: 125, max: 16384},
+	"standard": {min: 1, max: 1024, rootMax: 1024},
+}
+
+var azureVolumeTypes = map[string]volumeLimits{
+	"Standard_LRS":    {min: 1, max: 32767, rootMax: 4095},
+	"StandardSSD_LRS": {min: 1, max: 32767, rootMax: 4095},
+	"StandardSSD_ZRS": {min: 1, max: 32767, rootMax: 4095},
+	"Premium_LRS":     {min: 1, max: 32767, rootMax: 4095},
+	"Premium_ZRS":     {min: 1, max: 32767, rootMax: 4095},
+}
+
+var gcpVolumeTypes = map[string]volumeLimits{
+	"pd-standard": {min: 10, max: 65536, rootMax: 65536},
+	"pd-balanced": {min: 10, max: 65536, rootMax: 65536},
+	"pd-ssd":      {min: 10, max: 65536, rootMax: 65536},
+}
+
+// AWSDisks validates root volume size, volume type and data disks of EC2 instances. Supported types
+// are gp2, gp3, io1, io2, st1, sc1 (data disks only) and standard, the root volume keeps the AMI
+// volume type when empty.
+func AWSDisks(rootGB int64, volumeType string, dataDisks []models.DataDisk) error {
+	return validateDisks(awsVolumeTypes, AWSDefaultVolumeType, rootGB, volumeType, dataDisks)
+}
+
+// AzureDisks validates OS disk size, storage account type and data disks of Azure VMs. OS disks
+// are limited to 4095 GiB, data disks to 32767 GiB.
+func AzureDisks(rootGB int64, volumeType string, dataDisks []models.DataDisk) error {
+	return validateDisks(azureVolumeTypes, AzureDefaultVolumeType, rootGB, volumeType, dataDisks)
+}
+
+// GCPDisks validates boot disk size, disk type and data disks of GCP instances. Supported types are
+// pd-standard, pd-balanced and pd-ssd of 10 to 65536 GB.
+func GCPDisks(rootGB int64, volumeType string, dataDisks []models.DataDisk) error {
+	return validateDisks(gcpVolumeTypes, GCPDefaultVolumeType, rootGB, volumeType, dataDisks)
+}
+
+func validateDisks(volumeTypes map[string]volumeLimits, defaultType string, rootGB int64, volumeType string, dataDisks []models.DataDisk) error {
+	if volumeType != "" || rootGB != 0 {
+		rootType := volumeType
+		if rootType == "" {
+			rootType = defaultType
+		}
+		limits, ok := volumeTypes[rootType]
+		if !ok || limits.rootMax == 0 {
+			return fmt.Errorf("%w: %s cannot be used for root volumes", ErrInvalidVolumeType, rootType)
+		}
+		if rootGB == 0 && limits.sizeRequired {
+			return fmt.Errorf("%w: root volume size is required for %s", ErrInvalidVolumeSize, rootType)
+		}
+		if rootGB != 0 && (rootGB < limits.min || rootGB > limits.rootMax) {
+			return fmt.Errorf("%w: root volume of %d GiB, %s must be %d-%d GiB", ErrInvalidVolumeSize, rootGB, rootType, limits.min, limits.rootMax)
+		}
+	}
+
+	if len(dataDisks) > MaxDataDisks {
+		return fmt.Errorf("%w: %d disks, at most %d allowed", ErrTooManyDataDisks, len(dataDisks), MaxDataDisks)
+	}
+	for i, disk := range dataDisks {
+		diskType := disk.VolumeType
+		if diskType == "" {
+			diskType = volumeType
+		}
+		if diskType == "" {
+			diskType = defaultType
+		}
+		limits, ok := volumeTypes[diskType]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrInvalidVolumeType, diskType)
+		}
+		if disk.SizeGB < limits.min || disk.SizeGB > limits.max {
+			return fmt.Errorf("%w: data disk %d of %d GiB, %s must be %d-%d GiB", ErrInvalidVolumeSize, i, disk.SizeGB, diskType, limits.min, limits.max)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSDisks(t *testing.T) {
+	require.NoError(t, AWSDisks(0, "", nil))
+	require.NoError(t, AWSDisks(50, "gp3", []models.DataDisk{{SizeGB: 500, VolumeType: "st1"}, {SizeGB: 10}}))
+	require.NoError(t, AWSDisks(100, "io2", nil))
+
+	require.ErrorIs(t, AWSDisks(0, "io2", nil), ErrInvalidVolumeSize)
+	require.ErrorIs(t, AWSDisks(100, "st1", nil), ErrInvalidVolumeType)
+	require.ErrorIs(t, AWSDisks(20000, "", nil), ErrInvalidVolumeSize)
+	require.ErrorIs(t, AWSDisks(0, "", []models.DataDisk{{SizeGB: 10, VolumeType: "st1"}}), ErrInvalidVolumeSize)
+	require.ErrorIs(t, AWSDisks(0, "", []models.DataDisk{{SizeGB: 0}}), ErrInvalidVolumeSize)
+	require.ErrorIs(t, AWSDisks(0, "", []models.DataDisk{{SizeGB: 10, VolumeType: "pd-ssd"}}), ErrInvalidVolumeType)
+	require.ErrorIs(t, AWSDisks(0, "", make([]models.DataDisk, MaxDataDisks+1)), ErrTooManyDataDisks)
+}
+
+func TestAzureDisks(t *testing.T) {
+	require.NoError(t, AzureDisks(0, "", nil))
+	require.NoError(t, AzureDisks(256, "Premium_LRS", []models.DataDisk{{SizeGB: 8192}}))
+
+	require.ErrorIs(t, AzureDisks(8192, "Premium_LRS", nil), ErrInvalidVolumeSize)
+	require.ErrorIs(t, AzureDisks(0, "gp3", nil), ErrInvalidVolumeType)
+	require.ErrorIs(t, AzureDisks(0, "", []models.DataDisk{{SizeGB: 40000}}), ErrInvalidVolumeSize)
+}
+
+func TestGCPDisks(t *testing.T) {
+	require.NoError(t, GCPDisks(0, "", nil))
+	require.NoError(t, GCPDisks(50, "pd-ssd", []models.DataDisk{{SizeGB: 100, VolumeType: "pd-balanced"}}))
+
+	require.ErrorIs(t, GCPDisks(5, "", nil), ErrInvalidVolumeSize)
+	require.ErrorIs(t, GCPDisks(0, "Premium_LRS", nil), ErrInvalidVolumeType)
+	require.ErrorIs(t, GCPDisks(0, "", []models.DataDisk{{SizeGB: 1}}), ErrInvalidVolumeSize)
+}
@@ -0,0 +1,26 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrSpotMaxPriceWithoutSpot = errors.New("spot max price requires spot instances")
+	ErrInvalidSpotMaxPrice     = errors.New("invalid spot max price")
+)
+
+// SpotMaxPrice validates the maximum hourly price of spot instances, zero means the on-demand
+// price and is always valid.
+func SpotMaxPrice(spot bool, maxPrice float64) error {
+	if maxPrice == 0 {
+		return nil
+	}
+	if !spot {
+		return ErrSpotMaxPriceWithoutSpot
+	}
+	if maxPrice < 0 {
+		return fmt.Errorf("%w: %g, must be positive", ErrInvalidSpotMaxPrice, maxPrice)
+	}
+	return nil
+}
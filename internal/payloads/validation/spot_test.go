@@ -0,0 +1,16 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpotMaxPrice(t *testing.T) {
+	require.NoError(t, SpotMaxPrice(false, 0))
+	require.NoError(t, SpotMaxPrice(true, 0))
+	require.NoError(t, SpotMaxPrice(true, 0.0125))
+
+	require.ErrorIs(t, SpotMaxPrice(false, 0.5), ErrSpotMaxPriceWithoutSpot)
+	require.ErrorIs(t, SpotMaxPrice(true, -1), ErrInvalidSpotMaxPrice)
+}
@@ -0,0 +1,115 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/RHEnVision/provisioning-backend/internal/math"
+)
+
+// ReservedTagPrefix is used by tags and labels set by the service (rh-rid, rh-org, rh-uuid).
+const ReservedTagPrefix = "rh-"
+
+const (
+	// MaxAWSTags is the AWS limit of 50 tags per resource minus rh-rid, rh-org and Name tags.
+	MaxAWSTags = 47
+
+	// MaxGCPLabels is the GCP limit of 64 labels per resource minus rh-rid, rh-org and rh-uuid labels.
+	MaxGCPLabels = 61
+
+	// MaxAzureTags is the Azure limit of 50 tags per resource minus rh-rid and rh-org tags.
+	MaxAzureTags = 48
+)
+
+var (
+	ErrTooManyTags     = errors.New("too many tags")
+	ErrReservedTagKey  = errors.New("reserved tag key")
+	ErrInvalidTagKey   = errors.New("invalid tag key")
+	ErrInvalidTagValue = errors.New("invalid tag value")
+	ErrDuplicateTagKey = errors.New("duplicate tag key")
+)
+
+var (
+	awsTagRe      = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+	gcpLabelKeyRe = regexp.MustCompile(`^[\p{Ll}\p{Lo}][\p{Ll}\p{Lo}\p{N}_-]{0,62}$`)
+	gcpLabelRe    = regexp.MustCompile(`^[\p{Ll}\p{Lo}\p{N}_-]{0,63}$`)
+)
+
+// reserved Azure tag name prefixes, compared case-insensitive
+var azureReservedPrefixes = []string{"microsoft", "azure", "windows"}
+
+// AWSTags validates user tags of EC2 resources: keys up to 128 and values up to 256 characters of
+// letters, numbers, spaces and _ . : / = + - @. The aws: and rh- prefixes and the Name key are reserved.
+func AWSTags(tags map[string]string) error {
+	if len(tags) > MaxAWSTags {
+		return fmt.Errorf("%w: %d tags, at most %d allowed", ErrTooManyTags, len(tags), MaxAWSTags)
+	}
+	for _, key := range math.SortedKeys(tags) {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "aws:") || strings.HasPrefix(lower, ReservedTagPrefix) || key == "Name" {
+			return fmt.Errorf("%w: %s", ErrReservedTagKey, key)
+		}
+		if key == "" || utf8.RuneCountInString(key) > 128 || !awsTagRe.MatchString(key) {
+			return fmt.Errorf("%w: %s", ErrInvalidTagKey, key)
+		}
+		if utf8.RuneCountInString(tags[key]) > 256 || !awsTagRe.MatchString(tags[key]) {
+			return fmt.Errorf("%w: %s", ErrInvalidTagValue, key)
+		}
+	}
+	return nil
+}
+
+// GCPLabels validates user labels of GCP resources: keys must start with a lowercase letter, keys
+// and values are up to 63 lowercase letters, numbers, underscores and dashes. The rh- prefix is reserved.
+func GCPLabels(labels map[string]string) error {
+	if len(labels) > MaxGCPLabels {
+		return fmt.Errorf("%w: %d labels, at most %d allowed", ErrTooManyTags, len(labels), MaxGCPLabels)
+	}
+	for _, key := range math.SortedKeys(labels) {
+		if strings.HasPrefix(key, ReservedTagPrefix) {
+			return fmt.Errorf("%w: %s", ErrReservedTagKey, key)
+		}
+		if !gcpLabelKeyRe.MatchString(key) {
+			return fmt.Errorf("%w: %s", ErrInvalidTagKey, key)
+		}
+		if !gcpLabelRe.MatchString(labels[key]) {
+			return fmt.Errorf("%w: %s", ErrInvalidTagValue, key)
+		}
+	}
+	return nil
+}
+
+// AzureTags validates user tags of Azure resources: names up to 512 characters without < > % & \ ? /
+// and values up to 256 characters. Names are case-insensitive, rh-, microsoft, azure and windows
+// prefixes are reserved.
+func AzureTags(tags map[string]string) error {
+	if len(tags) > MaxAzureTags {
+		return fmt.Errorf("%w: %d tags, at most %d allowed", ErrTooManyTags, len(tags), MaxAzureTags)
+	}
+	seen := make(map[string]bool, len(tags))
+	for _, key := range math.SortedKeys(tags) {
+		lower := strings.ToLower(key)
+		if seen[lower] {
+			return fmt.Errorf("%w: %s", ErrDuplicateTagKey, key)
+		}
+		seen[lower] = true
+		if strings.HasPrefix(lower, ReservedTagPrefix) {
+			return fmt.Errorf("%w: %s", ErrReservedTagKey, key)
+		}
+		for _, prefix := range azureReservedPrefixes {
+			if strings.HasPrefix(lower, prefix) {
+				return fmt.Errorf("%w: %s", ErrReservedTagKey, key)
+			}
+		}
+		if key == "" || utf8.RuneCountInString(key) > 512 || strings.ContainsAny(key, `<>%&\?/`) {
+			return fmt.Errorf("%w: %s", ErrInvalidTagKey, key)
+		}
+		if utf8.RuneCountInString(tags[key]) > 256 {
+			return fmt.Errorf("%w: %s", ErrInvalidTagValue, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSTags(t *testing.T) {
+	require.NoError(t, AWSTags(nil))
+	require.NoError(t, AWSTags(map[string]string{"cost-center": "CC 1234", "team/owner": "me@example.com", "empty": ""}))
+
+	require.ErrorIs(t, AWSTags(map[string]string{"aws:cloudformation": "x"}), ErrReservedTagKey)
+	require.ErrorIs(t, AWSTags(map[string]string{"RH-rid": "x"}), ErrReservedTagKey)
+	require.ErrorIs(t, AWSTags(map[string]string{"Name": "x"}), ErrReservedTagKey)
+	require.ErrorIs(t, AWSTags(map[string]string{"": "x"}), ErrInvalidTagKey)
+	require.ErrorIs(t, AWSTags(map[string]string{"cost#center": "x"}), ErrInvalidTagKey)
+	require.ErrorIs(t, AWSTags(map[string]string{strings.Repeat("k", 129): "x"}), ErrInvalidTagKey)
+	require.ErrorIs(t, AWSTags(map[string]string{"key": strings.Repeat("v", 257)}), ErrInvalidTagValue)
+
+	tooMany := make(map[string]string)
+	for i := 0; i <= MaxAWSTags; i++ {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+	require.ErrorIs(t, AWSTags(tooMany), ErrTooManyTags)
+}
+
+func TestGCPLabels(t *testing.T) {
+	require.NoError(t, GCPLabels(nil))
+	require.NoError(t, GCPLabels(map[string]string{"cost-center": "cc_1234", "empty": ""}))
+
+	require.ErrorIs(t, GCPLabels(map[string]string{"rh-org": "x"}), ErrReservedTagKey)
+	require.ErrorIs(t, GCPLabels(map[string]string{"Cost": "x"}), ErrInvalidTagKey)
+	require.ErrorIs(t, GCPLabels(map[string]string{"1cost": "x"}), ErrInvalidTagKey)
+	require.ErrorIs(t, GCPLabels(map[string]string{strings.Repeat("k", 64): "x"}), ErrInvalidTagKey)
+	require.ErrorIs(t, GCPLabels(map[string]string{"cost": "CC"}), ErrInvalidTagValue)
+	require.ErrorIs(t, GCPLabels(map[string]string{"cost": "cc 1"}), ErrInvalidTagValue)
+}
+
+func TestAzureTags(t *testing.T) {
+	require.NoError(t, AzureTags(nil))
+	require.NoError(t, AzureTags(map[string]string{"Cost Center": "CC/1234"}))
+
+	require.ErrorIs(t, AzureTags(map[string]string{"Rh-rid": "x"}), ErrReservedTagKey)
+	require.ErrorIs(t, AzureTags(map[string]string{"MicrosoftTag": "x"}), ErrReservedTagKey)
+	require.ErrorIs(t, AzureTags(map[string]string{"cost/center": "x"}), ErrInvalidTagKey)
+	require.ErrorIs(t, AzureTags(map[string]string{"key": strings.Repeat("v", 257)}), ErrInvalidTagValue)
+	require.ErrorIs(t, AzureTags(map[string]string{"Env": "a", "env": "b"}), ErrDuplicateTagKey)
+}
@@ -0,0 +1,117 @@
+// Package progress delivers reservation progress from background jobs to API processes. Jobs
+// publish IDs of changed reservations via Postgres NOTIFY, every API process listens on the
+// channel and wakes up subscribers of the reservation. Subscribers load the current state of the
+// reservation from the database, notifications therefore carry no tenant data.
+package progress
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/rs/zerolog"
+)
+
+// channel is the Postgres notification channel
+const channel = "reservation_progress"
+
+// reconnectDelay is a delay before listening again after the listener failed
+const reconnectDelay = 5 * time.Second
+
+var (
+	initialized atomic.Bool
+
+	mu          sync.Mutex
+	subscribers = make(map[int64]map[chan struct{}]struct{})
+)
+
+// Initialize enables publishing via the database, it must be called after the database was
+// initialized. Publish only notifies subscribers of this process before that so jobs can run in
+// tests without a database.
+func Initialize() {
+	initialized.Store(true)
+}
+
+// Publish notifies subscribers in all API processes that the reservation has changed. Errors are
+// only logged, progress must never fail a job.
+func Publish(ctx context.Context, reservationId int64) {
+	if !initialized.Load() {
+		dispatch(strconv.FormatInt(reservationId, 10))
+		return
+	}
+
+	err := db.Notify(ctx, channel, strconv.FormatInt(reservationId, 10))
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Unable to publish reservation progress")
+	}
+}
+
+// Subscribe returns a channel which receives a value every time the reservation changes and a
+// function which must be called to unsubscribe. Notifications are coalesced, a slow subscriber
+// has at most one pending notification.
+func Subscribe(reservationId int64) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if subscribers[reservationId] == nil {
+		subscribers[reservationId] = make(map[chan struct{}]struct{})
+	}
+	subscribers[reservationId][ch] = struct{}{}
+
+	return ch, func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		delete(subscribers[reservationId], ch)
+		if len(subscribers[reservationId]) == 0 {
+			delete(subscribers, reservationId)
+		}
+	}
+}
+
+// Listen dispatches notifications to subscribers of this process until the context is
+// cancelled. It blocks, therefore it should be called from a separate goroutine.
+func Listen(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msg("Started reservation progress listener")
+	defer func() {
+		logger.Debug().Msg("Reservation progress listener exited")
+	}()
+
+	for {
+		err := db.Listen(ctx, channel, dispatch)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warn().Err(err).Msgf("Reservation progress listener failed, listening again in %s", reconnectDelay)
+
+		select {
+		case <-time.After(reconnectDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func dispatch(payload string) {
+	reservationId, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for ch := range subscribers[reservationId] {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// a notification is already pending
+		}
+	}
+}
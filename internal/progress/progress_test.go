@@ -0,0 +1,37 @@
+package progress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeDispatch(t *testing.T) {
+	ch1, unsubscribe1 := Subscribe(1)
+	ch2, unsubscribe2 := Subscribe(2)
+	defer unsubscribe2()
+
+	dispatch("1")
+	dispatch("1")
+	dispatch("invalid")
+
+	require.Len(t, ch1, 1, "notifications must be coalesced")
+	<-ch1
+	assert.Empty(t, ch2)
+
+	unsubscribe1()
+	dispatch("1")
+	assert.Empty(t, ch1)
+	assert.NotContains(t, subscribers, int64(1))
+	assert.Contains(t, subscribers, int64(2))
+}
+
+func TestPublishWithoutDatabase(t *testing.T) {
+	ch, unsubscribe := Subscribe(3)
+	defer unsubscribe()
+
+	Publish(context.Background(), 3)
+	require.Len(t, ch, 1, "subscribers of this process must be notified")
+}
@@ -25,6 +25,13 @@ func RegisterJobs(logger *zerolog.Logger) {
 	workers.RegisterHandler(jobs.TypeLaunchInstanceAws, jobs.HandleLaunchInstanceAWS, jobs.LaunchInstanceAWSTaskArgs{})
 	workers.RegisterHandler(jobs.TypeLaunchInstanceAzure, jobs.HandleLaunchInstanceAzure, jobs.LaunchInstanceAzureTaskArgs{})
 	workers.RegisterHandler(jobs.TypeLaunchInstanceGcp, jobs.HandleLaunchInstanceGCP, jobs.LaunchInstanceGCPTaskArgs{})
+	workers.RegisterHandler(jobs.TypeInstanceActionAws, jobs.HandleInstanceActionAWS, jobs.InstanceActionTaskArgs{})
+	workers.RegisterHandler(jobs.TypeInstanceActionAzure, jobs.HandleInstanceActionAzure, jobs.InstanceActionTaskArgs{})
+	workers.RegisterHandler(jobs.TypeInstanceActionGcp, jobs.HandleInstanceActionGCP, jobs.InstanceActionTaskArgs{})
+	workers.RegisterHandler(jobs.TypeExpireReservation, jobs.HandleExpireReservation, jobs.ExpireReservationTaskArgs{})
+	workers.RegisterHandler(jobs.TypeDeletePubkey, jobs.HandleDeletePubkey, jobs.DeletePubkeyTaskArgs{})
+	workers.RegisterHandler(jobs.TypeDeletePubkeyResources, jobs.HandleDeletePubkeyResources, jobs.DeletePubkeyResourcesTaskArgs{})
+	workers.RegisterCancelHandler(jobs.HandleCancelledJob)
 }
 
 func Initialize(_ context.Context, logger *zerolog.Logger) error {
@@ -0,0 +1,55 @@
+package jq
+
+import (
+	"context"
+	"encoding/gob"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisEnqueueExpireReservation(t *testing.T) {
+	mr := miniredis.RunT(t)
+	origWorker, origRedis := *config.Worker, config.Application.Cache.Redis
+	t.Cleanup(func() {
+		*config.Worker = origWorker
+		config.Application.Cache.Redis = origRedis
+	})
+	config.Worker.Queue = "redis"
+	config.Application.Cache.Redis.Host = mr.Host()
+	config.Application.Cache.Redis.Port, _ = strconv.Atoi(mr.Port())
+
+	ctx := identity.WithIdentity(context.Background(), identity.NewSystemPrincipal("1", "1"))
+	logger := zerolog.Nop()
+	err := Initialize(ctx, &logger)
+	require.NoError(t, err)
+	RegisterJobs(&logger)
+
+	job := worker.Job{
+		Type:     jobs.TypeExpireReservation,
+		Identity: identity.Identity(ctx),
+		Args:     jobs.ExpireReservationTaskArgs{ReservationID: 42},
+	}
+	err = getEnqueuer(ctx).Enqueue(ctx, &job)
+	require.NoError(t, err, "Expected registered job arguments to be encoded")
+
+	payloads, err := mr.List("provisioning-job-queue")
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+
+	var decoded worker.Job
+	err = gob.NewDecoder(strings.NewReader(payloads[0])).Decode(&decoded)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, decoded.ID)
+	assert.Equal(t, jobs.ExpireReservationTaskArgs{ReservationID: 42}, decoded.Args)
+	assert.Equal(t, "1", decoded.Identity.Identity.OrgID)
+}
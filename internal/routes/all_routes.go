@@ -79,6 +79,8 @@ func MountAPI(r *chi.Mux) {
 
 				r.With(middleware.Pagination).Get("/launch_templates", s.ListLaunchTemplates)
 				r.Get("/upload_info", s.GetSourceUploadInfo)
+				r.Get("/quotas", s.GetSourceQuotas)
+				r.Get("/networks", s.GetSourceNetworks)
 				r.Route("/validate_permissions", func(r chi.Router) {
 					r.Get("/", s.ValidatePermissions)
 				})
@@ -88,24 +90,34 @@ func MountAPI(r *chi.Mux) {
 		r.Route("/pubkeys", func(r chi.Router) {
 			r.With(middleware.EnforcePermissions("pubkey", "write")).Post("/", s.CreatePubkey)
 			r.With(middleware.EnforcePermissions("pubkey", "read")).With(middleware.Pagination).Get("/", s.ListPubkeys)
+			r.With(middleware.EnforcePermissions("pubkey", "write")).Post("/generate", s.GeneratePubkey)
 			r.Post("/", s.CreatePubkey)
 			r.Route("/{ID}", func(r chi.Router) {
 				r.With(middleware.EnforcePermissions("pubkey", "read")).Get("/", s.GetPubkey)
+				r.With(middleware.EnforcePermissions("pubkey", "write")).Patch("/", s.UpdatePubkey)
 				r.With(middleware.EnforcePermissions("pubkey", "write")).Delete("/", s.DeletePubkey)
 			})
 		})
 
 		r.Route("/reservations", func(r chi.Router) {
 			r.With(middleware.EnforcePermissions("reservation", "read")).With(middleware.Pagination).Get("/", s.ListReservations)
+			r.With(middleware.EnforcePermissions("reservation", "read")).With(middleware.Pagination).Get("/scheduled", s.ListScheduledReservations)
 			// Different types do have different payloads, therefore TYPE must be part of
 			// URL and not a URL (filter) parameter.
 			r.Route("/{TYPE}", func(r chi.Router) {
 				// additional permission checks are in the service functions
 				r.With(middleware.EnforcePermissions("reservation", "read")).Get("/{ID}", s.GetReservationDetail)
 				r.With(middleware.EnforcePermissions("reservation", "write")).Post("/", s.CreateReservation)
+				r.With(middleware.EnforcePermissions("reservation", "write")).Post("/preflight", s.PreflightReservation)
 			})
 			// Generic reservation detail request (no details provided)
 			r.With(middleware.EnforcePermissions("reservation", "read")).Get("/{ID}", s.GetReservationDetail)
+			// Live progress of a reservation as server-sent events, additional permission checks are in the service function
+			r.With(middleware.EnforcePermissions("reservation", "read")).Get("/{ID}/events", s.ReservationEvents)
+			// Cancellation of an in-flight reservation, additional permission checks are in the service function
+			r.With(middleware.EnforcePermissions("reservation", "write")).Delete("/{ID}", s.CancelReservation)
+			// Instance lifecycle actions, additional permission checks are in the service function
+			r.With(middleware.EnforcePermissions("reservation", "write")).Post("/{ID}/instances/{INSTANCE_ID}/actions", s.CreateInstanceAction)
 		})
 
 		// Endpoint used by sources background checker (no permissions needed)
@@ -148,6 +160,14 @@ func MountAPI(r *chi.Mux) {
 			})
 		})
 
+		// Administration of other accounts, only organization admins of organizations from
+		// APP_ADMIN_ORG_IDS are allowed. Limits are replaced as a whole, null values reset the default.
+		r.Route("/admin/accounts/{ORG_ID}", func(r chi.Router) {
+			r.Use(middleware.EnforceAdmin)
+			r.Get("/limits", s.GetAccountLimits)
+			r.Put("/limits", s.UpdateAccountLimits)
+		})
+
 		// We expose feature flags for image builder, this is undocumented since we
 		// want to push for the setup where we share the same unleash instance and this
 		// endpoint might not be needed anymore.
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog"
+)
+
+var ErrInvalidAccountLimit = errors.New("account limits must be positive numbers")
+
+// instancesPerDayRetryAfter is the retry period of the instances per day limit, instances are
+// counted over last 24 hours so capacity is freed gradually
+const instancesPerDayRetryAfter = time.Hour
+
+func GetAccountLimits(w http.ResponseWriter, r *http.Request) {
+	orgId := chi.URLParam(r, "ORG_ID")
+
+	account, err := dao.GetAccountDao(r.Context()).GetByOrgId(r.Context(), orgId)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, fmt.Sprintf("account of organization %s", orgId))
+		return
+	}
+
+	limits, err := dao.GetAccountDao(r.Context()).UnscopedGetLimits(r.Context(), account.ID)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "get account limits", err))
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewAccountLimitsResponse(orgId, limits)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render account limits", err))
+	}
+}
+
+func UpdateAccountLimits(w http.ResponseWriter, r *http.Request) {
+	logger := zerolog.Ctx(r.Context())
+	orgId := chi.URLParam(r, "ORG_ID")
+
+	payload := &payloads.AccountLimitsRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "update account limits", err))
+		return
+	}
+
+	for _, limit := range []*int32{payload.ReservationsPerSecond, payload.MaxPendingReservations, payload.MaxInstancesPerReservation, payload.MaxInstancesPerDay} {
+		if limit != nil && *limit <= 0 {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid account limits", ErrInvalidAccountLimit))
+			return
+		}
+	}
+
+	accDao := dao.GetAccountDao(r.Context())
+	account, err := accDao.GetByOrgId(r.Context(), orgId)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, fmt.Sprintf("account of organization %s", orgId))
+		return
+	}
+
+	limits := &models.AccountLimits{AccountID: account.ID}
+	payload.Apply(limits)
+	if err = accDao.UnscopedUpdateLimits(r.Context(), limits); err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "update account limits", err))
+		return
+	}
+	logger.Info().Int64("account_id", account.ID).
+		Str("principal", identity.PrincipalName(identity.Identity(r.Context()))).
+		Interface("limits", payload).
+		Msgf("Updated limits of organization %s", orgId)
+
+	if err := render.Render(w, r, payloads.NewAccountLimitsResponse(orgId, limits)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render account limits", err))
+	}
+}
+
+// ensureAccountLimits checks limits of instances per reservation and per day of the account, the
+// other limits are enforced when the reservation is created. Renders an error and returns false
+// when a limit is exceeded.
+func ensureAccountLimits(w http.ResponseWriter, r *http.Request, amount int64) bool {
+	limits, err := dao.GetAccountDao(r.Context()).GetLimits(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "get account limits", err))
+		return false
+	}
+
+	if limits.MaxInstancesPerReservation.Valid && amount > int64(limits.MaxInstancesPerReservation.Int32) {
+		renderError(w, r, payloads.NewLimitError(r.Context(), &dao.LimitError{
+			Limit:   dao.LimitMaxInstancesPerReservation,
+			Maximum: int64(limits.MaxInstancesPerReservation.Int32),
+		}))
+		return false
+	}
+
+	if limits.MaxInstancesPerDay.Valid {
+		count, err := dao.GetReservationDao(r.Context()).CountInstancesSince(r.Context(), time.Now().Add(-24*time.Hour))
+		if err != nil {
+			renderError(w, r, payloads.NewDAOError(r.Context(), "count instances", err))
+			return false
+		}
+
+		if count+amount > int64(limits.MaxInstancesPerDay.Int32) {
+			renderError(w, r, payloads.NewLimitError(r.Context(), &dao.LimitError{
+				Limit:      dao.LimitMaxInstancesPerDay,
+				Maximum:    int64(limits.MaxInstancesPerDay.Int32),
+				RetryAfter: instancesPerDayRetryAfter,
+			}))
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,142 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccountLimitsRequest(t *testing.T, ctx context.Context, method, orgId string, body string) *http.Request {
+	t.Helper()
+	rctx := chi.NewRouteContext()
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	rctx.URLParams.Add("ORG_ID", orgId)
+
+	req, err := http.NewRequestWithContext(ctx, method, "/api/provisioning/admin/accounts/"+orgId+"/limits", bytes.NewBufferString(body))
+	require.NoError(t, err, "failed to create request")
+	req.Header.Add("Content-Type", "application/json")
+	return req
+}
+
+func TestAccountLimitsHandlers(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+
+	t.Run("default limits", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(services.GetAccountLimits).ServeHTTP(rr, newAccountLimitsRequest(t, ctx, "GET", identity.DefaultOrgId, ""))
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.AccountLimitsResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		assert.Equal(t, identity.DefaultOrgId, result.OrgID)
+		assert.Nil(t, result.ReservationsPerSecond)
+		assert.Nil(t, result.MaxInstancesPerDay)
+	})
+
+	t.Run("update limits", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		body := `{"reservations_per_second": 2, "max_instances_per_day": 100}`
+		http.HandlerFunc(services.UpdateAccountLimits).ServeHTTP(rr, newAccountLimitsRequest(t, ctx, "PUT", identity.DefaultOrgId, body))
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		limits, err := dao.GetAccountDao(ctx).GetLimits(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, sql.NullInt32{Int32: 2, Valid: true}, limits.ReservationsPerSecond)
+		assert.Equal(t, sql.NullInt32{Int32: 100, Valid: true}, limits.MaxInstancesPerDay)
+		assert.False(t, limits.MaxPendingReservations.Valid)
+	})
+
+	t.Run("invalid limits", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		body := `{"max_pending_reservations": 0}`
+		http.HandlerFunc(services.UpdateAccountLimits).ServeHTTP(rr, newAccountLimitsRequest(t, ctx, "PUT", identity.DefaultOrgId, body))
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("unknown organization", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(services.GetAccountLimits).ServeHTTP(rr, newAccountLimitsRequest(t, ctx, "GET", "999", ""))
+		require.Equal(t, http.StatusNotFound, rr.Code, "Handler returned wrong status code")
+	})
+}
+
+func TestAccountLimitsReservation(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	pk := factories.NewPubkeyRSA()
+	require.NoError(t, stubs.AddPubkey(ctx, pk), "failed to generate pubkey")
+
+	createReservation := func(amount int) *httptest.ResponseRecorder {
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "ami-0c830793775595d4b",
+			"amount":        amount,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+		}
+		jsonData, err := json.Marshal(values)
+		require.NoError(t, err, "unable to marshal values to json")
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(jsonData))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(services.CreateAWSReservation).ServeHTTP(rr, req)
+		return rr
+	}
+
+	err := dao.GetAccountDao(ctx).UnscopedUpdateLimits(ctx, &models.AccountLimits{
+		AccountID:                  1,
+		MaxInstancesPerReservation: sql.NullInt32{Int32: 2, Valid: true},
+		MaxInstancesPerDay:         sql.NullInt32{Int32: 4, Valid: true},
+	})
+	require.NoError(t, err)
+
+	existing := &models.AWSReservation{Detail: &models.AWSDetail{Amount: 3}}
+	existing.AccountID = 1
+	existing.CreatedAt = time.Now()
+	require.NoError(t, dao.GetReservationDao(ctx).CreateAWS(ctx, existing))
+
+	t.Run("instances per reservation", func(t *testing.T) {
+		rr := createReservation(3)
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code, "Handler returned wrong status code")
+		assert.Empty(t, rr.Header().Get("Retry-After"))
+
+		var result payloads.ResponseError
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		assert.Equal(t, dao.LimitMaxInstancesPerReservation, result.Limit)
+		assert.Equal(t, int64(2), result.Maximum)
+	})
+
+	t.Run("instances per day", func(t *testing.T) {
+		rr := createReservation(2)
+		require.Equal(t, http.StatusTooManyRequests, rr.Code, "Handler returned wrong status code")
+		assert.Equal(t, "3600", rr.Header().Get("Retry-After"))
+
+		var result payloads.ResponseError
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		assert.Equal(t, dao.LimitMaxInstancesPerDay, result.Limit)
+		assert.Equal(t, int64(4), result.Maximum)
+		assert.Equal(t, int64(3600), result.RetryAfter)
+	})
+}
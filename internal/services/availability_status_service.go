@@ -4,9 +4,11 @@ import (
 	"net/http"
 
 	"github.com/RHEnVision/provisioning-backend/internal/background"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http/sources"
 	"github.com/RHEnVision/provisioning-backend/internal/kafka"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/go-chi/render"
+	"github.com/rs/zerolog"
 )
 
 func AvailabilityStatus(w http.ResponseWriter, r *http.Request) {
@@ -16,6 +18,11 @@ func AvailabilityStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the source has changed, the next request must not use the cached authentication
+	if err := sources.InvalidateAuthentication(r.Context(), payload.SourceID); err != nil {
+		zerolog.Ctx(r.Context()).Warn().Err(err).Msg("Could not invalidate cached authentication")
+	}
+
 	asm := kafka.AvailabilityStatusMessage{SourceID: payload.SourceID}
 	err := background.EnqueueAvailabilityStatusRequest(r.Context(), &asm)
 	if err != nil {
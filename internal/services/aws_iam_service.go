@@ -14,17 +14,14 @@ import (
 	"github.com/rs/zerolog"
 )
 
+// ValidatePermissions checks the source account grants all permissions used by the service. AWS
+// policies, Azure role assignments and GCP IAM roles are checked.
 func ValidatePermissions(w http.ResponseWriter, r *http.Request) {
 	logger := zerolog.Ctx(r.Context())
 	sourceId := chi.URLParam(r, "ID")
 	if err := validation.DigitsOnly(sourceId); err != nil {
 		renderError(w, r, payloads.NewURLParsingError(r.Context(), "id parameter invalid", err))
-	}
-
-	region := r.URL.Query().Get("region")
-
-	if region == "" {
-		region = config.AWS.DefaultRegion
+		return
 	}
 
 	// Get Sources client
@@ -41,25 +38,53 @@ func ValidatePermissions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !authentication.Is(models.ProviderTypeAWS) {
-		if err = render.Render(w, r, payloads.NewPermissionsResponse(nil)); err != nil {
-			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render missing permissions", err))
+	var missingPermissions []string
+	switch authentication.ProviderType {
+	case models.ProviderTypeAWS:
+		region := r.URL.Query().Get("region")
+		if region == "" {
+			region = config.AWS.DefaultRegion
+		}
+
+		ec2Client, err := clients.GetEC2Client(r.Context(), authentication, region)
+		if err != nil {
+			renderError(w, r, payloads.NewAWSError(r.Context(), "unable to get AWS EC2 client", err))
 			return
 		}
-		return
-	}
 
-	ec2Client, err := clients.GetEC2Client(r.Context(), authentication, region)
-	if err != nil {
-		renderError(w, r, payloads.NewAWSError(r.Context(), "unable to get AWS EC2 client", err))
-		return
-	}
+		logger.Info().Msgf("Listing permissions.")
+		missingPermissions, err = ec2Client.CheckPermission(r.Context(), authentication)
+		if err != nil && missingPermissions == nil {
+			renderError(w, r, payloads.NewAWSError(r.Context(), "unable to check aws permissions", err))
+			return
+		}
+	case models.ProviderTypeAzure:
+		azureClient, err := clients.GetAzureClient(r.Context(), authentication)
+		if err != nil {
+			renderError(w, r, payloads.NewAzureError(r.Context(), "unable to get Azure client", err))
+			return
+		}
 
-	logger.Info().Msgf("Listing permissions.")
-	missingPermissions, err := ec2Client.CheckPermission(r.Context(), authentication)
-	if err != nil && missingPermissions == nil {
-		renderError(w, r, payloads.NewAWSError(r.Context(), "unable to check aws permissions", err))
-		return
+		logger.Info().Msgf("Listing Azure permissions.")
+		missingPermissions, err = azureClient.CheckPermission(r.Context())
+		if err != nil && missingPermissions == nil {
+			renderError(w, r, payloads.NewAzureError(r.Context(), "unable to check Azure permissions", err))
+			return
+		}
+	case models.ProviderTypeGCP:
+		gcpClient, err := clients.GetGCPClient(r.Context(), authentication)
+		if err != nil {
+			renderError(w, r, payloads.NewGCPError(r.Context(), "unable to get GCP client", err))
+			return
+		}
+
+		logger.Info().Msgf("Listing GCP permissions.")
+		missingPermissions, err = gcpClient.CheckPermission(r.Context())
+		if err != nil && missingPermissions == nil {
+			renderError(w, r, payloads.NewGCPError(r.Context(), "unable to check GCP permissions", err))
+			return
+		}
+	case models.ProviderTypeNoop, models.ProviderTypeUnknown:
 	}
 
 	if err := render.Render(w, r, payloads.NewPermissionsResponse(missingPermissions)); err != nil {
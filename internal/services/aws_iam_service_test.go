@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	clientStub "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePermissions(t *testing.T) {
+	for _, provider := range []models.ProviderType{models.ProviderTypeAWS, models.ProviderTypeAzure, models.ProviderTypeGCP} {
+		t.Run(provider.String(), func(t *testing.T) {
+			ctx := stubs.WithAccountDaoOne(context.Background())
+			ctx = identity.WithTenant(t, ctx)
+			ctx = clientStub.WithSourcesClient(ctx)
+			ctx = clientStub.WithEC2Client(ctx)
+			ctx = clientStub.WithAzureClient(ctx)
+			ctx = clientStub.WithGCPCCustomerClient(ctx)
+
+			source, err := clientStub.AddSource(ctx, provider)
+			require.NoError(t, err, "failed to add stubbed source")
+
+			rctx := chi.NewRouteContext()
+			ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+			rctx.URLParams.Add("ID", source.ID)
+			req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("/api/provisioning/sources/%s/validate_permissions", source.ID), nil)
+			require.NoError(t, err, "failed to create request")
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(ValidatePermissions)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+			var result payloads.PermissionsResponse
+			err = json.NewDecoder(rr.Body).Decode(&result)
+			require.NoError(t, err, "failed to decode response body")
+
+			assert.True(t, result.Valid)
+			assert.Empty(t, result.MissingEntities)
+		})
+	}
+
+	t.Run("invalid id", func(t *testing.T) {
+		ctx := stubs.WithAccountDaoOne(context.Background())
+		ctx = identity.WithTenant(t, ctx)
+		ctx = clientStub.WithSourcesClient(ctx)
+
+		rctx := chi.NewRouteContext()
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		rctx.URLParams.Add("ID", "abc")
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/sources/abc/validate_permissions", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(ValidatePermissions)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -17,26 +18,178 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/jobs"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads/validation"
 	"github.com/RHEnVision/provisioning-backend/internal/preload"
 	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/internal/userdata"
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/go-chi/render"
 	"github.com/rs/zerolog"
 )
 
+// ErrRootVolumeWithoutImage is returned when the root volume is configured for an AMI of a launch template,
+// the root device name is not known then.
+var ErrRootVolumeWithoutImage = errors.New("root volume size and type require image_id")
+
 func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 	logger := zerolog.Ctx(r.Context())
 
 	accountId := identity.AccountId(r.Context())
 	id := identity.Identity(r.Context())
 
+	launch := prepareAWSReservation(w, r)
+	if launch == nil {
+		return
+	}
+	reservation := launch.reservation
+	rDao := dao.GetReservationDao(r.Context())
+
+	// The last step: create reservation in the database and submit new job
+	err := rDao.CreateAWS(r.Context(), reservation)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "create reservation", err))
+		return
+	}
+	logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
+
+	launchJob := worker.Job{
+		Type:      jobs.TypeLaunchInstanceAws,
+		Identity:  id,
+		EdgeID:    logging.EdgeRequestId(r.Context()),
+		AccountID: accountId,
+		RunAt:     reservation.LaunchAt.Time,
+		Args: jobs.LaunchInstanceAWSTaskArgs{
+			ReservationID:    reservation.ID,
+			Region:           reservation.Detail.Region,
+			PubkeyID:         launch.pubkey.ID,
+			SourceID:         reservation.SourceID,
+			Detail:           reservation.Detail,
+			AMI:              launch.ami,
+			LaunchTemplateID: reservation.Detail.LaunchTemplateID,
+			ARN:              launch.authentication,
+			UserData:         launch.userData,
+		},
+	}
+
+	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &launchJob)
+	if err != nil {
+		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
+		return
+	}
+	logger.Debug().Msgf("Enqueued reservation job %s", launchJob.ID)
+
+	// Return response payload
+	unused := make([]*models.ReservationInstance, 0, 0)
+	if err := render.Render(w, r, payloads.NewAWSReservationResponse(reservation, unused)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render AWS reservation", err))
+	}
+}
+
+// PreflightAWSReservation runs all validations of CreateAWSReservation and EC2 dry run of the launch
+// without creating the reservation.
+func PreflightAWSReservation(w http.ResponseWriter, r *http.Request) {
+	launch := prepareAWSReservation(w, r)
+	if launch == nil {
+		return
+	}
+	detail := launch.reservation.Detail
+
+	ec2Client, err := clients.GetEC2Client(r.Context(), launch.authentication, detail.Region)
+	if err != nil {
+		renderError(w, r, payloads.NewAWSError(r.Context(), "unable to get AWS EC2 client", err))
+		return
+	}
+
+	params := &clients.AWSInstanceParams{
+		LaunchTemplateID: detail.LaunchTemplateID,
+		InstanceType:     types.InstanceType(detail.InstanceType),
+		AMI:              launch.ami,
+		SubnetID:         detail.SubnetID,
+		SecurityGroupIDs: detail.SecurityGroupIDs,
+		NoPublicIP:       detail.NoPublicIP,
+		RootVolumeGB:     detail.RootVolumeGB,
+		VolumeType:       detail.VolumeType,
+		DataDisks:        detail.DataDisks,
+		Spot:             detail.MarketType == models.MarketTypeSpot,
+		SpotMaxPrice:     detail.SpotMaxPrice,
+	}
+	if err = ec2Client.DryRunInstances(r.Context(), params, detail.Amount); err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	if err = render.Render(w, r, payloads.NewPreflightResponse(models.ProviderTypeAWS, launch.ami, true)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render AWS preflight", err))
+	}
+}
+
+// awsLaunch is a validated AWS reservation request with its pubkey, source authentication and resolved AMI.
+type awsLaunch struct {
+	reservation    *models.AWSReservation
+	pubkey         *models.Pubkey
+	authentication *clients.Authentication
+	ami            string
+	userData       []byte
+}
+
+// prepareAWSReservation binds and validates AWS reservation request, on failure the error is rendered
+// and nil is returned. Nothing is written into the database.
+func prepareAWSReservation(w http.ResponseWriter, r *http.Request) *awsLaunch {
+	logger := zerolog.Ctx(r.Context())
+
 	payload := &payloads.AWSReservationRequest{}
 	if err := render.Bind(r, payload); err != nil {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "AWS reservation", err))
-		return
+		return nil
+	}
+
+	launchAt, err := parseLaunchAt(payload.LaunchAt)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid launch time", err))
+		return nil
+	}
+
+	userData := []byte(payload.UserData)
+	if err = userdata.ValidateCustom(userData); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid user data", err))
+		return nil
+	}
+
+	if err = validation.AWSTags(payload.Tags); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid tags", err))
+		return nil
+	}
+
+	if err = validateAWSNetwork(payload.SubnetID, payload.SecurityGroupIDs); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid network", err))
+		return nil
+	}
+
+	if err = validation.AWSDisks(payload.RootVolumeGB, payload.VolumeType, payload.DataDisks); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid disks", err))
+		return nil
+	}
+
+	if err = validation.SpotMaxPrice(payload.Spot, payload.SpotMaxPrice); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid spot options", err))
+		return nil
+	}
+	if payload.ImageID == "" && (payload.RootVolumeGB != 0 || payload.VolumeType != "") {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid disks", ErrRootVolumeWithoutImage))
+		return nil
+	}
+
+	expiresAt, err := parseExpiry(payload.ExpiresIn, payload.ExpiresAt, launchAt)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid expiry", err))
+		return nil
+	}
+
+	if !ensureAccountLimits(w, r, int64(payload.Amount)) {
+		return nil
 	}
 
-	rDao := dao.GetReservationDao(r.Context())
 	pkDao := dao.GetPubkeyDao(r.Context())
 
 	// Check for preloaded region
@@ -45,13 +198,13 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 	}
 	if !preload.EC2InstanceType.ValidateRegion(payload.Region) {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Unsupported region", ErrUnsupportedRegion))
-		return
+		return nil
 	}
 
 	// Either Launch Template or Instance Type must be set. Both can be set too, in that case, instance type overrides the launch template.
 	if payload.InstanceType == "" && payload.LaunchTemplateID == "" {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Both instance type and launch template are missing", ErrBothTypeAndTemplateMissing))
-		return
+		return nil
 	}
 
 	// Validate architecture match.
@@ -59,11 +212,11 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 	it := preload.EC2InstanceType.FindInstanceType(clients.InstanceTypeName(payload.InstanceType))
 	if it == nil {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("unknown type: %s", payload.InstanceType), ErrUnknownInstanceTypeName))
-		return
+		return nil
 	}
 	if !slices.Contains(supportedArches, it.Architecture) {
 		renderError(w, r, payloads.NewWrongArchitectureUserError(r.Context(), ErrArchitectureMismatch))
-		return
+		return nil
 	}
 
 	detail := &models.AWSDetail{
@@ -72,6 +225,15 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 		InstanceType:     payload.InstanceType,
 		Amount:           payload.Amount,
 		PowerOff:         payload.PowerOff,
+		Tags:             payload.Tags,
+		SubnetID:         payload.SubnetID,
+		SecurityGroupIDs: payload.SecurityGroupIDs,
+		NoPublicIP:       payload.NoPublicIP,
+		RootVolumeGB:     payload.RootVolumeGB,
+		VolumeType:       payload.VolumeType,
+		DataDisks:        payload.DataDisks,
+		MarketType:       models.NewMarketType(payload.Spot),
+		SpotMaxPrice:     payload.SpotMaxPrice,
 	}
 	reservation := &models.AWSReservation{
 		PubkeyID: &payload.PubkeyID,
@@ -79,8 +241,10 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 		ImageID:  payload.ImageID,
 		Detail:   detail,
 	}
-	reservation.AccountID = accountId
-	reservation.Status = "Created"
+	reservation.AccountID = identity.AccountId(r.Context())
+	reservation.LaunchAt = launchAt
+	reservation.ExpiresAt = expiresAt
+	reservation.Status = reservation.InitialStatus()
 	reservation.Provider = models.ProviderTypeAWS
 	reservation.Steps = 3
 	reservation.StepTitles = []string{"Ensure public key", "Launch instance(s)", "Fetch instance(s) description"}
@@ -90,6 +254,7 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 	// validate pubkey - must be always present because of data integrity (foreign keys)
 	if reservation.PubkeyID == nil {
 		renderError(w, r, payloads.NewNotFoundError(r.Context(), "could not create AWS reservation", ErrPubkeyNotFound))
+		return nil
 	}
 
 	logger.Debug().Msgf("Validating existence of pubkey %d for this account", *reservation.PubkeyID)
@@ -97,7 +262,7 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		message := fmt.Sprintf("get pubkey with id %d", reservation.PubkeyID)
 		renderNotFoundOrDAOError(w, r, err, message)
-		return
+		return nil
 	}
 	logger.Debug().Msgf("Found pubkey %d named '%s'", pk.ID, pk.Name)
 
@@ -105,19 +270,24 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 	sourcesClient, err := clients.GetSourcesClient(r.Context())
 	if err != nil {
 		renderError(w, r, payloads.NewClientError(r.Context(), err))
-		return
+		return nil
 	}
 
 	// Fetch arn from Sources
 	authentication, err := sourcesClient.GetAuthentication(r.Context(), payload.SourceID)
 	if err != nil {
 		renderError(w, r, payloads.NewClientError(r.Context(), err))
-		return
+		return nil
 	}
 
 	if typeErr := authentication.MustBe(models.ProviderTypeAWS); typeErr != nil {
 		renderError(w, r, payloads.NewClientError(r.Context(), typeErr))
-		return
+		return nil
+	}
+
+	// spot instances are counted against separate spot quotas which are not checked
+	if !payload.Spot && !ensureVCPUQuota(w, r, authentication, payload.Region, it, int64(payload.Amount)) {
+		return nil
 	}
 
 	var ami string
@@ -128,13 +298,13 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 		composeUUID, parseErr := uuid.Parse(reservation.ImageID)
 		if parseErr != nil {
 			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Image ID is not valid, UUID format expected", parseErr))
-			return
+			return nil
 		}
 
 		instanceType := preload.EC2InstanceType.FindInstanceType(clients.InstanceTypeName(reservation.Detail.InstanceType))
 		if instanceType == nil {
 			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Instance type is not a valid AWS EC2 instance type", nil))
-			return
+			return nil
 		}
 
 		// Not prefixed with "ami-" therefore this must be a valid UUID
@@ -143,52 +313,22 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 		logger.Trace().Msg("Creating IB client")
 		if ibErr != nil {
 			renderError(w, r, payloads.NewClientError(r.Context(), ibErr))
-			return
+			return nil
 		}
 
 		// Get AMI
 		ami, ibErr = IBClient.GetAWSAmi(r.Context(), composeUUID, *instanceType)
 		if ibErr != nil {
 			renderError(w, r, payloads.NewClientError(r.Context(), ibErr))
-			return
+			return nil
 		}
 	}
 
-	// The last step: create reservation in the database and submit new job
-	err = rDao.CreateAWS(r.Context(), reservation)
-	if err != nil {
-		renderError(w, r, payloads.NewDAOError(r.Context(), "create reservation", err))
-		return
-	}
-	logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
-
-	launchJob := worker.Job{
-		Type:      jobs.TypeLaunchInstanceAws,
-		Identity:  id,
-		EdgeID:    logging.EdgeRequestId(r.Context()),
-		AccountID: accountId,
-		Args: jobs.LaunchInstanceAWSTaskArgs{
-			ReservationID:    reservation.ID,
-			Region:           reservation.Detail.Region,
-			PubkeyID:         pk.ID,
-			SourceID:         reservation.SourceID,
-			Detail:           reservation.Detail,
-			AMI:              ami,
-			LaunchTemplateID: reservation.Detail.LaunchTemplateID,
-			ARN:              authentication,
-		},
-	}
-
-	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &launchJob)
-	if err != nil {
-		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
-		return
-	}
-	logger.Debug().Msgf("Enqueued reservation job %s", launchJob.ID)
-
-	// Return response payload
-	unused := make([]*models.ReservationInstance, 0, 0)
-	if err := render.Render(w, r, payloads.NewAWSReservationResponse(reservation, unused)); err != nil {
-		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render AWS reservation", err))
+	return &awsLaunch{
+		reservation:    reservation,
+		pubkey:         pk,
+		authentication: authentication,
+		ami:            ami,
+		userData:       userData,
 	}
 }
@@ -7,9 +7,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	Clientstubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/queue/stub"
 	"github.com/RHEnVision/provisioning-backend/internal/services"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
@@ -82,4 +87,281 @@ func TestCreateAWSReservationHandler(t *testing.T) {
 		assert.Contains(t, rr.Body.String(), "Unsupported region")
 		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
 	})
+
+	t.Run("failed reservation with invalid user data", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+			"user_data":     "echo missing shebang",
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "Invalid user data")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("failed reservation with spot price for on-demand instances", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":      "1",
+			"image_id":       "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":         1,
+			"instance_type":  "t1.micro",
+			"pubkey_id":      pk.ID,
+			"spot_max_price": 0.02,
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "Invalid spot options")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("failed reservation exceeding quota", func(t *testing.T) {
+		ctx := Clientstubs.WithEC2Client(ctx)
+
+		var err error
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "ami-random",
+			"amount":        25,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "Quota exceeded")
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("scheduled reservation", func(t *testing.T) {
+		ctx := stub.WithEnqueuer(ctx)
+		launchAt := time.Now().Add(time.Hour).Truncate(time.Second).In(time.FixedZone("CEST", 2*60*60))
+
+		var err error
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "ami-random",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+			"launch_at":     launchAt,
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+		response := payloads.AWSReservationResponse{}
+		err = json.Unmarshal(rr.Body.Bytes(), &response)
+		require.NoError(t, err, "failed to parse the response body")
+		require.NotNil(t, response.LaunchAt, "expected launch time in the response")
+		assert.True(t, launchAt.Equal(*response.LaunchAt))
+
+		reservation, err := dao.GetReservationDao(ctx).GetAWSById(ctx, response.ID)
+		require.NoError(t, err, "reservation has not been created through DAO")
+		assert.Equal(t, models.ReservationStatusScheduled, reservation.Status)
+		assert.Equal(t, time.UTC, reservation.LaunchAt.Time.Location(), "launch time must be stored in UTC")
+
+		require.Len(t, stub.EnqueuedJobs(ctx), 1, "Expected exactly one job to be planned")
+		assert.True(t, launchAt.Equal(stub.EnqueuedJobs(ctx)[0].RunAt), "Job must be delayed until the launch time")
+	})
+
+	t.Run("failed reservation with launch time in the past", func(t *testing.T) {
+		ctx := stub.WithEnqueuer(ctx)
+
+		var err error
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "ami-random",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+			"launch_at":     time.Now().Add(-time.Hour),
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "Invalid launch time")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+		assert.Empty(t, stub.EnqueuedJobs(ctx), "No job must be planned")
+	})
+
+	t.Run("failed reservation with both expiry fields", func(t *testing.T) {
+		ctx := stub.WithEnqueuer(ctx)
+
+		var err error
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "ami-random",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+			"expires_in":    "8h",
+			"expires_at":    time.Now().Add(time.Hour),
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "Invalid expiry")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+		assert.Empty(t, stub.EnqueuedJobs(ctx), "No job must be planned")
+	})
+
+	t.Run("successful reservation with expiry", func(t *testing.T) {
+		ctx := stub.WithEnqueuer(ctx)
+
+		var err error
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "ami-random",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+			"expires_in":    "8h",
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+		assert.Contains(t, rr.Body.String(), "expires_at")
+	})
+}
+
+func TestPreflightAWSReservationHandler(t *testing.T) {
+	var json_data []byte
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = Clientstubs.WithSourcesClient(ctx)
+	ctx = Clientstubs.WithImageBuilderClient(ctx)
+	ctx = Clientstubs.WithEC2Client(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to generate pubkey")
+
+	t.Run("successful preflight", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "ami-random",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws/preflight", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.PreflightAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+		response := payloads.PreflightResponse{}
+		err = json.Unmarshal(rr.Body.Bytes(), &response)
+		require.NoError(t, err, "failed to parse the response body")
+		assert.Equal(t, "ami-random", response.ImageID)
+		assert.True(t, response.DryRun, "expected EC2 dry run")
+
+		stubCount := stubs.AWSReservationStubCount(ctx)
+		assert.Equal(t, 0, stubCount, "Reservation must not be created through DAO")
+	})
+
+	t.Run("failed preflight with missing pubkey", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "ami-random",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID + 1000,
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws/preflight", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.PreflightAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNotFound, rr.Code, "Handler returned wrong status code")
+	})
 }
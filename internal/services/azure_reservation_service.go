@@ -14,8 +14,10 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/jobs"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads/validation"
 	"github.com/RHEnVision/provisioning-backend/internal/preload"
 	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/internal/userdata"
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
@@ -25,14 +27,129 @@ import (
 func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 	logger := zerolog.Ctx(r.Context())
 
+	launch := prepareAzureReservation(w, r)
+	if launch == nil {
+		return
+	}
+	reservation := launch.reservation
+	rDao := dao.GetReservationDao(r.Context())
+
+	// The last step: create reservation in the database and submit new job
+	err := rDao.CreateAzure(r.Context(), reservation)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "create Azure reservation", err))
+		return
+	}
+	logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
+
+	launchJob := worker.Job{
+		Type:      jobs.TypeLaunchInstanceAzure,
+		Identity:  identity.Identity(r.Context()),
+		EdgeID:    logging.EdgeRequestId(r.Context()),
+		AccountID: identity.AccountId(r.Context()),
+		RunAt:     reservation.LaunchAt.Time,
+		Args: jobs.LaunchInstanceAzureTaskArgs{
+			Location:          reservation.Detail.Location,
+			ReservationID:     reservation.ID,
+			ResourceGroupName: reservation.Detail.ResourceGroup,
+			PubkeyID:          launch.pubkey.ID,
+			SourceID:          reservation.SourceID,
+			AzureImageID:      launch.imageID,
+			Subscription:      launch.authentication,
+			Name:              reservation.Detail.Name,
+			UserData:          launch.userData,
+		},
+	}
+
+	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &launchJob)
+	if err != nil {
+		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
+		return
+	}
+	logger.Debug().Msgf("Enqueued reservation job %s", launchJob.ID)
+
+	// Return response payload
+	unused := make([]*models.ReservationInstance, 0, 0)
+	if err = render.Render(w, r, payloads.NewAzureReservationResponse(reservation, unused)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render Azure reservation", err))
+	}
+}
+
+// PreflightAzureReservation runs all validations of CreateAzureReservation without creating the reservation.
+func PreflightAzureReservation(w http.ResponseWriter, r *http.Request) {
+	launch := prepareAzureReservation(w, r)
+	if launch == nil {
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewPreflightResponse(models.ProviderTypeAzure, launch.imageID, false)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render Azure preflight", err))
+	}
+}
+
+// azureLaunch is a validated Azure reservation request with its pubkey, source authentication and resolved image ID.
+type azureLaunch struct {
+	reservation    *models.AzureReservation
+	pubkey         *models.Pubkey
+	authentication *clients.Authentication
+	imageID        string
+	userData       []byte
+}
+
+// prepareAzureReservation binds and validates Azure reservation request, on failure the error is rendered
+// and nil is returned. Nothing is written into the database.
+func prepareAzureReservation(w http.ResponseWriter, r *http.Request) *azureLaunch {
+	logger := zerolog.Ctx(r.Context())
+
 	payload := &payloads.AzureReservationRequest{}
 	if err := render.Bind(r, payload); err != nil {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Azure reservation", err))
-		return
+		return nil
+	}
+
+	launchAt, err := parseLaunchAt(payload.LaunchAt)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid launch time", err))
+		return nil
+	}
+
+	userData := []byte(payload.UserData)
+	if err = userdata.ValidateCustom(userData); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid user data", err))
+		return nil
+	}
+
+	if err = validation.AzureTags(payload.Tags); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid tags", err))
+		return nil
+	}
+
+	if err = validateAzureNetwork(payload.SubnetID, payload.SecurityGroupID); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid network", err))
+		return nil
+	}
+
+	if err = validation.AzureDisks(payload.RootVolumeGB, payload.VolumeType, payload.DataDisks); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid disks", err))
+		return nil
+	}
+
+	if err = validation.SpotMaxPrice(payload.Spot, payload.SpotMaxPrice); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid spot options", err))
+		return nil
+	}
+
+	expiresAt, err := parseExpiry(payload.ExpiresIn, payload.ExpiresAt, launchAt)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid expiry", err))
+		return nil
+	}
+
+	if !ensureAccountLimits(w, r, payload.Amount) {
+		return nil
 	}
 
 	pkDao := dao.GetPubkeyDao(r.Context())
-	rDao := dao.GetReservationDao(r.Context())
 
 	// validate region
 	// it needs to be in region format, but also
@@ -42,7 +159,7 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 			logger.Warn().Msgf("Azure region passed with location suffix (%s), this is deprecated behaviour format", payload.Location)
 		} else {
 			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Unsupported location", ErrUnsupportedRegion))
-			return
+			return nil
 		}
 	}
 
@@ -52,7 +169,7 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		message := fmt.Sprintf("get pubkey with id %d", payload.PubkeyID)
 		renderNotFoundOrDAOError(w, r, err, message)
-		return
+		return nil
 	}
 	logger.Debug().Msgf("Found pubkey %d named '%s'", pk.ID, pk.Name)
 
@@ -60,26 +177,26 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 	sourcesClient, err := clients.GetSourcesClient(r.Context())
 	if err != nil {
 		renderError(w, r, payloads.NewClientError(r.Context(), err))
-		return
+		return nil
 	}
 
 	// Get IB client
 	ibClient, err := clients.GetImageBuilderClient(r.Context())
 	if err != nil {
 		renderError(w, r, payloads.NewClientError(r.Context(), err))
-		return
+		return nil
 	}
 
 	// Fetch SubscriptionID from Sources
 	authentication, err := sourcesClient.GetAuthentication(r.Context(), payload.SourceID)
 	if err != nil {
 		renderError(w, r, payloads.NewClientError(r.Context(), err))
-		return
+		return nil
 	}
 
 	if typeErr := authentication.MustBe(models.ProviderTypeAzure); typeErr != nil {
 		renderError(w, r, payloads.NewClientError(r.Context(), typeErr))
-		return
+		return nil
 	}
 
 	var azureImageName string
@@ -90,14 +207,14 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 		instanceType := preload.AzureInstanceType.FindInstanceType(clients.InstanceTypeName(payload.InstanceSize))
 		if instanceType == nil {
 			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Instance size is not a valid Azure instance size", nil))
-			return
+			return nil
 		}
 
 		var imageResourceGroupName string
 		imageResourceGroupName, azureImageName, err = ibClient.GetAzureImageInfo(r.Context(), composeUUID, *instanceType)
 		if err != nil {
 			renderError(w, r, payloads.NewClientError(r.Context(), err))
-			return
+			return nil
 		}
 		if resourceGroupName == "" {
 			resourceGroupName = imageResourceGroupName
@@ -121,21 +238,35 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 	it := preload.AzureInstanceType.FindInstanceType(clients.InstanceTypeName(payload.InstanceSize))
 	if it == nil {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("unknown instance size: %s", payload.InstanceSize), ErrUnknownInstanceTypeName))
-		return
+		return nil
 	}
 	if it.Architecture.String() != supportedArch {
 		renderError(w, r, payloads.NewWrongArchitectureUserError(r.Context(), ErrArchitectureMismatch))
-		return
+		return nil
+	}
+
+	// spot instances are counted against separate spot quotas which are not checked
+	if !payload.Spot && !ensureVCPUQuota(w, r, authentication, strings.TrimSuffix(payload.Location, "_1"), it, payload.Amount) {
+		return nil
 	}
 
 	name := config.Application.InstancePrefix + payload.Name
 	detail := &models.AzureDetail{
-		Location:      payload.Location,
-		ResourceGroup: resourceGroupName,
-		InstanceSize:  payload.InstanceSize,
-		Amount:        payload.Amount,
-		PowerOff:      payload.PowerOff,
-		Name:          name,
+		Location:        payload.Location,
+		ResourceGroup:   resourceGroupName,
+		InstanceSize:    payload.InstanceSize,
+		Amount:          payload.Amount,
+		PowerOff:        payload.PowerOff,
+		Tags:            payload.Tags,
+		SubnetID:        payload.SubnetID,
+		SecurityGroupID: payload.SecurityGroupID,
+		NoPublicIP:      payload.NoPublicIP,
+		RootVolumeGB:    payload.RootVolumeGB,
+		VolumeType:      payload.VolumeType,
+		DataDisks:       payload.DataDisks,
+		MarketType:      models.NewMarketType(payload.Spot),
+		SpotMaxPrice:    payload.SpotMaxPrice,
+		Name:            name,
 	}
 	reservation := &models.AzureReservation{
 		PubkeyID: &payload.PubkeyID,
@@ -143,44 +274,17 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 		ImageID:  payload.ImageID,
 		Detail:   detail,
 	}
+	reservation.LaunchAt = launchAt
+	reservation.ExpiresAt = expiresAt
+	reservation.Status = reservation.InitialStatus()
 	reservation.Steps = int32(len(jobs.LaunchInstanceAzureSteps))
 	reservation.StepTitles = jobs.LaunchInstanceAzureSteps
 
-	// The last step: create reservation in the database and submit new job
-	err = rDao.CreateAzure(r.Context(), reservation)
-	if err != nil {
-		renderError(w, r, payloads.NewDAOError(r.Context(), "create Azure reservation", err))
-		return
-	}
-	logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
-
-	launchJob := worker.Job{
-		Type:      jobs.TypeLaunchInstanceAzure,
-		Identity:  identity.Identity(r.Context()),
-		EdgeID:    logging.EdgeRequestId(r.Context()),
-		AccountID: identity.AccountId(r.Context()),
-		Args: jobs.LaunchInstanceAzureTaskArgs{
-			Location:          reservation.Detail.Location,
-			ReservationID:     reservation.ID,
-			ResourceGroupName: reservation.Detail.ResourceGroup,
-			PubkeyID:          pk.ID,
-			SourceID:          reservation.SourceID,
-			AzureImageID:      azureImageName,
-			Subscription:      authentication,
-			Name:              name,
-		},
-	}
-
-	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &launchJob)
-	if err != nil {
-		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
-		return
-	}
-	logger.Debug().Msgf("Enqueued reservation job %s", launchJob.ID)
-
-	// Return response payload
-	unused := make([]*models.ReservationInstance, 0, 0)
-	if err = render.Render(w, r, payloads.NewAzureReservationResponse(reservation, unused)); err != nil {
-		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render Azure reservation", err))
+	return &azureLaunch{
+		reservation:    reservation,
+		pubkey:         pk,
+		authentication: authentication,
+		imageID:        azureImageName,
+		userData:       userData,
 	}
 }
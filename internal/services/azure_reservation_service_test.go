@@ -146,6 +146,38 @@ func TestCreateAzureReservationHandler(t *testing.T) {
 		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
 	})
 
+	t.Run("failed reservation with too large OS disk", func(t *testing.T) {
+		ctx := stubs.WithReservationDao(sharedCtx)
+		ctx = stub.WithEnqueuer(ctx)
+
+		var err error
+		values := map[string]interface{}{
+			"source_id":      source.ID,
+			"location":       "eastus",
+			"image_id":       "92ea98f8-7697-472e-80b1-7454fa0e7fa7",
+			"resource_group": "testGroup",
+			"amount":         1,
+			"instance_size":  "Basic_A0",
+			"pubkey_id":      pk.ID,
+			"root_volume_gb": 8192,
+			"volume_type":    "Premium_LRS",
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/azure", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAzureReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "Invalid disks")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
 	t.Run("successful reservation with blank location and resource group", func(t *testing.T) {
 		ctx := stubs.WithReservationDao(sharedCtx)
 		ctx = stub.WithEnqueuer(ctx)
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+var (
+	ErrInvalidProviderFilter = errors.New("provider must be one of: aws, azure, gcp")
+	ErrInvalidStatusFilter   = errors.New("status must be one of: pending, success, failure")
+	ErrInvalidTimeFilter     = errors.New("time must be in RFC 3339 format")
+	ErrInvalidSortBy         = errors.New("unknown sort_by field")
+	ErrInvalidSortDir        = errors.New("sort_dir must be one of: asc, desc")
+	ErrKeysetSortBy          = errors.New("sort_by cannot be used together with token pagination")
+)
+
+// parseReservationFilter reads reservation list filter from query parameters provider, status,
+// created_after, created_before, source_id, name, sort_by and sort_dir.
+func parseReservationFilter(query url.Values) (*dao.ReservationFilter, error) {
+	filter := &dao.ReservationFilter{
+		SourceID: query.Get("source_id"),
+		Name:     query.Get("name"),
+	}
+
+	if provider := query.Get("provider"); provider != "" {
+		filter.Provider = models.ProviderTypeFromString(provider)
+		if filter.Provider == models.ProviderTypeUnknown || filter.Provider == models.ProviderTypeNoop {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidProviderFilter, provider)
+		}
+	}
+
+	switch status := dao.ReservationResult(strings.ToLower(query.Get("status"))); status {
+	case "", dao.ReservationPending, dao.ReservationSuccess, dao.ReservationFailure:
+		filter.Status = status
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidStatusFilter, status)
+	}
+
+	var err error
+	if filter.CreatedAfter, err = parseTimeParam(query, "created_after"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedBefore, err = parseTimeParam(query, "created_before"); err != nil {
+		return nil, err
+	}
+
+	if filter.SortBy, filter.SortDir, err = parseSort(query, dao.ReservationSortFields); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// parsePubkeyFilter reads pubkey list filter from query parameters name, sort_by and sort_dir.
+func parsePubkeyFilter(query url.Values) (*dao.PubkeyFilter, error) {
+	filter := &dao.PubkeyFilter{
+		Name: query.Get("name"),
+	}
+
+	var err error
+	if filter.SortBy, filter.SortDir, err = parseSort(query, dao.PubkeySortFields); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// parseTimeParam returns zero time when the parameter is not present.
+func parseTimeParam(query url.Values, param string) (time.Time, error) {
+	str := query.Get(param)
+	if str == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidTimeFilter, param)
+	}
+	return t.UTC(), nil
+}
+
+func parseSort(query url.Values, fields []string) (string, dao.SortDirection, error) {
+	sortBy := strings.ToLower(query.Get("sort_by"))
+	if sortBy != "" && !slices.Contains(fields, sortBy) {
+		return "", "", fmt.Errorf("%w: %s, must be one of: %s", ErrInvalidSortBy, sortBy, strings.Join(fields, ", "))
+	}
+
+	sortDir := dao.SortDirection(strings.ToLower(query.Get("sort_dir")))
+	switch sortDir {
+	case "", dao.SortAsc, dao.SortDesc:
+	default:
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidSortDir, sortDir)
+	}
+
+	return sortBy, sortDir, nil
+}
@@ -18,7 +18,9 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/jobs"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads/validation"
 	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/internal/userdata"
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/go-chi/render"
 )
@@ -29,19 +31,128 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 	accountId := identity.AccountId(r.Context())
 	id := identity.Identity(r.Context())
 
+	launch := prepareGCPReservation(w, r)
+	if launch == nil {
+		return
+	}
+	reservation := launch.reservation
+	rDao := dao.GetReservationDao(r.Context())
+
+	// The last step: create reservation in the database and submit new job
+	err := rDao.CreateGCP(r.Context(), reservation)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "create reservation", err))
+		return
+	}
+	logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
+
+	launchJob := worker.Job{
+		Type:      jobs.TypeLaunchInstanceGcp,
+		AccountID: accountId,
+		EdgeID:    logging.EdgeRequestId(r.Context()),
+		Identity:  id,
+		RunAt:     reservation.LaunchAt.Time,
+		Args: jobs.LaunchInstanceGCPTaskArgs{
+			ReservationID:    reservation.ID,
+			Zone:             reservation.Detail.Zone,
+			PubkeyID:         *reservation.PubkeyID,
+			Detail:           reservation.Detail,
+			ImageName:        launch.imageName,
+			ProjectID:        launch.authentication,
+			LaunchTemplateID: reservation.Detail.LaunchTemplateID,
+			UserData:         launch.userData,
+		},
+	}
+
+	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &launchJob)
+	if err != nil {
+		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
+		return
+	}
+	logger.Debug().Msgf("Enqueued reservation job %s", launchJob.ID)
+
+	unused := make([]*models.ReservationInstance, 0, 0)
+	// Return response payload
+	if err := render.Render(w, r, payloads.NewGCPReservationResponse(reservation, unused)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation", err))
+		return
+	}
+}
+
+// PreflightGCPReservation runs all validations of CreateGCPReservation without creating the reservation.
+func PreflightGCPReservation(w http.ResponseWriter, r *http.Request) {
+	launch := prepareGCPReservation(w, r)
+	if launch == nil {
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewPreflightResponse(models.ProviderTypeGCP, launch.imageName, false)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render GCP preflight", err))
+	}
+}
+
+// gcpLaunch is a validated GCP reservation request with its source authentication and resolved image name.
+type gcpLaunch struct {
+	reservation    *models.GCPReservation
+	authentication *clients.Authentication
+	imageName      string
+	userData       []byte
+}
+
+// prepareGCPReservation binds and validates GCP reservation request, on failure the error is rendered
+// and nil is returned. Nothing is written into the database.
+func prepareGCPReservation(w http.ResponseWriter, r *http.Request) *gcpLaunch {
+	logger := zerolog.Ctx(r.Context())
+
 	payload := &payloads.GCPReservationRequest{}
 	if err := render.Bind(r, payload); err != nil {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "GCP reservation", err))
-		return
+		return nil
+	}
+
+	launchAt, err := parseLaunchAt(payload.LaunchAt)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid launch time", err))
+		return nil
+	}
+
+	userData := []byte(payload.UserData)
+	if err = userdata.ValidateCustom(userData); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid user data", err))
+		return nil
+	}
+
+	if err = validation.GCPLabels(payload.Tags); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid tags", err))
+		return nil
+	}
+
+	if err = validateGCPNetwork(payload.Network, payload.Subnetwork, payload.NetworkTags); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid network", err))
+		return nil
+	}
+
+	if err = validation.GCPDisks(payload.RootVolumeGB, payload.VolumeType, payload.DataDisks); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid disks", err))
+		return nil
+	}
+
+	expiresAt, err := parseExpiry(payload.ExpiresIn, payload.ExpiresAt, launchAt)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid expiry", err))
+		return nil
+	}
+
+	if !ensureAccountLimits(w, r, payload.Amount) {
+		return nil
 	}
 
-	rDao := dao.GetReservationDao(r.Context())
 	pkDao := dao.GetPubkeyDao(r.Context())
 
 	// Check for preloaded region
 	if !preload.GCPInstanceType.ValidateRegion(payload.Zone) {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Unsupported zone", ErrUnsupportedRegion))
-		return
+		return nil
 	}
 
 	namePattern := "inst-####"
@@ -52,7 +163,7 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 			namePattern = fmt.Sprintf("%s-#####", payload.NamePattern)
 		} else {
 			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid name pattern", ErrInvalidNamePattern))
-			return
+			return nil
 		}
 	}
 
@@ -63,6 +174,15 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 		MachineType:      payload.MachineType,
 		Amount:           payload.Amount,
 		PowerOff:         payload.PowerOff,
+		Tags:             payload.Tags,
+		Network:          payload.Network,
+		Subnetwork:       payload.Subnetwork,
+		NetworkTags:      payload.NetworkTags,
+		NoPublicIP:       payload.NoPublicIP,
+		RootVolumeGB:     payload.RootVolumeGB,
+		VolumeType:       payload.VolumeType,
+		DataDisks:        payload.DataDisks,
+		MarketType:       models.NewMarketType(payload.Spot),
 		UUID:             resUUID,
 		LaunchTemplateID: payload.LaunchTemplateID,
 	}
@@ -73,14 +193,17 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 		Detail:   detail,
 	}
 
-	reservation.AccountID = accountId
-	reservation.Status = "Created"
+	reservation.AccountID = identity.AccountId(r.Context())
+	reservation.LaunchAt = launchAt
+	reservation.ExpiresAt = expiresAt
+	reservation.Status = reservation.InitialStatus()
 	reservation.Provider = models.ProviderTypeGCP
 	reservation.Steps = 2
 	reservation.StepTitles = jobs.LaunchInstanceGCPSteps
 
 	if reservation.PubkeyID == nil {
 		renderError(w, r, payloads.NewNotFoundError(r.Context(), "could not create AWS reservation", ErrPubkeyNotFound))
+		return nil
 	}
 
 	logger.Debug().Msgf("Validating existence of pubkey %d for this account", *reservation.PubkeyID)
@@ -88,7 +211,7 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		message := fmt.Sprintf("get pubkey with id %d", *reservation.PubkeyID)
 		renderNotFoundOrDAOError(w, r, err, message)
-		return
+		return nil
 	}
 	logger.Debug().Msgf("Found pubkey %d named '%s'", pk.ID, pk.Name)
 
@@ -96,19 +219,25 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 	sourcesClient, err := clients.GetSourcesClient(r.Context())
 	if err != nil {
 		renderError(w, r, payloads.NewClientError(r.Context(), err))
-		return
+		return nil
 	}
 
 	// Fetch project id from Sources
 	authentication, err := sourcesClient.GetAuthentication(r.Context(), payload.SourceID)
 	if err != nil {
 		renderError(w, r, payloads.NewClientError(r.Context(), err))
-		return
+		return nil
 	}
 
 	if typeErr := authentication.MustBe(models.ProviderTypeGCP); typeErr != nil {
 		renderError(w, r, payloads.NewClientError(r.Context(), typeErr))
-		return
+		return nil
+	}
+
+	machineType := preload.GCPInstanceType.FindInstanceType(clients.InstanceTypeName(payload.MachineType))
+	// spot instances are counted against separate spot quotas which are not checked
+	if !payload.Spot && !ensureVCPUQuota(w, r, authentication, payload.Zone, machineType, payload.Amount) {
+		return nil
 	}
 
 	// Get Image builder client
@@ -116,7 +245,7 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 	logger.Trace().Msg("Creating IB client")
 	if ibErr != nil {
 		renderError(w, r, payloads.NewClientError(r.Context(), ibErr))
-		return
+		return nil
 	}
 
 	// Validate image
@@ -127,13 +256,13 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 		instanceType := preload.GCPInstanceType.FindInstanceType(clients.InstanceTypeName(payload.MachineType))
 		if instanceType == nil {
 			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Machine type is not a valid GCP machine type", nil))
-			return
+			return nil
 		}
 
 		name, ibErr = ibc.GetGCPImageName(r.Context(), composeUUID, *instanceType)
 		if ibErr != nil {
 			renderError(w, r, payloads.NewClientError(r.Context(), ibErr))
-			return
+			return nil
 		}
 
 		logger.Trace().Msgf("Image Name is %s", name)
@@ -142,42 +271,11 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 		name = payload.ImageID
 	}
 
-	// The last step: create reservation in the database and submit new job
-	err = rDao.CreateGCP(r.Context(), reservation)
-	if err != nil {
-		renderError(w, r, payloads.NewDAOError(r.Context(), "create reservation", err))
-		return
-	}
-	logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
-
-	launchJob := worker.Job{
-		Type:      jobs.TypeLaunchInstanceGcp,
-		AccountID: accountId,
-		EdgeID:    logging.EdgeRequestId(r.Context()),
-		Identity:  id,
-		Args: jobs.LaunchInstanceGCPTaskArgs{
-			ReservationID:    reservation.ID,
-			Zone:             reservation.Detail.Zone,
-			PubkeyID:         *reservation.PubkeyID,
-			Detail:           reservation.Detail,
-			ImageName:        name,
-			ProjectID:        authentication,
-			LaunchTemplateID: reservation.Detail.LaunchTemplateID,
-		},
-	}
-
-	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &launchJob)
-	if err != nil {
-		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
-		return
-	}
-	logger.Debug().Msgf("Enqueued reservation job %s", launchJob.ID)
-
-	unused := make([]*models.ReservationInstance, 0, 0)
-	// Return response payload
-	if err := render.Render(w, r, payloads.NewGCPReservationResponse(reservation, unused)); err != nil {
-		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation", err))
-		return
+	return &gcpLaunch{
+		reservation:    reservation,
+		authentication: authentication,
+		imageName:      name,
+		userData:       userData,
 	}
 }
 
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog"
+)
+
+var ErrUnknownInstanceAction = errors.New("unknown instance action, must be one of: stop, start, reboot, terminate")
+
+// CreateInstanceAction enqueues a lifecycle action (stop, start, reboot, terminate) for
+// a single instance of a reservation. The result is recorded on the reservation instance.
+func CreateInstanceAction(w http.ResponseWriter, r *http.Request) {
+	logger := zerolog.Ctx(r.Context())
+
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	// Azure instance IDs are full resource paths and must be URL-encoded
+	instanceId, err := url.PathUnescape(chi.URLParam(r, "INSTANCE_ID"))
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse INSTANCE_ID parameter", err))
+		return
+	}
+
+	payload := &payloads.InstanceActionRequest{}
+	if err = render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "instance action", err))
+		return
+	}
+
+	action := models.InstanceActionFromString(payload.Action)
+	if action == models.InstanceActionUnknown {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("unknown action: %s", payload.Action), ErrUnknownInstanceAction))
+		return
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	reservation, err := rDao.GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation for instance action")
+		return
+	}
+
+	// Check permission for individual provider type
+	if CheckPermissionAndRender(w, r, "write", "reservation", reservation.Provider.String()) != nil {
+		return
+	}
+
+	instance, err := rDao.GetInstance(r.Context(), id, instanceId)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation instance")
+		return
+	}
+
+	var sourceId, location string
+	var jobType worker.JobType
+	switch reservation.Provider {
+	case models.ProviderTypeAWS:
+		reservationAws, err := rDao.GetAWSById(r.Context(), id)
+		if err != nil {
+			renderNotFoundOrDAOError(w, r, err, fmt.Sprintf("get AWS reservation with id %d", id))
+			return
+		}
+		sourceId = reservationAws.SourceID
+		location = reservationAws.Detail.Region
+		jobType = jobs.TypeInstanceActionAws
+	case models.ProviderTypeAzure:
+		reservationAzure, err := rDao.GetAzureById(r.Context(), id)
+		if err != nil {
+			renderNotFoundOrDAOError(w, r, err, fmt.Sprintf("get Azure reservation with id %d", id))
+			return
+		}
+		sourceId = reservationAzure.SourceID
+		jobType = jobs.TypeInstanceActionAzure
+	case models.ProviderTypeGCP:
+		reservationGCP, err := rDao.GetGCPById(r.Context(), id)
+		if err != nil {
+			renderNotFoundOrDAOError(w, r, err, fmt.Sprintf("get GCP reservation with id %d", id))
+			return
+		}
+		sourceId = reservationGCP.SourceID
+		location = reservationGCP.Detail.Zone
+		jobType = jobs.TypeInstanceActionGcp
+	case models.ProviderTypeNoop, models.ProviderTypeUnknown:
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", ErrProviderTypeNotImplemented))
+		return
+	default:
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", ErrProviderTypeNotImplemented))
+		return
+	}
+
+	sourcesClient, err := clients.GetSourcesClient(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	authentication, err := sourcesClient.GetAuthentication(r.Context(), sourceId)
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	if typeErr := authentication.MustBe(reservation.Provider); typeErr != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), typeErr))
+		return
+	}
+
+	instance.LastAction = action
+	instance.LastActionStatus = models.InstanceActionStatusPending
+	instance.LastActionError = ""
+	err = rDao.UpdateInstanceAction(r.Context(), instance)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "update instance action", err))
+		return
+	}
+
+	actionJob := worker.Job{
+		Type:      jobType,
+		Identity:  identity.Identity(r.Context()),
+		EdgeID:    logging.EdgeRequestId(r.Context()),
+		AccountID: identity.AccountId(r.Context()),
+		Args: jobs.InstanceActionTaskArgs{
+			ReservationID:  id,
+			InstanceID:     instanceId,
+			Action:         action,
+			Location:       location,
+			Authentication: authentication,
+		},
+	}
+	if action == models.InstanceActionReboot {
+		// a failed attempt could have rebooted the instance already
+		actionJob.MaxRetries = worker.NoRetries
+	}
+
+	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &actionJob)
+	if err != nil {
+		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
+		return
+	}
+	logger.Debug().Msgf("Enqueued instance action %s job %s", action, actionJob.ID)
+
+	if err := render.Render(w, r, payloads.NewInstanceActionResponse(instance)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render instance action", err))
+	}
+}
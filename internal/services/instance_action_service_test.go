@@ -0,0 +1,125 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http/rbac"
+	Clientstubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/queue/stub"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	tidentity "github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateInstanceAction(t *testing.T) {
+	sharedCtx := stubs.WithAccountDaoOne(context.Background())
+	sharedCtx = tidentity.WithTenant(t, sharedCtx)
+	sharedCtx = Clientstubs.WithSourcesClient(sharedCtx)
+	sharedCtx = stubs.WithPubkeyDao(sharedCtx)
+	sharedCtx = rbac.WithAcl(sharedCtx, clients.AllPermissionsRbacAcl)
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(sharedCtx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	prepare := func(t *testing.T) context.Context {
+		t.Helper()
+		ctx := stubs.WithReservationDao(sharedCtx)
+		ctx = stub.WithEnqueuer(ctx)
+
+		reservation := &models.AWSReservation{
+			PubkeyID: &pk.ID,
+			SourceID: "1",
+			ImageID:  "ami-random",
+			Detail: &models.AWSDetail{
+				Region:       "us-east-1",
+				InstanceType: "t1.micro",
+				Amount:       1,
+			},
+		}
+		reservation.AccountID = identity.AccountId(ctx)
+		reservation.Status = "Finished"
+		reservation.Provider = models.ProviderTypeAWS
+		reservation.Steps = 2
+		err := stubs.AddAWSReservation(ctx, reservation)
+		require.NoError(t, err, "failed to create stub reservation")
+
+		err = stubs.AddReservationInstance(ctx, &models.ReservationInstance{ReservationID: reservation.ID, InstanceID: "i-1234567890"})
+		require.NoError(t, err, "failed to create stub reservation instance")
+		return ctx
+	}
+
+	request := func(t *testing.T, ctx context.Context, instanceId, action string) *httptest.ResponseRecorder {
+		t.Helper()
+		jsonData, err := json.Marshal(map[string]interface{}{"action": action})
+		require.NoError(t, err, "unable to marshal values to json")
+
+		rctx := chi.NewRouteContext()
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		rctx.URLParams.Add("ID", "1")
+		rctx.URLParams.Add("INSTANCE_ID", instanceId)
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/v1/reservations/1/instances/"+instanceId+"/actions", bytes.NewBuffer(jsonData))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateInstanceAction)
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("successful stop", func(t *testing.T) {
+		ctx := prepare(t)
+		rr := request(t, ctx, "i-1234567890", "stop")
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var response payloads.InstanceActionResponse
+		err := json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err, "failed to decode response body")
+		assert.Equal(t, "stop", response.Instance.LastAction)
+		assert.Equal(t, "pending", response.Instance.LastActionStatus)
+
+		require.Len(t, stub.EnqueuedJobs(ctx), 1, "Expected exactly one job to be planned")
+		assert.Equal(t, jobs.TypeInstanceActionAws, stub.EnqueuedJobs(ctx)[0].Type)
+		jobArgs := stub.EnqueuedJobs(ctx)[0].Args.(jobs.InstanceActionTaskArgs)
+		assert.Equal(t, models.InstanceActionStop, jobArgs.Action)
+		assert.Equal(t, "us-east-1", jobArgs.Location)
+		assert.Zero(t, stub.EnqueuedJobs(ctx)[0].MaxRetries, "Expected the worker default of retries")
+	})
+
+	t.Run("reboot is not retried", func(t *testing.T) {
+		ctx := prepare(t)
+		rr := request(t, ctx, "i-1234567890", "reboot")
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+		require.Len(t, stub.EnqueuedJobs(ctx), 1, "Expected exactly one job to be planned")
+		assert.Equal(t, worker.NoRetries, stub.EnqueuedJobs(ctx)[0].MaxRetries)
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		ctx := prepare(t)
+		rr := request(t, ctx, "i-1234567890", "explode")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+		assert.Empty(t, stub.EnqueuedJobs(ctx), "No job should be planned")
+	})
+
+	t.Run("unknown instance", func(t *testing.T) {
+		ctx := prepare(t)
+		rr := request(t, ctx, "i-unknown", "reboot")
+		require.Equal(t, http.StatusNotFound, rr.Code, "Handler returned wrong status code")
+		assert.Empty(t, stub.EnqueuedJobs(ctx), "No job should be planned")
+	})
+}
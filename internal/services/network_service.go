@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+var (
+	ErrInvalidSubnetID        = errors.New("invalid subnet ID")
+	ErrInvalidSecurityGroupID = errors.New("invalid security group ID")
+	ErrInvalidNetworkName     = errors.New("invalid network name")
+	ErrInvalidNetworkTag      = errors.New("invalid network tag")
+	ErrTooManySecurityGroups  = errors.New("too many security groups")
+)
+
+const (
+	// maxAWSSecurityGroups is the default limit of security groups per network interface
+	maxAWSSecurityGroups = 5
+
+	// maxGCPNetworkTags is the limit of network tags per instance
+	maxGCPNetworkTags = 64
+)
+
+var (
+	azureSubnetIDRe        = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourceGroups/[^/]+/providers/Microsoft\.Network/virtualNetworks/[^/]+/subnets/[^/]+$`)
+	azureSecurityGroupIDRe = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourceGroups/[^/]+/providers/Microsoft\.Network/networkSecurityGroups/[^/]+$`)
+)
+
+// GetSourceNetworks returns networks, subnets and security groups of the source account in the region
+// given by the region query parameter (AWS region, Azure location or GCP region or zone).
+func GetSourceNetworks(w http.ResponseWriter, r *http.Request) {
+	sourceId := chi.URLParam(r, "ID")
+	if err := validation.DigitsOnly(sourceId); err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "id parameter invalid", err))
+		return
+	}
+
+	region := r.URL.Query().Get("region")
+	if region == "" {
+		renderError(w, r, payloads.NewMissingRequestParameterError(r.Context(), "region parameter is missing"))
+		return
+	}
+
+	sourcesClient, err := clients.GetSourcesClient(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	authentication, err := sourcesClient.GetAuthentication(r.Context(), sourceId)
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	networks, err := listNetworks(r.Context(), authentication, region)
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewNetworkListResponse(region, networks)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render networks", err))
+	}
+}
+
+// listNetworks fetches networks of the source account in the region, GCP zones are accepted too.
+func listNetworks(ctx context.Context, auth *clients.Authentication, region string) ([]*clients.Network, error) {
+	switch auth.ProviderType {
+	case models.ProviderTypeAWS:
+		ec2Client, err := clients.GetEC2Client(ctx, auth, region)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get AWS EC2 client: %w", err)
+		}
+		networks, err := ec2Client.ListNetworks(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list AWS networks: %w", err)
+		}
+		return networks, nil
+	case models.ProviderTypeAzure:
+		azureClient, err := clients.GetAzureClient(ctx, auth)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get Azure client: %w", err)
+		}
+		networks, err := azureClient.ListNetworks(ctx, region)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list Azure networks: %w", err)
+		}
+		return networks, nil
+	case models.ProviderTypeGCP:
+		gcpClient, err := clients.GetGCPClient(ctx, auth)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get GCP client: %w", err)
+		}
+		networks, err := gcpClient.ListNetworks(ctx, clients.GCPRegion(region))
+		if err != nil {
+			return nil, fmt.Errorf("unable to list GCP networks: %w", err)
+		}
+		return networks, nil
+	case models.ProviderTypeNoop, models.ProviderTypeUnknown:
+	}
+	return nil, fmt.Errorf("%w: %s", clients.ErrUnknownProvider, auth.ProviderType)
+}
+
+// validateAWSNetwork checks format of subnet and security group IDs, empty values are valid.
+func validateAWSNetwork(subnetID string, securityGroupIDs []string) error {
+	if subnetID != "" && !strings.HasPrefix(subnetID, "subnet-") {
+		return fmt.Errorf("%w: %s", ErrInvalidSubnetID, subnetID)
+	}
+	if len(securityGroupIDs) > maxAWSSecurityGroups {
+		return fmt.Errorf("%w: at most %d allowed", ErrTooManySecurityGroups, maxAWSSecurityGroups)
+	}
+	for _, id := range securityGroupIDs {
+		if !strings.HasPrefix(id, "sg-") {
+			return fmt.Errorf("%w: %s", ErrInvalidSecurityGroupID, id)
+		}
+	}
+	return nil
+}
+
+// validateAzureNetwork checks that subnet and network security group are full Azure resource IDs,
+// empty values are valid.
+func validateAzureNetwork(subnetID, securityGroupID string) error {
+	if subnetID != "" && !azureSubnetIDRe.MatchString(subnetID) {
+		return fmt.Errorf("%w: %s", ErrInvalidSubnetID, subnetID)
+	}
+	if securityGroupID != "" && !azureSecurityGroupIDRe.MatchString(securityGroupID) {
+		return fmt.Errorf("%w: %s", ErrInvalidSecurityGroupID, securityGroupID)
+	}
+	return nil
+}
+
+// validateGCPNetwork checks that network, subnetwork and network tags are RFC-1035 names, empty
+// values are valid.
+func validateGCPNetwork(network, subnetwork string, tags []string) error {
+	if network != "" && !isValidNamePattern(network) {
+		return fmt.Errorf("%w: %s", ErrInvalidNetworkName, network)
+	}
+	if subnetwork != "" && !isValidNamePattern(subnetwork) {
+		return fmt.Errorf("%w: %s", ErrInvalidSubnetID, subnetwork)
+	}
+	if len(tags) > maxGCPNetworkTags {
+		return fmt.Errorf("%w: at most %d allowed", ErrInvalidNetworkTag, maxGCPNetworkTags)
+	}
+	for _, tag := range tags {
+		if !isValidNamePattern(tag) {
+			return fmt.Errorf("%w: %s", ErrInvalidNetworkTag, tag)
+		}
+	}
+	return nil
+}
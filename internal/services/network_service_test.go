@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	clientStub "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSourceNetworks(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStub.WithSourcesClient(ctx)
+	ctx = clientStub.WithEC2Client(ctx)
+	ctx = clientStub.WithGCPCCustomerClient(ctx)
+
+	getNetworks := func(t *testing.T, provider models.ProviderType, query string) (*httptest.ResponseRecorder, *payloads.NetworkListResponse) {
+		t.Helper()
+		source, err := clientStub.AddSource(ctx, provider)
+		require.NoError(t, err, "failed to add stubbed source")
+
+		rctx := chi.NewRouteContext()
+		ctx := context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		rctx.URLParams.Add("ID", source.ID)
+		req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("/api/provisioning/sources/%s/networks?%s", source.ID, query), nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(GetSourceNetworks)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			return rr, nil
+		}
+		result := &payloads.NetworkListResponse{}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(result), "failed to decode response body")
+		return rr, result
+	}
+
+	t.Run("aws", func(t *testing.T) {
+		_, result := getNetworks(t, models.ProviderTypeAWS, "region=us-east-1")
+		require.NotNil(t, result)
+		require.Len(t, result.Data, 1)
+		assert.True(t, result.Data[0].Default)
+		require.Len(t, result.Data[0].Subnets, 1)
+		assert.Equal(t, "subnet-0a1b2c3d", result.Data[0].Subnets[0].ID)
+		require.Len(t, result.Data[0].SecurityGroups, 1)
+	})
+
+	t.Run("gcp", func(t *testing.T) {
+		_, result := getNetworks(t, models.ProviderTypeGCP, "region=us-central1-a")
+		require.NotNil(t, result)
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, "http-server", result.Data[0].SecurityGroups[0].ID)
+	})
+
+	t.Run("missing region", func(t *testing.T) {
+		rr, _ := getNetworks(t, models.ProviderTypeAWS, "")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
+
+func TestValidateNetwork(t *testing.T) {
+	require.NoError(t, validateAWSNetwork("", nil))
+	require.NoError(t, validateAWSNetwork("subnet-0a1b2c3d", []string{"sg-0a1b2c3d"}))
+	require.ErrorIs(t, validateAWSNetwork("vpc-0a1b2c3d", nil), ErrInvalidSubnetID)
+	require.ErrorIs(t, validateAWSNetwork("", []string{"default"}), ErrInvalidSecurityGroupID)
+	require.ErrorIs(t, validateAWSNetwork("", []string{"sg-1", "sg-2", "sg-3", "sg-4", "sg-5", "sg-6"}), ErrTooManySecurityGroups)
+
+	subnet := "/subscriptions/4b9d213f/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/default"
+	nsg := "/subscriptions/4b9d213f/resourceGroups/rg/providers/Microsoft.Network/networkSecurityGroups/nsg"
+	require.NoError(t, validateAzureNetwork("", ""))
+	require.NoError(t, validateAzureNetwork(subnet, nsg))
+	require.ErrorIs(t, validateAzureNetwork("default", ""), ErrInvalidSubnetID)
+	require.ErrorIs(t, validateAzureNetwork(subnet, subnet), ErrInvalidSecurityGroupID)
+
+	require.NoError(t, validateGCPNetwork("", "", nil))
+	require.NoError(t, validateGCPNetwork("prod", "prod-us", []string{"http-server"}))
+	require.ErrorIs(t, validateGCPNetwork("Prod", "", nil), ErrInvalidNetworkName)
+	require.ErrorIs(t, validateGCPNetwork("", "", []string{"http_server"}), ErrInvalidNetworkTag)
+}
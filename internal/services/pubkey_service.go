@@ -7,19 +7,26 @@ import (
 
 	"github.com/go-playground/validator/v10"
 
-	"github.com/RHEnVision/provisioning-backend/internal/clients"
-	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/page"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/internal/ssh"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/go-chi/render"
 	"github.com/go-playground/mold/v4"
 	"github.com/rs/zerolog"
 )
 
-var ErrMissingNameOrBody = errors.New("name or body missing")
+var (
+	ErrMissingNameOrBody = errors.New("name or body missing")
+	ErrMissingName       = errors.New("name missing")
+)
 
 func CreatePubkey(w http.ResponseWriter, r *http.Request) {
 	payload := &payloads.PubkeyRequest{}
@@ -36,17 +43,8 @@ func CreatePubkey(w http.ResponseWriter, r *http.Request) {
 
 	pk := payload.NewModel()
 	err := pkDao.Create(r.Context(), pk)
-	var validationError validator.ValidationErrors
-	var transformValueError *mold.ErrInvalidTransformValue
-	var transformationError *mold.ErrInvalidTransformation
 	if err != nil {
-		if db.IsPostgresError(err, db.UniqueConstraintErrorCode) != nil {
-			renderError(w, r, payloads.PubkeyDuplicateError(r.Context(), "pubkey with such name or fingerprint already exists for this account", err))
-		} else if errors.As(err, &validationError) || errors.As(err, &transformValueError) || errors.As(err, &transformationError) {
-			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "validation error", err))
-		} else {
-			renderError(w, r, payloads.NewDAOError(r.Context(), "create pubkey", err))
-		}
+		renderPubkeySaveError(w, r, "create pubkey", err)
 		return
 	}
 
@@ -55,19 +53,89 @@ func CreatePubkey(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GeneratePubkey generates a new key pair and stores its public part as a pubkey. The private key
+// is not stored, it is only returned in the response.
+func GeneratePubkey(w http.ResponseWriter, r *http.Request) {
+	payload := &payloads.PubkeyGenerateRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "generate pubkey", err))
+		return
+	}
+
+	if payload.Name == "" {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), ErrMissingName.Error(), ErrMissingName))
+		return
+	}
+	if payload.Type == "" {
+		payload.Type = "ssh-ed25519"
+	}
+
+	keyPair, err := ssh.GenerateKeyPair(payload.Type, payload.Name)
+	if errors.Is(err, ssh.ErrUnsupportedKeyType) {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unsupported key type", err))
+		return
+	} else if err != nil {
+		renderError(w, r, payloads.NewResponseError(r.Context(), http.StatusInternalServerError, "unable to generate key pair", err))
+		return
+	}
+
+	pkDao := dao.GetPubkeyDao(r.Context())
+
+	pk := &models.Pubkey{
+		Name: payload.Name,
+		Body: keyPair.PublicKey,
+	}
+	err = pkDao.Create(r.Context(), pk)
+	if err != nil {
+		renderPubkeySaveError(w, r, "create pubkey", err)
+		return
+	}
+
+	// the private key must not be stored by any cache
+	w.Header().Set("Cache-Control", "no-store")
+	if err := render.Render(w, r, payloads.NewPubkeyGenerateResponse(pk, keyPair)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkey", err))
+	}
+}
+
+// renderPubkeySaveError renders duplicate, validation or DAO error of a pubkey create or update.
+func renderPubkeySaveError(w http.ResponseWriter, r *http.Request, action string, err error) {
+	var validationError validator.ValidationErrors
+	var transformValueError *mold.ErrInvalidTransformValue
+	var transformationError *mold.ErrInvalidTransformation
+	if db.IsPostgresError(err, db.UniqueConstraintErrorCode) != nil {
+		renderError(w, r, payloads.PubkeyDuplicateError(r.Context(), "pubkey with such name or fingerprint already exists for this account", err))
+	} else if errors.As(err, &validationError) || errors.As(err, &transformValueError) || errors.As(err, &transformationError) {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "validation error", err))
+	} else {
+		renderError(w, r, payloads.NewDAOError(r.Context(), action, err))
+	}
+}
+
 func ListPubkeys(w http.ResponseWriter, r *http.Request) {
 	pubkeyDao := dao.GetPubkeyDao(r.Context())
 
 	offset := page.Offset(r.Context()).Int64()
 	limit := page.Limit(r.Context()).Int64()
 
-	pubkeys, err := pubkeyDao.List(r.Context(), limit, offset)
+	filter, err := parsePubkeyFilter(r.URL.Query())
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid pubkey filter", err))
+		return
+	}
+
+	if r.URL.Query().Has("token") {
+		listPubkeysKeyset(w, r, filter)
+		return
+	}
+
+	pubkeys, err := pubkeyDao.List(r.Context(), filter, limit, offset)
 	if err != nil {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "list pubkeys", err))
 		return
 	}
 
-	totalPubkeys, err := pubkeyDao.Count(r.Context())
+	totalPubkeys, err := pubkeyDao.Count(r.Context(), filter)
 	if err != nil {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "count pubkeys", err))
 		return
@@ -81,6 +149,38 @@ func ListPubkeys(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// listPubkeysKeyset lists pubkeys ordered by creation time with cursor tokens instead of offsets,
+// pages are stable when new pubkeys are created meanwhile.
+func listPubkeysKeyset(w http.ResponseWriter, r *http.Request, filter *dao.PubkeyFilter) {
+	if filter.SortBy != "" {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid pubkey filter", ErrKeysetSortBy))
+		return
+	}
+
+	after, err := page.DecodeCursor(page.Token(r.Context()))
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid page token", err))
+		return
+	}
+
+	limit := page.Limit(r.Context()).Int()
+	pubkeys, err := dao.GetPubkeyDao(r.Context()).ListKeyset(r.Context(), filter, after, int64(limit)+1)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list pubkeys", err))
+		return
+	}
+
+	pubkeys, nextToken := page.NextCursor(pubkeys, limit, func(pk *models.Pubkey) *page.Cursor {
+		return &page.Cursor{CreatedAt: pk.CreatedAt, ID: pk.ID}
+	})
+	meta := page.NewTokenMetadata(r.Context(), r, nextToken)
+
+	if err := render.Render(w, r, payloads.NewPubkeyListResponse(pubkeys, meta)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkeys list", err))
+		return
+	}
+}
+
 func GetPubkey(w http.ResponseWriter, r *http.Request) {
 	id, err := ParseInt64(r, "ID")
 	if err != nil {
@@ -102,14 +202,86 @@ func GetPubkey(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func DeletePubkey(w http.ResponseWriter, r *http.Request) {
+// UpdatePubkey changes name or body of a pubkey. When the body changes, resources uploaded
+// to clouds are removed from the database so the next launch imports the new key, and a job
+// deleting the outdated keys from clouds is enqueued.
+func UpdatePubkey(w http.ResponseWriter, r *http.Request) {
 	logger := zerolog.Ctx(r.Context())
-	sourcesClient, err := clients.GetSourcesClient(r.Context())
+
+	id, err := ParseInt64(r, "ID")
 	if err != nil {
-		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	payload := &payloads.PubkeyUpdateRequest{}
+	if err = render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "update pubkey", err))
 		return
 	}
 
+	if payload.Name == "" && payload.Body == "" {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), ErrMissingNameOrBody.Error(), ErrMissingNameOrBody))
+		return
+	}
+
+	pkDao := dao.GetPubkeyDao(r.Context())
+
+	pk, err := pkDao.GetById(r.Context(), id)
+	if err != nil {
+		message := fmt.Sprintf("get pubkey with id %d", id)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	oldBody := pk.Body
+	payload.Apply(pk)
+	if pk.Body == oldBody {
+		err = pkDao.Update(r.Context(), pk)
+		if err != nil {
+			renderPubkeySaveError(w, r, "update pubkey", err)
+			return
+		}
+	} else {
+		// resources are forgotten together with the update so the next launch always imports the new key
+		resources, err := pkDao.UpdateAndDeleteResources(r.Context(), pk)
+		if err != nil {
+			renderPubkeySaveError(w, r, "update pubkey", err)
+			return
+		}
+
+		if len(resources) > 0 {
+			deleteJob := worker.Job{
+				Type:      jobs.TypeDeletePubkeyResources,
+				Identity:  identity.Identity(r.Context()),
+				EdgeID:    logging.EdgeRequestId(r.Context()),
+				AccountID: identity.AccountId(r.Context()),
+				Args: jobs.DeletePubkeyResourcesTaskArgs{
+					PubkeyID:  pk.ID,
+					Resources: resources,
+				},
+			}
+
+			err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &deleteJob)
+			if err != nil {
+				renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
+				return
+			}
+			logger.Debug().Msgf("Enqueued delete pubkey resources job %s for %d resources", deleteJob.ID, len(resources))
+		}
+	}
+
+	if err := render.Render(w, r, payloads.NewPubkeyResponse(pk)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkey", err))
+	}
+}
+
+// DeletePubkey enqueues a job which deletes the pubkey from all clouds it was uploaded to and
+// then removes it from the database, the job ID is returned. Pubkeys without any uploaded
+// resources are deleted immediately.
+func DeletePubkey(w http.ResponseWriter, r *http.Request) {
+	logger := zerolog.Ctx(r.Context())
+
 	id, err := ParseInt64(r, "ID")
 	if err != nil {
 		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
@@ -132,42 +304,37 @@ func DeletePubkey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	for _, res := range resources {
-		if res.Provider == models.ProviderTypeAWS {
-			if res.Handle != "" {
-				logger.Info().Msgf("Deleting pubkey resource ID %v with handle %s", res.ID, res.Handle)
-				authentication, errAuth := sourcesClient.GetAuthentication(r.Context(), res.SourceID)
-				if errAuth == nil {
-					ec2Client, errEc2 := clients.GetEC2Client(r.Context(), authentication, res.Region)
-					if errEc2 != nil {
-						renderError(w, r, payloads.NewAWSError(r.Context(), "unable to get AWS client", errEc2))
-						return
-					}
-
-					errDelete := ec2Client.DeleteSSHKey(r.Context(), res.Handle)
-					if errDelete != nil {
-						renderError(w, r, payloads.NewAWSError(r.Context(), "unable to delete AWS public key", errDelete))
-						return
-					}
-				} else if errors.Is(errAuth, httpClients.ErrAuthenticationForSourcesNotFound) {
-					logger.Warn().Msgf("Skipping source %s authorization which is no longer available", res.SourceID)
-				} else {
-					logger.Warn().Err(errAuth).Msg("Skipping source authorization because sources returned an error")
-				}
-			} else {
-				logger.Warn().Msgf("Skipping pubkey resource %d with empty handle", res.ID)
-			}
-		} else {
-			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "delete not implemented for this provider", ErrProviderTypeNotImplemented))
+	if len(resources) == 0 {
+		err = pubkeyDao.Delete(r.Context(), id)
+		if err != nil {
+			message := fmt.Sprintf("pubkey with id %d", id)
+			renderNotFoundOrDAOError(w, r, err, message)
+			return
 		}
+
+		render.NoContent(w, r)
+		return
+	}
+
+	deleteJob := worker.Job{
+		Type:      jobs.TypeDeletePubkey,
+		Identity:  identity.Identity(r.Context()),
+		EdgeID:    logging.EdgeRequestId(r.Context()),
+		AccountID: identity.AccountId(r.Context()),
+		Args: jobs.DeletePubkeyTaskArgs{
+			PubkeyID: pubkey.ID,
+		},
 	}
 
-	err = pubkeyDao.Delete(r.Context(), id)
+	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &deleteJob)
 	if err != nil {
-		message := fmt.Sprintf("pubkey with id %d", id)
-		renderNotFoundOrDAOError(w, r, err, message)
+		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
 		return
 	}
+	logger.Debug().Msgf("Enqueued delete pubkey job %s for %d resources", deleteJob.ID, len(resources))
 
-	render.NoContent(w, r)
+	render.Status(r, http.StatusAccepted)
+	if err := render.Render(w, r, payloads.NewPubkeyDeleteResponse(pubkey, deleteJob.ID.String(), len(resources))); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkey delete", err))
+	}
 }
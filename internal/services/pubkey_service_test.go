@@ -8,9 +8,13 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	queueStub "github.com/RHEnVision/provisioning-backend/internal/queue/stub"
 	"github.com/RHEnVision/provisioning-backend/internal/services"
 	_ "github.com/RHEnVision/provisioning-backend/internal/testing/initialization"
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/require"
 
 	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
@@ -50,6 +54,28 @@ func TestListPubkeysHandler(t *testing.T) {
 	require.NoError(t, err, "failed to decode response body")
 
 	assert.Len(t, result.Data, 2, "expected two pubkeys in response json")
+
+	t.Run("filtered by name", func(t *testing.T) {
+		err := stubs.AddPubkey(ctx, &models.Pubkey{
+			Name: "Laptop",
+			Body: factories.GenerateRSAPubKey(t),
+		})
+		require.NoError(t, err, "failed to add stubbed key")
+
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/pubkeys?name=lap&sort_by=name", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ListPubkeys)
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var result payloads.PubkeyListResponse
+		err = json.NewDecoder(rr.Body).Decode(&result)
+		require.NoError(t, err, "failed to decode response body")
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, "Laptop", result.Data[0].Name)
+	})
 }
 
 func TestCreatePubkeyHandler(t *testing.T) {
@@ -113,3 +139,228 @@ func TestCreatePubkeyHandler(t *testing.T) {
 		assert.Equal(t, 1, stubCount, "Pubkey has not been Created through DAO")
 	})
 }
+
+func TestDeletePubkeyHandler(t *testing.T) {
+	prepare := func(t *testing.T) (context.Context, *models.Pubkey) {
+		t.Helper()
+		ctx := stubs.WithAccountDaoOne(context.Background())
+		ctx = identity.WithTenant(t, ctx)
+		ctx = stubs.WithPubkeyDao(ctx)
+		ctx = queueStub.WithEnqueuer(ctx)
+
+		pk := factories.NewPubkeyRSA()
+		err := stubs.AddPubkey(ctx, pk)
+		require.NoError(t, err, "failed to add stubbed key")
+		return ctx, pk
+	}
+
+	request := func(t *testing.T, ctx context.Context) *httptest.ResponseRecorder {
+		t.Helper()
+		rctx := chi.NewRouteContext()
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		rctx.URLParams.Add("ID", "1")
+		req, err := http.NewRequestWithContext(ctx, "DELETE", "/api/provisioning/pubkeys/1", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.DeletePubkey)
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("without resources it is deleted immediately", func(t *testing.T) {
+		ctx, _ := prepare(t)
+		rr := request(t, ctx)
+
+		require.Equal(t, http.StatusNoContent, rr.Code, "Handler returned wrong status code")
+		assert.Equal(t, 0, stubs.PubkeyStubCount(ctx))
+		assert.Empty(t, queueStub.EnqueuedJobs(ctx), "No job must be planned")
+	})
+
+	t.Run("with resources it enqueues a job", func(t *testing.T) {
+		ctx, pk := prepare(t)
+		err := dao.GetPubkeyDao(ctx).UnscopedCreateResource(ctx, &models.PubkeyResource{
+			PubkeyID: pk.ID,
+			Provider: models.ProviderTypeAWS,
+			SourceID: "1",
+			Handle:   "key-name",
+		})
+		require.NoError(t, err, "failed to add stubbed resource")
+
+		rr := request(t, ctx)
+
+		require.Equal(t, http.StatusAccepted, rr.Code, "Handler returned wrong status code")
+		assert.Equal(t, 1, stubs.PubkeyStubCount(ctx), "Pubkey must be kept until the job finishes")
+		require.Len(t, queueStub.EnqueuedJobs(ctx), 1, "Expected exactly one job to be planned")
+		assert.Equal(t, jobs.TypeDeletePubkey, queueStub.EnqueuedJobs(ctx)[0].Type)
+
+		var response payloads.PubkeyDeleteResponse
+		err = json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err, "failed to decode response body")
+		assert.Equal(t, pk.ID, response.ID)
+		assert.Equal(t, queueStub.EnqueuedJobs(ctx)[0].ID.String(), response.JobID)
+		assert.Equal(t, 1, response.Resources)
+	})
+}
+
+func TestUpdatePubkeyHandler(t *testing.T) {
+	prepare := func(t *testing.T) (context.Context, *models.Pubkey) {
+		t.Helper()
+		ctx := stubs.WithAccountDaoOne(context.Background())
+		ctx = identity.WithTenant(t, ctx)
+		ctx = stubs.WithPubkeyDao(ctx)
+		ctx = queueStub.WithEnqueuer(ctx)
+
+		pk := factories.NewPubkeyRSA()
+		err := stubs.AddPubkey(ctx, pk)
+		require.NoError(t, err, "failed to add stubbed key")
+
+		err = dao.GetPubkeyDao(ctx).UnscopedCreateResource(ctx, &models.PubkeyResource{
+			PubkeyID: pk.ID,
+			Provider: models.ProviderTypeAWS,
+			SourceID: "1",
+			Region:   "us-east-1",
+			Handle:   "key-0123456789",
+		})
+		require.NoError(t, err, "failed to add stubbed resource")
+		return ctx, pk
+	}
+
+	request := func(t *testing.T, ctx context.Context, values map[string]interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		jsonData, err := json.Marshal(values)
+		require.NoError(t, err, "unable to marshal values to json")
+
+		rctx := chi.NewRouteContext()
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		rctx.URLParams.Add("ID", "1")
+		req, err := http.NewRequestWithContext(ctx, "PATCH", "/api/provisioning/pubkeys/1", bytes.NewBuffer(jsonData))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.UpdatePubkey)
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("rename keeps resources", func(t *testing.T) {
+		ctx, pk := prepare(t)
+		fingerprint := pk.Fingerprint
+		rr := request(t, ctx, map[string]interface{}{"name": "renamed"})
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var response payloads.PubkeyResponse
+		err := json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err, "failed to decode response body")
+		assert.Equal(t, "renamed", response.Name)
+		assert.Equal(t, fingerprint, response.Fingerprint)
+
+		resources, err := dao.GetPubkeyDao(ctx).UnscopedListResourcesByPubkeyId(ctx, pk.ID)
+		require.NoError(t, err, "failed to list resources")
+		assert.Len(t, resources, 1)
+		assert.Empty(t, queueStub.EnqueuedJobs(ctx), "No job must be planned")
+	})
+
+	t.Run("new body invalidates resources", func(t *testing.T) {
+		ctx, pk := prepare(t)
+		fingerprint := pk.Fingerprint
+		rr := request(t, ctx, map[string]interface{}{"body": factories.NewPubkeyED25519().Body})
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var response payloads.PubkeyResponse
+		err := json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err, "failed to decode response body")
+		assert.Equal(t, "ssh-ed25519", response.Type)
+		assert.NotEqual(t, fingerprint, response.Fingerprint)
+
+		resources, err := dao.GetPubkeyDao(ctx).UnscopedListResourcesByPubkeyId(ctx, pk.ID)
+		require.NoError(t, err, "failed to list resources")
+		assert.Empty(t, resources)
+		require.Len(t, queueStub.EnqueuedJobs(ctx), 1, "Expected exactly one job to be planned")
+		assert.Equal(t, jobs.TypeDeletePubkeyResources, queueStub.EnqueuedJobs(ctx)[0].Type)
+	})
+
+	t.Run("invalid body fails with 400 status", func(t *testing.T) {
+		ctx, _ := prepare(t)
+		rr := request(t, ctx, map[string]interface{}{"body": "ssh-rsa invalid"})
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+		assert.Empty(t, queueStub.EnqueuedJobs(ctx), "No job must be planned")
+	})
+
+	t.Run("empty request fails with 400 status", func(t *testing.T) {
+		ctx, _ := prepare(t)
+		rr := request(t, ctx, map[string]interface{}{})
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
+
+func TestGeneratePubkeyHandler(t *testing.T) {
+	request := func(t *testing.T, ctx context.Context, values map[string]interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		jsonData, err := json.Marshal(values)
+		require.NoError(t, err, "unable to marshal values to json")
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/pubkeys/generate", bytes.NewBuffer(jsonData))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.GeneratePubkey)
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	prepare := func(t *testing.T) context.Context {
+		t.Helper()
+		ctx := stubs.WithAccountDaoOne(context.Background())
+		ctx = identity.WithTenant(t, ctx)
+		ctx = stubs.WithPubkeyDao(ctx)
+		return ctx
+	}
+
+	t.Run("generates ed25519 key by default", func(t *testing.T) {
+		ctx := prepare(t)
+		rr := request(t, ctx, map[string]interface{}{"name": "generated"})
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+		assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+
+		var response payloads.PubkeyGenerateResponse
+		err := json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err, "failed to decode response body")
+		assert.Equal(t, "ssh-ed25519", response.Type)
+		assert.NotEmpty(t, response.Fingerprint)
+		assert.Contains(t, response.PrivateKeyOpenSSH, "OPENSSH PRIVATE KEY")
+		assert.Contains(t, response.PrivateKeyPEM, "BEGIN PRIVATE KEY")
+
+		stored, err := dao.GetPubkeyDao(ctx).GetById(ctx, response.ID)
+		require.NoError(t, err, "generated pubkey must be stored")
+		assert.Equal(t, response.Body, stored.Body)
+		assert.NotContains(t, stored.Body, "PRIVATE")
+	})
+
+	t.Run("generates rsa key", func(t *testing.T) {
+		ctx := prepare(t)
+		rr := request(t, ctx, map[string]interface{}{"name": "generated", "type": "ssh-rsa"})
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var response payloads.PubkeyGenerateResponse
+		err := json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err, "failed to decode response body")
+		assert.Equal(t, "ssh-rsa", response.Type)
+		assert.Contains(t, response.PrivateKeyPEM, "BEGIN RSA PRIVATE KEY")
+	})
+
+	t.Run("unsupported type fails with 400 status", func(t *testing.T) {
+		ctx := prepare(t)
+		rr := request(t, ctx, map[string]interface{}{"name": "generated", "type": "ssh-dss"})
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+		assert.Equal(t, 0, stubs.PubkeyStubCount(ctx))
+	})
+
+	t.Run("missing name fails with 400 status", func(t *testing.T) {
+		ctx := prepare(t)
+		rr := request(t, ctx, map[string]interface{}{})
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
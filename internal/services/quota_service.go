@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads/validation"
+	"github.com/RHEnVision/provisioning-backend/internal/preload"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrQuotaExceeded     = errors.New("not enough vCPU quota")
+	ErrInvalidAmount     = errors.New("amount must be a positive number")
+	ErrAmountWithoutType = errors.New("instance type is required together with amount")
+)
+
+// GetSourceQuotas returns vCPU quotas of the source account in the region given by the region query
+// parameter (AWS region, Azure location or GCP region). When instance_type and optional amount
+// parameters are provided, the response also tells whether the instances fit into the quotas.
+func GetSourceQuotas(w http.ResponseWriter, r *http.Request) {
+	sourceId := chi.URLParam(r, "ID")
+	if err := validation.DigitsOnly(sourceId); err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "id parameter invalid", err))
+		return
+	}
+
+	region := r.URL.Query().Get("region")
+	if region == "" {
+		renderError(w, r, payloads.NewMissingRequestParameterError(r.Context(), "region parameter is missing"))
+		return
+	}
+
+	amount := int64(1)
+	if str := r.URL.Query().Get("amount"); str != "" {
+		var err error
+		if amount, err = strconv.ParseInt(str, 10, 64); err != nil || amount <= 0 {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid amount", ErrInvalidAmount))
+			return
+		}
+		if r.URL.Query().Get("instance_type") == "" {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "missing instance type", ErrAmountWithoutType))
+			return
+		}
+	}
+
+	sourcesClient, err := clients.GetSourcesClient(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	authentication, err := sourcesClient.GetAuthentication(r.Context(), sourceId)
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	var it *clients.InstanceType
+	if name := r.URL.Query().Get("instance_type"); name != "" {
+		it = findInstanceType(authentication.ProviderType, clients.InstanceTypeName(name))
+		if it == nil {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), fmt.Sprintf("unknown type: %s", name), ErrUnknownInstanceTypeName))
+			return
+		}
+	}
+
+	quotas, err := listVCPUQuotas(r.Context(), authentication, region)
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	var required int64
+	var fits *bool
+	if it != nil {
+		required = int64(it.VCPUs) * amount
+		ok := checkVCPUQuota(quotas, authentication.ProviderType, it, amount) == nil
+		fits = &ok
+	}
+
+	if err := render.Render(w, r, payloads.NewQuotaListResponse(region, quotas, required, fits)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render quotas", err))
+	}
+}
+
+// findInstanceType returns preloaded instance type of the provider or nil when not found.
+func findInstanceType(provider models.ProviderType, name clients.InstanceTypeName) *clients.InstanceType {
+	switch provider {
+	case models.ProviderTypeAWS:
+		return preload.EC2InstanceType.FindInstanceType(name)
+	case models.ProviderTypeAzure:
+		return preload.AzureInstanceType.FindInstanceType(name)
+	case models.ProviderTypeGCP:
+		return preload.GCPInstanceType.FindInstanceType(name)
+	case models.ProviderTypeNoop, models.ProviderTypeUnknown:
+		return nil
+	}
+	return nil
+}
+
+// listVCPUQuotas fetches vCPU quotas of the source account in the region, GCP zones are
+// accepted too.
+func listVCPUQuotas(ctx context.Context, auth *clients.Authentication, region string) ([]*clients.Quota, error) {
+	switch auth.ProviderType {
+	case models.ProviderTypeAWS:
+		ec2Client, err := clients.GetEC2Client(ctx, auth, region)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get AWS EC2 client: %w", err)
+		}
+		quotas, err := ec2Client.ListVCPUQuotas(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list AWS quotas: %w", err)
+		}
+		return quotas, nil
+	case models.ProviderTypeAzure:
+		azureClient, err := clients.GetAzureClient(ctx, auth)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get Azure client: %w", err)
+		}
+		quotas, err := azureClient.ListVCPUQuotas(ctx, region)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list Azure quotas: %w", err)
+		}
+		return quotas, nil
+	case models.ProviderTypeGCP:
+		gcpClient, err := clients.GetGCPClient(ctx, auth)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get GCP client: %w", err)
+		}
+		quotas, err := gcpClient.ListVCPUQuotas(ctx, clients.GCPRegion(region))
+		if err != nil {
+			return nil, fmt.Errorf("unable to list GCP quotas: %w", err)
+		}
+		return quotas, nil
+	case models.ProviderTypeNoop, models.ProviderTypeUnknown:
+	}
+	return nil, fmt.Errorf("%w: %s", clients.ErrUnknownProvider, auth.ProviderType)
+}
+
+// quotaCacheExpiration is expiration of quotas checked during launch, usage is counted from all
+// running instances (e.g. DescribeInstances paging on AWS) and it is slow for large accounts
+const quotaCacheExpiration = time.Minute
+
+// cachedQuotas are vCPU quotas of an account in a region.
+type cachedQuotas struct {
+	Quotas []*clients.Quota
+}
+
+func (cachedQuotas) CacheKeyName() string {
+	return "vcpu_quotas"
+}
+
+// quotaCacheKey returns cache key of quotas of the cloud account in the region.
+func quotaCacheKey(ctx context.Context, auth *clients.Authentication, region string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", identity.Identity(ctx).Identity.OrgID, auth.ProviderType, auth.Payload, region)
+}
+
+// listCachedVCPUQuotas returns quotas from the application cache or fetches and caches them
+// briefly, cache errors are only logged.
+func listCachedVCPUQuotas(ctx context.Context, auth *clients.Authentication, region string) ([]*clients.Quota, error) {
+	logger := zerolog.Ctx(ctx)
+	key := quotaCacheKey(ctx, auth, region)
+
+	result := &cachedQuotas{}
+	err := cache.Find(ctx, key, result)
+	if err == nil {
+		return result.Quotas, nil
+	} else if !errors.Is(err, cache.ErrNotFound) {
+		logger.Warn().Err(err).Msg("Quota cache find error")
+	}
+
+	result.Quotas, err = listVCPUQuotas(ctx, auth, region)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cache.SetExpires(ctx, key, result, quotaCacheExpiration)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Quota cache set error")
+	}
+	return result.Quotas, nil
+}
+
+// checkVCPUQuota returns ErrQuotaExceeded when amount instances of the type do not fit into all
+// quotas which apply to the instance type family.
+func checkVCPUQuota(quotas []*clients.Quota, provider models.ProviderType, it *clients.InstanceType, amount int64) error {
+	required := int64(it.VCPUs) * amount
+	family := clients.InstanceFamily(provider, it.Name)
+	for _, quota := range quotas {
+		if quota.AppliesTo(family) && quota.Available() < required {
+			return fmt.Errorf("%w: %d vCPUs required but only %d available in %s", ErrQuotaExceeded, required, quota.Available(), quota.Name)
+		}
+	}
+	return nil
+}
+
+// ensureVCPUQuota renders an error and returns false when the instances do not fit into the quotas.
+// Quotas which cannot be read (e.g. missing permission) do not block the launch. Quotas are cached
+// briefly, usage of instances launched within the expiration is not counted.
+func ensureVCPUQuota(w http.ResponseWriter, r *http.Request, auth *clients.Authentication, region string, it *clients.InstanceType, amount int64) bool {
+	logger := zerolog.Ctx(r.Context())
+	if it == nil || region == "" {
+		return true
+	}
+
+	quotas, err := listCachedVCPUQuotas(r.Context(), auth, region)
+	if err != nil {
+		logger.Warn().Err(err).Msgf("Unable to read quotas in %s, skipping quota check", region)
+		return true
+	}
+
+	if err = checkVCPUQuota(quotas, auth.ProviderType, it, amount); err != nil {
+		renderError(w, r, payloads.NewQuotaExceededError(r.Context(), err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	clientStub "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSourceQuotas(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStub.WithSourcesClient(ctx)
+	ctx = clientStub.WithEC2Client(ctx)
+	ctx = clientStub.WithGCPCCustomerClient(ctx)
+
+	getQuotas := func(t *testing.T, provider models.ProviderType, query string) (*httptest.ResponseRecorder, *payloads.QuotaListResponse) {
+		t.Helper()
+		source, err := clientStub.AddSource(ctx, provider)
+		require.NoError(t, err, "failed to add stubbed source")
+
+		rctx := chi.NewRouteContext()
+		ctx := context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		rctx.URLParams.Add("ID", source.ID)
+		req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("/api/provisioning/sources/%s/quotas?%s", source.ID, query), nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(GetSourceQuotas)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			return rr, nil
+		}
+		result := &payloads.QuotaListResponse{}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(result), "failed to decode response body")
+		return rr, result
+	}
+
+	t.Run("list", func(t *testing.T) {
+		_, result := getQuotas(t, models.ProviderTypeAWS, "region=us-east-1")
+		require.NotNil(t, result)
+		require.Len(t, result.Quotas, 1)
+		assert.Equal(t, int64(24), result.Quotas[0].Available)
+		assert.Nil(t, result.Fits)
+	})
+
+	t.Run("fits", func(t *testing.T) {
+		_, result := getQuotas(t, models.ProviderTypeAWS, "region=us-east-1&instance_type=t3.medium&amount=12")
+		require.NotNil(t, result)
+		assert.Equal(t, int64(24), result.RequiredVCPUs)
+		require.NotNil(t, result.Fits)
+		assert.True(t, *result.Fits)
+	})
+
+	t.Run("exceeded family quota", func(t *testing.T) {
+		_, result := getQuotas(t, models.ProviderTypeGCP, "region=us-central1-a&instance_type=n2-standard-4&amount=3")
+		require.NotNil(t, result)
+		assert.Equal(t, int64(12), result.RequiredVCPUs)
+		require.NotNil(t, result.Fits)
+		assert.False(t, *result.Fits)
+	})
+
+	t.Run("missing region", func(t *testing.T) {
+		rr, _ := getQuotas(t, models.ProviderTypeAWS, "")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("amount without instance type", func(t *testing.T) {
+		rr, _ := getQuotas(t, models.ProviderTypeAWS, "region=us-east-1&amount=2")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
+
+func TestListCachedVCPUQuotas(t *testing.T) {
+	config.Application.Cache.Type = "memory"
+	cache.Initialize()
+	t.Cleanup(func() {
+		config.Application.Cache.Type = "none"
+		cache.Initialize()
+	})
+
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStub.WithEC2Client(ctx)
+	auth := clients.NewAuthentication("arn:aws:iam::230214684733:role/Test", models.ProviderTypeAWS)
+
+	quotas, err := listCachedVCPUQuotas(ctx, auth, "us-east-1")
+	require.NoError(t, err, "failed to list quotas")
+	require.Len(t, quotas, 1)
+
+	cached := &cachedQuotas{}
+	err = cache.Find(ctx, quotaCacheKey(ctx, auth, "us-east-1"), cached)
+	require.NoError(t, err, "quotas should be cached")
+	assert.Equal(t, quotas[0].Usage, cached.Quotas[0].Usage)
+
+	err = cache.Find(ctx, quotaCacheKey(ctx, auth, "eu-central-1"), cached)
+	assert.ErrorIs(t, err, cache.ErrNotFound, "quotas of other regions must not be cached")
+}
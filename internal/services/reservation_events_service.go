@@ -0,0 +1,146 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/progress"
+	"github.com/rs/zerolog"
+)
+
+var ErrStreamingUnsupported = errors.New("response streaming is not supported")
+
+const (
+	// reservationEventsKeepalive is the interval of keepalive comments. The reservation is also
+	// reloaded on every keepalive in case a progress notification was missed.
+	reservationEventsKeepalive = 15 * time.Second
+
+	// reservationEventsMaxDuration limits streams of long-running or scheduled reservations,
+	// clients reconnect to continue.
+	reservationEventsMaxDuration = 30 * time.Minute
+)
+
+var (
+	// closed on server shutdown, the server waits for all streams otherwise
+	reservationEventsStop     = make(chan struct{})
+	reservationEventsStopOnce sync.Once
+)
+
+// StopReservationEvents ends all reservation event streams. It is registered as a server shutdown
+// function, since graceful shutdown waits until all requests are finished.
+func StopReservationEvents() {
+	reservationEventsStopOnce.Do(func() {
+		close(reservationEventsStop)
+	})
+}
+
+// ReservationEvents streams progress of a reservation as server-sent events until it finishes.
+// The first event carries the current state, the last one is a success or failure event. Streams
+// end after reservationEventsMaxDuration and on server shutdown.
+func ReservationEvents(w http.ResponseWriter, r *http.Request) {
+	logger := zerolog.Ctx(r.Context())
+
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	reservation, err := dao.GetReservationDao(r.Context()).GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation for events")
+		return
+	}
+
+	// Check permission for individual provider type
+	if CheckPermissionAndRender(w, r, "read", "reservation", reservation.Provider.String()) != nil {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to stream reservation events", ErrStreamingUnsupported))
+		return
+	}
+
+	// subscribe before the first event is sent so no change is missed
+	notifications, unsubscribe := progress.Subscribe(id)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(reservationEventsKeepalive)
+	defer ticker.Stop()
+	timeout := time.NewTimer(reservationEventsMaxDuration)
+	defer timeout.Stop()
+
+	var last []byte
+	for {
+		var finished bool
+		last, finished, err = writeReservationEvent(r, w, id, last)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Unable to write reservation event")
+			return
+		}
+		flusher.Flush()
+		if finished {
+			return
+		}
+
+		select {
+		case <-notifications:
+		case <-ticker.C:
+			if _, err = fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case <-timeout.C:
+			return
+		case <-reservationEventsStop:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writeReservationEvent loads the reservation and writes an event unless its data are the same
+// as the last event. It returns data of the written event and true when the reservation finished.
+func writeReservationEvent(r *http.Request, w http.ResponseWriter, id int64, last []byte) ([]byte, bool, error) {
+	rDao := dao.GetReservationDao(r.Context())
+	reservation, err := rDao.GetById(r.Context(), id)
+	if err != nil {
+		return last, false, fmt.Errorf("unable to get reservation: %w", err)
+	}
+	instances, err := rDao.ListInstances(r.Context(), id)
+	if err != nil {
+		return last, false, fmt.Errorf("unable to list instances: %w", err)
+	}
+
+	event := payloads.NewReservationEventResponse(reservation, instances)
+	data, err := json.Marshal(event)
+	if err != nil {
+		return last, false, fmt.Errorf("unable to marshal event: %w", err)
+	}
+
+	finished := reservation.FinishedAt.Valid
+	if bytes.Equal(data, last) {
+		return last, finished, nil
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.EventType(), data)
+	if err != nil {
+		return last, false, fmt.Errorf("unable to write event: %w", err)
+	}
+	return data, finished, nil
+}
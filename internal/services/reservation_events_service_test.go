@@ -0,0 +1,190 @@
+package services_test
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http/rbac"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/progress"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	tidentity "github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseReservationEvent returns type and data of the first server-sent event in the body
+func parseReservationEvent(t *testing.T, body string) (string, payloads.ReservationEventResponse) {
+	t.Helper()
+
+	event, data, found := strings.Cut(strings.SplitN(body, "\n\n", 2)[0], "\n")
+	require.True(t, found, "event without data: %s", body)
+	require.True(t, strings.HasPrefix(event, "event: "), "event type missing: %s", body)
+	require.True(t, strings.HasPrefix(data, "data: "), "event data missing: %s", body)
+
+	var response payloads.ReservationEventResponse
+	err := json.Unmarshal([]byte(strings.TrimPrefix(data, "data: ")), &response)
+	require.NoError(t, err, "failed to decode event data")
+	return strings.TrimPrefix(event, "event: "), response
+}
+
+// streamRecorder passes the streamed response body into a pipe
+type streamRecorder struct {
+	*io.PipeWriter
+	header http.Header
+}
+
+func (s *streamRecorder) Header() http.Header {
+	return s.header
+}
+
+func (s *streamRecorder) WriteHeader(_ int) {}
+
+func (s *streamRecorder) Flush() {}
+
+// readReservationEvent reads the next server-sent event from the stream, keepalives are skipped
+func readReservationEvent(t *testing.T, reader *bufio.Reader) (string, payloads.ReservationEventResponse) {
+	t.Helper()
+
+	var event strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err, "failed to read event")
+		if line == "\n" && event.Len() > 0 {
+			return parseReservationEvent(t, event.String())
+		} else if !strings.HasPrefix(line, ":") && line != "\n" {
+			event.WriteString(line)
+		}
+	}
+}
+
+func TestReservationEvents(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = tidentity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = rbac.WithAcl(ctx, clients.AllPermissionsRbacAcl)
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	reservation := &models.AWSReservation{
+		PubkeyID: &pk.ID,
+		SourceID: "1",
+		ImageID:  "ami-random",
+		Detail: &models.AWSDetail{
+			Region:       "us-east-1",
+			InstanceType: "t1.micro",
+			Amount:       1,
+		},
+	}
+	reservation.AccountID = identity.AccountId(ctx)
+	reservation.Status = "Launching instance(s)"
+	reservation.Provider = models.ProviderTypeAWS
+	reservation.Steps = 3
+	reservation.Step = 1
+	reservation.StepTitles = []string{"Ensure public key", "Launch instance(s)", "Fetch instance(s) description"}
+	err = stubs.AddAWSReservation(ctx, reservation)
+	require.NoError(t, err, "failed to create stub reservation")
+
+	events := func(t *testing.T, ctx context.Context, id string) *httptest.ResponseRecorder {
+		t.Helper()
+		rctx := chi.NewRouteContext()
+		reqCtx := context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		rctx.URLParams.Add("ID", id)
+		req, err := http.NewRequestWithContext(reqCtx, "GET", "/api/provisioning/v1/reservations/"+id+"/events", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ReservationEvents)
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("in-flight reservation", func(t *testing.T) {
+		// the stream ends when the client disconnects
+		clientCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		rr := events(t, clientCtx, "1")
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+		assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+
+		eventType, response := parseReservationEvent(t, rr.Body.String())
+		assert.Equal(t, payloads.ReservationEventStatus, eventType)
+		assert.Equal(t, int32(1), response.Step)
+		assert.Equal(t, "Launch instance(s)", response.StepTitle)
+		assert.Nil(t, response.Success)
+	})
+
+	t.Run("progress notification", func(t *testing.T) {
+		clientCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		rctx := chi.NewRouteContext()
+		reqCtx := context.WithValue(clientCtx, chi.RouteCtxKey, rctx)
+		rctx.URLParams.Add("ID", "1")
+		req, err := http.NewRequestWithContext(reqCtx, "GET", "/api/provisioning/v1/reservations/1/events", nil)
+		require.NoError(t, err, "failed to create request")
+
+		pr, pw := io.Pipe()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			services.ReservationEvents(&streamRecorder{PipeWriter: pw, header: http.Header{}}, req)
+			_ = pw.Close()
+		}()
+		reader := bufio.NewReader(pr)
+
+		eventType, response := readReservationEvent(t, reader)
+		assert.Equal(t, payloads.ReservationEventStatus, eventType)
+		assert.Equal(t, int32(1), response.Step)
+
+		reservation.Step = 2
+		reservation.Status = "Fetching instance(s) description"
+		progress.Publish(ctx, reservation.ID)
+
+		eventType, response = readReservationEvent(t, reader)
+		assert.Equal(t, payloads.ReservationEventStatus, eventType)
+		assert.Equal(t, int32(2), response.Step)
+		assert.Equal(t, "Fetch instance(s) description", response.StepTitle)
+
+		cancel()
+		_, _ = io.Copy(io.Discard, pr)
+		<-done
+	})
+
+	t.Run("finished reservation", func(t *testing.T) {
+		reservation.Step = 3
+		reservation.Status = "Finished"
+		reservation.Success = sql.NullBool{Bool: true, Valid: true}
+		reservation.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+		rr := events(t, ctx, "1")
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		eventType, response := parseReservationEvent(t, rr.Body.String())
+		assert.Equal(t, payloads.ReservationEventSuccess, eventType)
+		assert.Equal(t, "Fetch instance(s) description", response.StepTitle)
+		require.NotNil(t, response.Success)
+		assert.True(t, *response.Success)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		rr := events(t, ctx, "99")
+		require.Equal(t, http.StatusNotFound, rr.Code, "Wrong status code")
+	})
+}
@@ -1,12 +1,15 @@
 package services
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/page"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
@@ -24,8 +27,66 @@ var (
 	ErrUnsupportedRegion          = errors.New("unknown region/location/zone")
 	ErrInvalidNamePattern         = errors.New("name pattern is not RFC-1035 compatible")
 	ErrPubkeyNotFound             = errors.New("no pubkey found")
+	ErrReservationFinished        = errors.New("reservation has already finished")
+	ErrLaunchTimeInPast           = errors.New("launch time must be in the future")
+	ErrLaunchTimeTooFar           = errors.New("launch time is too far in the future")
+	ErrExpiryConflict             = errors.New("expires_in and expires_at cannot be used together")
+	ErrExpiryInvalid              = errors.New("expires_in must be a positive duration")
+	ErrExpiryBeforeLaunch         = errors.New("expiry time must be after the launch time")
 )
 
+// parseLaunchAt validates optional launch time of a reservation. It must be in the future but
+// not later than the configured maximum delay (zero means no limit). The time is returned in UTC.
+func parseLaunchAt(launchAt *time.Time) (sql.NullTime, error) {
+	if launchAt == nil {
+		return sql.NullTime{}, nil
+	}
+
+	now := time.Now()
+	if !launchAt.After(now) {
+		return sql.NullTime{}, ErrLaunchTimeInPast
+	}
+	if maxDelay := config.Reservation.MaxLaunchDelay; maxDelay > 0 && launchAt.After(now.Add(maxDelay)) {
+		return sql.NullTime{}, fmt.Errorf("%w: maximum is %s", ErrLaunchTimeTooFar, maxDelay)
+	}
+	return sql.NullTime{Time: launchAt.UTC(), Valid: true}, nil
+}
+
+// parseExpiry validates optional expiry of a reservation given either as a duration or an absolute
+// time. The duration is counted from the scheduled launch time or from now for immediate launches.
+func parseExpiry(expiresIn string, expiresAt *time.Time, launchAt sql.NullTime) (sql.NullTime, error) {
+	if expiresIn != "" && expiresAt != nil {
+		return sql.NullTime{}, ErrExpiryConflict
+	}
+
+	start := time.Now()
+	if launchAt.Valid {
+		start = launchAt.Time
+	}
+
+	var expiry time.Time
+	switch {
+	case expiresIn != "":
+		duration, err := time.ParseDuration(expiresIn)
+		if err != nil {
+			return sql.NullTime{}, fmt.Errorf("%w: %s", ErrExpiryInvalid, err.Error())
+		}
+		if duration <= 0 {
+			return sql.NullTime{}, ErrExpiryInvalid
+		}
+		expiry = start.Add(duration)
+	case expiresAt != nil:
+		expiry = *expiresAt
+	default:
+		return sql.NullTime{}, nil
+	}
+
+	if !expiry.After(start) {
+		return sql.NullTime{}, ErrExpiryBeforeLaunch
+	}
+	return sql.NullTime{Time: expiry.UTC(), Valid: true}, nil
+}
+
 // CreateReservation dispatches requests to type provider specific handlers
 func CreateReservation(w http.ResponseWriter, r *http.Request) {
 	if !config.LaunchEnabled(r.Context()) {
@@ -59,19 +120,63 @@ func CreateReservation(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// PreflightReservation dispatches reservation validation requests to type provider specific handlers.
+// Requests are validated the same way as in CreateReservation but no reservation is created.
+func PreflightReservation(w http.ResponseWriter, r *http.Request) {
+	if !config.LaunchEnabled(r.Context()) {
+		writeUnauthorized(w, r)
+		return
+	}
+
+	pType := models.ProviderTypeFromString(chi.URLParam(r, "TYPE"))
+
+	// Check permission for individual provider type
+	if CheckPermissionAndRender(w, r, "write", "reservation", pType.String()) != nil {
+		return
+	}
+
+	switch pType {
+	case models.ProviderTypeAWS:
+		PreflightAWSReservation(w, r)
+	case models.ProviderTypeAzure:
+		if config.FeatureEnabled(r.Context(), "azure") {
+			PreflightAzureReservation(w, r)
+		} else {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "azure reservation is not implemented", ErrProviderTypeNotImplemented))
+		}
+	case models.ProviderTypeGCP:
+		PreflightGCPReservation(w, r)
+	case models.ProviderTypeUnknown, models.ProviderTypeNoop:
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", ErrUnknownProviderType))
+	default:
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", ErrUnknownProviderType))
+	}
+}
+
 func ListReservations(w http.ResponseWriter, r *http.Request) {
 	rDao := dao.GetReservationDao(r.Context())
 
 	offset := page.Offset(r.Context()).Int64()
 	limit := page.Limit(r.Context()).Int64()
 
-	reservations, err := rDao.List(r.Context(), limit, offset)
+	filter, err := parseReservationFilter(r.URL.Query())
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid reservation filter", err))
+		return
+	}
+
+	if r.URL.Query().Has("token") {
+		listReservationsKeyset(w, r, filter)
+		return
+	}
+
+	reservations, err := rDao.List(r.Context(), filter, limit, offset)
 	if err != nil {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "list reservations", err))
 		return
 	}
 
-	totalRes, err := rDao.Count(r.Context())
+	totalRes, err := rDao.Count(r.Context(), filter)
 	if err != nil {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "count reservations", err))
 		return
@@ -85,6 +190,65 @@ func ListReservations(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// listReservationsKeyset lists reservations ordered by creation time with cursor tokens instead
+// of offsets, pages are stable when new reservations are created meanwhile.
+func listReservationsKeyset(w http.ResponseWriter, r *http.Request, filter *dao.ReservationFilter) {
+	if filter.SortBy != "" && filter.SortBy != "created_at" {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid reservation filter", ErrKeysetSortBy))
+		return
+	}
+
+	after, err := page.DecodeCursor(page.Token(r.Context()))
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid page token", err))
+		return
+	}
+
+	limit := page.Limit(r.Context()).Int()
+	reservations, err := dao.GetReservationDao(r.Context()).ListKeyset(r.Context(), filter, after, int64(limit)+1)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list reservations", err))
+		return
+	}
+
+	reservations, nextToken := page.NextCursor(reservations, limit, func(reservation *models.Reservation) *page.Cursor {
+		return &page.Cursor{CreatedAt: reservation.CreatedAt, ID: reservation.ID}
+	})
+	meta := page.NewTokenMetadata(r.Context(), r, nextToken)
+
+	if err := render.Render(w, r, payloads.NewReservationListResponse(reservations, meta)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservations list", err))
+		return
+	}
+}
+
+// ListScheduledReservations lists reservations waiting for their launch time, the earliest first.
+func ListScheduledReservations(w http.ResponseWriter, r *http.Request) {
+	rDao := dao.GetReservationDao(r.Context())
+
+	offset := page.Offset(r.Context()).Int64()
+	limit := page.Limit(r.Context()).Int64()
+
+	reservations, err := rDao.ListScheduled(r.Context(), limit, offset)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list scheduled reservations", err))
+		return
+	}
+
+	totalRes, err := rDao.CountScheduled(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "count scheduled reservations", err))
+		return
+	}
+
+	meta := page.NewOffsetMetadata(r.Context(), r, totalRes)
+
+	if err := render.Render(w, r, payloads.NewReservationListResponse(reservations, meta)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render scheduled reservations list", err))
+		return
+	}
+}
+
 func GetReservationDetail(w http.ResponseWriter, r *http.Request) {
 	provider := chi.URLParam(r, "TYPE")
 	providerType := models.ProviderTypeFromString(provider)
@@ -176,3 +340,55 @@ func GetReservationDetail(w http.ResponseWriter, r *http.Request) {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", ErrProviderTypeNotImplemented))
 	}
 }
+
+// CancelReservation marks an in-flight reservation as cancelled. The background job stops at the
+// next step, rolls back created resources and finishes the reservation with an error. Scheduled
+// reservations are finished immediately.
+func CancelReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	reservation, err := rDao.GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation for cancellation")
+		return
+	}
+
+	// Check permission for individual provider type
+	if CheckPermissionAndRender(w, r, "write", "reservation", reservation.Provider.String()) != nil {
+		return
+	}
+
+	if reservation.FinishedAt.Valid {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "reservation cannot be cancelled", ErrReservationFinished))
+		return
+	}
+
+	// cancelling a cancelled reservation is a no-op
+	if !reservation.Cancelled() {
+		err = rDao.Cancel(r.Context(), id)
+		if err != nil {
+			renderError(w, r, payloads.NewDAOError(r.Context(), "cancel reservation", err))
+			return
+		}
+
+		reservation, err = rDao.GetById(r.Context(), id)
+		if err != nil {
+			renderNotFoundOrDAOError(w, r, err, "get cancelled reservation")
+			return
+		}
+
+		// scheduled reservations are finished right away, others by the launch job
+		if reservation.FinishedAt.Valid {
+			jobs.ReservationFailed(r.Context(), id)
+		}
+	}
+
+	if err := render.Render(w, r, payloads.NewReservationResponse(reservation)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation", err))
+	}
+}
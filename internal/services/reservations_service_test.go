@@ -2,15 +2,22 @@ package services_test
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http/rbac"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/middleware"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/RHEnVision/provisioning-backend/internal/services"
@@ -75,3 +82,311 @@ func TestGetReservationDetail(t *testing.T) {
 		assert.Equal(t, int(models.ProviderTypeAWS), response.Provider, "expected provider to be AWS in parsed json")
 	})
 }
+
+func TestCancelReservation(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = tidentity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = rbac.WithAcl(ctx, clients.AllPermissionsRbacAcl)
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	reservation := &models.AWSReservation{
+		PubkeyID: &pk.ID,
+		SourceID: "1",
+		ImageID:  "ami-random",
+		Detail: &models.AWSDetail{
+			Region:       "us-east-1",
+			InstanceType: "t1.micro",
+			Amount:       1,
+		},
+	}
+	reservation.AccountID = identity.AccountId(ctx)
+	reservation.Status = "Launching instance(s)"
+	reservation.Provider = models.ProviderTypeAWS
+	reservation.Steps = 3
+	err = stubs.AddAWSReservation(ctx, reservation)
+	require.NoError(t, err, "failed to create stub reservation")
+
+	cancel := func(t *testing.T) *httptest.ResponseRecorder {
+		t.Helper()
+		rctx := chi.NewRouteContext()
+		reqCtx := context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		rctx.URLParams.Add("ID", "1")
+		req, err := http.NewRequestWithContext(reqCtx, "DELETE", "/api/provisioning/v1/reservations/1", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CancelReservation)
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("in-flight reservation", func(t *testing.T) {
+		rr := cancel(t)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response payloads.GenericReservationResponse
+		err = json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err, "failed to decode response body")
+		assert.NotNil(t, response.CancelledAt, "expected cancellation time")
+		assert.True(t, reservation.Cancelled())
+	})
+
+	t.Run("already cancelled reservation", func(t *testing.T) {
+		rr := cancel(t)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+	})
+
+	t.Run("finished reservation", func(t *testing.T) {
+		reservation.FinishedAt.Valid = true
+		rr := cancel(t)
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+	})
+}
+
+func TestCancelScheduledReservation(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = tidentity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = rbac.WithAcl(ctx, clients.AllPermissionsRbacAcl)
+	config.Application.StatusEvents.Enabled = true
+	t.Cleanup(func() { config.Application.StatusEvents.Enabled = false })
+	require.NoError(t, kafka.InitializeStubBroker(16))
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	reservation := &models.AWSReservation{
+		PubkeyID: &pk.ID,
+		SourceID: "1",
+		ImageID:  "ami-random",
+		Detail: &models.AWSDetail{
+			Region:       "us-east-1",
+			InstanceType: "t1.micro",
+			Amount:       1,
+		},
+	}
+	reservation.AccountID = identity.AccountId(ctx)
+	reservation.LaunchAt = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+	reservation.Status = reservation.InitialStatus()
+	reservation.Provider = models.ProviderTypeAWS
+	reservation.Steps = 3
+	err = stubs.AddAWSReservation(ctx, reservation)
+	require.NoError(t, err, "failed to create stub reservation")
+
+	events := make(chan *kafka.ReservationStatusMessage, 1)
+	consumeCtx, cancelConsume := context.WithCancel(ctx)
+	defer cancelConsume()
+	go kafka.Consume(consumeCtx, kafka.ReservationStatusTopic, time.Now(), func(_ context.Context, msg *kafka.GenericMessage) {
+		rsm, msgErr := kafka.NewReservationStatusMessage(msg)
+		if msgErr == nil {
+			events <- rsm
+		}
+	})
+
+	rctx := chi.NewRouteContext()
+	reqCtx := context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	rctx.URLParams.Add("ID", fmt.Sprintf("%d", reservation.ID))
+	req, err := http.NewRequestWithContext(reqCtx, "DELETE", fmt.Sprintf("/api/provisioning/v1/reservations/%d", reservation.ID), nil)
+	require.NoError(t, err, "failed to create request")
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(services.CancelReservation)
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+	event := <-events
+	assert.Equal(t, kafka.ReservationFailureEventType, event.EventType)
+	assert.Equal(t, reservation.ID, event.ReservationID)
+	assert.Equal(t, "Cancelled", event.Status)
+}
+
+func TestListScheduledReservations(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = tidentity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = rbac.WithAcl(ctx, clients.AllPermissionsRbacAcl)
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	for _, launchAt := range []sql.NullTime{{}, {Time: time.Now().Add(time.Hour), Valid: true}} {
+		reservation := &models.AWSReservation{
+			PubkeyID: &pk.ID,
+			SourceID: "1",
+			ImageID:  "ami-random",
+			Detail: &models.AWSDetail{
+				Region:       "us-east-1",
+				InstanceType: "t1.micro",
+				Amount:       1,
+			},
+		}
+		reservation.AccountID = identity.AccountId(ctx)
+		reservation.LaunchAt = launchAt
+		reservation.Status = reservation.InitialStatus()
+		reservation.Provider = models.ProviderTypeAWS
+		reservation.Steps = 3
+		err = stubs.AddAWSReservation(ctx, reservation)
+		require.NoError(t, err, "failed to create stub reservation")
+	}
+
+	list := func(t *testing.T) payloads.GenericReservationListResponse {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/v1/reservations/scheduled", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ListScheduledReservations)
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response payloads.GenericReservationListResponse
+		err = json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err, "failed to decode response body")
+		return response
+	}
+
+	t.Run("lists scheduled reservations", func(t *testing.T) {
+		response := list(t)
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, int64(2), response.Data[0].ID)
+		assert.Equal(t, models.ReservationStatusScheduled, response.Data[0].Status)
+		assert.NotNil(t, response.Data[0].LaunchAt, "expected launch time")
+	})
+
+	t.Run("cancel finishes scheduled reservation", func(t *testing.T) {
+		rctx := chi.NewRouteContext()
+		reqCtx := context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		rctx.URLParams.Add("ID", "2")
+		req, err := http.NewRequestWithContext(reqCtx, "DELETE", "/api/provisioning/v1/reservations/2", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CancelReservation)
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response payloads.GenericReservationResponse
+		err = json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err, "failed to decode response body")
+		assert.NotNil(t, response.FinishedAt, "expected scheduled reservation to be finished")
+		assert.Equal(t, "Cancelled", response.Status)
+
+		assert.Empty(t, list(t).Data)
+	})
+}
+
+func TestListReservations(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = tidentity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	for i, sourceId := range []string{"1", "2", "2"} {
+		reservation := &models.AWSReservation{
+			PubkeyID: &pk.ID,
+			SourceID: sourceId,
+			ImageID:  "ami-random",
+			Detail: &models.AWSDetail{
+				Region:       "us-east-1",
+				Name:         fmt.Sprintf("instance-%d", i),
+				InstanceType: "t1.micro",
+				Amount:       1,
+			},
+		}
+		reservation.AccountID = identity.AccountId(ctx)
+		reservation.Status = reservation.InitialStatus()
+		reservation.Provider = models.ProviderTypeAWS
+		reservation.Steps = 3
+		reservation.Success = sql.NullBool{Bool: i == 2, Valid: i == 2}
+		err = stubs.AddAWSReservation(ctx, reservation)
+		require.NoError(t, err, "failed to create stub reservation")
+	}
+
+	list := func(t *testing.T, query string, status int) payloads.GenericReservationListResponse {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/v1/reservations?"+query, nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := middleware.Pagination(http.HandlerFunc(services.ListReservations))
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, status, rr.Code, "Wrong status code")
+
+		var response payloads.GenericReservationListResponse
+		if status == http.StatusOK {
+			err = json.NewDecoder(rr.Body).Decode(&response)
+			require.NoError(t, err, "failed to decode response body")
+		}
+		return response
+	}
+
+	t.Run("filters by source", func(t *testing.T) {
+		response := list(t, "source_id=2", http.StatusOK)
+		require.Len(t, response.Data, 2)
+		assert.Equal(t, 2, response.Metadata.Total)
+	})
+
+	t.Run("filters by name and status", func(t *testing.T) {
+		response := list(t, "name=INSTANCE&status=pending", http.StatusOK)
+		require.Len(t, response.Data, 2)
+		assert.Equal(t, int64(1), response.Data[0].ID)
+		assert.Equal(t, int64(2), response.Data[1].ID)
+	})
+
+	t.Run("sorts descending", func(t *testing.T) {
+		response := list(t, "sort_by=id&sort_dir=desc", http.StatusOK)
+		require.Len(t, response.Data, 3)
+		assert.Equal(t, int64(3), response.Data[0].ID)
+	})
+
+	t.Run("page links keep filters", func(t *testing.T) {
+		response := list(t, "source_id=2&sort_dir=desc&limit=1", http.StatusOK)
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, int64(3), response.Data[0].ID)
+		assert.Equal(t, "/api/provisioning/v1/reservations?limit=1&offset=1&sort_dir=desc&source_id=2", response.Metadata.Links.Next)
+	})
+
+	t.Run("token pagination", func(t *testing.T) {
+		response := list(t, "token=&limit=2", http.StatusOK)
+		require.Len(t, response.Data, 2)
+		assert.Equal(t, int64(1), response.Data[0].ID)
+		require.NotEmpty(t, response.Metadata.Links.Next)
+
+		next, err := url.Parse(response.Metadata.Links.Next)
+		require.NoError(t, err, "failed to parse next link")
+		response = list(t, next.RawQuery, http.StatusOK)
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, int64(3), response.Data[0].ID)
+		assert.Empty(t, response.Metadata.Links.Next)
+	})
+
+	t.Run("token pagination keeps filters", func(t *testing.T) {
+		response := list(t, "token=&limit=1&source_id=2&sort_dir=desc", http.StatusOK)
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, int64(3), response.Data[0].ID)
+
+		next, err := url.Parse(response.Metadata.Links.Next)
+		require.NoError(t, err, "failed to parse next link")
+		assert.Equal(t, "2", next.Query().Get("source_id"))
+		response = list(t, next.RawQuery, http.StatusOK)
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, int64(2), response.Data[0].ID)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		list(t, "token=invalid", http.StatusBadRequest)
+		list(t, "token=&sort_by=provider", http.StatusBadRequest)
+		list(t, "status=unknown", http.StatusBadRequest)
+		list(t, "sort_by=status", http.StatusBadRequest)
+		list(t, "created_after=yesterday", http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/rs/zerolog"
+)
+
+// sourceDestroyedError is stored as the error of unfinished reservations of deleted sources.
+const sourceDestroyedError = "Source was deleted before the launch finished"
+
+// ProcessSourcesEvent reacts to lifecycle events from the Sources event stream. Cached data of the
+// source is always invalidated, pubkeys uploaded via the source are forgotten when the source is
+// deleted or its authentication changes and unfinished reservations of deleted sources fail.
+func ProcessSourcesEvent(msgCtx context.Context, message *kafka.GenericMessage) {
+	logger := zerolog.Ctx(msgCtx)
+
+	sem, err := kafka.NewSourcesEventMessage(message)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Could not get sources event message")
+		return
+	}
+
+	switch sem.EventType {
+	case kafka.SourceDestroyEventType, kafka.SourcePauseEventType, kafka.SourceUnpauseEventType, kafka.AuthenticationUpdateEventType:
+	default:
+		return
+	}
+
+	sourceId, err := sem.SourceIdentifier()
+	if err != nil {
+		logger.Warn().Err(err).Str("event_type", sem.EventType).Msg("Could not get source id of sources event")
+		return
+	}
+
+	logger = ptr.To(logger.With().Str("source_id", sourceId).Str("event_type", sem.EventType).Logger())
+	ctx := logger.WithContext(msgCtx)
+	logger.Debug().Msgf("Processing sources event %s for source %s", sem.EventType, sourceId)
+
+	// cache keys are scoped by the organization, messages without identity only carry the header
+	orgId := identity.Identity(ctx).Identity.OrgID
+	systemPrincipal := orgId == ""
+	if systemPrincipal {
+		orgId = message.Header("x-rh-sources-org-id")
+	}
+	if orgId == "" {
+		logger.Warn().Msg("Sources event without organization")
+		return
+	}
+	if systemPrincipal {
+		ctx = identity.WithIdentity(ctx, identity.NewSystemPrincipal(orgId, ""))
+	}
+
+	err = InvalidateSourceCache(ctx, sourceId)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Could not invalidate cached source")
+	}
+
+	if sem.EventType == kafka.SourcePauseEventType || sem.EventType == kafka.SourceUnpauseEventType {
+		return
+	}
+
+	account, err := dao.GetAccountDao(ctx).GetByOrgId(ctx, orgId)
+	if errors.Is(err, dao.ErrNoRows) {
+		// organization has never used provisioning, there is nothing to clean up
+		return
+	} else if err != nil {
+		logger.Warn().Err(err).Msg("Could not find account of sources event")
+		return
+	}
+	ctx = identity.WithAccountId(ctx, account.ID)
+	if systemPrincipal {
+		ctx = identity.WithIdentity(ctx, identity.NewSystemPrincipal(account.OrgID, account.AccountNumber.String))
+	}
+
+	deleted, err := dao.GetPubkeyDao(ctx).DeleteResourcesBySourceId(ctx, sourceId)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Could not delete pubkey resources of source")
+	} else if deleted > 0 {
+		logger.Info().Int64("deleted", deleted).Msgf("Deleted %d pubkey resources of source", deleted)
+	}
+
+	if sem.EventType != kafka.SourceDestroyEventType {
+		return
+	}
+
+	failed, err := dao.GetReservationDao(ctx).FailUnfinishedBySourceId(ctx, sourceId, sourceDestroyedError)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Could not fail reservations of deleted source")
+		return
+	}
+	if len(failed) > 0 {
+		logger.Info().Ints64("failed", failed).Msgf("Failed %d unfinished reservations of deleted source", len(failed))
+	}
+	for _, reservationId := range failed {
+		jobs.ReservationFailed(ctx, reservationId)
+	}
+}
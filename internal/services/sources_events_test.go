@@ -0,0 +1,139 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	tidentity "github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessSourcesEvent(t *testing.T) {
+	config.Application.Cache.Type = "memory"
+	cache.Initialize()
+	config.Application.StatusEvents.Enabled = true
+	t.Cleanup(func() {
+		config.Application.Cache.Type = "none"
+		cache.Initialize()
+		config.Application.StatusEvents.Enabled = false
+	})
+
+	// sources events carry the organization in a header, the identity header is optional
+	sourcesEvent := func(eventType string, headers ...string) *kafka.GenericMessage {
+		return &kafka.GenericMessage{
+			Value:   []byte(`{"id":42,"name":"aws","source_type_id":1}`),
+			Headers: kafka.GenericHeaders(append([]string{"event_type", eventType}, headers...)...),
+		}
+	}
+
+	prepare := func(t *testing.T) (context.Context, context.Context, *models.AWSReservation) {
+		t.Helper()
+		msgCtx := stubs.WithAccountDaoOne(context.Background())
+		msgCtx = stubs.WithPubkeyDao(msgCtx)
+		msgCtx = stubs.WithReservationDao(msgCtx)
+		ctx := tidentity.WithTenant(t, msgCtx)
+		require.NoError(t, kafka.InitializeStubBroker(16))
+
+		pk := factories.NewPubkeyRSA()
+		require.NoError(t, stubs.AddPubkey(ctx, pk), "failed to add stubbed key")
+		reservation := &models.AWSReservation{
+			PubkeyID: &pk.ID,
+			SourceID: "42",
+			ImageID:  "ami-random",
+			Detail: &models.AWSDetail{
+				Region:       "us-east-1",
+				InstanceType: "t1.micro",
+				Amount:       1,
+			},
+		}
+		reservation.AccountID = identity.AccountId(ctx)
+		reservation.Status = reservation.InitialStatus()
+		reservation.Provider = models.ProviderTypeAWS
+		reservation.Steps = 3
+		require.NoError(t, stubs.AddAWSReservation(ctx, reservation), "failed to create stub reservation")
+
+		// authentications are cached per organization
+		require.NoError(t, cache.Set(ctx, tidentity.DefaultOrgId+":42", &clients.Authentication{}))
+		return msgCtx, ctx, reservation
+	}
+
+	cached := func(t *testing.T) bool {
+		t.Helper()
+		err := cache.Find(context.Background(), tidentity.DefaultOrgId+":42", &clients.Authentication{})
+		if err == nil {
+			return true
+		}
+		require.ErrorIs(t, err, cache.ErrNotFound)
+		return false
+	}
+
+	unfinished := func(t *testing.T, ctx context.Context, reservation *models.AWSReservation) bool {
+		t.Helper()
+		r, err := dao.GetReservationDao(ctx).GetById(ctx, reservation.ID)
+		require.NoError(t, err)
+		return !r.FinishedAt.Valid
+	}
+
+	t.Run("ignored event type", func(t *testing.T) {
+		msgCtx, ctx, reservation := prepare(t)
+		services.ProcessSourcesEvent(msgCtx, sourcesEvent("Source.create", "x-rh-sources-org-id", tidentity.DefaultOrgId))
+
+		assert.True(t, cached(t), "Expected the authentication to stay cached")
+		assert.True(t, unfinished(t, ctx, reservation))
+	})
+
+	t.Run("without organization", func(t *testing.T) {
+		msgCtx, ctx, reservation := prepare(t)
+		services.ProcessSourcesEvent(msgCtx, sourcesEvent(kafka.SourceDestroyEventType))
+
+		assert.True(t, cached(t), "Expected the authentication to stay cached")
+		assert.True(t, unfinished(t, ctx, reservation))
+	})
+
+	t.Run("pause invalidates cache of the organization header", func(t *testing.T) {
+		msgCtx, ctx, reservation := prepare(t)
+		services.ProcessSourcesEvent(msgCtx, sourcesEvent(kafka.SourcePauseEventType, "x-rh-sources-org-id", tidentity.DefaultOrgId))
+
+		assert.False(t, cached(t), "Expected the authentication to be invalidated")
+		assert.True(t, unfinished(t, ctx, reservation), "Expected paused source not to fail reservations")
+	})
+
+	t.Run("destroy fails reservations", func(t *testing.T) {
+		msgCtx, ctx, reservation := prepare(t)
+
+		events := make(chan *kafka.ReservationStatusMessage, 1)
+		consumeCtx, cancelConsume := context.WithCancel(ctx)
+		defer cancelConsume()
+		go kafka.Consume(consumeCtx, kafka.ReservationStatusTopic, time.Now(), func(_ context.Context, msg *kafka.GenericMessage) {
+			rsm, msgErr := kafka.NewReservationStatusMessage(msg)
+			if msgErr == nil {
+				events <- rsm
+			}
+		})
+
+		services.ProcessSourcesEvent(msgCtx, sourcesEvent(kafka.SourceDestroyEventType, "x-rh-sources-org-id", tidentity.DefaultOrgId))
+
+		assert.False(t, cached(t), "Expected the authentication to be invalidated")
+		r, err := dao.GetReservationDao(ctx).GetById(ctx, reservation.ID)
+		require.NoError(t, err)
+		assert.True(t, r.FinishedAt.Valid, "Expected the reservation to be finished")
+		assert.False(t, r.Success.Bool)
+		assert.Contains(t, r.Error, "Source was deleted")
+
+		event := <-events
+		assert.Equal(t, kafka.ReservationFailureEventType, event.EventType)
+		assert.Equal(t, reservation.ID, event.ReservationID)
+	})
+}
@@ -10,9 +10,11 @@ import (
 
 	"github.com/RHEnVision/provisioning-backend/internal/cache"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http/sources"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/page"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 )
@@ -188,6 +190,27 @@ func getAzureAccountDetails(ctx context.Context, sourceId string, authentication
 	}, nil
 }
 
+// InvalidateSourceCache deletes cached authentication and account details of a source. It is used
+// when the source is changed or deleted in Sources.
+func InvalidateSourceCache(ctx context.Context, sourceId string) error {
+	err := sources.InvalidateAuthentication(ctx, sourceId)
+	if err != nil {
+		return fmt.Errorf("unable to invalidate authentication: %w", err)
+	}
+
+	err = cache.Delete(ctx, sourceId, &clients.AccountDetailsAWS{})
+	if err != nil {
+		return fmt.Errorf("unable to invalidate AWS account details: %w", err)
+	}
+
+	err = cache.Delete(ctx, sourceId, ptr.To(clients.AzureTenantId("")))
+	if err != nil {
+		return fmt.Errorf("unable to invalidate Azure tenant: %w", err)
+	}
+
+	return nil
+}
+
 func getGCPAccountDetails(ctx context.Context, sourceId string, authentication *clients.Authentication) (*clients.AccountDetailsGCP, error) {
 	return &clients.AccountDetailsGCP{}, nil
 }
@@ -0,0 +1,97 @@
+package ssh
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// RSA key size used for generated RSA key pairs.
+const rsaKeyBits = 4096
+
+var ErrUnsupportedKeyType = errors.New("unsupported key type, must be one of: ssh-ed25519, ssh-rsa")
+
+// KeyPair is a newly generated SSH key pair. Private key is available in two formats:
+// OpenSSH ("BEGIN OPENSSH PRIVATE KEY") and PEM, which is PKCS#1 ("BEGIN RSA PRIVATE KEY")
+// for RSA keys and PKCS#8 ("BEGIN PRIVATE KEY") for ed25519 keys.
+type KeyPair struct {
+	// Public key in authorized_keys format including the comment.
+	PublicKey string
+
+	// Private key in OpenSSH format.
+	PrivateKeyOpenSSH string
+
+	// Private key in PEM format.
+	PrivateKeyPEM string
+}
+
+// GenerateKeyPair generates a new key pair of type "ssh-ed25519" or "ssh-rsa". Control characters
+// (e.g. newlines) are removed from the comment so it cannot break the authorized_keys line.
+func GenerateKeyPair(keyType, comment string) (*KeyPair, error) {
+	comment = sanitizeComment(comment)
+	var privateKey crypto.PrivateKey
+	var publicKey crypto.PublicKey
+	var pemBlock *pem.Block
+
+	switch keyType {
+	case ssh.KeyAlgoED25519:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate ed25519 key: %w", err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(priv)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal ed25519 key: %w", err)
+		}
+		privateKey, publicKey = priv, pub
+		pemBlock = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	case ssh.KeyAlgoRSA:
+		priv, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate rsa key: %w", err)
+		}
+		privateKey, publicKey = priv, &priv.PublicKey
+		pemBlock = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKeyType, keyType)
+	}
+
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert public key: %w", err)
+	}
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublicKey)))
+	if comment != "" {
+		authorizedKey = authorizedKey + " " + comment
+	}
+
+	opensshBlock, err := ssh.MarshalPrivateKey(privateKey, comment)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal private key: %w", err)
+	}
+
+	return &KeyPair{
+		PublicKey:         authorizedKey,
+		PrivateKeyOpenSSH: string(pem.EncodeToMemory(opensshBlock)),
+		PrivateKeyPEM:     string(pem.EncodeToMemory(pemBlock)),
+	}, nil
+}
+
+// sanitizeComment removes control characters from a key comment.
+func sanitizeComment(comment string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, comment))
+}
@@ -0,0 +1,115 @@
+package userdata
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaxCustomSize is the maximum size of custom user data in bytes. The smallest limit is on AWS
+// (16 kB) and it must also fit the generated content and multipart headers.
+const MaxCustomSize = 12 * 1024
+
+const (
+	cloudConfigHeader = "#cloud-config"
+	scriptHeader      = "#!"
+
+	multipartBoundary = "==PROVISIONING-USER-DATA=="
+
+	// appends lists (e.g. write_files or runcmd) instead of replacing the generated ones
+	mergeType = "list(append)+dict(recurse_array)+str()"
+)
+
+var (
+	ErrCustomUserDataTooLarge = fmt.Errorf("user data must not be longer than %d bytes", MaxCustomSize)
+	ErrCustomUserDataType     = errors.New("user data must start with #cloud-config or #! (shell script)")
+	ErrCustomUserDataYAML     = errors.New("user data is not a valid cloud-config YAML")
+)
+
+// IsCloudConfig returns true when the user data is a cloud-config document.
+func IsCloudConfig(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(cloudConfigHeader))
+}
+
+// IsScript returns true when the user data is a script with a shebang.
+func IsScript(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(scriptHeader))
+}
+
+// ValidateCustom checks custom user data provided by the user, empty data are valid. Only
+// cloud-config with a valid YAML and scripts starting with a shebang are supported.
+func ValidateCustom(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if len(data) > MaxCustomSize {
+		return ErrCustomUserDataTooLarge
+	}
+
+	switch {
+	case IsScript(data):
+		return nil
+	case IsCloudConfig(data):
+		m := make(map[string]any)
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("%w: %s", ErrCustomUserDataYAML, err.Error())
+		}
+		return nil
+	default:
+		return ErrCustomUserDataType
+	}
+}
+
+// multipartUserData creates a multipart MIME cloud-init document with the generated cloud-config
+// as the first part and the custom user data as the second part.
+func multipartUserData(generated, custom []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("Content-Type: multipart/mixed; boundary=\"" + multipartBoundary + "\"\n")
+	buffer.WriteString("MIME-Version: 1.0\n\n")
+
+	writer := multipart.NewWriter(&buffer)
+	if err := writer.SetBoundary(multipartBoundary); err != nil {
+		return nil, fmt.Errorf("cannot set boundary: %w", err)
+	}
+
+	if err := writePart(writer, "text/cloud-config", "provisioning.yaml", generated, false); err != nil {
+		return nil, err
+	}
+	if IsScript(custom) {
+		if err := writePart(writer, "text/x-shellscript", "user-script.sh", custom, false); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := writePart(writer, "text/cloud-config", "user-data.yaml", custom, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("cannot close multipart: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+func writePart(writer *multipart.Writer, contentType, filename string, content []byte, merge bool) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType+"; charset=\"utf-8\"")
+	header.Set("MIME-Version", "1.0")
+	header.Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	if merge {
+		header.Set("Merge-Type", mergeType)
+	}
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("cannot create part %s: %w", filename, err)
+	}
+	if _, err = part.Write(content); err != nil {
+		return fmt.Errorf("cannot write part %s: %w", filename, err)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"context"
 	_ "embed"
+	"encoding/base64"
 	"fmt"
 	"text/template"
 
@@ -33,6 +34,11 @@ type UserData struct {
 
 	// InsightsTags renders a first-boot script which populates /etc/insights-client/tags.yaml
 	InsightsTags bool
+
+	// Custom is an optional user-provided cloud-config or shell script, see ValidateCustom.
+	// Cloud-init platforms receive it merged with the generated cloud-config as a multipart
+	// document, GCP startup script executes custom scripts.
+	Custom []byte
 }
 
 func (ud UserData) IsAWS() bool {
@@ -47,6 +53,15 @@ func (ud UserData) IsGCP() bool {
 	return ud.Type == models.ProviderTypeGCP
 }
 
+// CustomScriptBase64 returns the custom user data encoded in base64 when it is a shell script,
+// empty string otherwise. The encoding makes it safe to embed the script in a heredoc.
+func (ud UserData) CustomScriptBase64() string {
+	if IsScript(ud.Custom) {
+		return base64.StdEncoding.EncodeToString(ud.Custom)
+	}
+	return ""
+}
+
 //go:embed cloud-init.goyaml
 var cloudinitBuffer []byte
 var cloudinitTemplate *template.Template
@@ -90,6 +105,14 @@ func GenerateUserData(ctx context.Context, userData *UserData) ([]byte, error) {
 	}
 
 	udBytes := buffer.Bytes()
-	logger.Trace().Bytes("payload", udBytes).Msg("Generated userdata")
+	if len(userData.Custom) > 0 && userData.Type != models.ProviderTypeGCP {
+		udBytes, err = multipartUserData(udBytes, userData.Custom)
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate user data: %w", err)
+		}
+	}
+
+	// the payload can contain secrets of the custom user data
+	logger.Trace().Int("size", len(udBytes)).Msg("Generated userdata")
 	return udBytes, nil
 }
@@ -2,6 +2,7 @@ package userdata
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"regexp"
 	"strings"
@@ -141,3 +142,87 @@ power_state:
 	require.NoError(t, validateYAML(userData))
 	assert.Equal(t, expected, strings.Trim(trimRe.ReplaceAllString(string(userData), "\n"), "\n"))
 }
+
+func TestGenerateAWSCustomCloudConfig(t *testing.T) {
+	userDataInput := UserData{
+		Type:   models.ProviderTypeAWS,
+		Custom: []byte("#cloud-config\npackages:\n- vim\n"),
+	}
+	userData, err := GenerateUserData(context.Background(), &userDataInput)
+	require.NoError(t, err)
+	expected := "Content-Type: multipart/mixed; boundary=\"==PROVISIONING-USER-DATA==\"\n" +
+		"MIME-Version: 1.0\n" +
+		"--==PROVISIONING-USER-DATA==\r\n" +
+		"Content-Disposition: attachment; filename=\"provisioning.yaml\"\r\n" +
+		"Content-Type: text/cloud-config; charset=\"utf-8\"\r\n" +
+		"Mime-Version: 1.0\r\n" +
+		"\r\n" +
+		"#cloud-config\n\r\n" +
+		"--==PROVISIONING-USER-DATA==\r\n" +
+		"Content-Disposition: attachment; filename=\"user-data.yaml\"\r\n" +
+		"Content-Type: text/cloud-config; charset=\"utf-8\"\r\n" +
+		"Merge-Type: list(append)+dict(recurse_array)+str()\r\n" +
+		"Mime-Version: 1.0\r\n" +
+		"\r\n" +
+		"#cloud-config\npackages:\n- vim\n\r\n" +
+		"--==PROVISIONING-USER-DATA==--\r\n"
+
+	assert.Equal(t, expected, trimRe.ReplaceAllString(string(userData), "\n"))
+}
+
+func TestGenerateAzureCustomScript(t *testing.T) {
+	userDataInput := UserData{
+		Type:   models.ProviderTypeAzure,
+		Custom: []byte("#!/bin/sh\necho hello\n"),
+	}
+	userData, err := GenerateUserData(context.Background(), &userDataInput)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(userData), "Content-Type: text/x-shellscript; charset=\"utf-8\"\r\n")
+	assert.Contains(t, string(userData), "#!/bin/sh\necho hello\n")
+	assert.NotContains(t, string(userData), "Merge-Type")
+}
+
+func TestGenerateGCPCustomScript(t *testing.T) {
+	userDataInput := UserData{
+		Type:   models.ProviderTypeGCP,
+		Custom: []byte("#!/bin/sh\necho hello"),
+	}
+	userData, err := GenerateUserData(context.Background(), &userDataInput)
+	require.NoError(t, err)
+	expected := `#! /bin/bash
+# startup scripts run on every boot, the user script only on the first one
+if [ ! -e /var/lib/provisioning/user-script ]; then
+mkdir -p -m 0700 /var/lib/provisioning
+base64 -d > /var/lib/provisioning/user-script <<'PROVISIONING_USER_SCRIPT_EOF'
+IyEvYmluL3NoCmVjaG8gaGVsbG8=
+PROVISIONING_USER_SCRIPT_EOF
+chmod 0700 /var/lib/provisioning/user-script
+/var/lib/provisioning/user-script
+fi
+exit 0`
+
+	assert.Equal(t, expected, strings.Trim(trimRe.ReplaceAllString(string(userData), "\n"), "\n"))
+}
+
+func TestGenerateGCPCustomScriptDelimiter(t *testing.T) {
+	script := "#!/bin/sh\ncat <<'PROVISIONING_USER_SCRIPT_EOF'\nhello\nPROVISIONING_USER_SCRIPT_EOF\n"
+	userDataInput := UserData{
+		Type:   models.ProviderTypeGCP,
+		Custom: []byte(script),
+	}
+	userData, err := GenerateUserData(context.Background(), &userDataInput)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, strings.Count(string(userData), "PROVISIONING_USER_SCRIPT_EOF"), "Expected only the generated heredoc delimiters")
+	assert.Contains(t, string(userData), base64.StdEncoding.EncodeToString([]byte(script)))
+}
+
+func TestValidateCustom(t *testing.T) {
+	require.NoError(t, ValidateCustom(nil))
+	require.NoError(t, ValidateCustom([]byte("#!/bin/bash\necho hi")))
+	require.NoError(t, ValidateCustom([]byte("#cloud-config\npackages:\n- vim")))
+	require.ErrorIs(t, ValidateCustom([]byte("echo hi")), ErrCustomUserDataType)
+	require.ErrorIs(t, ValidateCustom([]byte("#cloud-config\npackages: [")), ErrCustomUserDataYAML)
+	require.ErrorIs(t, ValidateCustom([]byte("#!"+strings.Repeat("x", MaxCustomSize))), ErrCustomUserDataTooLarge)
+}
@@ -4,4 +4,11 @@ import (
 	"errors"
 )
 
-var ErrJobNotFound = errors.New("job not found")
+var (
+	ErrJobNotFound   = errors.New("job not found")
+	ErrWorkerStopped = errors.New("worker was stopped")
+
+	// ErrPermanent can be wrapped by handler errors which cannot be fixed by retrying, such jobs
+	// are moved to the dead-letter queue right away.
+	ErrPermanent = errors.New("permanent job error")
+)
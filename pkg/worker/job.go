@@ -3,6 +3,7 @@ package worker
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
@@ -22,7 +23,15 @@ func init() {
 
 type JobType string
 
-type JobHandler func(ctx context.Context, job *Job)
+// JobHandler processes a job. Returned error means the job failed, it is retried with exponential
+// backoff when there are retries left, otherwise it is moved into the dead-letter queue. Handlers
+// which report failures by other means (e.g. reservation status) should return nil.
+type JobHandler func(ctx context.Context, job *Job) error
+
+// CancelHandler is called right before a job is dispatched to its handler. It returns true when
+// the job was cancelled or it must not run again after it was redelivered, and it was already
+// taken care of. The job is then not dispatched.
+type CancelHandler func(ctx context.Context, job *Job) bool
 
 type Job struct {
 	// Random UUID for logging and tracing. It is generated randomly by Enqueue function when blank.
@@ -45,6 +54,16 @@ type Job struct {
 
 	// Job arguments.
 	Args any
+
+	// Time when the job should be dispatched, zero value or time in the past means immediately.
+	RunAt time.Time
+
+	// Maximum number of retries of a failed job, zero means the worker default (WORKER_MAX_RETRIES)
+	// and NoRetries disables retries. A pointer cannot be used, gob does not transmit zero values.
+	MaxRetries int
+
+	// Current attempt starting from 1, set by the worker. Redelivered jobs count as failed attempts.
+	Attempt int
 }
 
 var ErrHandlerNotFound = errors.New("handler not registered")
@@ -60,6 +79,9 @@ type JobWorker interface {
 	// RegisterHandler registers an event listener for a particular type with an associated handler.
 	RegisterHandler(JobType, JobHandler, any)
 
+	// RegisterCancelHandler registers a function which checks every job for cancellation before it is dispatched.
+	RegisterCancelHandler(CancelHandler)
+
 	// DequeueLoop starts one or more goroutines to dispatch incoming jobs.
 	DequeueLoop(ctx context.Context)
 
@@ -81,6 +103,9 @@ type Stats struct {
 
 	// Number of jobs currently being processed. Local value - each client has its own number.
 	InFlight int64
+
+	// Number of jobs in the dead-letter queue which failed all attempts. This is a global value.
+	DeadJobs uint64
 }
 
 func initJobContext(origCtx context.Context, job *Job) (context.Context, *zerolog.Logger, trace.Span) {
@@ -2,7 +2,11 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/google/uuid"
@@ -13,13 +17,27 @@ import (
 
 type MemoryWorker struct {
 	handlers map[JobType]JobHandler
+	cancel   CancelHandler
 	todo     chan *Job
+
+	// number of jobs which failed all attempts (must be used via atomic functions)
+	dead uint64
+
+	// closed by Stop, pending sends of delayed jobs are abandoned
+	done chan struct{}
+
+	// timers of delayed jobs, protected by the mutex
+	timersMu sync.Mutex
+	timers   map[uuid.UUID]*time.Timer
+	stopped  bool
 }
 
 func NewMemoryClient() *MemoryWorker {
 	return &MemoryWorker{
 		handlers: make(map[JobType]JobHandler),
 		todo:     make(chan *Job),
+		done:     make(chan struct{}),
+		timers:   make(map[uuid.UUID]*time.Timer),
 	}
 }
 
@@ -27,6 +45,10 @@ func (w *MemoryWorker) RegisterHandler(jtype JobType, handler JobHandler, _ any)
 	w.handlers[jtype] = handler
 }
 
+func (w *MemoryWorker) RegisterCancelHandler(handler CancelHandler) {
+	w.cancel = handler
+}
+
 func (w *MemoryWorker) Enqueue(ctx context.Context, job *Job) error {
 	var err error
 	if job == nil {
@@ -52,12 +74,56 @@ func (w *MemoryWorker) Enqueue(ctx context.Context, job *Job) error {
 		otel.GetTextMapPropagator().Inject(ctx, job.TraceContext)
 	}
 
-	w.todo <- job
-	return nil
+	if delay := time.Until(job.RunAt); delay > 0 {
+		logger.Info().Time("run_at", job.RunAt).Msg("Scheduled job via memory")
+		w.schedule(job, delay)
+		return nil
+	}
+
+	select {
+	case w.todo <- job:
+		return nil
+	case <-w.done:
+		return fmt.Errorf("unable to enqueue job: %w", ErrWorkerStopped)
+	}
+}
+
+// schedule sends the job to the queue after the delay unless the worker was stopped. The lock
+// is not held while sending, the send blocks until the job is dequeued.
+func (w *MemoryWorker) schedule(job *Job, delay time.Duration) {
+	w.timersMu.Lock()
+	defer w.timersMu.Unlock()
+
+	w.timers[job.ID] = time.AfterFunc(delay, func() {
+		w.timersMu.Lock()
+		delete(w.timers, job.ID)
+		stopped := w.stopped
+		w.timersMu.Unlock()
+
+		if stopped {
+			return
+		}
+		select {
+		case w.todo <- job:
+		case <-w.done:
+		}
+	})
 }
 
 func (w *MemoryWorker) Stop(_ context.Context) {
-	close(w.todo)
+	w.timersMu.Lock()
+	defer w.timersMu.Unlock()
+
+	if w.stopped {
+		return
+	}
+
+	// scheduled jobs are lost, memory worker is not durable
+	for _, timer := range w.timers {
+		timer.Stop()
+	}
+	w.stopped = true
+	close(w.done)
 }
 
 func (w *MemoryWorker) DequeueLoop(ctx context.Context) {
@@ -66,8 +132,13 @@ func (w *MemoryWorker) DequeueLoop(ctx context.Context) {
 }
 
 func (w *MemoryWorker) dequeueLoop(ctx context.Context) {
-	for job := range w.todo {
-		w.processJob(ctx, job)
+	for {
+		select {
+		case job := <-w.todo:
+			w.processJob(ctx, job)
+		case <-w.done:
+			return
+		}
 	}
 }
 
@@ -77,20 +148,52 @@ func (w *MemoryWorker) processJob(origCtx context.Context, job *Job) {
 		return
 	}
 
+	job.Attempt++
 	ctx, logger, span := initJobContext(origCtx, job)
 	defer span.End()
-	logger.Info().Interface("job_args", job.Args).Msgf("Dequeued job from memory")
+	logger.Info().Interface("job_args", job.Args).Int("attempt", job.Attempt).Msgf("Dequeued job from memory")
+
+	if w.cancel != nil && w.cancel(ctx, job) {
+		logger.Info().Msg("Job was cancelled, skipping")
+		return
+	}
 
-	if h, ok := w.handlers[job.Type]; ok {
-		cCtx, cFunc := context.WithTimeout(ctx, config.Worker.Timeout)
-		defer cFunc()
-		h(cCtx, job)
-	} else {
+	h, ok := w.handlers[job.Type]
+	if !ok {
 		span.SetStatus(codes.Error, "worker has not found handler for a job type")
-		logger.Warn().Msgf("Memory worker handler not found for job type: %s", job.Type)
+		logger.Error().Msgf("Memory worker handler not found for job type: %s, dropping it", job.Type)
+		atomic.AddUint64(&w.dead, 1)
+		return
 	}
+
+	err := w.runHandler(ctx, h, job)
+	switch {
+	case err == nil:
+		return
+	case errors.Is(err, ErrPermanent):
+		span.SetStatus(codes.Error, "job failed permanently")
+		logger.Error().Err(err).Int("attempt", job.Attempt).Msg("Job failed permanently, dropping it")
+		atomic.AddUint64(&w.dead, 1)
+	case job.canRetry():
+		// the dequeue loop is not blocked, the job is sent to the queue again after the delay
+		delay := retryDelay(job.Attempt)
+		logger.Warn().Err(err).Int("attempt", job.Attempt).Msgf("Job failed, retrying in %s", delay)
+		w.schedule(job, delay)
+	default:
+		span.SetStatus(codes.Error, "job failed all attempts")
+		logger.Error().Err(err).Int("attempt", job.Attempt).Msg("Job failed all attempts, dropping it")
+		atomic.AddUint64(&w.dead, 1)
+	}
+}
+
+func (w *MemoryWorker) runHandler(ctx context.Context, h JobHandler, job *Job) error {
+	cCtx, cFunc := context.WithTimeout(ctx, config.Worker.Timeout)
+	defer cFunc()
+	return h(cCtx, job)
 }
 
 func (w *MemoryWorker) Stats(_ context.Context) (Stats, error) {
-	return Stats{}, nil
+	return Stats{
+		DeadJobs: atomic.LoadUint64(&w.dead),
+	}, nil
 }
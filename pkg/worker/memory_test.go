@@ -0,0 +1,154 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAttempt struct {
+	Value   string
+	Attempt int
+}
+
+func newTestMemoryWorker(t *testing.T, handler JobHandler) *MemoryWorker {
+	t.Helper()
+	setupWorkerConfig(t)
+
+	w := NewMemoryClient()
+	w.RegisterHandler(testJobType, handler, testArgs{})
+	w.DequeueLoop(context.Background())
+	t.Cleanup(func() { w.Stop(context.Background()) })
+	return w
+}
+
+func receive(t *testing.T, ch <-chan testAttempt) testAttempt {
+	t.Helper()
+	select {
+	case a := <-ch:
+		return a
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "job was not processed")
+		return testAttempt{}
+	}
+}
+
+func deadJobs(t *testing.T, w *MemoryWorker) uint64 {
+	t.Helper()
+	stats, err := w.Stats(context.Background())
+	require.NoError(t, err)
+	return stats.DeadJobs
+}
+
+func TestMemoryProcessJob(t *testing.T) {
+	ch := make(chan testAttempt, 1)
+	w := newTestMemoryWorker(t, func(ctx context.Context, job *Job) error {
+		ch <- testAttempt{Value: job.Args.(testArgs).Value, Attempt: job.Attempt}
+		return nil
+	})
+
+	err := w.Enqueue(context.Background(), &Job{Type: testJobType, Args: testArgs{Value: "test"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, testAttempt{Value: "test", Attempt: 1}, receive(t, ch))
+	assert.Zero(t, deadJobs(t, w))
+}
+
+func TestMemoryRetryDoesNotBlock(t *testing.T) {
+	ch := make(chan testAttempt, 10)
+	w := newTestMemoryWorker(t, func(ctx context.Context, job *Job) error {
+		value := job.Args.(testArgs).Value
+		ch <- testAttempt{Value: value, Attempt: job.Attempt}
+		if value == "failing" {
+			return errTest
+		}
+		return nil
+	})
+
+	err := w.Enqueue(context.Background(), &Job{Type: testJobType, Args: testArgs{Value: "failing"}})
+	require.NoError(t, err)
+	assert.Equal(t, testAttempt{Value: "failing", Attempt: 1}, receive(t, ch))
+
+	err = w.Enqueue(context.Background(), &Job{Type: testJobType, Args: testArgs{Value: "other"}})
+	require.NoError(t, err)
+	assert.Equal(t, testAttempt{Value: "other", Attempt: 1}, receive(t, ch), "Expected the job to be processed before the retry")
+}
+
+func TestMemoryRetry(t *testing.T) {
+	ch := make(chan testAttempt, 10)
+	w := newTestMemoryWorker(t, func(ctx context.Context, job *Job) error {
+		ch <- testAttempt{Attempt: job.Attempt}
+		return errTest
+	})
+	config.Worker.RetryBackoff = 10 * time.Millisecond
+
+	err := w.Enqueue(context.Background(), &Job{Type: testJobType, Args: testArgs{}})
+	require.NoError(t, err)
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		assert.Equal(t, testAttempt{Attempt: attempt}, receive(t, ch))
+	}
+	assert.Eventually(t, func() bool { return deadJobs(t, w) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, ch, "Expected no further attempts")
+}
+
+func TestMemoryNoRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		job    Job
+		jobErr error
+	}{
+		{"no retries", Job{Type: testJobType, Args: testArgs{}, MaxRetries: NoRetries}, errTest},
+		{"permanent error", Job{Type: testJobType, Args: testArgs{}}, ErrPermanent},
+		{"unknown job type", Job{Type: "unknown_job", Args: testArgs{}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := make(chan testAttempt, 10)
+			w := newTestMemoryWorker(t, func(ctx context.Context, job *Job) error {
+				calls <- testAttempt{Attempt: job.Attempt}
+				return tt.jobErr
+			})
+			config.Worker.RetryBackoff = time.Millisecond
+
+			err := w.Enqueue(context.Background(), &tt.job)
+			require.NoError(t, err)
+
+			assert.Eventually(t, func() bool { return deadJobs(t, w) == 1 }, 5*time.Second, 10*time.Millisecond)
+			assert.LessOrEqual(t, len(calls), 1, "Expected no retry")
+		})
+	}
+}
+
+func TestMemoryCancelledJob(t *testing.T) {
+	ch := make(chan testAttempt, 1)
+	w := newTestMemoryWorker(t, func(ctx context.Context, job *Job) error {
+		ch <- testAttempt{Value: job.Args.(testArgs).Value}
+		return nil
+	})
+	w.RegisterCancelHandler(func(ctx context.Context, job *Job) bool {
+		return job.Args.(testArgs).Value == "cancelled"
+	})
+
+	err := w.Enqueue(context.Background(), &Job{Type: testJobType, Args: testArgs{Value: "cancelled"}})
+	require.NoError(t, err)
+	err = w.Enqueue(context.Background(), &Job{Type: testJobType, Args: testArgs{Value: "other"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, testAttempt{Value: "other"}, receive(t, ch), "Expected the cancelled job not to be dispatched")
+}
+
+func TestMemoryStop(t *testing.T) {
+	w := newTestMemoryWorker(t, func(ctx context.Context, job *Job) error {
+		return nil
+	})
+	w.Stop(context.Background())
+
+	err := w.Enqueue(context.Background(), &Job{Type: testJobType, Args: testArgs{}})
+	require.ErrorIs(t, err, ErrWorkerStopped)
+}
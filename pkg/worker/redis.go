@@ -28,9 +28,27 @@ type RedisWorker struct {
 	// handler functions
 	handlers map[JobType]JobHandler
 
+	// cancellation check function
+	cancel CancelHandler
+
 	// queue for all jobs
 	queueName string
 
+	// list of jobs currently being processed by all workers
+	inFlightName string
+
+	// sorted set of in-flight jobs scored by their visibility deadline
+	deadlineName string
+
+	// sorted set of failed jobs scored by the time of their next attempt
+	delayedName string
+
+	// list of jobs which failed all attempts
+	deadName string
+
+	// hash of delivery counts per job id
+	attemptsName string
+
 	// close channel
 	closeCh chan interface{}
 
@@ -48,6 +66,11 @@ var _ JobWorker = &RedisWorker{}
 // NewRedisWorker creates new worker that keeps all jobs in a single queue (list), starts N polling
 // goroutines which fetch jobs from the queue and process them in the same goroutine. Use the
 // Stats function to track number of in-flight jobs.
+//
+// Jobs are atomically moved into an in-flight list when fetched and removed only after they were
+// processed. Jobs which are not processed within the visibility timeout (e.g. the worker crashed
+// or the pod was rescheduled) are moved back to the queue by a reaper goroutine. Failed jobs are
+// retried with exponential backoff, jobs which failed all attempts end up in the dead-letter list.
 func NewRedisWorker(address, username, password string, db int, queueName string, pollInterval time.Duration, concurrency int) (*RedisWorker, error) {
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     address,
@@ -60,6 +83,11 @@ func NewRedisWorker(address, username, password string, db int, queueName string
 		handlers:     make(map[JobType]JobHandler),
 		client:       rdb,
 		queueName:    queueName,
+		inFlightName: queueName + ":inflight",
+		deadlineName: queueName + ":deadline",
+		delayedName:  queueName + ":delayed",
+		deadName:     queueName + ":dead",
+		attemptsName: queueName + ":attempts",
 		pollInterval: pollInterval,
 		concurrency:  concurrency,
 		closeCh:      make(chan interface{}),
@@ -71,6 +99,10 @@ func (w *RedisWorker) RegisterHandler(jtype JobType, handler JobHandler, args an
 	gob.Register(args)
 }
 
+func (w *RedisWorker) RegisterCancelHandler(handler CancelHandler) {
+	w.cancel = handler
+}
+
 func (w *RedisWorker) Enqueue(ctx context.Context, job *Job) error {
 	var err error
 	if job == nil {
@@ -105,6 +137,17 @@ func (w *RedisWorker) Enqueue(ctx context.Context, job *Job) error {
 		return fmt.Errorf("unable to encode args: %w", err)
 	}
 
+	if job.RunAt.After(time.Now()) {
+		// delayed jobs are moved into the queue by the reaper when they are due
+		err = w.client.ZAdd(ctx, w.delayedName, redis.Z{Score: float64(job.RunAt.Unix()), Member: buffer.Bytes()}).Err()
+		if err != nil {
+			logger.Error().Err(err).Msg("Unable to schedule job in Redis")
+			return fmt.Errorf("unable to schedule job in Redis: %w", err)
+		}
+		logger.Info().Time("run_at", job.RunAt).Msg("Scheduled job successfully")
+		return nil
+	}
+
 	cmd := w.client.LPush(ctx, w.queueName, buffer.Bytes())
 	if cmd.Err() != nil {
 		logger.Error().Err(cmd.Err()).Msg("Unable to push job into Redis")
@@ -135,6 +178,8 @@ func (w *RedisWorker) DequeueLoop(ctx context.Context) {
 		w.loopWG.Add(1)
 		go w.dequeueLoop(ctx, i, w.concurrency)
 	}
+	w.loopWG.Add(1)
+	go w.reaperLoop(ctx)
 }
 
 func (w *RedisWorker) dequeueLoop(ctx context.Context, i, total int) {
@@ -175,7 +220,7 @@ func recoverAndLog(ctx context.Context) {
 func (w *RedisWorker) fetchJob(ctx context.Context) {
 	defer recoverAndLog(ctx)
 
-	res, err := w.client.BLPop(ctx, w.pollInterval, w.queueName).Result()
+	payload, err := w.client.BLMove(ctx, w.queueName, w.inFlightName, "RIGHT", "LEFT", w.pollInterval).Result()
 
 	if errors.Is(err, redis.Nil) {
 		// timeout occurred
@@ -186,8 +231,15 @@ func (w *RedisWorker) fetchJob(ctx context.Context) {
 		return
 	}
 
+	// when the worker crashes before the deadline is set, the reaper sets it
+	deadline := time.Now().Add(config.Worker.Visibility)
+	err = w.client.ZAdd(ctx, w.deadlineName, redis.Z{Score: float64(deadline.Unix()), Member: payload}).Err()
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Unable to set job visibility deadline")
+	}
+
 	var job Job
-	dec := gob.NewDecoder(strings.NewReader(res[1]))
+	dec := gob.NewDecoder(strings.NewReader(payload))
 	err = dec.Decode(&job)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().
@@ -195,17 +247,18 @@ func (w *RedisWorker) fetchJob(ctx context.Context) {
 			Str("job_id", job.ID.String()).
 			Str("job_type", job.Type.String()).
 			Interface("job_args", job.Args).
-			Msg("Unable to unmarshal job payload, skipping")
+			Msg("Unable to unmarshal job payload, moving to dead-letter queue")
+		w.finishJob(ctx, &job, payload, w.deadName, 0)
 		return
 	}
 
 	atomic.AddInt64(&w.inFlight, 1)
 	defer atomic.AddInt64(&w.inFlight, -1)
 
-	w.processJob(ctx, &job)
+	w.processJob(ctx, &job, payload)
 }
 
-func (w *RedisWorker) processJob(origCtx context.Context, job *Job) {
+func (w *RedisWorker) processJob(origCtx context.Context, job *Job, payload string) {
 	if job == nil {
 		return
 	}
@@ -213,34 +266,175 @@ func (w *RedisWorker) processJob(origCtx context.Context, job *Job) {
 	ctx, logger, span := initJobContext(origCtx, job)
 	defer span.End()
 	defer recoverAndLog(ctx)
-	logger.Info().Interface("job_args", job.Args).Msgf("Dequeued job from Redis")
-
-	if h, ok := w.handlers[job.Type]; ok {
-		cCtx, cFunc := context.WithTimeout(ctx, config.Worker.Timeout)
-		defer func() {
-			if c := cCtx.Err(); c != nil {
-				zerolog.Ctx(ctx).Error().Err(c).Msg("Job was either cancelled or timeout occurred")
-			}
-			cFunc()
-		}()
-		metrics.ObserveBackgroundJobDuration(job.Type.String(), func() {
-			h(cCtx, job)
-		})
-	} else {
+
+	attempt, err := w.client.HIncrBy(ctx, w.attemptsName, job.ID.String(), 1).Result()
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to increase job attempt counter")
+		attempt = 1
+	}
+	job.Attempt = int(attempt)
+	logger.Info().Interface("job_args", job.Args).Int("attempt", job.Attempt).Msgf("Dequeued job from Redis")
+
+	// redelivered jobs are counted as failed attempts
+	if job.Attempt > job.maxRetries()+1 {
+		span.SetStatus(codes.Error, "job exceeded number of attempts")
+		logger.Error().Int("attempt", job.Attempt).Msg("Job exceeded number of attempts, moving to dead-letter queue")
+		w.finishJob(ctx, job, payload, w.deadName, 0)
+		return
+	}
+
+	if w.cancel != nil && w.cancel(ctx, job) {
+		logger.Info().Msg("Job was cancelled, skipping")
+		w.finishJob(ctx, job, payload, "", 0)
+		return
+	}
+
+	h, ok := w.handlers[job.Type]
+	if !ok {
 		// handler not found
 		span.SetStatus(codes.Error, "worker has not found handler for a job type")
-		zerolog.Ctx(ctx).Warn().Msgf("Redis worker handler not found for job type: %s", job.Type)
+		logger.Warn().Msgf("Redis worker handler not found for job type: %s", job.Type)
+		w.finishJob(ctx, job, payload, w.deadName, 0)
+		return
+	}
+
+	cCtx, cFunc := context.WithTimeout(ctx, config.Worker.Timeout)
+	defer func() {
+		if c := cCtx.Err(); c != nil {
+			zerolog.Ctx(ctx).Error().Err(c).Msg("Job was either cancelled or timeout occurred")
+		}
+		cFunc()
+	}()
+	var jobErr error
+	metrics.ObserveBackgroundJobDuration(job.Type.String(), func() {
+		jobErr = h(cCtx, job)
+	})
+
+	switch {
+	case jobErr == nil:
+		w.finishJob(ctx, job, payload, "", 0)
+	case errors.Is(jobErr, ErrPermanent):
+		span.SetStatus(codes.Error, "job failed permanently")
+		logger.Error().Err(jobErr).Int("attempt", job.Attempt).Msg("Job failed permanently, moving to dead-letter queue")
+		w.finishJob(ctx, job, payload, w.deadName, 0)
+	case job.canRetry():
+		delay := retryDelay(job.Attempt)
+		logger.Warn().Err(jobErr).Int("attempt", job.Attempt).Msgf("Job failed, retrying in %s", delay)
+		w.finishJob(ctx, job, payload, w.delayedName, delay)
+	default:
+		span.SetStatus(codes.Error, "job failed all attempts")
+		logger.Error().Err(jobErr).Int("attempt", job.Attempt).Msg("Job failed all attempts, moving to dead-letter queue")
+		w.finishJob(ctx, job, payload, w.deadName, 0)
+	}
+}
+
+// finishJob atomically removes the job from the in-flight list and optionally moves it to the
+// delayed set (retry) or to the dead-letter list. Attempt counter is kept for retried jobs.
+func (w *RedisWorker) finishJob(ctx context.Context, job *Job, payload, target string, delay time.Duration) {
+	// use a fresh context, the job context can be already expired
+	fCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.pollInterval)
+	defer cancel()
+
+	_, err := w.client.TxPipelined(fCtx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(fCtx, w.inFlightName, 1, payload)
+		pipe.ZRem(fCtx, w.deadlineName, payload)
+		switch target {
+		case w.delayedName:
+			pipe.ZAdd(fCtx, w.delayedName, redis.Z{Score: float64(time.Now().Add(delay).Unix()), Member: payload})
+		case w.deadName:
+			pipe.LPush(fCtx, w.deadName, payload)
+			pipe.HDel(fCtx, w.attemptsName, job.ID.String())
+		default:
+			pipe.HDel(fCtx, w.attemptsName, job.ID.String())
+		}
+		return nil
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Unable to finish job in Redis, it will be redelivered")
+	}
+}
+
+// reapScript moves in-flight jobs with expired visibility deadline and delayed jobs which are due
+// back to the queue. In-flight jobs without deadline (worker crashed right after fetching) get one.
+//
+// KEYS: queue, in-flight list, deadline set, delayed set
+// ARGV: current time, deadline for jobs without one, maximum number of jobs moved
+var reapScript = redis.NewScript(`
+local items = redis.call('LRANGE', KEYS[2], 0, -1)
+for _, item in ipairs(items) do
+	redis.call('ZADD', KEYS[3], 'NX', ARGV[2], item)
+end
+local moved = 0
+local expired = redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+for _, item in ipairs(expired) do
+	redis.call('ZREM', KEYS[3], item)
+	if redis.call('LREM', KEYS[2], 1, item) > 0 then
+		redis.call('RPUSH', KEYS[1], item)
+		moved = moved + 1
+	end
+end
+local due = redis.call('ZRANGEBYSCORE', KEYS[4], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+for _, item in ipairs(due) do
+	redis.call('ZREM', KEYS[4], item)
+	redis.call('RPUSH', KEYS[1], item)
+end
+return {moved, #due}
+`)
+
+// maximum number of jobs moved by a single reaper run
+const reapBatchSize = 100
+
+func (w *RedisWorker) reaperLoop(ctx context.Context) {
+	defer w.loopWG.Done()
+	logger := zerolog.Ctx(ctx)
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.closeCh:
+			logger.Info().Msg("Shutting down a Redis reaper (stop)")
+			return
+		case <-ctx.Done():
+			logger.Info().Msg("Shutting down a Redis reaper (cancel)")
+			return
+		case <-ticker.C:
+			w.reap(ctx)
+		}
+	}
+}
+
+func (w *RedisWorker) reap(ctx context.Context) {
+	defer recoverAndLog(ctx)
+	logger := zerolog.Ctx(ctx)
+
+	now := time.Now()
+	keys := []string{w.queueName, w.inFlightName, w.deadlineName, w.delayedName}
+	result, err := reapScript.Run(ctx, w.client, keys, now.Unix(), now.Add(config.Worker.Visibility).Unix(), reapBatchSize).Int64Slice()
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to reap Redis job queue")
+		return
+	}
+
+	if len(result) == 2 && (result[0] > 0 || result[1] > 0) {
+		logger.Info().Msgf("Redelivered %d expired and %d delayed job(s)", result[0], result[1])
 	}
 }
 
 func (w *RedisWorker) Stats(ctx context.Context) (Stats, error) {
-	count, err := w.client.LLen(ctx, w.queueName).Result()
+	var queueLen, deadLen *redis.IntCmd
+	_, err := w.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		queueLen = pipe.LLen(ctx, w.queueName)
+		deadLen = pipe.LLen(ctx, w.deadName)
+		return nil
+	})
 	if err != nil {
 		return Stats{}, fmt.Errorf("unable to get queue len: %w", err)
 	}
 
 	return Stats{
-		EnqueuedJobs: uint64(count),
+		EnqueuedJobs: uint64(queueLen.Val()),
 		InFlight:     atomic.LoadInt64(&w.inFlight),
+		DeadJobs:     uint64(deadLen.Val()),
 	}, nil
 }
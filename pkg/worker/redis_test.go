@@ -0,0 +1,317 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJobType JobType = "test_job"
+
+type testArgs struct {
+	Value string
+}
+
+var errTest = errors.New("test error")
+
+// setupWorkerConfig sets worker configuration for the test and restores it afterwards.
+func setupWorkerConfig(t *testing.T) {
+	t.Helper()
+	orig := *config.Worker
+	t.Cleanup(func() { *config.Worker = orig })
+
+	config.Worker.Timeout = time.Second
+	config.Worker.Visibility = time.Minute
+	config.Worker.MaxRetries = 2
+	config.Worker.RetryBackoff = 10 * time.Second
+}
+
+func newTestRedisWorker(t *testing.T, handler JobHandler) (*RedisWorker, *miniredis.Miniredis) {
+	t.Helper()
+	setupWorkerConfig(t)
+
+	mr := miniredis.RunT(t)
+	w, err := NewRedisWorker(mr.Addr(), "", "", 0, "test-queue", 100*time.Millisecond, 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.client.Close() })
+
+	if handler != nil {
+		w.RegisterHandler(testJobType, handler, testArgs{})
+	}
+	return w, mr
+}
+
+func enqueueTestJob(t *testing.T, w *RedisWorker, job *Job) string {
+	t.Helper()
+	err := w.Enqueue(context.Background(), job)
+	require.NoError(t, err)
+	return job.ID.String()
+}
+
+func listLen(t *testing.T, mr *miniredis.Miniredis, key string) int {
+	t.Helper()
+	if !mr.Exists(key) {
+		return 0
+	}
+	items, err := mr.List(key)
+	require.NoError(t, err)
+	return len(items)
+}
+
+func setLen(t *testing.T, mr *miniredis.Miniredis, key string) int {
+	t.Helper()
+	if !mr.Exists(key) {
+		return 0
+	}
+	members, err := mr.ZMembers(key)
+	require.NoError(t, err)
+	return len(members)
+}
+
+// makeDelayedDue sets the score of all delayed jobs to the past, returns the original scores.
+func makeDelayedDue(t *testing.T, w *RedisWorker, mr *miniredis.Miniredis) []float64 {
+	t.Helper()
+	members, err := mr.ZMembers(w.delayedName)
+	require.NoError(t, err)
+
+	scores := make([]float64, 0, len(members))
+	for _, member := range members {
+		score, err := mr.ZScore(w.delayedName, member)
+		require.NoError(t, err)
+		scores = append(scores, score)
+		_, err = mr.ZAdd(w.delayedName, 0, member)
+		require.NoError(t, err)
+	}
+	return scores
+}
+
+func TestRedisProcessJob(t *testing.T) {
+	var received []*Job
+	var w *RedisWorker
+	var mr *miniredis.Miniredis
+	w, mr = newTestRedisWorker(t, func(ctx context.Context, job *Job) error {
+		assert.Equal(t, 1, listLen(t, mr, w.inFlightName), "Expected the job in the in-flight list")
+		assert.Equal(t, 1, setLen(t, mr, w.deadlineName), "Expected the job visibility deadline")
+		assert.Equal(t, 0, listLen(t, mr, w.queueName), "Expected the job removed from the queue")
+		received = append(received, job)
+		return nil
+	})
+	enqueueTestJob(t, w, &Job{Type: testJobType, Args: testArgs{Value: "test"}})
+
+	stats, err := w.Stats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), stats.EnqueuedJobs)
+
+	w.fetchJob(context.Background())
+
+	require.Len(t, received, 1)
+	assert.Equal(t, testArgs{Value: "test"}, received[0].Args)
+	assert.Equal(t, 1, received[0].Attempt)
+	assert.Equal(t, 0, listLen(t, mr, w.queueName))
+	assert.Equal(t, 0, listLen(t, mr, w.inFlightName))
+	assert.Equal(t, 0, setLen(t, mr, w.deadlineName))
+	assert.False(t, mr.Exists(w.attemptsName), "Expected attempts to be cleared")
+
+	stats, err = w.Stats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Stats{}, stats)
+}
+
+func TestRedisRetry(t *testing.T) {
+	var attempts []int
+	w, mr := newTestRedisWorker(t, func(ctx context.Context, job *Job) error {
+		attempts = append(attempts, job.Attempt)
+		return errTest
+	})
+	id := enqueueTestJob(t, w, &Job{Type: testJobType, Args: testArgs{}})
+
+	// first attempt and retry delayed by the backoff
+	before := time.Now()
+	w.fetchJob(context.Background())
+	assert.Equal(t, 0, listLen(t, mr, w.inFlightName))
+	assert.Equal(t, 0, setLen(t, mr, w.deadlineName))
+	require.Equal(t, 1, setLen(t, mr, w.delayedName), "Expected the job to be delayed")
+	assert.Equal(t, "1", mr.HGet(w.attemptsName, id))
+
+	// delayed jobs are not moved to the queue before they are due
+	w.reap(context.Background())
+	assert.Equal(t, 0, listLen(t, mr, w.queueName))
+
+	scores := makeDelayedDue(t, w, mr)
+	assert.InDelta(t, float64(before.Add(10*time.Second).Unix()), scores[0], 1)
+	w.reap(context.Background())
+	require.Equal(t, 1, listLen(t, mr, w.queueName), "Expected the due job in the queue")
+
+	// second attempt with doubled backoff
+	before = time.Now()
+	w.fetchJob(context.Background())
+	scores = makeDelayedDue(t, w, mr)
+	require.Len(t, scores, 1)
+	assert.InDelta(t, float64(before.Add(20*time.Second).Unix()), scores[0], 1)
+	w.reap(context.Background())
+
+	// the last attempt moves the job to the dead-letter list
+	w.fetchJob(context.Background())
+	assert.Equal(t, []int{1, 2, 3}, attempts)
+	assert.Equal(t, 0, listLen(t, mr, w.queueName))
+	assert.Equal(t, 0, listLen(t, mr, w.inFlightName))
+	assert.Equal(t, 0, setLen(t, mr, w.delayedName))
+	assert.Equal(t, 1, listLen(t, mr, w.deadName))
+	assert.False(t, mr.Exists(w.attemptsName), "Expected attempts to be cleared")
+
+	stats, err := w.Stats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), stats.DeadJobs)
+}
+
+func TestRedisNoRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		job    Job
+		jobErr error
+	}{
+		{"no retries", Job{Type: testJobType, Args: testArgs{}, MaxRetries: NoRetries}, errTest},
+		{"permanent error", Job{Type: testJobType, Args: testArgs{}}, ErrPermanent},
+		{"unknown job type", Job{Type: "unknown_job", Args: testArgs{}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			w, mr := newTestRedisWorker(t, func(ctx context.Context, job *Job) error {
+				calls++
+				return tt.jobErr
+			})
+			enqueueTestJob(t, w, &tt.job)
+
+			w.fetchJob(context.Background())
+
+			assert.LessOrEqual(t, calls, 1)
+			assert.Equal(t, 0, listLen(t, mr, w.inFlightName))
+			assert.Equal(t, 0, setLen(t, mr, w.delayedName), "Expected no retry")
+			assert.Equal(t, 1, listLen(t, mr, w.deadName), "Expected the job in the dead-letter list")
+		})
+	}
+}
+
+func TestRedisCancelledJob(t *testing.T) {
+	calls := 0
+	w, mr := newTestRedisWorker(t, func(ctx context.Context, job *Job) error {
+		calls++
+		return nil
+	})
+	w.RegisterCancelHandler(func(ctx context.Context, job *Job) bool {
+		return true
+	})
+	enqueueTestJob(t, w, &Job{Type: testJobType, Args: testArgs{}})
+
+	w.fetchJob(context.Background())
+
+	assert.Zero(t, calls, "Expected the cancelled job not to be dispatched")
+	assert.Equal(t, 0, listLen(t, mr, w.inFlightName))
+	assert.Equal(t, 0, listLen(t, mr, w.deadName))
+}
+
+func TestRedisScheduledJob(t *testing.T) {
+	w, mr := newTestRedisWorker(t, func(ctx context.Context, job *Job) error {
+		return nil
+	})
+	enqueueTestJob(t, w, &Job{Type: testJobType, Args: testArgs{}, RunAt: time.Now().Add(time.Hour)})
+
+	assert.Equal(t, 0, listLen(t, mr, w.queueName))
+	require.Equal(t, 1, setLen(t, mr, w.delayedName), "Expected the job to be scheduled")
+
+	w.reap(context.Background())
+	assert.Equal(t, 0, listLen(t, mr, w.queueName), "Expected the job to wait until it is due")
+
+	makeDelayedDue(t, w, mr)
+	w.reap(context.Background())
+	assert.Equal(t, 1, listLen(t, mr, w.queueName))
+	assert.Equal(t, 0, setLen(t, mr, w.delayedName))
+}
+
+// moveToInFlight simulates a worker which fetched the job and crashed before processing it.
+func moveToInFlight(t *testing.T, w *RedisWorker) string {
+	t.Helper()
+	payload, err := w.client.LMove(context.Background(), w.queueName, w.inFlightName, "RIGHT", "LEFT").Result()
+	require.NoError(t, err)
+	return payload
+}
+
+func TestRedisReapStuckJob(t *testing.T) {
+	var attempts []int
+	w, mr := newTestRedisWorker(t, func(ctx context.Context, job *Job) error {
+		attempts = append(attempts, job.Attempt)
+		return nil
+	})
+	id := enqueueTestJob(t, w, &Job{Type: testJobType, Args: testArgs{}})
+
+	// the worker crashed before the deadline was set
+	payload := moveToInFlight(t, w)
+	mr.HSet(w.attemptsName, id, "1")
+	w.reap(context.Background())
+	assert.Equal(t, 1, listLen(t, mr, w.inFlightName), "Expected the job to stay in-flight")
+	deadline, err := mr.ZScore(w.deadlineName, payload)
+	require.NoError(t, err, "Expected the reaper to set the deadline")
+	assert.InDelta(t, float64(time.Now().Add(time.Minute).Unix()), deadline, 1)
+
+	// visibility timeout expired
+	_, err = mr.ZAdd(w.deadlineName, 0, payload)
+	require.NoError(t, err)
+	w.reap(context.Background())
+	assert.Equal(t, 0, listLen(t, mr, w.inFlightName))
+	assert.Equal(t, 0, setLen(t, mr, w.deadlineName))
+	require.Equal(t, 1, listLen(t, mr, w.queueName), "Expected the job to be redelivered")
+
+	// the redelivered job counts as a failed attempt
+	w.fetchJob(context.Background())
+	assert.Equal(t, []int{2}, attempts)
+	assert.Equal(t, 0, listLen(t, mr, w.inFlightName))
+	assert.Equal(t, 0, listLen(t, mr, w.deadName))
+}
+
+func TestRedisRedeliveryExhausted(t *testing.T) {
+	calls := 0
+	w, mr := newTestRedisWorker(t, func(ctx context.Context, job *Job) error {
+		calls++
+		return nil
+	})
+	id := enqueueTestJob(t, w, &Job{Type: testJobType, Args: testArgs{}, MaxRetries: NoRetries})
+
+	payload := moveToInFlight(t, w)
+	mr.HSet(w.attemptsName, id, "1")
+	_, err := mr.ZAdd(w.deadlineName, 0, payload)
+	require.NoError(t, err)
+	w.reap(context.Background())
+
+	w.fetchJob(context.Background())
+	assert.Zero(t, calls, "Expected the job not to be dispatched again")
+	assert.Equal(t, 1, listLen(t, mr, w.deadName), "Expected the job in the dead-letter list")
+	assert.False(t, mr.Exists(w.attemptsName), "Expected attempts to be cleared")
+}
+
+func TestRedisDequeueLoop(t *testing.T) {
+	done := make(chan testArgs, 1)
+	w, _ := newTestRedisWorker(t, func(ctx context.Context, job *Job) error {
+		done <- job.Args.(testArgs)
+		return nil
+	})
+	ctx := context.Background()
+	w.DequeueLoop(ctx)
+	defer w.Stop(ctx)
+
+	enqueueTestJob(t, w, &Job{Type: testJobType, Args: testArgs{Value: "loop"}})
+
+	select {
+	case args := <-done:
+		assert.Equal(t, "loop", args.Value)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "job was not processed")
+	}
+}
@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+)
+
+// maximum exponent of the backoff, the delay does not grow any further
+const maxBackoffExponent = 10
+
+// NoRetries can be set as MaxRetries of a job to disable retries.
+const NoRetries = -1
+
+// maxRetries returns the number of retries of the job.
+func (j *Job) maxRetries() int {
+	switch {
+	case j.MaxRetries < 0:
+		return 0
+	case j.MaxRetries > 0:
+		return j.MaxRetries
+	default:
+		return config.Worker.MaxRetries
+	}
+}
+
+// canRetry returns true when the current attempt is not the last one.
+func (j *Job) canRetry() bool {
+	return j.Attempt <= j.maxRetries()
+}
+
+// retryDelay returns exponential backoff delay after the given failed attempt (starting from 1).
+func retryDelay(attempt int) time.Duration {
+	exp := attempt - 1
+	if exp < 0 {
+		exp = 0
+	}
+	if exp > maxBackoffExponent {
+		exp = maxBackoffExponent
+	}
+	return config.Worker.RetryBackoff * time.Duration(1<<exp)
+}